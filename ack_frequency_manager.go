@@ -0,0 +1,66 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// The ack-eliciting threshold the peer uses before receiving the first ACK_FREQUENCY frame.
+const defaultAckElicitingThreshold = 1
+
+// We want to receive (roughly) this many ACKs per congestion window.
+const acksPerCongestionWindow = 4
+
+// The ackFrequencyManager decides when to send ACK_FREQUENCY frames.
+// When the congestion window is large, receiving an ACK for every other packet is not necessary,
+// so we ask the peer to send fewer ACKs.
+type ackFrequencyManager struct {
+	rttStats *utils.RTTStats
+	// the max_ack_delay advertised by the peer
+	maxAckDelay time.Duration
+
+	nextSeqNum uint64
+	threshold  uint64
+	lastSent   time.Time
+}
+
+func newAckFrequencyManager(rttStats *utils.RTTStats, maxAckDelay time.Duration) *ackFrequencyManager {
+	return &ackFrequencyManager{
+		rttStats:    rttStats,
+		maxAckDelay: maxAckDelay,
+		threshold:   defaultAckElicitingThreshold,
+	}
+}
+
+// GetFrame returns an ACK_FREQUENCY frame, if the ack-eliciting threshold should be updated.
+// In order to not send too many frames, the threshold is updated at most once per RTT.
+func (m *ackFrequencyManager) GetFrame(cwnd, maxDatagramSize protocol.ByteCount, now time.Time) *wire.AckFrequencyFrame {
+	if !m.lastSent.IsZero() && now.Sub(m.lastSent) < m.rttStats.SmoothedRTT() {
+		return nil
+	}
+	threshold := m.calculateThreshold(cwnd, maxDatagramSize)
+	if threshold == m.threshold {
+		return nil
+	}
+	m.threshold = threshold
+	m.lastSent = now
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        m.nextSeqNum,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    m.maxAckDelay,
+		ReorderingThreshold:   1,
+	}
+	m.nextSeqNum++
+	return f
+}
+
+func (m *ackFrequencyManager) calculateThreshold(cwnd, maxDatagramSize protocol.ByteCount) uint64 {
+	packetsPerAck := uint64(cwnd / (acksPerCongestionWindow * maxDatagramSize))
+	if packetsPerAck <= defaultAckElicitingThreshold+1 {
+		return defaultAckElicitingThreshold
+	}
+	return utils.MinUint64(packetsPerAck-1, protocol.MaxAckElicitingThreshold)
+}
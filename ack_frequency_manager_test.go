@@ -0,0 +1,72 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK Frequency Manager", func() {
+	const (
+		rtt                                = 100 * time.Millisecond
+		maxDatagramSize protocol.ByteCount = 1000
+	)
+
+	var (
+		m        *ackFrequencyManager
+		rttStats *utils.RTTStats
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(rtt, 0, time.Now())
+		Expect(rttStats.SmoothedRTT()).To(Equal(rtt))
+		m = newAckFrequencyManager(rttStats, 42*time.Millisecond)
+		now = time.Now()
+	})
+
+	It("doesn't send a frame if the congestion window is small", func() {
+		Expect(m.GetFrame(8*maxDatagramSize, maxDatagramSize, now)).To(BeNil())
+	})
+
+	It("asks for fewer ACKs when the congestion window grows", func() {
+		f := m.GetFrame(40*maxDatagramSize, maxDatagramSize, now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeZero())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(9))
+		Expect(f.RequestMaxAckDelay).To(Equal(42 * time.Millisecond))
+		Expect(f.ReorderingThreshold).To(BeEquivalentTo(1))
+	})
+
+	It("limits the ack-eliciting threshold", func() {
+		f := m.GetFrame(1000*maxDatagramSize, maxDatagramSize, now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(protocol.MaxAckElicitingThreshold))
+	})
+
+	It("sends at most one frame per RTT", func() {
+		Expect(m.GetFrame(40*maxDatagramSize, maxDatagramSize, now)).ToNot(BeNil())
+		Expect(m.GetFrame(80*maxDatagramSize, maxDatagramSize, now.Add(rtt/2))).To(BeNil())
+		f := m.GetFrame(80*maxDatagramSize, maxDatagramSize, now.Add(rtt))
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(19))
+	})
+
+	It("only sends a frame when the threshold changes", func() {
+		Expect(m.GetFrame(40*maxDatagramSize, maxDatagramSize, now)).ToNot(BeNil())
+		Expect(m.GetFrame(41*maxDatagramSize, maxDatagramSize, now.Add(2*rtt))).To(BeNil())
+	})
+
+	It("goes back to the default threshold when the congestion window shrinks", func() {
+		Expect(m.GetFrame(40*maxDatagramSize, maxDatagramSize, now)).ToNot(BeNil())
+		f := m.GetFrame(4*maxDatagramSize, maxDatagramSize, now.Add(rtt))
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(1))
+	})
+})
@@ -0,0 +1,63 @@
+package quic
+
+import (
+	"sync"
+	"time"
+)
+
+// antiReplay is a strike register for session ticket IDs.
+type antiReplay struct {
+	window time.Duration
+
+	mutex       sync.Mutex
+	seen        map[string]time.Time // ticket ID -> time when the ticket expires
+	nextCleanup time.Time
+}
+
+var _ AntiReplay = &antiReplay{}
+
+// NewAntiReplay creates an in-memory AntiReplay.
+// It rejects session tickets that were issued more than window ago,
+// as well as session tickets that were already used.
+// The ID of every accepted session ticket is stored until the ticket leaves the window,
+// so the memory usage grows with the number of 0-RTT connection attempts during the window.
+func NewAntiReplay(window time.Duration) AntiReplay {
+	return &antiReplay{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+func (a *antiReplay) Accept(ticketID []byte, issued time.Time) bool {
+	if len(ticketID) == 0 {
+		return false
+	}
+	now := time.Now()
+	expiry := issued.Add(a.window)
+	// Reject tickets that are too old, as well as tickets that claim to have been issued in the future.
+	// The latter would otherwise occupy memory for longer than the window.
+	if !expiry.After(now) || issued.After(now.Add(a.window)) {
+		return false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !now.Before(a.nextCleanup) {
+		a.cleanup(now)
+	}
+	if _, ok := a.seen[string(ticketID)]; ok {
+		return false
+	}
+	a.seen[string(ticketID)] = expiry
+	return true
+}
+
+func (a *antiReplay) cleanup(now time.Time) {
+	for id, expiry := range a.seen {
+		if !expiry.After(now) {
+			delete(a.seen, id)
+		}
+	}
+	a.nextCleanup = now.Add(a.window)
+}
@@ -0,0 +1,66 @@
+package quic
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Anti-Replay", func() {
+	const window = time.Minute
+	var a *antiReplay
+
+	BeforeEach(func() {
+		a = NewAntiReplay(window).(*antiReplay)
+	})
+
+	It("accepts a session ticket once", func() {
+		Expect(a.Accept([]byte("foobar"), time.Now())).To(BeTrue())
+		Expect(a.Accept([]byte("foobar"), time.Now())).To(BeFalse())
+		Expect(a.Accept([]byte("raboof"), time.Now())).To(BeTrue())
+	})
+
+	It("rejects session tickets without an ID", func() {
+		Expect(a.Accept(nil, time.Now())).To(BeFalse())
+	})
+
+	It("rejects session tickets issued outside of the window", func() {
+		Expect(a.Accept([]byte("foo"), time.Now().Add(-window-time.Second))).To(BeFalse())
+		Expect(a.Accept([]byte("bar"), time.Now().Add(window+time.Second))).To(BeFalse())
+		Expect(a.Accept([]byte("foo"), time.Now().Add(-window+time.Second))).To(BeTrue())
+		Expect(a.seen).To(HaveLen(1))
+	})
+
+	It("removes expired session tickets", func() {
+		Expect(a.Accept([]byte("foo"), time.Now().Add(-window+scaleDuration(10*time.Millisecond)))).To(BeTrue())
+		Expect(a.Accept([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(a.seen).To(HaveLen(2))
+		time.Sleep(scaleDuration(20 * time.Millisecond))
+		a.nextCleanup = time.Now()
+		Expect(a.Accept([]byte("baz"), time.Now())).To(BeTrue())
+		Expect(a.seen).To(HaveLen(2))
+		Expect(a.seen).To(HaveKey("bar"))
+		Expect(a.seen).To(HaveKey("baz"))
+		Expect(a.nextCleanup).To(BeTemporally("~", time.Now().Add(window), scaleDuration(10*time.Millisecond)))
+	})
+
+	It("accepts every session ticket once, when called concurrently", func() {
+		const num = 10
+		accepted := make(chan struct{}, num)
+		var wg sync.WaitGroup
+		wg.Add(num)
+		for i := 0; i < num; i++ {
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				if a.Accept([]byte("foobar"), time.Now()) {
+					accepted <- struct{}{}
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(accepted).To(HaveLen(1))
+	})
+})
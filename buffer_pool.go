@@ -2,6 +2,7 @@ package quic
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
@@ -13,6 +14,9 @@ type packetBuffer struct {
 	// It doesn't support concurrent use.
 	// It is > 1 when used for coalesced packet.
 	refCount int
+
+	// groBuffer is set if Data points into the buffer of a message coalesced by the kernel (UDP GRO).
+	groBuffer *groBuffer
 }
 
 // Split increases the refCount.
@@ -56,13 +60,56 @@ func (b *packetBuffer) Len() protocol.ByteCount {
 }
 
 func (b *packetBuffer) putBack() {
-	if cap(b.Data) != int(protocol.MaxPacketBufferSize) {
+	if b.groBuffer != nil {
+		b.groBuffer.Release()
+		b.groBuffer = nil
+		b.Data = nil
+		return
+	}
+	switch cap(b.Data) {
+	case int(protocol.MaxPacketBufferSize):
+		bufferPool.Put(b)
+	case int(protocol.MaxLargePacketBufferSize):
+		largeBufferPool.Put(b)
+	default:
 		panic("putPacketBuffer called with packet of wrong size!")
 	}
-	bufferPool.Put(b)
 }
 
-var bufferPool sync.Pool
+// The maximum size of a message received when the kernel coalesces datagrams (UDP GRO).
+// This is the maximum size of a UDP datagram.
+const maxGROMessageSize = 1<<16 - 1
+
+// A groBuffer is the buffer that a message is read into when the kernel coalesces datagrams (UDP GRO).
+// If the message was coalesced, the datagrams in the message use packet buffers pointing into this buffer,
+// so they don't need to be copied.
+// The datagrams might be handled by different sessions, so the reference counter is accessed atomically.
+type groBuffer struct {
+	Data []byte
+
+	refCount int32
+}
+
+// NewPacketBuffer returns a packet buffer for a datagram.
+// data must point into the buffer.
+func (b *groBuffer) NewPacketBuffer(data []byte) *packetBuffer {
+	atomic.AddInt32(&b.refCount, 1)
+	return &packetBuffer{Data: data, refCount: 1, groBuffer: b}
+}
+
+// Release decrements the reference counter.
+// The buffer is put back into the pool when it's not used by any packet buffer any more.
+func (b *groBuffer) Release() {
+	refCount := atomic.AddInt32(&b.refCount, -1)
+	if refCount < 0 {
+		panic("negative groBuffer refCount")
+	}
+	if refCount == 0 {
+		groBufferPool.Put(b)
+	}
+}
+
+var bufferPool, largeBufferPool, groBufferPool sync.Pool
 
 func getPacketBuffer() *packetBuffer {
 	buf := bufferPool.Get().(*packetBuffer)
@@ -71,10 +118,33 @@ func getPacketBuffer() *packetBuffer {
 	return buf
 }
 
+// getLargePacketBuffer returns a buffer that is large enough to hold multiple packets.
+// It is used for sending packets using GSO.
+func getLargePacketBuffer() *packetBuffer {
+	buf := largeBufferPool.Get().(*packetBuffer)
+	buf.refCount = 1
+	buf.Data = buf.Data[:0]
+	return buf
+}
+
+func getGROBuffer() *groBuffer {
+	buf := groBufferPool.Get().(*groBuffer)
+	buf.refCount = 1
+	return buf
+}
+
 func init() {
 	bufferPool.New = func() interface{} {
 		return &packetBuffer{
 			Data: make([]byte, 0, protocol.MaxPacketBufferSize),
 		}
 	}
+	largeBufferPool.New = func() interface{} {
+		return &packetBuffer{
+			Data: make([]byte, 0, protocol.MaxLargePacketBufferSize),
+		}
+	}
+	groBufferPool.New = func() interface{} {
+		return &groBuffer{Data: make([]byte, maxGROMessageSize)}
+	}
 }
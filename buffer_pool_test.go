@@ -13,11 +13,39 @@ var _ = Describe("Buffer Pool", func() {
 		Expect(buf.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
 	})
 
+	It("returns large buffers of cap", func() {
+		buf := getLargePacketBuffer()
+		Expect(buf.Data).To(HaveCap(int(protocol.MaxLargePacketBufferSize)))
+	})
+
 	It("releases buffers", func() {
 		buf := getPacketBuffer()
 		buf.Release()
 	})
 
+	It("releases large buffers", func() {
+		buf := getLargePacketBuffer()
+		buf.Release()
+	})
+
+	It("hands out packet buffers pointing into GRO buffers", func() {
+		groBuf := getGROBuffer()
+		Expect(groBuf.Data).To(HaveLen(maxGROMessageSize))
+		buf1 := groBuf.NewPacketBuffer(groBuf.Data[:3:3])
+		buf2 := groBuf.NewPacketBuffer(groBuf.Data[3:6:6])
+		groBuf.Release()
+		Expect(groBuf.refCount).To(BeEquivalentTo(2))
+		buf1.Release()
+		Expect(groBuf.refCount).To(BeEquivalentTo(1))
+		buf2.Split()
+		buf2.Decrement()
+		buf2.MaybeRelease()
+		Expect(groBuf.refCount).To(BeEquivalentTo(1))
+		buf2.Release()
+		Expect(groBuf.refCount).To(BeZero())
+		Expect(func() { groBuf.Release() }).To(Panic())
+	})
+
 	It("gets the length", func() {
 		buf := getPacketBuffer()
 		buf.Data = append(buf.Data, []byte("foobar")...)
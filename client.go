@@ -254,7 +254,13 @@ func newClient(
 		}
 	}
 
-	srcConnID, err := generateConnectionID(config.ConnectionIDLength)
+	var srcConnID protocol.ConnectionID
+	var err error
+	if config.ConnectionIDGenerator != nil {
+		srcConnID, err = config.newConnectionID()
+	} else {
+		srcConnID, err = generateConnectionID(config.ConnectionIDLength)
+	}
 	if err != nil {
 		return nil, err
 	}
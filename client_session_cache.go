@@ -0,0 +1,319 @@
+package quic
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/qtls"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const clientSessionCacheRevision = 1
+
+// A ClientSessionCache is a tls.ClientSessionCache that can be exported and imported.
+// This allows clients to resume sessions, and to use 0-RTT, across process restarts.
+// The session states contain the QUIC transport parameters required for 0-RTT.
+// It can only be used for QUIC connections established by this package.
+type ClientSessionCache interface {
+	tls.ClientSessionCache
+	// Export serializes all session states that haven't expired yet.
+	// The serialized data contains the secrets needed to resume the sessions, and must be stored securely.
+	Export() []byte
+	// Import adds the session states serialized by Export.
+	// Session states that have expired in the meantime are skipped.
+	Import(data []byte) error
+}
+
+type clientSessionCacheEntry struct {
+	key   string
+	state *tls.ClientSessionState
+}
+
+type clientSessionCache struct {
+	mutex sync.Mutex
+
+	m        map[string]*list.Element
+	q        *list.List
+	capacity int
+}
+
+var _ ClientSessionCache = &clientSessionCache{}
+
+// NewClientSessionCache creates a new LRU cache for session states, which can be exported and imported.
+// Session states are stored per server, using the keys chosen by crypto/tls.
+// capacity specifies how many session states this cache is saving.
+// Session states are removed when the session ticket lifetime set by the server expires.
+func NewClientSessionCache(capacity int) ClientSessionCache {
+	return &clientSessionCache{
+		m:        make(map[string]*list.Element),
+		q:        list.New(),
+		capacity: capacity,
+	}
+}
+
+func (c *clientSessionCache) Put(key string, cs *tls.ClientSessionState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if el, ok := c.m[key]; ok {
+		if cs == nil {
+			c.q.Remove(el)
+			delete(c.m, key)
+			return
+		}
+		el.Value.(*clientSessionCacheEntry).state = cs
+		c.q.MoveToFront(el)
+		return
+	}
+	if cs == nil {
+		return
+	}
+
+	if c.q.Len() < c.capacity {
+		c.m[key] = c.q.PushFront(&clientSessionCacheEntry{key: key, state: cs})
+		return
+	}
+
+	el := c.q.Back()
+	entry := el.Value.(*clientSessionCacheEntry)
+	delete(c.m, entry.key)
+	entry.key = key
+	entry.state = cs
+	c.q.MoveToFront(el)
+	c.m[key] = el
+}
+
+func (c *clientSessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+	state := el.Value.(*clientSessionCacheEntry).state
+	if isExpiredSessionState(qtls.ToClientSessionStateData(state), time.Now()) {
+		c.q.Remove(el)
+		delete(c.m, key)
+		return nil, false
+	}
+	c.q.MoveToFront(el)
+	return state, true
+}
+
+func isExpiredSessionState(d *qtls.ClientSessionStateData, now time.Time) bool {
+	return !d.UseBy.IsZero() && now.After(d.UseBy)
+}
+
+// Export writes the session states starting with the least recently used one,
+// such that importing them into a new cache restores its state.
+func (c *clientSessionCache) Export() []byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	var entries []*clientSessionCacheEntry
+	var states []*qtls.ClientSessionStateData
+	for el := c.q.Back(); el != nil; el = el.Prev() {
+		entry := el.Value.(*clientSessionCacheEntry)
+		d := qtls.ToClientSessionStateData(entry.state)
+		if isExpiredSessionState(d, now) {
+			continue
+		}
+		entries = append(entries, entry)
+		states = append(states, d)
+	}
+
+	b := &bytes.Buffer{}
+	quicvarint.Write(b, clientSessionCacheRevision)
+	quicvarint.Write(b, uint64(len(entries)))
+	for i, entry := range entries {
+		writeLengthPrefixed(b, []byte(entry.key))
+		marshalClientSessionState(b, states[i])
+	}
+	return b.Bytes()
+}
+
+func (c *clientSessionCache) Import(data []byte) error {
+	r := bytes.NewReader(data)
+	rev, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read revision")
+	}
+	if rev != clientSessionCacheRevision {
+		return fmt.Errorf("unknown revision: %d", rev)
+	}
+	num, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read number of session states")
+	}
+	// Parse all session states first, so that no session states are added if parsing fails.
+	keys := make([]string, 0, num)
+	states := make([]*qtls.ClientSessionStateData, 0, num)
+	for i := uint64(0); i < num; i++ {
+		key, err := readLengthPrefixed(r)
+		if err != nil {
+			return errors.New("failed to read key")
+		}
+		d, err := unmarshalClientSessionState(r)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, string(key))
+		states = append(states, d)
+	}
+	if r.Len() > 0 {
+		return errors.New("unexpected trailing data")
+	}
+	now := time.Now()
+	for i, d := range states {
+		if !isExpiredSessionState(d, now) {
+			c.Put(keys[i], qtls.FromClientSessionStateData(d))
+		}
+	}
+	return nil
+}
+
+func marshalClientSessionState(b *bytes.Buffer, d *qtls.ClientSessionStateData) {
+	writeLengthPrefixed(b, d.SessionTicket)
+	quicvarint.Write(b, uint64(d.Vers))
+	quicvarint.Write(b, uint64(d.CipherSuite))
+	writeLengthPrefixed(b, d.MasterSecret)
+	writeCertificates(b, d.ServerCertificates)
+	quicvarint.Write(b, uint64(len(d.VerifiedChains)))
+	for _, chain := range d.VerifiedChains {
+		writeCertificates(b, chain)
+	}
+	writeTime(b, d.ReceivedAt)
+	writeLengthPrefixed(b, d.OCSPResponse)
+	quicvarint.Write(b, uint64(len(d.SCTs)))
+	for _, sct := range d.SCTs {
+		writeLengthPrefixed(b, sct)
+	}
+	writeLengthPrefixed(b, d.Nonce)
+	writeTime(b, d.UseBy)
+	quicvarint.Write(b, uint64(d.AgeAdd))
+}
+
+func unmarshalClientSessionState(r *bytes.Reader) (*qtls.ClientSessionStateData, error) {
+	d := &qtls.ClientSessionStateData{}
+	var err error
+	if d.SessionTicket, err = readLengthPrefixed(r); err != nil {
+		return nil, errors.New("failed to read session ticket")
+	}
+	vers, err := quicvarint.Read(r)
+	if err != nil || vers > 0xffff {
+		return nil, errors.New("failed to read TLS version")
+	}
+	d.Vers = uint16(vers)
+	cipherSuite, err := quicvarint.Read(r)
+	if err != nil || cipherSuite > 0xffff {
+		return nil, errors.New("failed to read cipher suite")
+	}
+	d.CipherSuite = uint16(cipherSuite)
+	if d.MasterSecret, err = readLengthPrefixed(r); err != nil {
+		return nil, errors.New("failed to read master secret")
+	}
+	if d.ServerCertificates, err = readCertificates(r); err != nil {
+		return nil, err
+	}
+	numChains, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, errors.New("failed to read number of verified chains")
+	}
+	for i := uint64(0); i < numChains; i++ {
+		chain, err := readCertificates(r)
+		if err != nil {
+			return nil, err
+		}
+		d.VerifiedChains = append(d.VerifiedChains, chain)
+	}
+	if d.ReceivedAt, err = readTime(r); err != nil {
+		return nil, errors.New("failed to read receive time")
+	}
+	if d.OCSPResponse, err = readLengthPrefixed(r); err != nil {
+		return nil, errors.New("failed to read OCSP response")
+	}
+	numSCTs, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, errors.New("failed to read number of SCTs")
+	}
+	for i := uint64(0); i < numSCTs; i++ {
+		sct, err := readLengthPrefixed(r)
+		if err != nil {
+			return nil, errors.New("failed to read SCT")
+		}
+		d.SCTs = append(d.SCTs, sct)
+	}
+	if d.Nonce, err = readLengthPrefixed(r); err != nil {
+		return nil, errors.New("failed to read nonce")
+	}
+	if d.UseBy, err = readTime(r); err != nil {
+		return nil, errors.New("failed to read expiry time")
+	}
+	ageAdd, err := quicvarint.Read(r)
+	if err != nil || ageAdd > 0xffffffff {
+		return nil, errors.New("failed to read age add")
+	}
+	d.AgeAdd = uint32(ageAdd)
+	return d, nil
+}
+
+func writeCertificates(b *bytes.Buffer, certs []*x509.Certificate) {
+	quicvarint.Write(b, uint64(len(certs)))
+	for _, cert := range certs {
+		writeLengthPrefixed(b, cert.Raw)
+	}
+}
+
+func readCertificates(r *bytes.Reader) ([]*x509.Certificate, error) {
+	num, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, errors.New("failed to read number of certificates")
+	}
+	var certs []*x509.Certificate
+	for i := uint64(0); i < num; i++ {
+		raw, err := readLengthPrefixed(r)
+		if err != nil {
+			return nil, errors.New("failed to read certificate")
+		}
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// writeTime writes a time with millisecond precision.
+// The zero time is encoded as 0.
+func writeTime(b *bytes.Buffer, t time.Time) {
+	if t.IsZero() {
+		quicvarint.Write(b, 0)
+		return
+	}
+	quicvarint.Write(b, uint64(t.UnixNano()/int64(time.Millisecond)))
+}
+
+func readTime(r *bytes.Reader) (time.Time, error) {
+	ms, err := quicvarint.Read(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ms == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(0, int64(ms)*int64(time.Millisecond)), nil
+}
+
+func writeLengthPrefixed(b *bytes.Buffer, data []byte) {
+	quicvarint.Write(b, uint64(len(data)))
+	b.Write(data)
+}
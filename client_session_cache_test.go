@@ -0,0 +1,177 @@
+package quic
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/qtls"
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client Session Cache", func() {
+	var c ClientSessionCache
+
+	BeforeEach(func() {
+		c = NewClientSessionCache(2)
+	})
+
+	newState := func(ticket string, useBy time.Time) *tls.ClientSessionState {
+		return qtls.FromClientSessionStateData(&qtls.ClientSessionStateData{
+			SessionTicket: []byte(ticket),
+			Vers:          tls.VersionTLS13,
+			UseBy:         useBy,
+		})
+	}
+
+	getTicket := func(key string) string {
+		state, ok := c.Get(key)
+		if !ok {
+			return ""
+		}
+		return string(qtls.ToClientSessionStateData(state).SessionTicket)
+	}
+
+	Context("caching", func() {
+		It("adds and gets session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("bar", newState("bar", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("foo"))
+			Expect(getTicket("bar")).To(Equal("bar"))
+			_, ok := c.Get("baz")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("replaces session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("foo", newState("bar", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("bar"))
+		})
+
+		It("deletes session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("foo", nil)
+			_, ok := c.Get("foo")
+			Expect(ok).To(BeFalse())
+			c.Put("bar", nil)
+			_, ok = c.Get("bar")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("evicts the least recently used session state", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("bar", newState("bar", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("foo"))
+			c.Put("baz", newState("baz", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("foo"))
+			Expect(getTicket("baz")).To(Equal("baz"))
+			_, ok := c.Get("bar")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("removes expired session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(-time.Second)))
+			_, ok := c.Get("foo")
+			Expect(ok).To(BeFalse())
+			Expect(c.(*clientSessionCache).q.Len()).To(BeZero())
+		})
+	})
+
+	Context("exporting and importing", func() {
+		var cert *x509.Certificate
+
+		BeforeEach(func() {
+			var err error
+			cert, err = x509.ParseCertificate(testdata.GetTLSConfig().Certificates[0].Certificate[0])
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("exports and imports session states", func() {
+			now := time.Now()
+			d := &qtls.ClientSessionStateData{
+				SessionTicket:      []byte("ticket"),
+				Vers:               tls.VersionTLS13,
+				CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+				MasterSecret:       []byte("secret"),
+				ServerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:     [][]*x509.Certificate{{cert, cert}},
+				ReceivedAt:         now,
+				OCSPResponse:       []byte("ocsp"),
+				SCTs:               [][]byte{[]byte("foo"), []byte("bar")},
+				Nonce:              []byte("nonce"),
+				UseBy:              now.Add(time.Hour),
+				AgeAdd:             0xdeadbeef,
+			}
+			c.Put("foo", qtls.FromClientSessionStateData(d))
+
+			c2 := NewClientSessionCache(2)
+			Expect(c2.Import(c.Export())).To(Succeed())
+			state, ok := c2.Get("foo")
+			Expect(ok).To(BeTrue())
+			d2 := qtls.ToClientSessionStateData(state)
+			Expect(d2.SessionTicket).To(Equal(d.SessionTicket))
+			Expect(d2.Vers).To(Equal(d.Vers))
+			Expect(d2.CipherSuite).To(Equal(d.CipherSuite))
+			Expect(d2.MasterSecret).To(Equal(d.MasterSecret))
+			Expect(d2.ServerCertificates).To(HaveLen(1))
+			Expect(d2.ServerCertificates[0].Equal(cert)).To(BeTrue())
+			Expect(d2.VerifiedChains).To(HaveLen(1))
+			Expect(d2.VerifiedChains[0]).To(HaveLen(2))
+			Expect(d2.VerifiedChains[0][1].Equal(cert)).To(BeTrue())
+			Expect(d2.ReceivedAt).To(BeTemporally("~", now, time.Millisecond))
+			Expect(d2.OCSPResponse).To(Equal(d.OCSPResponse))
+			Expect(d2.SCTs).To(Equal(d.SCTs))
+			Expect(d2.Nonce).To(Equal(d.Nonce))
+			Expect(d2.UseBy).To(BeTemporally("~", now.Add(time.Hour), time.Millisecond))
+			Expect(d2.AgeAdd).To(Equal(d.AgeAdd))
+		})
+
+		It("restores the order of the session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("bar", newState("bar", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("foo"))
+
+			c2 := NewClientSessionCache(2)
+			Expect(c2.Import(c.Export())).To(Succeed())
+			c = c2
+			// bar is the least recently used session state, and gets evicted
+			c.Put("baz", newState("baz", time.Now().Add(time.Hour)))
+			Expect(getTicket("foo")).To(Equal("foo"))
+			_, ok := c.Get("bar")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("doesn't export expired session states", func() {
+			c.Put("foo", newState("foo", time.Now().Add(-time.Second)))
+			c.Put("bar", newState("bar", time.Now().Add(time.Hour)))
+			c2 := NewClientSessionCache(2)
+			Expect(c2.Import(c.Export())).To(Succeed())
+			Expect(c2.(*clientSessionCache).q.Len()).To(Equal(1))
+		})
+
+		It("doesn't import session states that expired in the meantime", func() {
+			c.Put("foo", newState("foo", time.Now().Add(scaleDuration(10*time.Millisecond))))
+			data := c.Export()
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			c2 := NewClientSessionCache(2)
+			Expect(c2.Import(data)).To(Succeed())
+			Expect(c2.(*clientSessionCache).q.Len()).To(BeZero())
+		})
+
+		It("errors on unknown revisions", func() {
+			Expect(c.Import([]byte{0x3f})).To(MatchError("unknown revision: 63"))
+		})
+
+		It("doesn't import anything if parsing fails", func() {
+			c.Put("foo", newState("foo", time.Now().Add(time.Hour)))
+			c.Put("bar", newState("bar", time.Now().Add(time.Hour)))
+			data := c.Export()
+			c2 := NewClientSessionCache(2)
+			Expect(c2.Import(data[:len(data)-1])).ToNot(Succeed())
+			Expect(c2.(*clientSessionCache).q.Len()).To(BeZero())
+		})
+	})
+})
@@ -445,7 +445,7 @@ var _ = Describe("Client", func() {
 			Eventually(sessionCreated).Should(BeClosed())
 
 			// check that the connection is not closed
-			Expect(conn.Write([]byte("foobar"))).To(Succeed())
+			Expect(conn.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
 
 			manager.EXPECT().Destroy()
 			close(run)
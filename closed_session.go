@@ -74,7 +74,7 @@ func (s *closedLocalSession) handlePacketImpl(_ *receivedPacket) {
 		}
 	}
 	s.logger.Debugf("Received %d packets after sending CONNECTION_CLOSE. Retransmitting.", s.counter)
-	if err := s.conn.Write(s.connClosePacket); err != nil {
+	if err := s.conn.Write(s.connClosePacket, 0, protocol.ECNNon); err != nil {
 		s.logger.Debugf("Error retransmitting CONNECTION_CLOSE: %s", err)
 	}
 }
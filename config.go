@@ -2,11 +2,12 @@ package quic
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
-	"github.com/lucas-clemente/quic-go/internal/utils"
-
+	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
 // Clone clones a Config
@@ -19,6 +20,22 @@ func (c *Config) handshakeTimeout() time.Duration {
 	return utils.MaxDuration(protocol.DefaultHandshakeTimeout, 2*c.HandshakeIdleTimeout)
 }
 
+// newConnectionID generates a new connection ID.
+// It uses the ConnectionIDGenerator, if one is configured.
+func (c *Config) newConnectionID() (protocol.ConnectionID, error) {
+	if c.ConnectionIDGenerator == nil {
+		return protocol.GenerateConnectionID(c.ConnectionIDLength)
+	}
+	connID, err := c.ConnectionIDGenerator.GenerateConnectionID()
+	if err != nil {
+		return nil, err
+	}
+	if len(connID) != c.ConnectionIDLength {
+		return nil, fmt.Errorf("ConnectionIDGenerator generated a connection ID of length %d (expected %d)", len(connID), c.ConnectionIDLength)
+	}
+	return connID, nil
+}
+
 func validateConfig(config *Config) error {
 	if config == nil {
 		return nil
@@ -29,6 +46,31 @@ func validateConfig(config *Config) error {
 	if config.MaxIncomingUniStreams > 1<<60 {
 		return errors.New("invalid value for Config.MaxIncomingUniStreams")
 	}
+	if config.PreferredAddressIPv4 != nil && config.PreferredAddressIPv4.IP.To4() == nil {
+		return errors.New("invalid value for Config.PreferredAddressIPv4")
+	}
+	if config.PreferredAddressIPv6 != nil && (config.PreferredAddressIPv6.IP.To16() == nil || config.PreferredAddressIPv6.IP.To4() != nil) {
+		return errors.New("invalid value for Config.PreferredAddressIPv6")
+	}
+	if config.DatagramSendQueueLen < 0 {
+		return errors.New("invalid value for Config.DatagramSendQueueLen")
+	}
+	if config.DatagramDropPolicy > DatagramDropNewest {
+		return errors.New("invalid value for Config.DatagramDropPolicy")
+	}
+	if config.TokenProtector != nil && len(config.TokenKeys) > 0 {
+		return errors.New("cannot use both Config.TokenKeys and Config.TokenProtector")
+	}
+	for _, key := range config.TokenKeys {
+		if len(key) != handshake.TokenKeySize {
+			return errors.New("invalid value for Config.TokenKeys")
+		}
+	}
+	if config.ConnectionIDGenerator != nil {
+		if l := config.ConnectionIDGenerator.ConnectionIDLen(); l < 4 || l > protocol.MaxConnIDLen {
+			return errors.New("invalid connection ID length for Config.ConnectionIDGenerator")
+		}
+	}
 	return nil
 }
 
@@ -49,7 +91,7 @@ func populateServerConfig(config *Config) *Config {
 // it may be called with nil
 func populateClientConfig(config *Config, createdPacketConn bool) *Config {
 	config = populateConfig(config)
-	if config.ConnectionIDLength == 0 && !createdPacketConn {
+	if config.ConnectionIDLength == 0 && (!createdPacketConn || config.EnableMultipath) {
 		config.ConnectionIDLength = protocol.DefaultConnectionIDLength
 	}
 	return config
@@ -99,12 +141,32 @@ func populateConfig(config *Config) *Config {
 	} else if maxIncomingUniStreams < 0 {
 		maxIncomingUniStreams = 0
 	}
+	connIDLen := config.ConnectionIDLength
+	if config.ConnectionIDGenerator != nil {
+		connIDLen = config.ConnectionIDGenerator.ConnectionIDLen()
+	}
+	datagramSendQueueLen := config.DatagramSendQueueLen
+	if datagramSendQueueLen == 0 {
+		datagramSendQueueLen = protocol.DatagramSendQueueLen
+	}
+	// Copy the token keys, so that the application can't modify them after the Listener was created.
+	var tokenKeys [][]byte
+	if len(config.TokenKeys) > 0 {
+		tokenKeys = make([][]byte, 0, len(config.TokenKeys))
+		for _, key := range config.TokenKeys {
+			tokenKeys = append(tokenKeys, append([]byte(nil), key...))
+		}
+	}
 
 	return &Config{
 		Versions:                         versions,
 		HandshakeIdleTimeout:             handshakeIdleTimeout,
 		MaxIdleTimeout:                   idleTimeout,
 		AcceptToken:                      config.AcceptToken,
+		TokenKeys:                        tokenKeys,
+		TokenProtector:                   config.TokenProtector,
+		Allow0RTT:                        config.Allow0RTT,
+		AntiReplay:                       config.AntiReplay,
 		KeepAlive:                        config.KeepAlive,
 		InitialStreamReceiveWindow:       initialStreamReceiveWindow,
 		MaxStreamReceiveWindow:           maxStreamReceiveWindow,
@@ -112,12 +174,22 @@ func populateConfig(config *Config) *Config {
 		MaxConnectionReceiveWindow:       maxConnectionReceiveWindow,
 		MaxIncomingStreams:               maxIncomingStreams,
 		MaxIncomingUniStreams:            maxIncomingUniStreams,
-		ConnectionIDLength:               config.ConnectionIDLength,
+		ConnectionIDLength:               connIDLen,
+		ConnectionIDGenerator:            config.ConnectionIDGenerator,
 		StatelessResetKey:                config.StatelessResetKey,
 		TokenStore:                       config.TokenStore,
 		EnableDatagrams:                  config.EnableDatagrams,
+		DatagramSendQueueLen:             datagramSendQueueLen,
+		DatagramDropPolicy:               config.DatagramDropPolicy,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		EnableMultipath:                  config.EnableMultipath,
+		PathScheduler:                    config.PathScheduler,
+		CongestionControl:                config.CongestionControl,
 		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
+		DisableActiveMigration:           config.DisableActiveMigration,
 		DisableVersionNegotiationPackets: config.DisableVersionNegotiationPackets,
+		PreferredAddressIPv4:             config.PreferredAddressIPv4,
+		PreferredAddressIPv6:             config.PreferredAddressIPv6,
 		Tracer:                           config.Tracer,
 	}
 }
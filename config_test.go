@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
@@ -30,6 +31,42 @@ var _ = Describe("Config", func() {
 		It("errors on too large values for MaxIncomingUniStreams", func() {
 			Expect(validateConfig(&Config{MaxIncomingUniStreams: 1<<60 + 1})).To(MatchError("invalid value for Config.MaxIncomingUniStreams"))
 		})
+
+		It("errors on preferred addresses of the wrong address family", func() {
+			Expect(validateConfig(&Config{PreferredAddressIPv4: &net.UDPAddr{IP: net.IPv6loopback}})).To(MatchError("invalid value for Config.PreferredAddressIPv4"))
+			Expect(validateConfig(&Config{PreferredAddressIPv6: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}})).To(MatchError("invalid value for Config.PreferredAddressIPv6"))
+		})
+
+		It("errors on negative values for DatagramSendQueueLen", func() {
+			Expect(validateConfig(&Config{DatagramSendQueueLen: -1})).To(MatchError("invalid value for Config.DatagramSendQueueLen"))
+		})
+
+		It("errors on invalid values for DatagramDropPolicy", func() {
+			Expect(validateConfig(&Config{DatagramDropPolicy: 42})).To(MatchError("invalid value for Config.DatagramDropPolicy"))
+		})
+
+		It("errors on invalid token keys", func() {
+			Expect(validateConfig(&Config{TokenKeys: [][]byte{make([]byte, 32)}})).To(Succeed())
+			Expect(validateConfig(&Config{TokenKeys: [][]byte{make([]byte, 32), make([]byte, 31)}})).To(MatchError("invalid value for Config.TokenKeys"))
+		})
+
+		It("errors when both TokenKeys and a TokenProtector are set", func() {
+			Expect(validateConfig(&Config{
+				TokenKeys:      [][]byte{make([]byte, 32)},
+				TokenProtector: NewMockTokenProtector(mockCtrl),
+			})).To(MatchError("cannot use both Config.TokenKeys and Config.TokenProtector"))
+		})
+
+		It("errors on invalid connection ID lengths of the ConnectionIDGenerator", func() {
+			for _, l := range []int{0, 3, 21} {
+				connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+				connIDGenerator.EXPECT().ConnectionIDLen().Return(l)
+				Expect(validateConfig(&Config{ConnectionIDGenerator: connIDGenerator})).To(MatchError("invalid connection ID length for Config.ConnectionIDGenerator"))
+			}
+			connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+			connIDGenerator.EXPECT().ConnectionIDLen().Return(20)
+			Expect(validateConfig(&Config{ConnectionIDGenerator: connIDGenerator})).To(Succeed())
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -45,16 +82,26 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "AcceptToken", "GetLogWriter":
+			case "AcceptToken", "Allow0RTT", "GetLogWriter", "CongestionControl":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]VersionNumber{1, 2, 3}))
 			case "ConnectionIDLength":
 				f.Set(reflect.ValueOf(8))
+			case "ConnectionIDGenerator":
+				connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+				connIDGenerator.EXPECT().ConnectionIDLen().Return(8).AnyTimes()
+				f.Set(reflect.ValueOf(connIDGenerator))
 			case "HandshakeIdleTimeout":
 				f.Set(reflect.ValueOf(time.Second))
 			case "MaxIdleTimeout":
 				f.Set(reflect.ValueOf(time.Hour))
+			case "TokenKeys":
+				f.Set(reflect.ValueOf([][]byte{make([]byte, 32)}))
+			case "TokenProtector":
+				f.Set(reflect.ValueOf(NewMockTokenProtector(mockCtrl)))
+			case "AntiReplay":
+				f.Set(reflect.ValueOf(NewAntiReplay(time.Minute)))
 			case "TokenStore":
 				f.Set(reflect.ValueOf(NewLRUTokenStore(2, 3)))
 			case "InitialStreamReceiveWindow":
@@ -75,10 +122,26 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(true))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(13))
+			case "DatagramDropPolicy":
+				f.Set(reflect.ValueOf(DatagramDropOldest))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableMultipath":
+				f.Set(reflect.ValueOf(true))
+			case "PathScheduler":
+				f.Set(reflect.ValueOf(&minRTTScheduler{}))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "DisableActiveMigration":
+				f.Set(reflect.ValueOf(true))
+			case "PreferredAddressIPv4":
+				f.Set(reflect.ValueOf(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}))
+			case "PreferredAddressIPv6":
+				f.Set(reflect.ValueOf(&net.UDPAddr{IP: net.IPv6loopback, Port: 1338}))
 			case "Tracer":
 				f.Set(reflect.ValueOf(mocklogging.NewMockTracer(mockCtrl)))
 			default:
@@ -88,6 +151,46 @@ var _ = Describe("Config", func() {
 		return c
 	}
 
+	Context("generating connection IDs", func() {
+		It("generates random connection IDs", func() {
+			c := &Config{ConnectionIDLength: 7}
+			connID1, err := c.newConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID1.Len()).To(Equal(7))
+			connID2, err := c.newConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID2).ToNot(Equal(connID1))
+		})
+
+		It("uses the ConnectionIDGenerator", func() {
+			connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+			connIDGenerator.EXPECT().ConnectionIDLen().Return(5).AnyTimes()
+			c := populateServerConfig(&Config{ConnectionIDGenerator: connIDGenerator})
+			Expect(c.ConnectionIDLength).To(Equal(5))
+			connIDGenerator.EXPECT().GenerateConnectionID().Return([]byte{1, 2, 3, 4, 5}, nil)
+			Expect(c.newConnectionID()).To(Equal(protocol.ConnectionID{1, 2, 3, 4, 5}))
+		})
+
+		It("errors when the ConnectionIDGenerator generates a connection ID of the wrong length", func() {
+			connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+			connIDGenerator.EXPECT().ConnectionIDLen().Return(5).AnyTimes()
+			c := populateServerConfig(&Config{ConnectionIDGenerator: connIDGenerator})
+			connIDGenerator.EXPECT().GenerateConnectionID().Return([]byte{1, 2, 3, 4}, nil)
+			_, err := c.newConnectionID()
+			Expect(err).To(MatchError("ConnectionIDGenerator generated a connection ID of length 4 (expected 5)"))
+		})
+
+		It("returns errors from the ConnectionIDGenerator", func() {
+			connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+			connIDGenerator.EXPECT().ConnectionIDLen().Return(5).AnyTimes()
+			c := populateServerConfig(&Config{ConnectionIDGenerator: connIDGenerator})
+			testErr := errors.New("test error")
+			connIDGenerator.EXPECT().GenerateConnectionID().Return(nil, testErr)
+			_, err := c.newConnectionID()
+			Expect(err).To(MatchError(testErr))
+		})
+	})
+
 	It("uses 10s handshake timeout for short handshake idle timeouts", func() {
 		c := &Config{HandshakeIdleTimeout: time.Second}
 		Expect(c.handshakeTimeout()).To(Equal(protocol.DefaultHandshakeTimeout))
@@ -130,13 +233,16 @@ var _ = Describe("Config", func() {
 
 	Context("populating", func() {
 		It("populates function fields", func() {
-			var calledAcceptToken bool
+			var calledAcceptToken, calledAllow0RTT bool
 			c1 := &Config{
 				AcceptToken: func(_ net.Addr, _ *Token) bool { calledAcceptToken = true; return true },
+				Allow0RTT:   func(net.Addr) bool { calledAllow0RTT = true; return true },
 			}
 			c2 := populateConfig(c1)
 			c2.AcceptToken(&net.UDPAddr{}, &Token{})
 			Expect(calledAcceptToken).To(BeTrue())
+			c2.Allow0RTT(&net.UDPAddr{})
+			Expect(calledAllow0RTT).To(BeTrue())
 		})
 
 		It("copies non-function fields", func() {
@@ -144,6 +250,17 @@ var _ = Describe("Config", func() {
 			Expect(populateConfig(c)).To(Equal(c))
 		})
 
+		It("copies the token keys", func() {
+			key := make([]byte, 32)
+			keys := [][]byte{key}
+			c := populateConfig(&Config{TokenKeys: keys})
+			Expect(c.TokenKeys).To(Equal([][]byte{make([]byte, 32)}))
+			key[0] = 1
+			Expect(c.TokenKeys).To(Equal([][]byte{make([]byte, 32)}))
+			keys[0] = []byte("foobar")
+			Expect(c.TokenKeys).To(Equal([][]byte{make([]byte, 32)}))
+		})
+
 		It("populates empty fields with default values", func() {
 			c := populateConfig(&Config{})
 			Expect(c.Versions).To(Equal(protocol.SupportedVersions))
@@ -156,6 +273,8 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisableVersionNegotiationPackets).To(BeFalse())
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
+			Expect(c.DatagramSendQueueLen).To(Equal(protocol.DatagramSendQueueLen))
+			Expect(c.DatagramDropPolicy).To(Equal(DatagramDropNone))
 		})
 
 		It("populates empty fields with default values, for the server", func() {
@@ -173,5 +292,10 @@ var _ = Describe("Config", func() {
 			c := populateClientConfig(&Config{}, true)
 			Expect(c.ConnectionIDLength).To(BeZero())
 		})
+
+		It("sets a default connection ID length if we created the conn, for a client using multipath", func() {
+			c := populateClientConfig(&Config{EnableMultipath: true}, true)
+			Expect(c.ConnectionIDLength).To(Equal(protocol.DefaultConnectionIDLength))
+		})
 	})
 })
@@ -0,0 +1,100 @@
+package congestion
+
+import (
+	"time"
+
+	internalcongestion "github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// Bandwidth of a connection, in bits per second.
+type Bandwidth uint64
+
+const (
+	// BitsPerSecond is 1 bit per second
+	BitsPerSecond Bandwidth = 1
+	// BytesPerSecond is 1 byte per second
+	BytesPerSecond = 8 * BitsPerSecond
+)
+
+// BandwidthFromDelta calculates the bandwidth from a number of bytes and a time delta.
+func BandwidthFromDelta(bytes ByteCount, delta time.Duration) Bandwidth {
+	return Bandwidth(internalcongestion.BandwidthFromDelta(protocol.ByteCount(bytes), delta))
+}
+
+// A Pacer implements a token bucket pacing algorithm.
+// It can be used by CongestionControl implementations to implement pacing.
+type Pacer struct {
+	pacer *internalcongestion.Pacer
+}
+
+// NewPacer creates a new Pacer.
+// getBandwidth returns the current bandwidth estimate of the congestion controller.
+func NewPacer(getBandwidth func() Bandwidth) *Pacer {
+	return &Pacer{
+		pacer: internalcongestion.NewPacer(func() internalcongestion.Bandwidth {
+			return internalcongestion.Bandwidth(getBandwidth())
+		}),
+	}
+}
+
+// SentPacket is called when a packet is sent.
+func (p *Pacer) SentPacket(sendTime time.Time, size ByteCount) {
+	p.pacer.SentPacket(sendTime, protocol.ByteCount(size))
+}
+
+// Budget returns the number of bytes that can be sent at the given time.
+func (p *Pacer) Budget(now time.Time) ByteCount {
+	return ByteCount(p.pacer.Budget(now))
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+// It returns the zero value of time.Time if a packet can be sent immediately.
+func (p *Pacer) TimeUntilSend() time.Time {
+	return p.pacer.TimeUntilSend()
+}
+
+// SetMaxDatagramSize sets the maximum packet size.
+func (p *Pacer) SetMaxDatagramSize(s ByteCount) {
+	p.pacer.SetMaxDatagramSize(protocol.ByteCount(s))
+}
+
+var (
+	_ CongestionControl           = &sendAlgorithm{}
+	_ SpuriousLossHandler         = &sendAlgorithm{}
+	_ PersistentCongestionHandler = &sendAlgorithm{}
+	_ PacketNumberSpaceHandler    = &sendAlgorithm{}
+	_ ECNCongestionHandler        = &ecnSendAlgorithm{}
+)
+
+// NewReno creates a NewReno congestion controller (RFC 9002, Section 7).
+// This is the congestion controller that is used if no other congestion controller is configured.
+func NewReno(p Parameters) CongestionControl {
+	return newECNSendAlgorithm(internalcongestion.NewCubicSender(internalcongestion.DefaultClock{}, p.RTTStats, protocol.ByteCount(p.InitialMaxDatagramSize), true, p.SlowStart == SlowStartHyStartPlusPlus, p.Tracer))
+}
+
+// NewCubic creates a CUBIC congestion controller (RFC 8312).
+func NewCubic(p Parameters) CongestionControl {
+	return newECNSendAlgorithm(internalcongestion.NewCubicSender(internalcongestion.DefaultClock{}, p.RTTStats, protocol.ByteCount(p.InitialMaxDatagramSize), false, p.SlowStart == SlowStartHyStartPlusPlus, p.Tracer))
+}
+
+// NewRenoHyStartPlusPlus creates a NewReno congestion controller that uses HyStart++ (RFC 9406) to leave slow start.
+// It can be used as the Config.CongestionControl.
+func NewRenoHyStartPlusPlus(p Parameters) CongestionControl {
+	p.SlowStart = SlowStartHyStartPlusPlus
+	return NewReno(p)
+}
+
+// NewCubicHyStartPlusPlus creates a CUBIC congestion controller that uses HyStart++ (RFC 9406) to leave slow start.
+// It can be used as the Config.CongestionControl.
+func NewCubicHyStartPlusPlus(p Parameters) CongestionControl {
+	p.SlowStart = SlowStartHyStartPlusPlus
+	return NewCubic(p)
+}
+
+// NewBBR creates a BBR congestion controller (draft-cardwell-iccrg-bbr-congestion-control).
+// Unlike the loss-based congestion controllers, it tolerates a small amount of random packet loss,
+// which makes it suitable for lossy long-distance paths.
+func NewBBR(p Parameters) CongestionControl {
+	return newSendAlgorithm(internalcongestion.NewBBRSender(internalcongestion.DefaultClock{}, p.RTTStats, protocol.ByteCount(p.InitialMaxDatagramSize), p.Tracer))
+}
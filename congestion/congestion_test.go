@@ -0,0 +1,97 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Congestion Controllers", func() {
+	for _, n := range []string{"NewReno", "NewReno with HyStart++", "Cubic", "Cubic with HyStart++", "BBR"} {
+		name := n
+
+		Context(name, func() {
+			var cc CongestionControl
+
+			BeforeEach(func() {
+				p := Parameters{
+					Perspective:            PerspectiveClient,
+					RTTStats:               &utils.RTTStats{},
+					InitialMaxDatagramSize: 1200,
+				}
+				switch name {
+				case "NewReno":
+					cc = NewReno(p)
+				case "NewReno with HyStart++":
+					cc = NewRenoHyStartPlusPlus(p)
+				case "Cubic":
+					cc = NewCubic(p)
+				case "Cubic with HyStart++":
+					cc = NewCubicHyStartPlusPlus(p)
+				case "BBR":
+					cc = NewBBR(p)
+				}
+			})
+
+			It("starts in slow start", func() {
+				Expect(cc.InSlowStart()).To(BeTrue())
+				Expect(cc.InRecovery()).To(BeFalse())
+				Expect(cc.GetCongestionWindow()).To(Equal(32 * ByteCount(1200)))
+			})
+
+			It("handles the optional congestion events", func() {
+				_, ok := cc.(SpuriousLossHandler)
+				Expect(ok).To(BeTrue())
+				_, ok = cc.(PersistentCongestionHandler)
+				Expect(ok).To(BeTrue())
+				// BBR doesn't react to ECN-CE marks, so ECN is disabled when using BBR.
+				_, ok = cc.(ECNCongestionHandler)
+				Expect(ok).To(Equal(name != "BBR"))
+			})
+
+			It("grows the congestion window when packets are acknowledged", func() {
+				now := time.Now()
+				cwnd := cc.GetCongestionWindow()
+				for pn := PacketNumber(1); pn <= 32; pn++ {
+					cc.OnPacketSent(now, ByteCount(pn)*1200, pn, 1200, true)
+				}
+				Expect(cc.CanSend(cwnd)).To(BeFalse())
+				cc.OnPacketAcked(1, 1200, cwnd, now.Add(time.Millisecond))
+				Expect(cc.GetCongestionWindow()).To(Equal(cwnd + 1200))
+			})
+
+			It("reduces the congestion window when a packet is lost", func() {
+				now := time.Now()
+				cc.OnPacketSent(now, 1200, 1, 1200, true)
+				cc.OnPacketSent(now, 2400, 2, 1200, true)
+				cc.OnPacketLost(1, 1200, 2400)
+				Expect(cc.InSlowStart()).To(BeFalse())
+				cwnd := cc.GetCongestionWindow()
+				Expect(cwnd).To(BeNumerically("<", 32*ByteCount(1200)))
+				cc.OnPacketAcked(2, 1200, cwnd, now.Add(time.Millisecond))
+				Expect(cc.InRecovery()).To(BeTrue())
+				// For the loss-based congestion controllers, packets sent before the loss don't increase the congestion window.
+				// BBR already starts growing the recovery window after one round trip.
+				if name != "BBR" {
+					Expect(cc.GetCongestionWindow()).To(Equal(cwnd))
+				}
+			})
+		})
+	}
+
+	It("paces packets", func() {
+		p := NewPacer(func() Bandwidth { return 1e6 * BytesPerSecond })
+		p.SetMaxDatagramSize(1200)
+		budget := p.Budget(time.Now())
+		Expect(budget).To(BeNumerically(">=", 1200))
+		p.SentPacket(time.Now(), budget)
+		Expect(p.Budget(time.Now())).To(BeNumerically("<", 1200))
+	})
+
+	It("calculates the bandwidth", func() {
+		Expect(BandwidthFromDelta(1, time.Second)).To(Equal(8 * BitsPerSecond))
+	})
+})
@@ -0,0 +1,194 @@
+// Package congestion defines the interface for congestion controllers used by quic-go.
+// The CongestionControl interface is stable: new congestion events are added as optional interfaces,
+// such that existing implementations keep working.
+package congestion
+
+import (
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+// A ByteCount is used to count bytes.
+type ByteCount int64
+
+// The PacketNumber is the packet number of a packet.
+// Packet numbers are only unique within a packet number space, see EncryptionLevel.
+type PacketNumber int64
+
+// The Perspective is the role of a QUIC endpoint (client or server).
+type Perspective int
+
+const (
+	// PerspectiveServer is used for a QUIC server
+	PerspectiveServer Perspective = 1
+	// PerspectiveClient is used for a QUIC client
+	PerspectiveClient Perspective = 2
+)
+
+func (p Perspective) String() string {
+	switch p {
+	case PerspectiveServer:
+		return "server"
+	case PerspectiveClient:
+		return "client"
+	default:
+		return "invalid perspective"
+	}
+}
+
+// The EncryptionLevel is the encryption level of a packet.
+// Initial and Handshake packets each use their own packet number space,
+// 0-RTT and 1-RTT packets share the application data packet number space (RFC 9000, Section 12.3).
+type EncryptionLevel uint8
+
+const (
+	// EncryptionInitial is the Initial encryption level
+	EncryptionInitial EncryptionLevel = 1 + iota
+	// EncryptionHandshake is the Handshake encryption level
+	EncryptionHandshake
+	// Encryption0RTT is the 0-RTT encryption level
+	Encryption0RTT
+	// Encryption1RTT is the 1-RTT encryption level
+	Encryption1RTT
+)
+
+func (e EncryptionLevel) String() string {
+	switch e {
+	case EncryptionInitial:
+		return "Initial"
+	case EncryptionHandshake:
+		return "Handshake"
+	case Encryption0RTT:
+		return "0-RTT"
+	case Encryption1RTT:
+		return "1-RTT"
+	}
+	return "unknown"
+}
+
+// The RTTStats contain the RTT statistics of a connection.
+// They are updated by the connection, before the congestion controller is notified of acknowledged packets.
+type RTTStats interface {
+	// MinRTT is the minimum RTT observed on the path.
+	MinRTT() time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT() time.Duration
+	// SmoothedRTT is the smoothed RTT (RFC 9002, Section 5.3).
+	// It is 0 until the first RTT sample was taken.
+	SmoothedRTT() time.Duration
+	// MeanDeviation is the RTT variation (RFC 9002, Section 5.3).
+	MeanDeviation() time.Duration
+	// MaxAckDelay is the peer's max_ack_delay.
+	MaxAckDelay() time.Duration
+	// PTO is the probe timeout (RFC 9002, Section 6.2.1).
+	PTO(includeMaxAckDelay bool) time.Duration
+}
+
+// A CongestionControl implements a congestion control algorithm.
+// All methods are called from the connection's run loop, so implementations don't need to be thread-safe.
+type CongestionControl interface {
+	// TimeUntilSend returns when the next packet should be sent.
+	// It returns the zero value of time.Time if a packet can be sent immediately.
+	TimeUntilSend(bytesInFlight ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending a packet right now.
+	HasPacingBudget() bool
+	// OnPacketSent is called when a packet is sent.
+	// bytesInFlight is the number of bytes in flight, including this packet.
+	OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool)
+	// CanSend says if the congestion window allows sending more packets.
+	CanSend(bytesInFlight ByteCount) bool
+	// MaybeExitSlowStart is called when the RTT estimate was updated.
+	MaybeExitSlowStart()
+	// OnPacketAcked is called for every ack-eliciting packet that is acknowledged.
+	OnPacketAcked(number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time)
+	// OnPacketLost is called for every ack-eliciting packet that is declared lost.
+	OnPacketLost(number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount)
+	// OnRetransmissionTimeout is called when the retransmission timer fires.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// OnApplicationLimited is called when the congestion window would have allowed sending more data,
+	// but the connection didn't have any data to send.
+	OnApplicationLimited(bytesInFlight ByteCount)
+	// SetMaxDatagramSize is called when the maximum packet size changes, e.g. due to Path MTU Discovery.
+	SetMaxDatagramSize(ByteCount)
+	// InSlowStart says if the congestion controller is in slow start.
+	InSlowStart() bool
+	// InRecovery says if the congestion controller is in recovery.
+	InRecovery() bool
+	// GetCongestionWindow returns the congestion window.
+	GetCongestionWindow() ByteCount
+}
+
+// A SpuriousLossHandler is a CongestionControl that wants to be notified of spurious losses,
+// e.g. to undo the congestion window reduction.
+type SpuriousLossHandler interface {
+	// OnSpuriousLoss is called when a packet that was declared lost is acknowledged.
+	// OnPacketLost was called for this packet before.
+	OnSpuriousLoss(number PacketNumber, lostBytes ByteCount)
+}
+
+// An ECNCongestionHandler is a CongestionControl that reacts to ECN-CE marks.
+// ECN is only used if the CongestionControl implements this interface:
+// Otherwise, packets are sent without ECN markings, since CE marks wouldn't reduce the sending rate.
+type ECNCongestionHandler interface {
+	// OnECNCongestion is called when the peer reports that packets were marked ECN-CE (RFC 9002, Section 7.1).
+	// largestAcked is the largest packet number acknowledged by the ACK frame that reported the new CE marks.
+	// It is called after OnPacketAcked was called for the packets acknowledged by this ACK frame.
+	OnECNCongestion(largestAcked PacketNumber, priorInFlight ByteCount)
+}
+
+// A PersistentCongestionHandler is a CongestionControl that reacts to persistent congestion.
+type PersistentCongestionHandler interface {
+	// OnPersistentCongestion is called when persistent congestion is detected (RFC 9002, Section 7.6),
+	// after OnPacketLost was called for the packets that were declared lost.
+	OnPersistentCongestion()
+}
+
+// A PacketNumberSpaceHandler is a CongestionControl that keeps state for individual packets.
+// Packet numbers are only unique within a packet number space.
+// If a CongestionControl implements this interface, OnPacketSentAtLevel, OnPacketAckedAtLevel and OnPacketLostAtLevel
+// are called instead of OnPacketSent, OnPacketAcked and OnPacketLost.
+type PacketNumberSpaceHandler interface {
+	// OnPacketSentAtLevel is called when a packet is sent.
+	OnPacketSentAtLevel(encLevel EncryptionLevel, sentTime time.Time, bytesInFlight ByteCount, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool)
+	// OnPacketAckedAtLevel is called for every ack-eliciting packet that is acknowledged.
+	OnPacketAckedAtLevel(encLevel EncryptionLevel, number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time)
+	// OnPacketLostAtLevel is called for every ack-eliciting packet that is declared lost.
+	OnPacketLostAtLevel(encLevel EncryptionLevel, number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount)
+	// OnPacketsDropped is called when the packets sent at an encryption level are dropped without being acknowledged or declared lost,
+	// i.e. when the Initial or Handshake keys are dropped, when 0-RTT is rejected, or when a Retry is received.
+	OnPacketsDropped(encLevel EncryptionLevel)
+}
+
+// Parameters are the parameters that a CongestionControl is created with.
+type Parameters struct {
+	// The Perspective of this endpoint.
+	Perspective Perspective
+	// RemoteAddr is the address of the peer.
+	RemoteAddr net.Addr
+	// RTTStats are the RTT statistics of the connection.
+	RTTStats RTTStats
+	// InitialMaxDatagramSize is the initial maximum packet size.
+	InitialMaxDatagramSize ByteCount
+	// Tracer is the tracer of the connection. It might be nil.
+	// It can be used to report changes of the congestion state.
+	Tracer logging.ConnectionTracer
+	// SlowStart is the slow start algorithm used by NewReno and NewCubic.
+	// quic-go leaves it at its zero value, SlowStartHybrid.
+	// NewRenoHyStartPlusPlus and NewCubicHyStartPlusPlus use HyStart++ instead.
+	SlowStart SlowStartAlgorithm
+}
+
+// A SlowStartAlgorithm is the algorithm used to decide when to leave slow start.
+type SlowStartAlgorithm uint8
+
+const (
+	// SlowStartHybrid is the hybrid slow start algorithm used by Chromium.
+	// It leaves slow start as soon as it detects an increase of the RTT.
+	SlowStartHybrid SlowStartAlgorithm = iota
+	// SlowStartHyStartPlusPlus is HyStart++ (RFC 9406).
+	// When it detects an increase of the RTT, it first enters Conservative Slow Start,
+	// and only leaves slow start if the RTT increase persists for multiple rounds.
+	SlowStartHyStartPlusPlus
+)
@@ -0,0 +1,133 @@
+package congestion
+
+import (
+	"time"
+
+	internalcongestion "github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// internalSendAlgorithm is implemented by all congestion controllers in the internal/congestion package.
+type internalSendAlgorithm interface {
+	internalcongestion.SendAlgorithmWithDebugInfos
+	internalcongestion.SpuriousLossHandler
+	internalcongestion.PersistentCongestionHandler
+}
+
+// sendAlgorithm makes a congestion controller of the internal/congestion package usable as a CongestionControl.
+type sendAlgorithm struct {
+	s internalSendAlgorithm
+}
+
+func newSendAlgorithm(s internalSendAlgorithm) *sendAlgorithm {
+	return &sendAlgorithm{s: s}
+}
+
+func (a *sendAlgorithm) TimeUntilSend(bytesInFlight ByteCount) time.Time {
+	return a.s.TimeUntilSend(protocol.ByteCount(bytesInFlight))
+}
+
+func (a *sendAlgorithm) HasPacingBudget() bool {
+	return a.s.HasPacingBudget()
+}
+
+func (a *sendAlgorithm) OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool) {
+	a.s.OnPacketSent(sentTime, protocol.ByteCount(bytesInFlight), protocol.PacketNumber(packetNumber), protocol.ByteCount(bytes), isRetransmittable)
+}
+
+func (a *sendAlgorithm) CanSend(bytesInFlight ByteCount) bool {
+	return a.s.CanSend(protocol.ByteCount(bytesInFlight))
+}
+
+func (a *sendAlgorithm) MaybeExitSlowStart() {
+	a.s.MaybeExitSlowStart()
+}
+
+func (a *sendAlgorithm) OnPacketAcked(number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time) {
+	a.s.OnPacketAcked(protocol.PacketNumber(number), protocol.ByteCount(ackedBytes), protocol.ByteCount(priorInFlight), eventTime)
+}
+
+func (a *sendAlgorithm) OnPacketLost(number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount) {
+	a.s.OnPacketLost(protocol.PacketNumber(number), protocol.ByteCount(lostBytes), protocol.ByteCount(priorInFlight))
+}
+
+func (a *sendAlgorithm) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	a.s.OnRetransmissionTimeout(packetsRetransmitted)
+}
+
+func (a *sendAlgorithm) OnApplicationLimited(bytesInFlight ByteCount) {
+	a.s.OnApplicationLimited(protocol.ByteCount(bytesInFlight))
+}
+
+func (a *sendAlgorithm) SetMaxDatagramSize(s ByteCount) {
+	a.s.SetMaxDatagramSize(protocol.ByteCount(s))
+}
+
+func (a *sendAlgorithm) InSlowStart() bool {
+	return a.s.InSlowStart()
+}
+
+func (a *sendAlgorithm) InRecovery() bool {
+	return a.s.InRecovery()
+}
+
+func (a *sendAlgorithm) GetCongestionWindow() ByteCount {
+	return ByteCount(a.s.GetCongestionWindow())
+}
+
+func (a *sendAlgorithm) OnSpuriousLoss(number PacketNumber, lostBytes ByteCount) {
+	a.s.OnSpuriousLoss(protocol.PacketNumber(number), protocol.ByteCount(lostBytes))
+}
+
+func (a *sendAlgorithm) OnPersistentCongestion() {
+	a.s.OnPersistentCongestion()
+}
+
+// OnPacketSentAtLevel passes the encryption level to congestion controllers that keep state for individual packets.
+// The other congestion controllers ignore it.
+func (a *sendAlgorithm) OnPacketSentAtLevel(encLevel EncryptionLevel, sentTime time.Time, bytesInFlight ByteCount, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool) {
+	s, ok := a.s.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm)
+	if !ok {
+		a.OnPacketSent(sentTime, bytesInFlight, packetNumber, bytes, isRetransmittable)
+		return
+	}
+	s.OnPacketSentAtLevel(protocol.EncryptionLevel(encLevel), sentTime, protocol.ByteCount(bytesInFlight), protocol.PacketNumber(packetNumber), protocol.ByteCount(bytes), isRetransmittable)
+}
+
+func (a *sendAlgorithm) OnPacketAckedAtLevel(encLevel EncryptionLevel, number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time) {
+	s, ok := a.s.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm)
+	if !ok {
+		a.OnPacketAcked(number, ackedBytes, priorInFlight, eventTime)
+		return
+	}
+	s.OnPacketAckedAtLevel(protocol.EncryptionLevel(encLevel), protocol.PacketNumber(number), protocol.ByteCount(ackedBytes), protocol.ByteCount(priorInFlight), eventTime)
+}
+
+func (a *sendAlgorithm) OnPacketLostAtLevel(encLevel EncryptionLevel, number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount) {
+	s, ok := a.s.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm)
+	if !ok {
+		a.OnPacketLost(number, lostBytes, priorInFlight)
+		return
+	}
+	s.OnPacketLostAtLevel(protocol.EncryptionLevel(encLevel), protocol.PacketNumber(number), protocol.ByteCount(lostBytes), protocol.ByteCount(priorInFlight))
+}
+
+func (a *sendAlgorithm) OnPacketsDropped(encLevel EncryptionLevel) {
+	if s, ok := a.s.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+		s.OnPacketsDropped(protocol.EncryptionLevel(encLevel))
+	}
+}
+
+// ecnSendAlgorithm is a sendAlgorithm for a congestion controller that reacts to ECN-CE marks.
+type ecnSendAlgorithm struct {
+	sendAlgorithm
+	ecn internalcongestion.ECNCongestionHandler
+}
+
+func newECNSendAlgorithm(s internalcongestion.SendAlgorithmWithHandlers) *ecnSendAlgorithm {
+	return &ecnSendAlgorithm{sendAlgorithm: sendAlgorithm{s: s}, ecn: s}
+}
+
+func (a *ecnSendAlgorithm) OnECNCongestion(largestAcked PacketNumber, priorInFlight ByteCount) {
+	a.ecn.OnECNCongestion(protocol.PacketNumber(largestAcked), protocol.ByteCount(priorInFlight))
+}
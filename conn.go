@@ -12,9 +12,22 @@ import (
 
 type connection interface {
 	ReadPacket() (*receivedPacket, error)
-	WritePacket(b []byte, addr net.Addr, oob []byte) (int, error)
+	// WritePacket writes a packet. If gsoSize is not 0, b contains multiple packets of size gsoSize
+	// (only the last one may be smaller), which are sent using a single syscall.
+	// This must only be used if the connection supports GSO.
+	WritePacket(b []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) (int, error)
 	LocalAddr() net.Addr
+	SetReadDeadline(time.Time) error
 	io.Closer
+	capabilities() connCapabilities
+}
+
+// connCapabilities are the features supported by a connection.
+type connCapabilities struct {
+	// ECN says if the ECN bits can be set on outgoing packets.
+	ECN bool
+	// GSO says if multiple packets can be sent using a single syscall (UDP generic segmentation offload).
+	GSO bool
 }
 
 // If the PacketConn passed to Dial or Listen satisfies this interface, quic-go will read the ECN bits from the IP header.
@@ -60,6 +73,8 @@ func (c *basicConn) ReadPacket() (*receivedPacket, error) {
 	}, nil
 }
 
-func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte) (n int, err error) {
+func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte, _ uint16, _ protocol.ECN) (n int, err error) {
 	return c.PacketConn.WriteTo(b, addr)
 }
+
+func (c *basicConn) capabilities() connCapabilities { return connCapabilities{} }
@@ -14,3 +14,5 @@ func inspectReadBuffer(interface{}) (int, error) {
 }
 
 func (i *packetInfo) OOB() []byte { return nil }
+
+func isGSOError(error) bool { return false }
@@ -3,7 +3,14 @@
 
 package quic
 
-import "golang.org/x/sys/unix"
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// The size of the data of the IP_TOS control message used to set the ECN bits on outgoing packets.
+const ecnIPv4DataLen = 1
 
 const msgTypeIPTOS = unix.IP_RECVTOS
 
@@ -18,3 +25,15 @@ const (
 )
 
 const batchSize = 8
+
+// UDP GRO is only supported on Linux.
+// Since it is never enabled, the message type is never checked.
+const msgTypeUDPGRO = 0
+
+func isGSOSupported(syscall.RawConn) bool { return false }
+
+func enableGRO(syscall.RawConn) bool { return false }
+
+func appendUDPSegmentSizeMsg(b []byte, _ uint16) []byte { return b }
+
+func isGSOError(error) bool { return false }
@@ -3,7 +3,17 @@
 
 package quic
 
-import "golang.org/x/sys/unix"
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The size of the data of the IP_TOS control message used to set the ECN bits on outgoing packets.
+const ecnIPv4DataLen = 1
 
 const msgTypeIPTOS = unix.IP_TOS
 
@@ -17,4 +27,62 @@ const (
 	msgTypeIPv6PKTINFO = unix.IPV6_PKTINFO
 )
 
+// UDP_SEGMENT and UDP_GRO are not defined in the version of x/sys/unix we're using.
+// See https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git/tree/include/uapi/linux/udp.h.
+const (
+	udpSegment = 103
+	udpGRO     = 104
+)
+
+const msgTypeUDPGRO = udpGRO
+
 const batchSize = 8 // needs to smaller than MaxUint8 (otherwise the type of oobConn.readPos has to be changed)
+
+// isGSOSupported says if the kernel supports UDP GSO (Linux 4.18 and newer).
+// Sending might still fail if the network interface doesn't support it, see isGSOError.
+func isGSOSupported(conn syscall.RawConn) bool {
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		_, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_UDP, udpSegment)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// enableGRO enables UDP GRO on the socket (Linux 5.0 and newer).
+// The kernel then coalesces multiple datagrams received from the same sender into a single message.
+func enableGRO(conn syscall.RawConn) bool {
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, udpGRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// appendUDPSegmentSizeMsg appends the control message that tells the kernel to split the payload
+// into multiple UDP datagrams of the given size.
+func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
+	startLen := len(b)
+	const dataLen = 2 // the segment size is a uint16
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.IPPROTO_UDP
+	h.Type = udpSegment
+	h.SetLen(unix.CmsgLen(dataLen))
+	*(*uint16)(unsafe.Pointer(&b[startLen+unix.CmsgSpace(0)])) = size
+	return b
+}
+
+// isGSOError says if a write failed because GSO is not supported on the network interface.
+// The kernel returns EIO if the device driver doesn't support TX checksum offloading,
+// which is a requirement for UDP_SEGMENT (see udp(7)).
+func isGSOError(err error) bool {
+	var serr *os.SyscallError
+	if errors.As(err, &serr) {
+		return serr.Err == unix.EIO
+	}
+	return false
+}
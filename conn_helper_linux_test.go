@@ -0,0 +1,120 @@
+//go:build linux
+// +build linux
+
+package quic
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sys/unix"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GSO", func() {
+	gsoErr := &net.OpError{Op: "write", Err: &os.SyscallError{Syscall: "sendmsg", Err: unix.EIO}}
+
+	It("detects GSO errors", func() {
+		Expect(isGSOError(gsoErr)).To(BeTrue())
+		Expect(isGSOError(&os.SyscallError{Syscall: "sendmsg", Err: unix.EINVAL})).To(BeFalse())
+		Expect(isGSOError(errors.New("foobar"))).To(BeFalse())
+	})
+
+	It("falls back to sending packets one by one if the network interface doesn't support GSO", func() {
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+		rawConn := NewMockConnection(mockCtrl)
+		rawConn.EXPECT().capabilities().Return(connCapabilities{ECN: true, GSO: true}).AnyTimes()
+		c := newSendConn(rawConn, remoteAddr, nil)
+		Expect(c.capabilities().GSO).To(BeTrue())
+		gomock.InOrder(
+			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(4), protocol.ECT0).Return(0, gsoErr),
+			rawConn.EXPECT().WritePacket([]byte("foob"), remoteAddr, gomock.Any(), uint16(0), protocol.ECT0).Return(4, nil),
+			rawConn.EXPECT().WritePacket([]byte("ar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECT0).Return(2, nil),
+		)
+		Expect(c.Write([]byte("foobar"), 4, protocol.ECT0)).To(Succeed())
+		Expect(c.capabilities()).To(Equal(connCapabilities{ECN: true}))
+	})
+})
+
+var _ = Describe("GRO", func() {
+	appendCmsg := func(b []byte, level, typ int32, data []byte) []byte {
+		startLen := len(b)
+		b = append(b, make([]byte, unix.CmsgSpace(len(data)))...)
+		h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+		h.Level = level
+		h.Type = typ
+		h.SetLen(unix.CmsgLen(len(data)))
+		copy(b[startLen+unix.CmsgSpace(0):], data)
+		return b
+	}
+
+	It("splits coalesced datagrams", func() {
+		udpConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer udpConn.Close()
+		oobConn, err := newConn(udpConn)
+		Expect(err).ToNot(HaveOccurred())
+		if oobConn.groBuffers == nil {
+			Skip("GRO not supported by the kernel")
+		}
+		batchConn := NewMockBatchConn(mockCtrl)
+		oobConn.batchConn = batchConn
+
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+		segmentSize := int32(6)
+		var oob []byte
+		oob = appendCmsg(oob, unix.IPPROTO_IP, unix.IP_TOS, []byte{byte(protocol.ECT0)})
+		oob = appendCmsg(oob, unix.IPPROTO_UDP, udpGRO, (*[4]byte)(unsafe.Pointer(&segmentSize))[:])
+		batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, _ int) (int, error) {
+			ms[0].N = copy(ms[0].Buffers[0], "foobarfoobarfoo")
+			ms[0].NN = copy(ms[0].OOB, oob)
+			ms[0].Addr = remoteAddr
+			ms[1].N = copy(ms[1].Buffers[0], "raboof")
+			ms[1].NN = 0
+			ms[1].Addr = remoteAddr
+			return 2, nil
+		})
+
+		var buffers []*packetBuffer
+		var groBuf *groBuffer
+		for _, data := range []string{"foobar", "foobar", "foo"} {
+			p, err := oobConn.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte(data)))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+			Expect(p.remoteAddr).To(Equal(remoteAddr))
+			// the datagrams are not copied, but point into the buffer that the message was read into
+			Expect(p.buffer.groBuffer).ToNot(BeNil())
+			if groBuf != nil {
+				Expect(p.buffer.groBuffer).To(BeIdenticalTo(groBuf))
+			}
+			groBuf = p.buffer.groBuffer
+			Expect(p.buffer.Data).To(HaveCap(len(data)))
+			Expect(buffers).ToNot(ContainElement(BeIdenticalTo(p.buffer)))
+			buffers = append(buffers, p.buffer)
+		}
+		// a message that wasn't coalesced is copied into a regular packet buffer
+		p, err := oobConn.ReadPacket()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.data).To(Equal([]byte("raboof")))
+		Expect(p.ecn).To(Equal(protocol.ECNNon))
+		Expect(p.buffer.groBuffer).To(BeNil())
+		Expect(p.buffer.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
+		Expect(atomic.LoadInt32(&groBuf.refCount)).To(BeEquivalentTo(3))
+		for _, b := range buffers {
+			b.Release()
+		}
+		// the buffer is returned to the pool once all datagrams are released
+		Expect(atomic.LoadInt32(&groBuf.refCount)).To(BeZero())
+		p.buffer.Release()
+	})
+})
@@ -10,11 +10,15 @@ import (
 )
 
 type connIDGenerator struct {
-	connIDLen  int
-	highestSeq uint64
+	generateConnectionID func() (protocol.ConnectionID, error)
+	connIDLen            int
+	highestSeq           uint64
 
 	activeSrcConnIDs        map[uint64]protocol.ConnectionID
 	initialClientDestConnID protocol.ConnectionID
+	// preferredAddressConnID is the connection ID sent in the preferred_address transport parameter.
+	// It is only set until it is registered with the session runner.
+	preferredAddressConnID protocol.ConnectionID
 
 	addConnectionID        func(protocol.ConnectionID)
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken
@@ -29,6 +33,7 @@ type connIDGenerator struct {
 func newConnIDGenerator(
 	initialConnectionID protocol.ConnectionID,
 	initialClientDestConnID protocol.ConnectionID, // nil for the client
+	generateConnectionID func() (protocol.ConnectionID, error),
 	addConnectionID func(protocol.ConnectionID),
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken,
 	removeConnectionID func(protocol.ConnectionID),
@@ -38,6 +43,7 @@ func newConnIDGenerator(
 	version protocol.VersionNumber,
 ) *connIDGenerator {
 	m := &connIDGenerator{
+		generateConnectionID:   generateConnectionID,
 		connIDLen:              initialConnectionID.Len(),
 		activeSrcConnIDs:       make(map[uint64]protocol.ConnectionID),
 		addConnectionID:        addConnectionID,
@@ -61,8 +67,12 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
+	// The peer might use the connection ID sent in the preferred_address transport parameter
+	// as soon as it receives our transport parameters, so we need to register it now.
+	if m.preferredAddressConnID != nil {
+		m.addConnectionID(m.preferredAddressConnID)
+		m.preferredAddressConnID = nil
+	}
 	for i := uint64(len(m.activeSrcConnIDs)); i < utils.MinUint64(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -99,7 +109,7 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 }
 
 func (m *connIDGenerator) issueNewConnID() error {
-	connID, err := protocol.GenerateConnectionID(m.connIDLen)
+	connID, err := m.generateConnectionID()
 	if err != nil {
 		return err
 	}
@@ -114,6 +124,20 @@ func (m *connIDGenerator) issueNewConnID() error {
 	return nil
 }
 
+// GeneratePreferredAddressConnID generates the connection ID for the preferred_address transport parameter.
+// It uses sequence number 1, and must therefore be called before any other connection IDs are issued.
+// The connection ID is registered with the session runner when SetMaxActiveConnIDs is called.
+func (m *connIDGenerator) GeneratePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	connID, err := m.generateConnectionID()
+	if err != nil {
+		return nil, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq++
+	m.activeSrcConnIDs[m.highestSeq] = connID
+	m.preferredAddressConnID = connID
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
 func (m *connIDGenerator) SetHandshakeComplete() {
 	if m.initialClientDestConnID != nil {
 		m.retireConnectionID(m.initialClientDestConnID)
@@ -121,6 +145,28 @@ func (m *connIDGenerator) SetHandshakeComplete() {
 	}
 }
 
+// SequenceNumber returns the sequence number of an active connection ID.
+func (m *connIDGenerator) SequenceNumber(connID protocol.ConnectionID) (uint64, bool) {
+	for seq, c := range m.activeSrcConnIDs {
+		if c.Equal(connID) {
+			return seq, true
+		}
+	}
+	return 0, false
+}
+
+// ActiveConnIDs returns all connection IDs that the peer might currently use to address us.
+func (m *connIDGenerator) ActiveConnIDs() []protocol.ConnectionID {
+	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs)+1)
+	if m.initialClientDestConnID != nil {
+		connIDs = append(connIDs, m.initialClientDestConnID)
+	}
+	for _, connID := range m.activeSrcConnIDs {
+		connIDs = append(connIDs, connID)
+	}
+	return connIDs
+}
+
 func (m *connIDGenerator) RemoveAll() {
 	if m.initialClientDestConnID != nil {
 		m.removeConnectionID(m.initialClientDestConnID)
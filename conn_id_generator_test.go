@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -36,6 +37,7 @@ var _ = Describe("Connection ID Generator", func() {
 		g = newConnIDGenerator(
 			initialConnID,
 			initialClientDestConnID,
+			func() (protocol.ConnectionID, error) { return protocol.GenerateConnectionID(initialConnID.Len()) },
 			func(c protocol.ConnectionID) { addedConnIDs = append(addedConnIDs, c) },
 			connIDToToken,
 			func(c protocol.ConnectionID) { removedConnIDs = append(removedConnIDs, c) },
@@ -64,6 +66,25 @@ var _ = Describe("Connection ID Generator", func() {
 		}
 	})
 
+	It("uses the connection ID generation function", func() {
+		var counter byte
+		g.generateConnectionID = func() (protocol.ConnectionID, error) {
+			counter++
+			return protocol.ConnectionID{counter, counter, counter, counter, counter}, nil
+		}
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{{1, 1, 1, 1, 1}, {2, 2, 2, 2, 2}}))
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).ConnectionID).To(Equal(protocol.ConnectionID{1, 1, 1, 1, 1}))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).ConnectionID).To(Equal(protocol.ConnectionID{2, 2, 2, 2, 2}))
+	})
+
+	It("returns errors from the connection ID generation function", func() {
+		testErr := errors.New("test error")
+		g.generateConnectionID = func() (protocol.ConnectionID, error) { return nil, testErr }
+		Expect(g.SetMaxActiveConnIDs(3)).To(MatchError(testErr))
+	})
+
 	It("limits the number of connection IDs that it issues", func() {
 		Expect(g.SetMaxActiveConnIDs(9999999)).To(Succeed())
 		Expect(retiredConnIDs).To(BeEmpty())
@@ -71,6 +92,27 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(queuedFrames).To(HaveLen(protocol.MaxIssuedConnectionIDs - 1))
 	})
 
+	It("generates the connection ID for the preferred_address", func() {
+		connID, token, err := g.GeneratePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(7))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		// the connection ID is registered when the transport parameters are received
+		Expect(addedConnIDs).To(BeEmpty())
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(addedConnIDs).To(HaveLen(3))
+		Expect(addedConnIDs[0]).To(Equal(connID))
+		// the connection ID sent in the preferred_address counts towards the limit
+		Expect(queuedFrames).To(HaveLen(2))
+		for i, f := range queuedFrames {
+			Expect(f.(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(i + 2))
+		}
+		Expect(g.ActiveConnIDs()).To(ContainElement(connID))
+		// the peer can retire the connection ID
+		Expect(g.Retire(1, protocol.ConnectionID{})).To(Succeed())
+		Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+	})
+
 	// SetMaxActiveConnIDs is called twice when we dialing a 0-RTT connection:
 	// once for the restored from the old connections, once when we receive the transport parameters
 	Context("dealing with 0-RTT", func() {
@@ -158,6 +200,27 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(retiredConnIDs[0]).To(Equal(initialClientDestConnID))
 	})
 
+	It("returns all active connection IDs", func() {
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		Expect(g.ActiveConnIDs()).To(ConsistOf(append([]protocol.ConnectionID{initialConnID, initialClientDestConnID}, addedConnIDs...)))
+		g.SetHandshakeComplete()
+		Expect(g.ActiveConnIDs()).To(ConsistOf(append([]protocol.ConnectionID{initialConnID}, addedConnIDs...)))
+	})
+
+	It("returns the sequence number of a connection ID", func() {
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		seq, ok := g.SequenceNumber(initialConnID)
+		Expect(ok).To(BeTrue())
+		Expect(seq).To(BeZero())
+		Expect(queuedFrames).ToNot(BeEmpty())
+		nf := queuedFrames[len(queuedFrames)-1].(*wire.NewConnectionIDFrame)
+		seq, ok = g.SequenceNumber(nf.ConnectionID)
+		Expect(ok).To(BeTrue())
+		Expect(seq).To(Equal(nf.SequenceNumber))
+		_, ok = g.SequenceNumber(protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef})
+		Expect(ok).To(BeFalse())
+	})
+
 	It("removes all connection IDs", func() {
 		Expect(g.SetMaxActiveConnIDs(5)).To(Succeed())
 		Expect(queuedFrames).To(HaveLen(4))
@@ -12,7 +12,9 @@ import (
 type connIDManager struct {
 	queue utils.NewConnectionIDList
 
-	handshakeComplete         bool
+	handshakeComplete bool
+	// rotationDisabled is set when using multipath, since every path keeps using its connection ID.
+	rotationDisabled          bool
 	activeSequenceNumber      uint64
 	highestRetired            uint64
 	activeConnectionID        protocol.ConnectionID
@@ -135,6 +137,10 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 }
 
 func (h *connIDManager) updateConnectionID() {
+	h.switchToConnID(h.queue.Remove(h.queue.Front()))
+}
+
+func (h *connIDManager) switchToConnID(c utils.NewConnectionID) {
 	h.queueControlFrame(&wire.RetireConnectionIDFrame{
 		SequenceNumber: h.activeSequenceNumber,
 	})
@@ -143,10 +149,9 @@ func (h *connIDManager) updateConnectionID() {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
 
-	front := h.queue.Remove(h.queue.Front())
-	h.activeSequenceNumber = front.SequenceNumber
-	h.activeConnectionID = front.ConnectionID
-	h.activeStatelessResetToken = &front.StatelessResetToken
+	h.activeSequenceNumber = c.SequenceNumber
+	h.activeConnectionID = c.ConnectionID
+	h.activeStatelessResetToken = &c.StatelessResetToken
 	h.packetsSinceLastChange = 0
 	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
 	h.addStatelessResetToken(*h.activeStatelessResetToken)
@@ -181,7 +186,7 @@ func (h *connIDManager) SentPacket() {
 }
 
 func (h *connIDManager) shouldUpdateConnID() bool {
-	if !h.handshakeComplete {
+	if !h.handshakeComplete || h.rotationDisabled {
 		return false
 	}
 	// initiate the first change as early as possible (after handshake completion)
@@ -202,6 +207,45 @@ func (h *connIDManager) Get() protocol.ConnectionID {
 	return h.activeConnectionID
 }
 
+// GetConnIDForPath returns an unused connection ID, to be used when probing a new path.
+// The connection ID is removed from the queue, so it won't be used on the current path.
+// If the peer uses zero-length connection IDs, a zero-length connection ID is returned.
+func (h *connIDManager) GetConnIDForPath() (utils.NewConnectionID, bool) {
+	if h.activeConnectionID.Len() == 0 {
+		return utils.NewConnectionID{}, true
+	}
+	if h.queue.Len() == 0 {
+		return utils.NewConnectionID{}, false
+	}
+	return h.queue.Remove(h.queue.Front()), true
+}
+
+// SwitchToConnID starts using a connection ID obtained from GetConnIDForPath,
+// after the connection migrated to the new path.
+// The currently active connection ID is retired.
+func (h *connIDManager) SwitchToConnID(c utils.NewConnectionID) {
+	if c.ConnectionID.Len() == 0 {
+		return
+	}
+	h.switchToConnID(c)
+}
+
+// RetireConnIDForPath retires a connection ID obtained from GetConnIDForPath,
+// if the path it was used on was abandoned.
+func (h *connIDManager) RetireConnIDForPath(c utils.NewConnectionID) {
+	if c.ConnectionID.Len() == 0 {
+		return
+	}
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{SequenceNumber: c.SequenceNumber})
+}
+
+// DisableRotation stops changing the connection ID used on the current path.
+// It is called when using multipath, since the packet number space of a path is
+// identified by the sequence number of the connection ID used on that path.
+func (h *connIDManager) DisableRotation() {
+	h.rotationDisabled = true
+}
+
 func (h *connIDManager) SetHandshakeComplete() {
 	h.handshakeComplete = true
 }
@@ -259,6 +259,24 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(m.Get()).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
 	})
 
+	It("doesn't update the connection ID if rotation is disabled", func() {
+		m.SetHandshakeComplete()
+		m.DisableRotation()
+		Expect(m.Add(&wire.NewConnectionIDFrame{
+			SequenceNumber:      1,
+			ConnectionID:        protocol.ConnectionID{1, 2, 3, 4},
+			StatelessResetToken: protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+		})).To(Succeed())
+		for i := 0; i < 2*protocol.PacketsPerConnectionID; i++ {
+			m.SentPacket()
+		}
+		Expect(m.Get()).To(Equal(initialConnID))
+		// the connection ID can still be used for a new path
+		c, ok := m.GetConnIDForPath()
+		Expect(ok).To(BeTrue())
+		Expect(c.SequenceNumber).To(BeEquivalentTo(1))
+	})
+
 	It("initiates subsequent updates when enough packets are sent", func() {
 		var s uint8
 		for s = uint8(1); s < protocol.MaxActiveConnectionIDs; s++ {
@@ -361,4 +379,56 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("connection IDs for new paths", func() {
+		BeforeEach(func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ConnectionID{1, 2, 3, 4},
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+		})
+
+		It("hands out unused connection IDs", func() {
+			c, ok := m.GetConnIDForPath()
+			Expect(ok).To(BeTrue())
+			Expect(c.SequenceNumber).To(BeEquivalentTo(1))
+			Expect(c.ConnectionID).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
+			Expect(m.queue.Len()).To(BeZero())
+			_, ok = m.GetConnIDForPath()
+			Expect(ok).To(BeFalse())
+			// the connection ID is not used on the current path
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(initialConnID))
+		})
+
+		It("switches to a connection ID", func() {
+			c, ok := m.GetConnIDForPath()
+			Expect(ok).To(BeTrue())
+			m.SwitchToConnID(c)
+			Expect(m.Get()).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}))
+			Expect(frameQueue).To(HaveLen(1))
+			Expect(frameQueue[0].(*wire.RetireConnectionIDFrame).SequenceNumber).To(BeZero())
+		})
+
+		It("retires a connection ID that won't be used", func() {
+			c, ok := m.GetConnIDForPath()
+			Expect(ok).To(BeTrue())
+			m.RetireConnIDForPath(c)
+			Expect(frameQueue).To(HaveLen(1))
+			Expect(frameQueue[0].(*wire.RetireConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(1))
+			Expect(m.Get()).To(Equal(initialConnID))
+		})
+
+		It("uses zero-length connection IDs, if the peer uses them", func() {
+			m.ChangeInitialConnID(protocol.ConnectionID{})
+			c, ok := m.GetConnIDForPath()
+			Expect(ok).To(BeTrue())
+			Expect(c.ConnectionID.Len()).To(BeZero())
+			m.SwitchToConnID(c)
+			m.RetireConnIDForPath(c)
+			Expect(frameQueue).To(BeEmpty())
+		})
+	})
 })
@@ -10,6 +10,7 @@ import (
 	"net"
 	"syscall"
 	"time"
+	"unsafe"
 
 	"golang.org/x/net/ipv4"
 	"golang.org/x/net/ipv6"
@@ -62,6 +63,15 @@ type oobConn struct {
 	// Packets received from the kernel, but not yet returned by ReadPacket().
 	messages []ipv4.Message
 	buffers  [batchSize]*packetBuffer
+
+	// When UDP GRO is enabled, the kernel coalesces multiple datagrams into a single message.
+	// Messages are then read into these (pooled) buffers, and the datagrams point into these buffers.
+	// It is nil if GRO is not enabled.
+	groBuffers []*groBuffer
+	// Datagrams split from a coalesced message, but not yet returned by ReadPacket().
+	segments []*receivedPacket
+
+	cap connCapabilities
 }
 
 var _ connection = &oobConn{}
@@ -113,6 +123,15 @@ func newConn(c OOBCapablePacketConn) (*oobConn, error) {
 		}
 	}
 
+	supportsGSO := isGSOSupported(rawConn)
+	if supportsGSO {
+		utils.DefaultLogger.Debugf("Activating GSO.")
+	}
+	groEnabled := enableGRO(rawConn)
+	if groEnabled {
+		utils.DefaultLogger.Debugf("Activating GRO.")
+	}
+
 	// Allows callers to pass in a connection that already satisfies batchConn interface
 	// to make use of the optimisation. Otherwise, ipv4.NewPacketConn would unwrap the file descriptor
 	// via SyscallConn(), and read it that way, which might not be what the caller wants.
@@ -128,18 +147,34 @@ func newConn(c OOBCapablePacketConn) (*oobConn, error) {
 		batchConn:            bc,
 		messages:             make([]ipv4.Message, batchSize),
 		readPos:              batchSize,
+		cap:                  connCapabilities{ECN: true, GSO: supportsGSO},
 	}
 	for i := 0; i < batchSize; i++ {
 		oobConn.messages[i].OOB = make([]byte, oobBufferSize)
 	}
+	if groEnabled {
+		// The buffers are taken from the pool when reading the first batch of messages.
+		oobConn.groBuffers = make([]*groBuffer, batchSize)
+	}
 	return oobConn, nil
 }
 
 func (c *oobConn) ReadPacket() (*receivedPacket, error) {
+	if len(c.segments) > 0 {
+		p := c.segments[0]
+		c.segments[0] = nil
+		c.segments = c.segments[1:]
+		return p, nil
+	}
 	if len(c.messages) == int(c.readPos) { // all messages read. Read the next batch of messages.
 		c.messages = c.messages[:batchSize]
 		// replace buffers data buffers up to the packet that has been consumed during the last ReadBatch call
 		for i := uint8(0); i < c.readPos; i++ {
+			if c.groBuffers != nil {
+				c.groBuffers[i] = getGROBuffer()
+				c.messages[i].Buffers = [][]byte{c.groBuffers[i].Data}
+				continue
+			}
 			buffer := getPacketBuffer()
 			buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
 			c.buffers[i] = buffer
@@ -156,14 +191,23 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 
 	msg := c.messages[c.readPos]
 	buffer := c.buffers[c.readPos]
+	var groBuf *groBuffer
+	if c.groBuffers != nil {
+		groBuf = c.groBuffers[c.readPos]
+		c.groBuffers[c.readPos] = nil
+	}
 	c.readPos++
 	ctrlMsgs, err := unix.ParseSocketControlMessage(msg.OOB[:msg.NN])
 	if err != nil {
+		if groBuf != nil {
+			groBuf.Release()
+		}
 		return nil, err
 	}
 	var ecn protocol.ECN
 	var destIP net.IP
 	var ifIndex uint32
+	var segmentSize int
 	for _, ctrlMsg := range ctrlMsgs {
 		if ctrlMsg.Header.Level == unix.IPPROTO_IP {
 			switch ctrlMsg.Header.Type {
@@ -204,6 +248,10 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 				}
 			}
 		}
+		// The segment size of a coalesced datagram is an int.
+		if c.groBuffers != nil && ctrlMsg.Header.Level == unix.IPPROTO_UDP && ctrlMsg.Header.Type == msgTypeUDPGRO && len(ctrlMsg.Data) >= 4 {
+			segmentSize = int(*(*int32)(unsafe.Pointer(&ctrlMsg.Data[0])))
+		}
 	}
 	var info *packetInfo
 	if destIP != nil {
@@ -212,21 +260,116 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 			ifIndex: ifIndex,
 		}
 	}
-	return &receivedPacket{
-		remoteAddr: msg.Addr,
-		rcvTime:    time.Now(),
-		data:       msg.Buffers[0][:msg.N],
-		ecn:        ecn,
-		info:       info,
-		buffer:     buffer,
-	}, nil
+	rcvTime := time.Now()
+	if c.groBuffers == nil {
+		return &receivedPacket{
+			remoteAddr: msg.Addr,
+			rcvTime:    rcvTime,
+			data:       msg.Buffers[0][:msg.N],
+			ecn:        ecn,
+			info:       info,
+			buffer:     buffer,
+		}, nil
+	}
+
+	data := msg.Buffers[0][:msg.N]
+	// If the message wasn't coalesced, the kernel doesn't send the segment size.
+	// Copy the datagram into a regular packet buffer, so that it doesn't keep the (much larger) GRO buffer alive.
+	if segmentSize <= 0 || segmentSize >= len(data) {
+		buffer := getPacketBuffer()
+		// The packet size should not exceed protocol.MaxPacketBufferSize bytes
+		// If it does, we only use a truncated packet, which will then end up undecryptable
+		buffer.Data = buffer.Data[:utils.Min(len(data), int(protocol.MaxPacketBufferSize))]
+		copy(buffer.Data, data)
+		groBuf.Release()
+		return &receivedPacket{
+			remoteAddr: msg.Addr,
+			rcvTime:    rcvTime,
+			data:       buffer.Data,
+			ecn:        ecn,
+			info:       info,
+			buffer:     buffer,
+		}, nil
+	}
+	// Split the message into the datagrams it was coalesced from.
+	for len(data) > 0 {
+		l := utils.Min(len(data), segmentSize)
+		// The packet size should not exceed protocol.MaxPacketBufferSize bytes
+		// If it does, we only use a truncated packet, which will then end up undecryptable
+		n := utils.Min(l, int(protocol.MaxPacketBufferSize))
+		// limit the capacity, so that a datagram can't be used to overwrite the next one
+		buffer := groBuf.NewPacketBuffer(data[:n:n])
+		c.segments = append(c.segments, &receivedPacket{
+			remoteAddr: msg.Addr,
+			rcvTime:    rcvTime,
+			data:       buffer.Data,
+			ecn:        ecn,
+			info:       info,
+			buffer:     buffer,
+		})
+		data = data[l:]
+	}
+	// the datagrams now hold a reference to the buffer
+	groBuf.Release()
+	return c.ReadPacket()
 }
 
-func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte) (n int, err error) {
-	n, _, err = c.OOBCapablePacketConn.WriteMsgUDP(b, oob, addr.(*net.UDPAddr))
+func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) (n int, err error) {
+	udpAddr := addr.(*net.UDPAddr)
+	// The oob slice is shared between packets. Make sure to not modify the underlying array.
+	oob = oob[:len(oob):len(oob)]
+	if ecn != protocol.ECNNon {
+		if udpAddr.IP.To4() != nil {
+			oob = appendIPv4ECNMsg(oob, ecn)
+		} else {
+			oob = appendIPv6ECNMsg(oob, ecn)
+		}
+	}
+	if gsoSize > 0 {
+		if !c.cap.GSO {
+			panic("GSO disabled")
+		}
+		oob = appendUDPSegmentSizeMsg(oob, gsoSize)
+	}
+	n, _, err = c.OOBCapablePacketConn.WriteMsgUDP(b, oob, udpAddr)
 	return n, err
 }
 
+func (c *oobConn) capabilities() connCapabilities {
+	return c.cap
+}
+
+// appendIPv4ECNMsg appends the control message that sets the ECN bits of an IPv4 packet.
+func appendIPv4ECNMsg(b []byte, ecn protocol.ECN) []byte {
+	startLen := len(b)
+	b = append(b, make([]byte, unix.CmsgSpace(ecnIPv4DataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.IPPROTO_IP
+	h.Type = unix.IP_TOS
+	h.SetLen(unix.CmsgLen(ecnIPv4DataLen))
+	data := b[startLen+unix.CmsgSpace(0):]
+	if ecnIPv4DataLen == 1 {
+		data[0] = uint8(ecn)
+	} else {
+		*(*int32)(unsafe.Pointer(&data[0])) = int32(ecn)
+	}
+	return b
+}
+
+// appendIPv6ECNMsg appends the control message that sets the ECN bits of an IPv6 packet.
+// The traffic class is an int on all platforms.
+func appendIPv6ECNMsg(b []byte, ecn protocol.ECN) []byte {
+	startLen := len(b)
+	const dataLen = 4
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.IPPROTO_IPV6
+	h.Type = unix.IPV6_TCLASS
+	h.SetLen(unix.CmsgLen(dataLen))
+	*(*int32)(unsafe.Pointer(&b[startLen+unix.CmsgSpace(0)])) = int32(ecn)
+	return b
+}
+
 func (info *packetInfo) OOB() []byte {
 	if info == nil {
 		return nil
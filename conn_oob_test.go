@@ -131,6 +131,91 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 	})
 
+	Context("setting ECN flags", func() {
+		sendPacketWithECN := func(network string, addr *net.UDPAddr, oob []byte, ecn protocol.ECN) {
+			udpConn, err := net.ListenUDP(network, nil)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			conn, err := newConn(udpConn)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, conn.capabilities().ECN).To(BeTrue())
+			_, err = conn.WritePacket([]byte("foobar"), addr, oob, 0, ecn)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		}
+
+		It("sets ECN flags on IPv4", func() {
+			conn, packetChan := runServer("udp4", "localhost:0")
+			defer conn.Close()
+
+			sendPacketWithECN("udp4", conn.LocalAddr().(*net.UDPAddr), nil, protocol.ECT0)
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("foobar")))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+		})
+
+		It("sets ECN flags on IPv6", func() {
+			conn, packetChan := runServer("udp6", "[::1]:0")
+			defer conn.Close()
+
+			sendPacketWithECN("udp6", conn.LocalAddr().(*net.UDPAddr), nil, protocol.ECNCE)
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("foobar")))
+			Expect(p.ecn).To(Equal(protocol.ECNCE))
+		})
+
+		It("sets ECN flags on a connection that supports both IPv4 and IPv6", func() {
+			conn, packetChan := runServer("udp4", "127.0.0.1:0")
+			defer conn.Close()
+
+			sendPacketWithECN("udp", conn.LocalAddr().(*net.UDPAddr), nil, protocol.ECT0)
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+		})
+
+		It("doesn't modify the packet info OOB data", func() {
+			conn, packetChan := runServer("udp4", "localhost:0")
+			defer conn.Close()
+
+			info := &packetInfo{addr: net.IPv4(127, 0, 0, 1)}
+			oob := info.OOB()
+			oob = append(oob[:len(oob):len(oob)], make([]byte, 64)...)[:len(oob)]
+			oobCopy := append([]byte{}, oob...)
+			sendPacketWithECN("udp4", conn.LocalAddr().(*net.UDPAddr), oob, protocol.ECT0)
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+			Expect(oob).To(Equal(oobCopy))
+			Expect(oob[:cap(oob)][len(oob):]).To(Equal(make([]byte, 64)))
+		})
+	})
+
+	Context("GSO", func() {
+		It("sends multiple packets using a single syscall", func() {
+			conn, packetChan := runServer("udp4", "localhost:0")
+			defer conn.Close()
+
+			udpConn, err := net.ListenUDP("udp4", nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			oobConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			if !oobConn.capabilities().GSO {
+				Skip("GSO not supported on this platform")
+			}
+			_, err = oobConn.WritePacket([]byte("foobarfoobarfoo"), conn.LocalAddr(), nil, 6, protocol.ECT0)
+			Expect(err).ToNot(HaveOccurred())
+			for _, data := range []string{"foobar", "foobar", "foo"} {
+				var p *receivedPacket
+				Eventually(packetChan).Should(Receive(&p))
+				Expect(p.data).To(Equal([]byte(data)))
+				Expect(p.ecn).To(Equal(protocol.ECT0))
+			}
+		})
+	})
+
 	Context("Packet Info conn", func() {
 		sendPacket := func(network string, addr *net.UDPAddr) net.Addr {
 			conn, err := net.DialUDP(network, nil, addr)
@@ -212,11 +297,13 @@ var _ = Describe("OOB Conn Test", func() {
 		It("reads multiple messages in one batch", func() {
 			const numMsgRead = batchSize/2 + 1
 			var counter int
+			// when GRO is enabled, messages are read into larger buffers
+			bufferSize := int(protocol.MaxPacketBufferSize)
 			batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, flags int) (int, error) {
 				Expect(ms).To(HaveLen(batchSize))
 				for i := 0; i < numMsgRead; i++ {
 					Expect(ms[i].Buffers).To(HaveLen(1))
-					Expect(ms[i].Buffers[0]).To(HaveLen(int(protocol.MaxPacketBufferSize)))
+					Expect(ms[i].Buffers[0]).To(HaveLen(bufferSize))
 					data := []byte(fmt.Sprintf("message %d", counter))
 					counter++
 					ms[i].Buffers[0] = data
@@ -232,6 +319,9 @@ var _ = Describe("OOB Conn Test", func() {
 			oobConn, err := newConn(udpConn)
 			Expect(err).ToNot(HaveOccurred())
 			oobConn.batchConn = batchConn
+			if oobConn.groBuffers != nil {
+				bufferSize = maxGROMessageSize
+			}
 
 			for i := 0; i < batchSize+1; i++ {
 				p, err := oobConn.ReadPacket()
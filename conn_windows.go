@@ -52,3 +52,5 @@ func inspectReadBuffer(c net.PacketConn) (int, error) {
 }
 
 func (i *packetInfo) OOB() []byte { return nil }
+
+func isGSOError(error) bool { return false }
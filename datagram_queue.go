@@ -1,63 +1,231 @@
 package quic
 
 import (
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
+type queuedDatagram struct {
+	frame *wire.DatagramFrame
+	// If set, it is called with true when the DATAGRAM frame is acknowledged,
+	// and with false when it is declared lost or dropped without being sent.
+	callback func(acked bool)
+}
+
 type datagramQueue struct {
-	sendQueue chan *wire.DatagramFrame
-	rcvQueue  chan []byte
+	mutex      sync.Mutex
+	sendQueue  []queuedDatagram
+	maxLen     int
+	dropPolicy DatagramDropPolicy
+	numDropped uint64
+	// the maximum length of a message that fits into a DATAGRAM frame
+	maxDataLen protocol.ByteCount
+
+	rcvQueue chan []byte
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
+	// signaled (non-blocking) every time a frame is dequeued
 	dequeued chan struct{}
 
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), maxLen int, dropPolicy DatagramDropPolicy, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData:   hasData,
-		sendQueue: make(chan *wire.DatagramFrame, 1),
-		rcvQueue:  make(chan []byte, protocol.DatagramRcvQueueLen),
-		dequeued:  make(chan struct{}),
-		closed:    make(chan struct{}),
-		logger:    logger,
+		hasData:    hasData,
+		maxLen:     maxLen,
+		dropPolicy: dropPolicy,
+		rcvQueue:   make(chan []byte, protocol.DatagramRcvQueueLen),
+		dequeued:   make(chan struct{}, 1),
+		closed:     make(chan struct{}),
+		logger:     logger,
 	}
 }
 
-// AddAndWait queues a new DATAGRAM frame for sending.
-// It blocks until the frame has been dequeued.
-func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame) error {
-	select {
-	case h.sendQueue <- f:
-		h.hasData()
-	case <-h.closed:
-		return h.closeErr
+// Add queues new DATAGRAM frames for sending.
+// The frames are queued atomically: Either all of them are queued, or none of them.
+// If the queue doesn't have space for all frames, the behavior depends on the drop policy:
+// With DatagramDropNone, it blocks until there's space in the queue.
+// With DatagramDropOldest, the oldest queued frames are dropped.
+// With DatagramDropNewest, the new frames are dropped.
+// A batch that is larger than the queue is queued once the queue is empty (DatagramDropNone),
+// or after dropping all previously queued frames (DatagramDropOldest).
+func (h *datagramQueue) Add(frames ...*wire.DatagramFrame) error {
+	batch := make([]queuedDatagram, 0, len(frames))
+	for _, f := range frames {
+		batch = append(batch, queuedDatagram{frame: f})
 	}
+	return h.add(batch)
+}
 
-	select {
-	case <-h.dequeued:
+// AddWithCallback queues a new DATAGRAM frame for sending, like Add.
+// The callback is called with true when the frame is acknowledged,
+// and with false when it is declared lost or dropped without being sent.
+func (h *datagramQueue) AddWithCallback(f *wire.DatagramFrame, callback func(acked bool)) error {
+	return h.add([]queuedDatagram{{frame: f, callback: callback}})
+}
+
+func (h *datagramQueue) add(batch []queuedDatagram) error {
+	if len(batch) == 0 {
 		return nil
-	case <-h.closed:
-		return h.closeErr
+	}
+	for {
+		h.mutex.Lock()
+		select {
+		case <-h.closed:
+			h.mutex.Unlock()
+			return h.closeErr
+		default:
+		}
+		if len(h.sendQueue) == 0 || len(h.sendQueue)+len(batch) <= h.maxLen {
+			h.sendQueue = append(h.sendQueue, batch...)
+			h.mutex.Unlock()
+			h.hasData()
+			return nil
+		}
+		//nolint:exhaustive // DatagramDropNone is handled below.
+		switch h.dropPolicy {
+		case DatagramDropOldest:
+			numDrop := len(h.sendQueue) + len(batch) - h.maxLen
+			if numDrop > len(h.sendQueue) {
+				numDrop = len(h.sendQueue)
+			}
+			dropped := make([]queuedDatagram, numDrop)
+			copy(dropped, h.sendQueue)
+			h.logger.Debugf("Datagram queue full. Dropping %d oldest DATAGRAM frame(s)", numDrop)
+			for i := 0; i < numDrop; i++ {
+				h.sendQueue[i] = queuedDatagram{}
+			}
+			h.sendQueue = append(h.sendQueue[numDrop:], batch...)
+			h.numDropped += uint64(numDrop)
+			h.mutex.Unlock()
+			for _, d := range dropped {
+				if d.callback != nil {
+					d.callback(false)
+				}
+			}
+			h.hasData()
+			return nil
+		case DatagramDropNewest:
+			h.logger.Debugf("Datagram queue full. Dropping %d new DATAGRAM frame(s)", len(batch))
+			h.numDropped += uint64(len(batch))
+			h.mutex.Unlock()
+			for _, d := range batch {
+				if d.callback != nil {
+					d.callback(false)
+				}
+			}
+			return nil
+		}
+		h.mutex.Unlock()
+
+		select {
+		case <-h.dequeued:
+		case <-h.closed:
+			return h.closeErr
+		}
 	}
 }
 
-// Get dequeues a DATAGRAM frame for sending.
-func (h *datagramQueue) Get() *wire.DatagramFrame {
+// HasData says if there are DATAGRAM frames queued for sending.
+func (h *datagramQueue) HasData() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.sendQueue) > 0
+}
+
+// PopIfFits removes the next DATAGRAM frame from the queue, if it fits into maxLen bytes.
+// It returns the frame, with callbacks that report acknowledgement and loss of the frame.
+// A frame that doesn't fit is kept in the queue, unless it is larger than dropLen:
+// Such a frame can't be sent at all, so it is dropped.
+// Checking the size and removing the frame happens atomically. Otherwise, when using DatagramDropOldest,
+// a concurrent call to Add could replace the frame in between.
+func (h *datagramQueue) PopIfFits(maxLen, dropLen protocol.ByteCount, v protocol.VersionNumber) (ackhandler.Frame, bool) {
+	h.mutex.Lock()
+	if len(h.sendQueue) == 0 {
+		h.mutex.Unlock()
+		return ackhandler.Frame{}, false
+	}
+	d := h.sendQueue[0]
+	size := d.frame.Length(v)
+	if size > maxLen && size <= dropLen {
+		h.mutex.Unlock()
+		return ackhandler.Frame{}, false
+	}
+	h.sendQueue[0] = queuedDatagram{}
+	h.sendQueue = h.sendQueue[1:]
+	tooLarge := size > maxLen
+	if tooLarge {
+		h.numDropped++
+	}
+	h.mutex.Unlock()
+
 	select {
-	case f := <-h.sendQueue:
-		h.dequeued <- struct{}{}
-		return f
+	case h.dequeued <- struct{}{}:
 	default:
-		return nil
 	}
+
+	if tooLarge {
+		h.logger.Debugf("Dropping DATAGRAM frame (%d bytes payload)", len(d.frame.Data))
+		if d.callback != nil {
+			d.callback(false)
+		}
+		return ackhandler.Frame{}, false
+	}
+
+	f := ackhandler.Frame{Frame: d.frame}
+	if d.callback == nil {
+		// set it to a no-op. Then we won't set the default callback, which would retransmit the frame.
+		f.OnLost = func(wire.Frame) {}
+		return f, true
+	}
+	// A frame that was declared lost might still be acknowledged later.
+	// Only report the first outcome, so that the application is never notified twice.
+	// OnLost and OnAcked are both called from the session's run loop.
+	var reported bool
+	report := func(acked bool) {
+		if reported {
+			return
+		}
+		reported = true
+		d.callback(acked)
+	}
+	f.OnLost = func(wire.Frame) { report(false) }
+	f.OnAcked = func(wire.Frame) { report(true) }
+	return f, true
+}
+
+// NumDropped returns the number of DATAGRAM frames that were dropped without being sent.
+func (h *datagramQueue) NumDropped() uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.numDropped
+}
+
+// SetMaxDataLen sets the maximum length of a message that can be sent in a DATAGRAM frame.
+func (h *datagramQueue) SetMaxDataLen(l protocol.ByteCount) {
+	h.mutex.Lock()
+	h.maxDataLen = l
+	h.mutex.Unlock()
+}
+
+// MaxDataLen returns the maximum length of a message that can be sent in a DATAGRAM frame.
+// It is 0 if the peer's transport parameters are not yet known.
+func (h *datagramQueue) MaxDataLen() protocol.ByteCount {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.maxDataLen
 }
 
 // HandleDatagramFrame handles a received DATAGRAM frame.
@@ -82,6 +250,8 @@ func (h *datagramQueue) Receive() ([]byte, error) {
 }
 
 func (h *datagramQueue) CloseWithError(e error) {
+	h.mutex.Lock()
 	h.closeErr = e
+	h.mutex.Unlock()
 	close(h.closed)
 }
@@ -3,6 +3,8 @@ package quic
 import (
 	"errors"
 
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 
@@ -18,41 +20,278 @@ var _ = Describe("Datagram Queue", func() {
 		queued = make(chan struct{}, 100)
 		queue = newDatagramQueue(func() {
 			queued <- struct{}{}
-		}, utils.DefaultLogger)
+		}, 2, DatagramDropNone, utils.DefaultLogger)
 	})
 
+	popFrame := func() ackhandler.Frame {
+		f, ok := queue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, protocol.VersionTLS)
+		Expect(ok).To(BeTrue())
+		return f
+	}
+
+	pop := func() *wire.DatagramFrame {
+		return popFrame().Frame.(*wire.DatagramFrame)
+	}
+
 	Context("sending", func() {
-		It("returns nil when there's no datagram to send", func() {
-			Expect(queue.Get()).To(BeNil())
+		It("doesn't return a frame when there's no datagram to send", func() {
+			Expect(queue.HasData()).To(BeFalse())
+			_, ok := queue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, protocol.VersionTLS)
+			Expect(ok).To(BeFalse())
 		})
 
 		It("queues a datagram", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queued).To(HaveLen(2))
+			Expect(queue.HasData()).To(BeTrue())
+			Expect(pop().Data).To(Equal([]byte("foo")))
+			Expect(queue.HasData()).To(BeTrue())
+			Expect(pop().Data).To(Equal([]byte("bar")))
+			Expect(queue.HasData()).To(BeFalse())
+			Expect(queue.NumDropped()).To(BeZero())
+		})
+
+		It("queues multiple datagrams at once", func() {
+			Expect(queue.Add(
+				&wire.DatagramFrame{Data: []byte("foo")},
+				&wire.DatagramFrame{Data: []byte("bar")},
+			)).To(Succeed())
+			Expect(pop().Data).To(Equal([]byte("foo")))
+			Expect(pop().Data).To(Equal([]byte("bar")))
+		})
+
+		It("blocks until there's space in the queue", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")})).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("baz")})).To(Succeed())
 			}()
 
-			Eventually(queued).Should(HaveLen(1))
 			Consistently(done).ShouldNot(BeClosed())
-			f := queue.Get()
-			Expect(f).ToNot(BeNil())
-			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(pop().Data).To(Equal([]byte("foo")))
 			Eventually(done).Should(BeClosed())
-			Expect(queue.Get()).To(BeNil())
+			Expect(pop().Data).To(Equal([]byte("bar")))
+			Expect(pop().Data).To(Equal([]byte("baz")))
+			Expect(queue.NumDropped()).To(BeZero())
+		})
+
+		It("drops the oldest datagram when the queue is full", func() {
+			queue = newDatagramQueue(func() {}, 2, DatagramDropOldest, utils.DefaultLogger)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("baz")})).To(Succeed())
+			Expect(queue.NumDropped()).To(BeEquivalentTo(1))
+			Expect(pop().Data).To(Equal([]byte("bar")))
+			Expect(pop().Data).To(Equal([]byte("baz")))
+			Expect(queue.HasData()).To(BeFalse())
+		})
+
+		It("drops the newest datagram when the queue is full", func() {
+			queue = newDatagramQueue(func() {}, 2, DatagramDropNewest, utils.DefaultLogger)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("baz")})).To(Succeed())
+			Expect(queue.NumDropped()).To(BeEquivalentTo(1))
+			Expect(pop().Data).To(Equal([]byte("foo")))
+			Expect(pop().Data).To(Equal([]byte("bar")))
+			Expect(queue.HasData()).To(BeFalse())
+		})
+
+		Context("queueing multiple datagrams atomically", func() {
+			It("blocks until there's space for all datagrams", func() {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					Expect(queue.Add(
+						&wire.DatagramFrame{Data: []byte("bar")},
+						&wire.DatagramFrame{Data: []byte("baz")},
+					)).To(Succeed())
+				}()
+
+				Consistently(done).ShouldNot(BeClosed())
+				Expect(pop().Data).To(Equal([]byte("foo")))
+				Eventually(done).Should(BeClosed())
+				Expect(pop().Data).To(Equal([]byte("bar")))
+				Expect(pop().Data).To(Equal([]byte("baz")))
+			})
+
+			It("queues batches larger than the queue once the queue is empty", func() {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					Expect(queue.Add(
+						&wire.DatagramFrame{Data: []byte("bar")},
+						&wire.DatagramFrame{Data: []byte("baz")},
+						&wire.DatagramFrame{Data: []byte("qux")},
+					)).To(Succeed())
+				}()
+
+				Consistently(done).ShouldNot(BeClosed())
+				Expect(pop().Data).To(Equal([]byte("foo")))
+				Eventually(done).Should(BeClosed())
+				Expect(pop().Data).To(Equal([]byte("bar")))
+				Expect(pop().Data).To(Equal([]byte("baz")))
+				Expect(pop().Data).To(Equal([]byte("qux")))
+			})
+
+			It("doesn't queue any datagram when the queue is closed while waiting", func() {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				errChan := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					errChan <- queue.Add(
+						&wire.DatagramFrame{Data: []byte("bar")},
+						&wire.DatagramFrame{Data: []byte("baz")},
+					)
+				}()
+
+				Consistently(errChan).ShouldNot(Receive())
+				queue.CloseWithError(errors.New("test error"))
+				Eventually(errChan).Should(Receive(MatchError("test error")))
+				Expect(pop().Data).To(Equal([]byte("foo")))
+				Expect(queue.HasData()).To(BeFalse())
+			})
+
+			It("drops the oldest datagrams to make space for all datagrams", func() {
+				queue = newDatagramQueue(func() {}, 2, DatagramDropOldest, utils.DefaultLogger)
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.Add(
+					&wire.DatagramFrame{Data: []byte("bar")},
+					&wire.DatagramFrame{Data: []byte("baz")},
+					&wire.DatagramFrame{Data: []byte("qux")},
+				)).To(Succeed())
+				Expect(queue.NumDropped()).To(BeEquivalentTo(1))
+				Expect(pop().Data).To(Equal([]byte("bar")))
+				Expect(pop().Data).To(Equal([]byte("baz")))
+				Expect(pop().Data).To(Equal([]byte("qux")))
+				Expect(queue.HasData()).To(BeFalse())
+			})
+
+			It("drops all new datagrams if they don't fit", func() {
+				queue = newDatagramQueue(func() {}, 2, DatagramDropNewest, utils.DefaultLogger)
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.Add(
+					&wire.DatagramFrame{Data: []byte("bar")},
+					&wire.DatagramFrame{Data: []byte("baz")},
+				)).To(Succeed())
+				Expect(queue.NumDropped()).To(BeEquivalentTo(2))
+				Expect(pop().Data).To(Equal([]byte("foo")))
+				Expect(queue.HasData()).To(BeFalse())
+			})
+		})
+
+		It("keeps datagrams that don't fit", func() {
+			f := &wire.DatagramFrame{Data: []byte("foobar")}
+			Expect(queue.Add(f)).To(Succeed())
+			_, ok := queue.PopIfFits(f.Length(protocol.VersionTLS)-1, f.Length(protocol.VersionTLS), protocol.VersionTLS)
+			Expect(ok).To(BeFalse())
+			Expect(queue.NumDropped()).To(BeZero())
+			af, ok := queue.PopIfFits(f.Length(protocol.VersionTLS), f.Length(protocol.VersionTLS), protocol.VersionTLS)
+			Expect(ok).To(BeTrue())
+			Expect(af.Frame).To(Equal(f))
+		})
+
+		It("drops and counts datagrams that are too large", func() {
+			f := &wire.DatagramFrame{Data: []byte("foobar")}
+			Expect(queue.Add(f)).To(Succeed())
+			_, ok := queue.PopIfFits(f.Length(protocol.VersionTLS)-1, f.Length(protocol.VersionTLS)-1, protocol.VersionTLS)
+			Expect(ok).To(BeFalse())
+			Expect(queue.HasData()).To(BeFalse())
+			Expect(queue.NumDropped()).To(BeEquivalentTo(1))
+		})
+
+		Context("callbacks", func() {
+			var results []bool
+
+			BeforeEach(func() {
+				results = nil
+			})
+
+			callback := func(acked bool) { results = append(results, acked) }
+
+			It("reports acknowledgements", func() {
+				f := &wire.DatagramFrame{Data: []byte("foobar")}
+				Expect(queue.AddWithCallback(f, callback)).To(Succeed())
+				af := popFrame()
+				Expect(af.Frame).To(Equal(f))
+				Expect(results).To(BeEmpty())
+				af.OnAcked(af.Frame)
+				Expect(results).To(Equal([]bool{true}))
+			})
+
+			It("reports losses", func() {
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("foobar")}, callback)).To(Succeed())
+				af := popFrame()
+				af.OnLost(af.Frame)
+				Expect(results).To(Equal([]bool{false}))
+			})
+
+			It("only reports the first outcome", func() {
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("foobar")}, callback)).To(Succeed())
+				af := popFrame()
+				af.OnLost(af.Frame)
+				// the packet was declared lost, but is acknowledged later
+				af.OnAcked(af.Frame)
+				Expect(results).To(Equal([]bool{false}))
+			})
+
+			It("reports dropped frames", func() {
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("foobar")}, callback)).To(Succeed())
+				_, ok := queue.PopIfFits(1, 1, protocol.VersionTLS)
+				Expect(ok).To(BeFalse())
+				Expect(results).To(Equal([]bool{false}))
+			})
+
+			It("reports frames dropped when the queue is full", func() {
+				queue = newDatagramQueue(func() {}, 1, DatagramDropOldest, utils.DefaultLogger)
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("foo")}, callback)).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+				Expect(results).To(Equal([]bool{false}))
+				queue = newDatagramQueue(func() {}, 1, DatagramDropNewest, utils.DefaultLogger)
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("bar")}, callback)).To(Succeed())
+				Expect(results).To(Equal([]bool{false, false}))
+			})
+
+			It("doesn't retransmit frames without a callback", func() {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")})).To(Succeed())
+				af := popFrame()
+				Expect(af.OnAcked).To(BeNil())
+				Expect(af.OnLost).ToNot(BeNil())
+				af.OnLost(af.Frame)
+				Expect(queue.HasData()).To(BeFalse())
+			})
+		})
+
+		It("stores the maximum data length", func() {
+			Expect(queue.MaxDataLen()).To(BeZero())
+			queue.SetMaxDataLen(1234)
+			Expect(queue.MaxDataLen()).To(BeEquivalentTo(1234))
 		})
 
 		It("closes", func() {
+			Expect(queue.Add(
+				&wire.DatagramFrame{Data: []byte("foo")},
+				&wire.DatagramFrame{Data: []byte("bar")},
+			)).To(Succeed())
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")})
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("baz")})
 			}()
 
 			Consistently(errChan).ShouldNot(Receive())
 			queue.CloseWithError(errors.New("test error"))
 			Eventually(errChan).Should(Receive(MatchError("test error")))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")})).To(MatchError("test error"))
 		})
 	})
 
@@ -0,0 +1,264 @@
+package quic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const fileTokenStoreRevision = 1
+
+type fileTokenStore struct {
+	mutex sync.Mutex
+
+	path   string
+	maxAge time.Duration
+	store  *lruTokenStore
+	logger utils.Logger
+
+	dirty    bool          // the tokens were modified since the last write started
+	saveDone chan struct{} // closed when the goroutine writing the file returns, nil if no write was started yet
+	saveErr  error         // the error that occurred during the last write
+}
+
+var _ FileTokenStore = &fileTokenStore{}
+
+// NewFileTokenStore creates a TokenStore that persists tokens to the file at path,
+// so that they can be used by future runs of the process.
+// Like the TokenStore returned by NewLRUTokenStore, it saves tokens for up to maxOrigins origins,
+// and keeps at most tokensPerOrigin tokens per origin.
+// Tokens received more than maxAge ago are discarded. If maxAge is 0, tokens don't expire.
+// The file doesn't need to exist. It is read once, when the TokenStore is created,
+// and rewritten (atomically) in the background every time a token is added or removed.
+// Flush must be called before the process exits, otherwise changes made right before might not be persisted.
+// The file is not locked: It must not be used by multiple TokenStores at the same time.
+// If multiple processes use the same file concurrently, they overwrite each other's tokens.
+func NewFileTokenStore(path string, maxOrigins, tokensPerOrigin int, maxAge time.Duration) (FileTokenStore, error) {
+	s := &fileTokenStore{
+		path:   path,
+		maxAge: maxAge,
+		store:  NewLRUTokenStore(maxOrigins, tokensPerOrigin).(*lruTokenStore),
+		logger: utils.DefaultLogger.WithPrefix("token store"),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := s.unmarshal(data); err != nil {
+		return nil, fmt.Errorf("failed to parse token store file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *fileTokenStore) Put(key string, token *ClientToken) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.store.Put(key, &ClientToken{data: token.data, rcvTime: time.Now()})
+	s.save()
+}
+
+func (s *fileTokenStore) Pop(key string) *ClientToken {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var changed bool
+	defer func() {
+		if changed {
+			s.save()
+		}
+	}()
+	for {
+		token := s.store.Pop(key)
+		if token == nil {
+			return nil
+		}
+		changed = true
+		// Tokens are stored in the order they were received.
+		// If this token is expired, all remaining tokens for this origin are expired as well.
+		if !s.isExpired(token, time.Now()) {
+			return token
+		}
+	}
+}
+
+// Flush waits until the goroutine writing the file returns,
+// and writes the tokens if they were modified since.
+func (s *fileTokenStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.saveDone != nil {
+		done := s.saveDone
+		s.mutex.Unlock()
+		<-done
+		s.mutex.Lock()
+		// Put or Pop might have started a new write while we were waiting.
+		if s.saveDone == done {
+			break
+		}
+	}
+	if s.dirty {
+		s.dirty = false
+		s.saveErr = writeFileAtomic(s.path, s.marshal())
+	}
+	return s.saveErr
+}
+
+func (s *fileTokenStore) isExpired(token *ClientToken, now time.Time) bool {
+	return s.maxAge > 0 && now.Sub(token.rcvTime) > s.maxAge
+}
+
+// save schedules writing the tokens to the file. It must be called with the mutex held.
+// Put and Pop are called from the session's run loop, so the file is written on a separate goroutine.
+// Changes made while a write is in progress are coalesced into a single write.
+func (s *fileTokenStore) save() {
+	s.dirty = true
+	if s.saveDone != nil {
+		select {
+		case <-s.saveDone:
+		default: // the goroutine will pick up this change
+			return
+		}
+	}
+	s.saveDone = make(chan struct{})
+	go s.runSave(s.saveDone)
+}
+
+func (s *fileTokenStore) runSave(done chan<- struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer close(done)
+
+	for s.dirty {
+		s.dirty = false
+		data := s.marshal()
+		s.mutex.Unlock()
+		err := writeFileAtomic(s.path, data)
+		s.mutex.Lock()
+		s.saveErr = err
+		if err != nil {
+			s.logger.Errorf("Failed to save tokens: %s", err)
+		}
+	}
+}
+
+// marshal serializes the tokens.
+// Origins are written starting with the least recently used one,
+// and tokens are written in the order they were received,
+// such that adding them to a new lruTokenStore restores its state.
+func (s *fileTokenStore) marshal() []byte {
+	b := &bytes.Buffer{}
+	quicvarint.Write(b, fileTokenStoreRevision)
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+	quicvarint.Write(b, uint64(s.store.q.Len()))
+	for el := s.store.q.Back(); el != nil; el = el.Prev() {
+		entry := el.Value.(*lruTokenStoreEntry)
+		quicvarint.Write(b, uint64(len(entry.key)))
+		b.WriteString(entry.key)
+		tokens := entry.cache.tokensInOrder()
+		quicvarint.Write(b, uint64(len(tokens)))
+		for _, t := range tokens {
+			quicvarint.Write(b, uint64(len(t.data)))
+			b.Write(t.data)
+			quicvarint.Write(b, uint64(t.rcvTime.UnixNano()/int64(time.Millisecond)))
+		}
+	}
+	return b.Bytes()
+}
+
+func (s *fileTokenStore) unmarshal(data []byte) error {
+	r := bytes.NewReader(data)
+	rev, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read revision")
+	}
+	if rev != fileTokenStoreRevision {
+		return fmt.Errorf("unknown revision: %d", rev)
+	}
+	numOrigins, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read number of origins")
+	}
+	now := time.Now()
+	for i := uint64(0); i < numOrigins; i++ {
+		key, err := readLengthPrefixed(r)
+		if err != nil {
+			return errors.New("failed to read origin")
+		}
+		numTokens, err := quicvarint.Read(r)
+		if err != nil {
+			return errors.New("failed to read number of tokens")
+		}
+		for j := uint64(0); j < numTokens; j++ {
+			tokenData, err := readLengthPrefixed(r)
+			if err != nil {
+				return errors.New("failed to read token")
+			}
+			rcvTime, err := quicvarint.Read(r)
+			if err != nil {
+				return errors.New("failed to read token receive time")
+			}
+			token := &ClientToken{
+				data:    tokenData,
+				rcvTime: time.Unix(0, int64(rcvTime)*int64(time.Millisecond)),
+			}
+			if !s.isExpired(token, now) {
+				s.store.Put(string(key), token)
+			}
+		}
+	}
+	if r.Len() > 0 {
+		return errors.New("unexpected trailing data")
+	}
+	return nil
+}
+
+func readLengthPrefixed(r *bytes.Reader) ([]byte, error) {
+	l, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(r.Len()) {
+		return nil, io.EOF
+	}
+	b := make([]byte, l)
+	r.Read(b)
+	return b, nil
+}
+
+// writeFileAtomic writes data to a temporary file, and renames it to path.
+// This makes sure that path either contains the old or the new data, even if the process crashes.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
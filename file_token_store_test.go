@@ -0,0 +1,226 @@
+package quic
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File Token Store", func() {
+	var (
+		dir, path string
+		stores    []*fileTokenStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "quic-go-token-store")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "tokens")
+		stores = nil
+	})
+
+	AfterEach(func() {
+		// wait for all writes to finish before deleting the directory
+		for _, s := range stores {
+			Expect(s.Flush()).To(Succeed())
+		}
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	newStore := func(maxOrigins, tokensPerOrigin int, maxAge time.Duration) *fileTokenStore {
+		s, err := NewFileTokenStore(path, maxOrigins, tokensPerOrigin, maxAge)
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		stores = append(stores, s.(*fileTokenStore))
+		return s.(*fileTokenStore)
+	}
+
+	// waitForSave waits until all changes have been written to the file
+	waitForSave := func(s *fileTokenStore) {
+		s.mutex.Lock()
+		done := s.saveDone
+		s.mutex.Unlock()
+		ExpectWithOffset(1, done).ToNot(BeNil())
+		EventuallyWithOffset(1, done).Should(BeClosed())
+	}
+
+	save := func(s *fileTokenStore) {
+		s.mutex.Lock()
+		s.save()
+		s.mutex.Unlock()
+		waitForSave(s)
+	}
+
+	popData := func(s TokenStore, origin string) []byte {
+		token := s.Pop(origin)
+		if token == nil {
+			return nil
+		}
+		return token.data
+	}
+
+	It("starts empty if the file doesn't exist", func() {
+		s := newStore(3, 4, 0)
+		Expect(s.Pop("localhost")).To(BeNil())
+		_, err := os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("persists tokens", func() {
+		s := newStore(3, 4, 0)
+		s.Put("localhost", &ClientToken{data: []byte("foo")})
+		s.Put("localhost", &ClientToken{data: []byte("bar")})
+		s.Put("example.com", &ClientToken{data: []byte("baz")})
+		waitForSave(s)
+
+		s = newStore(3, 4, 0)
+		Expect(popData(s, "localhost")).To(Equal([]byte("bar")))
+		Expect(popData(s, "localhost")).To(Equal([]byte("foo")))
+		Expect(s.Pop("localhost")).To(BeNil())
+		Expect(popData(s, "example.com")).To(Equal([]byte("baz")))
+		entries, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1)) // no temporary files are left behind
+	})
+
+	It("persists that tokens were popped", func() {
+		s := newStore(3, 4, 0)
+		s.Put("localhost", &ClientToken{data: []byte("foo")})
+		s.Put("localhost", &ClientToken{data: []byte("bar")})
+		Expect(popData(s, "localhost")).To(Equal([]byte("bar")))
+		waitForSave(s)
+
+		s = newStore(3, 4, 0)
+		Expect(popData(s, "localhost")).To(Equal([]byte("foo")))
+		waitForSave(s)
+		s = newStore(3, 4, 0)
+		Expect(s.Pop("localhost")).To(BeNil())
+	})
+
+	It("persists the most recent tokens when they are modified while writing", func() {
+		s := newStore(3, 4, 0)
+		for i := 0; i < 100; i++ {
+			s.Put("localhost", &ClientToken{data: []byte{byte(i)}})
+		}
+		waitForSave(s)
+
+		s = newStore(3, 4, 0)
+		for i := 99; i >= 96; i-- {
+			Expect(popData(s, "localhost")).To(Equal([]byte{byte(i)}))
+		}
+		Expect(s.Pop("localhost")).To(BeNil())
+	})
+
+	It("writes all tokens when flushing", func() {
+		s := newStore(3, 4, 0)
+		for i := 0; i < 10; i++ {
+			s.Put("localhost", &ClientToken{data: []byte{byte(i)}})
+		}
+		s.Put("example.com", &ClientToken{data: []byte("foobar")})
+		Expect(s.Flush()).To(Succeed())
+		s.mutex.Lock()
+		Expect(s.saveDone).To(BeClosed())
+		s.mutex.Unlock()
+
+		s = newStore(3, 4, 0)
+		Expect(popData(s, "example.com")).To(Equal([]byte("foobar")))
+		Expect(popData(s, "localhost")).To(Equal([]byte{9}))
+	})
+
+	It("flushes if no tokens were modified", func() {
+		s := newStore(3, 4, 0)
+		Expect(s.Flush()).To(Succeed())
+		_, err := os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("returns the error that occurred when writing the file", func() {
+		path = filepath.Join(dir, "nonexistent", "tokens")
+		s := newStore(3, 4, 0)
+		stores = nil
+		s.Put("localhost", &ClientToken{data: []byte("foobar")})
+		Expect(s.Flush()).To(HaveOccurred())
+	})
+
+	It("limits the number of tokens per origin", func() {
+		s := newStore(3, 2, 0)
+		s.Put("localhost", &ClientToken{data: []byte("foo")})
+		s.Put("localhost", &ClientToken{data: []byte("bar")})
+		s.Put("localhost", &ClientToken{data: []byte("baz")})
+		waitForSave(s)
+
+		s = newStore(3, 2, 0)
+		Expect(popData(s, "localhost")).To(Equal([]byte("baz")))
+		Expect(popData(s, "localhost")).To(Equal([]byte("bar")))
+		Expect(s.Pop("localhost")).To(BeNil())
+	})
+
+	It("restores the order of the origins", func() {
+		s := newStore(2, 4, 0)
+		s.Put("a", &ClientToken{data: []byte("foo")})
+		s.Put("b", &ClientToken{data: []byte("bar")})
+		s.Put("a", &ClientToken{data: []byte("baz")})
+		waitForSave(s)
+
+		s = newStore(2, 4, 0)
+		// b is the least recently used origin, and gets evicted
+		s.Put("c", &ClientToken{data: []byte("raboof")})
+		Expect(s.Pop("b")).To(BeNil())
+		Expect(popData(s, "a")).To(Equal([]byte("baz")))
+		Expect(popData(s, "c")).To(Equal([]byte("raboof")))
+	})
+
+	It("discards expired tokens when loading the file", func() {
+		s := newStore(3, 4, time.Hour)
+		s.store.Put("localhost", &ClientToken{data: []byte("foo"), rcvTime: time.Now().Add(-2 * time.Hour)})
+		s.store.Put("localhost", &ClientToken{data: []byte("bar"), rcvTime: time.Now().Add(-time.Minute)})
+		save(s)
+
+		s = newStore(3, 4, time.Hour)
+		Expect(popData(s, "localhost")).To(Equal([]byte("bar")))
+		Expect(s.Pop("localhost")).To(BeNil())
+	})
+
+	It("discards expired tokens when popping", func() {
+		s := newStore(3, 4, time.Hour)
+		s.store.Put("localhost", &ClientToken{data: []byte("foo"), rcvTime: time.Now().Add(-3 * time.Hour)})
+		s.store.Put("localhost", &ClientToken{data: []byte("bar"), rcvTime: time.Now().Add(-2 * time.Hour)})
+		Expect(s.Pop("localhost")).To(BeNil())
+		Expect(s.store.q.Len()).To(BeZero())
+	})
+
+	It("doesn't expire tokens if no maximum age is set", func() {
+		s := newStore(3, 4, 0)
+		s.store.Put("localhost", &ClientToken{data: []byte("foo"), rcvTime: time.Now().Add(-1000 * time.Hour)})
+		save(s)
+
+		s = newStore(3, 4, 0)
+		Expect(popData(s, "localhost")).To(Equal([]byte("foo")))
+	})
+
+	It("errors when the file can't be parsed", func() {
+		s := newStore(3, 4, 0)
+		s.Put("localhost", &ClientToken{data: []byte("foobar")})
+		waitForSave(s)
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, data[:len(data)-1], 0o600)).To(Succeed())
+		_, err = NewFileTokenStore(path, 3, 4, 0)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to parse token store file"))
+	})
+
+	It("errors on unknown revisions", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, 1337)
+		Expect(os.WriteFile(path, b.Bytes(), 0o600)).To(Succeed())
+		_, err := NewFileTokenStore(path, 3, 4, 0)
+		Expect(err).To(MatchError("failed to parse token store file " + path + ": unknown revision: 1337"))
+	})
+})
@@ -16,7 +16,7 @@ type framer interface {
 	QueueControlFrame(wire.Frame)
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount)
 
-	AddActiveStream(protocol.StreamID)
+	AddActiveStream(protocol.StreamID, streamPriority)
 	AppendStreamFrames([]ackhandler.Frame, protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
@@ -28,8 +28,10 @@ type framerI struct {
 	streamGetter streamGetter
 	version      protocol.VersionNumber
 
-	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   []protocol.StreamID
+	activeStreams map[protocol.StreamID]streamPriority
+	// Active streams are queued by their urgency.
+	// Streams with a lower urgency value are served first.
+	streamQueues [maxStreamUrgency + 1][]protocol.StreamID
 
 	controlFrameMutex sync.Mutex
 	controlFrames     []wire.Frame
@@ -43,14 +45,14 @@ func newFramer(
 ) framer {
 	return &framerI{
 		streamGetter:  streamGetter,
-		activeStreams: make(map[protocol.StreamID]struct{}),
+		activeStreams: make(map[protocol.StreamID]streamPriority),
 		version:       v,
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := len(f.streamQueue) > 0
+	hasData := len(f.activeStreams) > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -84,55 +86,84 @@ func (f *framerI) AppendControlFrames(frames []ackhandler.Frame, maxLen protocol
 	return frames, length
 }
 
-func (f *framerI) AddActiveStream(id protocol.StreamID) {
+func (f *framerI) AddActiveStream(id protocol.StreamID, prio streamPriority) {
 	f.mutex.Lock()
-	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue = append(f.streamQueue, id)
-		f.activeStreams[id] = struct{}{}
+	oldPrio, ok := f.activeStreams[id]
+	if !ok || oldPrio != prio {
+		// If the priority of an active stream changed, move it to the queue for its new urgency.
+		if ok {
+			f.removeFromQueue(oldPrio.urgency, id)
+		}
+		f.streamQueues[prio.urgency] = append(f.streamQueues[prio.urgency], id)
+		f.activeStreams[id] = prio
 	}
 	f.mutex.Unlock()
 }
 
+func (f *framerI) removeFromQueue(urgency uint8, id protocol.StreamID) {
+	queue := f.streamQueues[urgency]
+	for i, sid := range queue {
+		if sid == id {
+			f.streamQueues[urgency] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// AppendStreamFrames appends STREAM frames, serving the queues in order of their urgency.
+// Within a queue, incremental streams are served round-robin:
+// after a STREAM frame was popped, the stream is re-queued at the end.
+// Non-incremental streams stay at the front of their queue until they don't have any more data to send.
 func (f *framerI) AppendStreamFrames(frames []ackhandler.Frame, maxLen protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
 	var length protocol.ByteCount
 	var lastFrame *ackhandler.Frame
 	f.mutex.Lock()
-	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := len(f.streamQueue)
-	for i := 0; i < numActiveStreams; i++ {
-		if protocol.MinStreamFrameSize+length > maxLen {
-			break
+	for urgency := range f.streamQueues {
+		// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
+		numActiveStreams := len(f.streamQueues[urgency])
+		for i := 0; i < numActiveStreams; i++ {
+			if protocol.MinStreamFrameSize+length > maxLen {
+				break
+			}
+			id := f.streamQueues[urgency][0]
+			// This should never return an error. Better check it anyway.
+			// The stream will only be in the streamQueue, if it enqueued itself there.
+			str, err := f.streamGetter.GetOrOpenSendStream(id)
+			// The stream can be nil if it completed after it said it had data.
+			if str == nil || err != nil {
+				f.streamQueues[urgency] = f.streamQueues[urgency][1:]
+				delete(f.activeStreams, id)
+				continue
+			}
+			remainingLen := maxLen - length
+			// For the last STREAM frame, we'll remove the DataLen field later.
+			// Therefore, we can pretend to have more bytes available when popping
+			// the STREAM frame (which will always have the DataLen set).
+			remainingLen += quicvarint.Len(uint64(remainingLen))
+			frame, hasMoreData := str.popStreamFrame(remainingLen)
+			// A non-incremental stream stays at the front of the queue, as long as it has more data.
+			keepAtFront := hasMoreData && !f.activeStreams[id].incremental
+			if !keepAtFront {
+				f.streamQueues[urgency] = f.streamQueues[urgency][1:]
+				if hasMoreData { // put the stream back in the queue (at the end)
+					f.streamQueues[urgency] = append(f.streamQueues[urgency], id)
+				} else { // no more data to send. Stream is not active any more
+					delete(f.activeStreams, id)
+				}
+			}
+			// The frame can be nil
+			// * if the receiveStream was canceled after it said it had data
+			// * the remaining size doesn't allow us to add another STREAM frame
+			if frame != nil {
+				frames = append(frames, *frame)
+				length += frame.Length(f.version)
+				lastFrame = frame
+			}
+			// Only dequeue data from each stream once per packet.
+			if keepAtFront {
+				break
+			}
 		}
-		id := f.streamQueue[0]
-		f.streamQueue = f.streamQueue[1:]
-		// This should never return an error. Better check it anyway.
-		// The stream will only be in the streamQueue, if it enqueued itself there.
-		str, err := f.streamGetter.GetOrOpenSendStream(id)
-		// The stream can be nil if it completed after it said it had data.
-		if str == nil || err != nil {
-			delete(f.activeStreams, id)
-			continue
-		}
-		remainingLen := maxLen - length
-		// For the last STREAM frame, we'll remove the DataLen field later.
-		// Therefore, we can pretend to have more bytes available when popping
-		// the STREAM frame (which will always have the DataLen set).
-		remainingLen += quicvarint.Len(uint64(remainingLen))
-		frame, hasMoreData := str.popStreamFrame(remainingLen)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue = append(f.streamQueue, id)
-		} else { // no more data to send. Stream is not active any more
-			delete(f.activeStreams, id)
-		}
-		// The frame can be nil
-		// * if the receiveStream was canceled after it said it had data
-		// * the remaining size doesn't allow us to add another STREAM frame
-		if frame == nil {
-			continue
-		}
-		frames = append(frames, *frame)
-		length += frame.Length(f.version)
-		lastFrame = frame
 	}
 	f.mutex.Unlock()
 	if lastFrame != nil {
@@ -149,7 +180,9 @@ func (f *framerI) Handle0RTTRejection() error {
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue = f.streamQueue[:0]
+	for i := range f.streamQueues {
+		f.streamQueues[i] = f.streamQueues[i][:0]
+	}
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
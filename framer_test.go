@@ -122,7 +122,7 @@ var _ = Describe("Framer", func() {
 				DataLenPresent: true,
 			}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			fs, length := framer.AppendStreamFrames(nil, 1000)
 			Expect(fs).To(HaveLen(1))
 			Expect(fs[0].Frame.(*wire.StreamFrame).DataLenPresent).To(BeFalse())
@@ -132,7 +132,7 @@ var _ = Describe("Framer", func() {
 		It("says if it has data", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			Expect(framer.HasData()).To(BeFalse())
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			Expect(framer.HasData()).To(BeTrue())
 			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foo")}
 			f2 := &wire.StreamFrame{StreamID: id1, Data: []byte("bar")}
@@ -156,7 +156,7 @@ var _ = Describe("Framer", func() {
 				DataLenPresent: true,
 			}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			mdf := &wire.MaxDataFrame{MaximumData: 1337}
 			frames := []ackhandler.Frame{{Frame: mdf}}
 			fs, length := framer.AppendStreamFrames(frames, 1000)
@@ -176,8 +176,8 @@ var _ = Describe("Framer", func() {
 				DataLenPresent: true,
 			}
 			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
-			framer.AddActiveStream(id2)
+			framer.AddActiveStream(id1, defaultStreamPriority)
+			framer.AddActiveStream(id2, defaultStreamPriority)
 			frames, _ := framer.AppendStreamFrames(nil, 1000)
 			Expect(frames).To(HaveLen(1))
 			Expect(frames[0].Frame).To(Equal(f))
@@ -193,8 +193,8 @@ var _ = Describe("Framer", func() {
 			}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(nil, false)
 			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
-			framer.AddActiveStream(id2)
+			framer.AddActiveStream(id1, defaultStreamPriority)
+			framer.AddActiveStream(id2, defaultStreamPriority)
 			frames, _ := framer.AppendStreamFrames(nil, 1000)
 			Expect(frames).To(HaveLen(1))
 			Expect(frames[0].Frame).To(Equal(f))
@@ -206,7 +206,7 @@ var _ = Describe("Framer", func() {
 			f2 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, true)
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
-			framer.AddActiveStream(id1) // only add it once
+			framer.AddActiveStream(id1, defaultStreamPriority) // only add it once
 			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
 			Expect(frames).To(HaveLen(1))
 			Expect(frames[0].Frame).To(Equal(f1))
@@ -218,7 +218,7 @@ var _ = Describe("Framer", func() {
 			Expect(frames).To(BeNil())
 		})
 
-		It("re-queues a stream at the end, if it has enough data", func() {
+		It("re-queues an incremental stream at the end, if it has enough data", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
 			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
@@ -227,8 +227,8 @@ var _ = Describe("Framer", func() {
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
 			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
-			framer.AddActiveStream(id1) // only add it once
-			framer.AddActiveStream(id2)
+			framer.AddActiveStream(id1, streamPriority{urgency: 3, incremental: true}) // only add it once
+			framer.AddActiveStream(id2, streamPriority{urgency: 3, incremental: true})
 			// first a frame from stream 1
 			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
 			Expect(frames).To(HaveLen(1))
@@ -243,7 +243,7 @@ var _ = Describe("Framer", func() {
 			Expect(frames[0].Frame).To(Equal(f12))
 		})
 
-		It("only dequeues data from each stream once per packet", func() {
+		It("only dequeues data from each incremental stream once per packet", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
 			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
 			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
@@ -251,8 +251,8 @@ var _ = Describe("Framer", func() {
 			// both streams have more data, and will be re-queued
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, true)
 			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, true)
-			framer.AddActiveStream(id1)
-			framer.AddActiveStream(id2)
+			framer.AddActiveStream(id1, streamPriority{urgency: 3, incremental: true})
+			framer.AddActiveStream(id2, streamPriority{urgency: 3, incremental: true})
 			frames, length := framer.AppendStreamFrames(nil, 1000)
 			Expect(frames).To(HaveLen(2))
 			Expect(frames[0].Frame).To(Equal(f1))
@@ -267,8 +267,8 @@ var _ = Describe("Framer", func() {
 			f2 := &wire.StreamFrame{Data: []byte("foobaz")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
 			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
-			framer.AddActiveStream(id2)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id2, defaultStreamPriority)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			frames, _ := framer.AppendStreamFrames(nil, 1000)
 			Expect(frames).To(HaveLen(2))
 			Expect(frames[0].Frame).To(Equal(f2))
@@ -279,8 +279,8 @@ var _ = Describe("Framer", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
 			f := &wire.StreamFrame{Data: []byte("foobar")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false) // only one call to this function
-			framer.AddActiveStream(id1)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			frames, _ := framer.AppendStreamFrames(nil, 1000)
 			Expect(frames).To(HaveLen(1))
 		})
@@ -303,7 +303,7 @@ var _ = Describe("Framer", func() {
 					Expect(f.Length(version)).To(Equal(size))
 					return &ackhandler.Frame{Frame: f}, false
 				})
-				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id1, defaultStreamPriority)
 				frames, _ := framer.AppendStreamFrames(nil, i)
 				Expect(frames).To(HaveLen(1))
 				f := frames[0].Frame.(*wire.StreamFrame)
@@ -333,8 +333,8 @@ var _ = Describe("Framer", func() {
 					Expect(f.Length(version)).To(Equal(size))
 					return &ackhandler.Frame{Frame: f}, false
 				})
-				framer.AddActiveStream(id1)
-				framer.AddActiveStream(id2)
+				framer.AddActiveStream(id1, defaultStreamPriority)
+				framer.AddActiveStream(id2, defaultStreamPriority)
 				frames, _ := framer.AppendStreamFrames(nil, i)
 				Expect(frames).To(HaveLen(2))
 				f1 := frames[0].Frame.(*wire.StreamFrame)
@@ -349,7 +349,7 @@ var _ = Describe("Framer", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
 			f := &wire.StreamFrame{Data: []byte("foobar")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
 		})
 
@@ -367,7 +367,7 @@ var _ = Describe("Framer", func() {
 				DataLenPresent: true,
 			}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			fs, length := framer.AppendStreamFrames(nil, 500)
 			Expect(fs).To(HaveLen(1))
 			Expect(fs[0].Frame).To(Equal(f))
@@ -375,11 +375,116 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("drops all STREAM frames when 0-RTT is rejected", func() {
-			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id1, defaultStreamPriority)
 			Expect(framer.Handle0RTTRejection()).To(Succeed())
 			fs, length := framer.AppendStreamFrames(nil, protocol.MaxByteCount)
 			Expect(fs).To(BeEmpty())
 			Expect(length).To(BeZero())
 		})
 	})
+
+	Context("prioritizing streams", func() {
+		It("serves streams with a higher urgency first", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			framer.AddActiveStream(id1, streamPriority{urgency: 5, incremental: true})
+			framer.AddActiveStream(id2, streamPriority{urgency: 1, incremental: true})
+			frames, _ := framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f1))
+		})
+
+		It("doesn't serve streams with a lower urgency, as long as higher urgency streams fill the packet", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			framer.AddActiveStream(id2, streamPriority{urgency: 7, incremental: true})
+			framer.AddActiveStream(id1, streamPriority{urgency: 0, incremental: true})
+			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f11))
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f12))
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f2))
+		})
+
+		It("sends non-incremental streams one after the other", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			framer.AddActiveStream(id1, streamPriority{urgency: 3})
+			framer.AddActiveStream(id2, streamPriority{urgency: 3})
+			// stream 1 is sent completely, before stream 2 is served
+			frames, _ := framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f11))
+			frames, _ = framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f12))
+			Expect(frames[1].Frame).To(Equal(f2))
+		})
+
+		It("interleaves incremental streams with non-incremental streams of the same urgency", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f21 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			f22 := &wire.StreamFrame{StreamID: id2, Data: []byte("zaboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f21}, true)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f22}, false)
+			framer.AddActiveStream(id1, streamPriority{urgency: 2, incremental: true})
+			framer.AddActiveStream(id2, streamPriority{urgency: 2})
+			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f11))
+			// the non-incremental stream is now sent until it doesn't have any more data
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f21))
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f22))
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f12))
+		})
+
+		It("reschedules a stream when its priority changes", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			framer.AddActiveStream(id1, defaultStreamPriority)
+			framer.AddActiveStream(id2, defaultStreamPriority)
+			framer.AddActiveStream(id2, streamPriority{urgency: 0, incremental: true})
+			frames, _ := framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f1))
+			Expect(framer.HasData()).To(BeFalse())
+		})
+	})
 })
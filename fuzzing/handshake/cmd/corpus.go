@@ -65,6 +65,7 @@ func (r *runner) OnError(err error) {
 	(*r.server).Close()
 }
 func (r *runner) DropKeys(protocol.EncryptionLevel) {}
+func (r *runner) OnChangedVersion(protocol.VersionNumber) {}
 
 const alpn = "fuzz"
 
@@ -106,6 +107,7 @@ func main() {
 		runner,
 		config,
 		false,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -192,6 +192,7 @@ func (r *runner) Errored() bool {
 	return r.errored
 }
 func (r *runner) DropKeys(protocol.EncryptionLevel) {}
+func (r *runner) OnChangedVersion(protocol.VersionNumber) {}
 
 const (
 	alpn      = "fuzzing"
@@ -401,6 +402,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		runner,
 		serverConf,
 		enable0RTTServer,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
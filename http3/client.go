@@ -150,6 +150,7 @@ func (c *client) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	applyPriority(str, req.Header.Get("Priority"))
 
 	// Request Cancellation:
 	// This go routine keeps running even after RoundTrip() returns.
@@ -704,6 +704,21 @@ var _ = Describe("Client", func() {
 			Expect(res.StatusCode).To(Equal(418))
 		})
 
+		It("sets the priority of the request stream", func() {
+			resBuf := bytes.NewBuffer(getSimpleResponse(200))
+			request.Header.Set("Priority", "u=0")
+			sess.EXPECT().HandshakeComplete().Return(handshakeCtx)
+			sess.EXPECT().OpenStreamSync(context.Background()).Return(str, nil)
+			sess.EXPECT().ConnectionState().Return(quic.ConnectionState{})
+			str.EXPECT().SetPriority(uint8(0), false)
+			str.EXPECT().Write(gomock.Any()).AnyTimes().DoAndReturn(func(p []byte) (int, error) { return len(p), nil })
+			str.EXPECT().Close()
+			str.EXPECT().Read(gomock.Any()).DoAndReturn(resBuf.Read).AnyTimes()
+			res, err := client.RoundTrip(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(200))
+		})
+
 		It("handles interim responses", func() {
 			resBuf := &bytes.Buffer{}
 			resBuf.Write(getSimpleResponse(http.StatusProcessing))
@@ -0,0 +1,70 @@
+package http3
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lucas-clemente/quic-go"
+)
+
+// Priority is the priority of an HTTP request, as defined in RFC 9218.
+// It is carried in the Priority header field.
+type Priority struct {
+	// Urgency ranges from 0 (highest priority) to 7 (lowest priority).
+	Urgency uint8
+	// Incremental says if the response can be processed incrementally by the client.
+	Incremental bool
+}
+
+// DefaultPriority is the priority of requests that don't carry a Priority header field, as defined in RFC 9218.
+// It is the same as the default priority of QUIC streams.
+var DefaultPriority = Priority{Urgency: 3}
+
+// ParsePriority parses the value of a Priority header field.
+// Parameters that are unknown or have an invalid value are ignored.
+func ParsePriority(v string) Priority {
+	p := DefaultPriority
+	for _, member := range strings.Split(v, ",") {
+		member = strings.TrimSpace(member)
+		// parameters of dictionary members are not used
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		key, value := member, "?1" // a key without a value is a boolean true
+		if i := strings.IndexByte(member, '='); i >= 0 {
+			key, value = member[:i], member[i+1:]
+		}
+		switch key {
+		case "u":
+			if u, err := strconv.ParseUint(value, 10, 8); err == nil && u <= 7 {
+				p.Urgency = uint8(u)
+			}
+		case "i":
+			switch value {
+			case "?0":
+				p.Incremental = false
+			case "?1":
+				p.Incremental = true
+			}
+		}
+	}
+	return p
+}
+
+// String returns the value of the Priority header field.
+func (p Priority) String() string {
+	s := "u=" + strconv.Itoa(int(p.Urgency))
+	if p.Incremental {
+		s += ", i"
+	}
+	return s
+}
+
+// applyPriority sets the priority of the stream, if the Priority header field is present.
+func applyPriority(str quic.SendStream, header string) {
+	if header == "" {
+		return
+	}
+	p := ParsePriority(header)
+	str.SetPriority(p.Urgency, p.Incremental)
+}
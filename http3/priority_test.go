@@ -0,0 +1,46 @@
+package http3
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priority", func() {
+	It("uses the default priority", func() {
+		Expect(ParsePriority("")).To(Equal(DefaultPriority))
+		Expect(DefaultPriority.Urgency).To(BeEquivalentTo(3))
+		Expect(DefaultPriority.Incremental).To(BeFalse())
+	})
+
+	It("parses the urgency", func() {
+		Expect(ParsePriority("u=0")).To(Equal(Priority{Urgency: 0}))
+		Expect(ParsePriority("u=7")).To(Equal(Priority{Urgency: 7}))
+	})
+
+	It("parses the incremental flag", func() {
+		Expect(ParsePriority("i")).To(Equal(Priority{Urgency: 3, Incremental: true}))
+		Expect(ParsePriority("i=?1")).To(Equal(Priority{Urgency: 3, Incremental: true}))
+		Expect(ParsePriority("i=?0")).To(Equal(Priority{Urgency: 3}))
+	})
+
+	It("parses both parameters", func() {
+		Expect(ParsePriority("u=1, i")).To(Equal(Priority{Urgency: 1, Incremental: true}))
+		Expect(ParsePriority("i,u=5")).To(Equal(Priority{Urgency: 5, Incremental: true}))
+		Expect(ParsePriority("u=2;foo=bar, i;baz")).To(Equal(Priority{Urgency: 2, Incremental: true}))
+	})
+
+	It("ignores invalid and unknown parameters", func() {
+		Expect(ParsePriority("u=8")).To(Equal(DefaultPriority))
+		Expect(ParsePriority("u=-1")).To(Equal(DefaultPriority))
+		Expect(ParsePriority("u=foo")).To(Equal(DefaultPriority))
+		Expect(ParsePriority("i=1")).To(Equal(DefaultPriority))
+		Expect(ParsePriority("foo=bar, u=4")).To(Equal(Priority{Urgency: 4}))
+	})
+
+	It("serializes the priority", func() {
+		for _, p := range []Priority{{Urgency: 0}, {Urgency: 6, Incremental: true}, DefaultPriority} {
+			Expect(ParsePriority(p.String())).To(Equal(p))
+		}
+		Expect(Priority{Urgency: 1, Incremental: true}.String()).To(Equal("u=1, i"))
+	})
+})
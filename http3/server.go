@@ -33,7 +33,7 @@ const (
 )
 
 func versionToALPN(v protocol.VersionNumber) string {
-	if v == protocol.Version1 {
+	if v == protocol.Version1 || v == protocol.Version2 {
 		return nextProtoH3
 	}
 	if v == protocol.VersionTLS || v == protocol.VersionDraft29 {
@@ -138,7 +138,7 @@ func (s *Server) serveImpl(tlsConf *tls.Config, conn net.PacketConn) error {
 			// determine the ALPN from the QUIC version used
 			proto := nextProtoH3Draft29
 			if qconn, ok := ch.Conn.(handshake.ConnWithVersion); ok {
-				if qconn.GetQUICVersion() == protocol.Version1 {
+				if v := qconn.GetQUICVersion(); v == protocol.Version1 || v == protocol.Version2 {
 					proto = nextProtoH3
 				}
 			}
@@ -302,6 +302,8 @@ func (s *Server) handleRequestStream(str RequestStream) error {
 	}
 
 	req.RemoteAddr = str.RemoteAddr().String()
+	// The response is sent with the priority requested by the client.
+	applyPriority(str, req.Header.Get("Priority"))
 
 	onTrailers := func(fields []qpack.HeaderField, err error) {
 		if err != nil {
@@ -412,11 +414,18 @@ func (s *Server) SetQuicHeaders(hdr http.Header) error {
 		supportedVersions = s.QuicConfig.Versions
 	}
 	altSvc := make([]string, 0, len(supportedVersions))
+	// QUIC v1 and v2 use the same ALPN
+	alpns := make(map[string]struct{}, len(supportedVersions))
 	for _, version := range supportedVersions {
 		v := versionToALPN(version)
-		if len(v) > 0 {
-			altSvc = append(altSvc, fmt.Sprintf(`%s=":%d"; ma=2592000`, v, port))
+		if len(v) == 0 {
+			continue
 		}
+		if _, ok := alpns[v]; ok {
+			continue
+		}
+		alpns[v] = struct{}{}
+		altSvc = append(altSvc, fmt.Sprintf(`%s=":%d"; ma=2592000`, v, port))
 	}
 	hdr.Add("Alt-Svc", strings.Join(altSvc, ","))
 	return nil
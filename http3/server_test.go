@@ -173,6 +173,20 @@ var _ = Describe("Server", func() {
 			Expect(req.Context().Value(ServerContextKey)).To(Equal(s))
 		})
 
+		It("sends the response with the priority requested by the client", func() {
+			s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			exampleGetRequest.Header.Set("Priority", "u=1, i")
+			setRequest(encodeRequest(exampleGetRequest))
+			str.EXPECT().SetPriority(uint8(1), true)
+			str.EXPECT().Write(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return len(p), nil
+			}).AnyTimes()
+			str.EXPECT().CancelRead(gomock.Any())
+			str.EXPECT().Context().Return(ctx).AnyTimes()
+
+			Expect(s.handleRequestStream(rstr)).To(Succeed())
+		})
+
 		It("returns 200 with an empty handler", func() {
 			s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 
@@ -659,6 +673,14 @@ var _ = Describe("Server", func() {
 			Expect(s.SetQuicHeaders(hdr)).To(Succeed())
 			Expect(hdr).To(Equal(http.Header{"Alt-Svc": {`h3=":443"; ma=2592000,h3-29=":443"; ma=2592000`}}))
 		})
+
+		It("only adds one entry for QUIC v1 and v2", func() {
+			s.Server.Addr = ":443"
+			s.QuicConfig.Versions = []quic.VersionNumber{quic.Version2, quic.Version1, quic.VersionDraft29}
+			hdr := http.Header{}
+			Expect(s.SetQuicHeaders(hdr)).To(Succeed())
+			Expect(hdr).To(Equal(http.Header{"Alt-Svc": {`h3=":443"; ma=2592000,h3-29=":443"; ma=2592000`}}))
+		})
 	})
 
 	It("errors when ListenAndServe is called with s.Server nil", func() {
@@ -780,6 +802,9 @@ var _ = Describe("Server", func() {
 			c, err = conf.GetConfigForClient(&tls.ClientHelloInfo{Conn: newMockNetConn(protocol.Version1)})
 			ExpectWithOffset(1, err).ToNot(HaveOccurred())
 			ExpectWithOffset(1, c.NextProtos).To(Equal([]string{nextProtoH3}))
+			c, err = conf.GetConfigForClient(&tls.ClientHelloInfo{Conn: newMockNetConn(protocol.Version2)})
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, c.NextProtos).To(Equal([]string{nextProtoH3}))
 		}
 
 		It("uses the quic.Config to start the QUIC server", func() {
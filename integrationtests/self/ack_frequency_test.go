@@ -0,0 +1,81 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK Frequency", func() {
+	countFrames := func(packets []packet) (numAckFrequency int) {
+		for _, p := range packets {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrequencyFrame); ok {
+					numAckFrequency++
+				}
+			}
+		}
+		return
+	}
+
+	runTransfer := func(serverEnable, clientEnable bool) (*packetTracer, *packetTracer) {
+		serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: serverEnable,
+				Tracer:             newTracer(func() logging.ConnectionTracer { return serverTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRDataLong)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		clientTracer := newPacketTracer()
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: clientEnable,
+				Tracer:             newTracer(func() logging.ConnectionTracer { return clientTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRDataLong))
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+		return serverTracer, clientTracer
+	}
+
+	It("asks the peer to send fewer ACKs", func() {
+		serverTracer, clientTracer := runTransfer(true, true)
+		Expect(countFrames(serverTracer.getSentPackets())).ToNot(BeZero())
+		Expect(countFrames(clientTracer.getRcvdPackets())).ToNot(BeZero())
+	})
+
+	It("doesn't use the extension if the peer doesn't support it", func() {
+		serverTracer, _ := runTransfer(true, false)
+		Expect(countFrames(serverTracer.getSentPackets())).To(BeZero())
+	})
+})
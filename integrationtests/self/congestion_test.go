@@ -0,0 +1,87 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/congestion"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type countingCongestionControl struct {
+	congestion.CongestionControl
+	numAcked *int32
+}
+
+func (c *countingCongestionControl) OnPacketAcked(pn congestion.PacketNumber, ackedBytes, priorInFlight congestion.ByteCount, eventTime time.Time) {
+	atomic.AddInt32(c.numAcked, 1)
+	c.CongestionControl.OnPacketAcked(pn, ackedBytes, priorInFlight, eventTime)
+}
+
+var _ = Describe("Congestion Control", func() {
+	for _, n := range []string{"NewReno", "Cubic", "BBR"} {
+		name := n
+
+		It(fmt.Sprintf("transfers data using %s", name), func() {
+			var numAcked int32
+			remoteAddrChan := make(chan net.Addr, 1)
+			server, err := quic.ListenAddr(
+				"localhost:0",
+				getTLSConfig(),
+				getQuicConfig(&quic.Config{
+					CongestionControl: func(p congestion.Parameters) congestion.CongestionControl {
+						remoteAddrChan <- p.RemoteAddr
+						var cc congestion.CongestionControl
+						switch name {
+						case "NewReno":
+							cc = congestion.NewReno(p)
+						case "Cubic":
+							cc = congestion.NewCubic(p)
+						case "BBR":
+							cc = congestion.NewBBR(p)
+						}
+						return &countingCongestionControl{CongestionControl: cc, numAcked: &numAcked}
+					},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			go func() {
+				defer GinkgoRecover()
+				sess, err := server.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				str, err := sess.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(PRDataLong)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+			}()
+
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			str, err := sess.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRDataLong))
+
+			var remoteAddr net.Addr
+			Expect(remoteAddrChan).To(Receive(&remoteAddr))
+			Expect(remoteAddr.(*net.UDPAddr).Port).To(Equal(sess.LocalAddr().(*net.UDPAddr).Port))
+			Eventually(func() int32 { return atomic.LoadInt32(&numAcked) }).Should(BeNumerically(">", 10))
+		})
+	}
+})
@@ -9,6 +9,8 @@ import (
 
 	quic "github.com/lucas-clemente/quic-go"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/logging"
+	"github.com/lucas-clemente/quic-go/quiclb"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -87,4 +89,52 @@ var _ = Describe("Connection ID lengths tests", func() {
 		defer ln.Close()
 		runClient(ln.Addr(), clientConf)
 	})
+
+	It("uses connection IDs generated by a QUIC-LB encoder", func() {
+		lbConf := &quiclb.Config{
+			ID:          1,
+			Mode:        quiclb.StreamCipher,
+			ServerIDLen: 3,
+			NonceLen:    10,
+			Key:         []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		}
+		serverID := []byte{0xde, 0xad, 0xbe}
+		encoder, err := quiclb.NewEncoder(lbConf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		decoder, err := quiclb.NewDecoder(lbConf)
+		Expect(err).ToNot(HaveOccurred())
+
+		serverConf := getQuicConfig(&quic.Config{
+			ConnectionIDGenerator: encoder,
+			Versions:              []protocol.VersionNumber{protocol.VersionTLS},
+		})
+		clientTracer := newPacketTracer()
+		clientConf := getQuicConfig(&quic.Config{
+			Versions: []protocol.VersionNumber{protocol.VersionTLS},
+			Tracer:   newTracer(func() logging.ConnectionTracer { return clientTracer }),
+		})
+
+		ln := runServer(serverConf)
+		defer ln.Close()
+		runClient(ln.Addr(), clientConf)
+
+		var connIDs []logging.ConnectionID
+		for _, p := range clientTracer.getSentPackets() {
+			if !p.hdr.IsLongHeader || p.hdr.Type == protocol.PacketTypeHandshake {
+				connIDs = append(connIDs, p.hdr.DestConnectionID)
+			}
+		}
+		for _, p := range clientTracer.getRcvdPackets() {
+			for _, f := range p.frames {
+				if ncid, ok := f.(*logging.NewConnectionIDFrame); ok {
+					connIDs = append(connIDs, ncid.ConnectionID)
+				}
+			}
+		}
+		Expect(len(connIDs)).To(BeNumerically(">", 3))
+		for _, connID := range connIDs {
+			Expect(connID.Len()).To(Equal(encoder.ConnectionIDLen()))
+			Expect(decoder.ServerID(connID)).To(Equal(serverID))
+		}
+	})
 })
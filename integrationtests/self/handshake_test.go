@@ -1,12 +1,15 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/lucas-clemente/quic-go"
@@ -181,6 +184,68 @@ var _ = Describe("Handshake tests", func() {
 		})
 	}
 
+	Context("Compatible Version Negotiation", func() {
+		It("upgrades from QUIC v1 to QUIC v2 without a round trip", func() {
+			serverConfig.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			serverSessChan := make(chan quic.Session, 1)
+			go func() {
+				defer GinkgoRecover()
+				sess, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				str, err := sess.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				_, err = io.Copy(str, str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+				serverSessChan <- sess
+			}()
+
+			clientTracer := &versionNegotiationTracer{}
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					Versions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+					Tracer:   newTracer(func() logging.ConnectionTracer { return clientTracer }),
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			Expect(sess.(versioner).GetVersion()).To(Equal(protocol.Version2))
+			str, err := sess.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+			Expect(clientTracer.receivedVersionNegotiation).To(BeFalse())
+			Expect(clientTracer.chosen).To(Equal(protocol.Version2))
+
+			var serverSess quic.Session
+			Eventually(serverSessChan).Should(Receive(&serverSess))
+			Expect(serverSess.(versioner).GetVersion()).To(Equal(protocol.Version2))
+		})
+
+		It("stays on QUIC v1 if the server doesn't support QUIC v2", func() {
+			serverConfig.Versions = []protocol.VersionNumber{protocol.Version1}
+			runServer(getTLSConfig())
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{protocol.Version1, protocol.Version2}}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.(versioner).GetVersion()).To(Equal(protocol.Version1))
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+		})
+	})
+
 	Context("using different cipher suites", func() {
 		for n, id := range map[string]uint16{
 			"TLS_AES_128_GCM_SHA256":       tls.TLS_AES_128_GCM_SHA256,
@@ -533,6 +598,117 @@ var _ = Describe("Handshake tests", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("uses tokens persisted by a file-backed TokenStore", func() {
+			tokenChan := make(chan *quic.Token, 100)
+			serverConfig.AcceptToken = func(addr net.Addr, token *quic.Token) bool {
+				if token != nil && !token.IsRetryToken {
+					tokenChan <- token
+				}
+				return true
+			}
+			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+			go func() {
+				defer GinkgoRecover()
+				for {
+					if _, err := server.Accept(context.Background()); err != nil {
+						return
+					}
+				}
+			}()
+
+			dir, err := os.MkdirTemp("", "quic-go-tokens")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "tokens")
+
+			// dial the first session and receive the token
+			tokenStore, err := quic.NewFileTokenStore(path, 10, 4, time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{TokenStore: tokenStore}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(func() error { _, err := os.Stat(path); return err }).Should(Succeed())
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+			Expect(tokenChan).ToNot(Receive())
+			Expect(tokenStore.Flush()).To(Succeed())
+
+			// load the token from the file, as a new process would do
+			tokenStore, err = quic.NewFileTokenStore(path, 10, 4, time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			sess, err = quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{TokenStore: tokenStore}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			Expect(tokenChan).To(Receive())
+		})
+
+		It("accepts tokens issued by a different server using the same token keys", func() {
+			oldKey := bytes.Repeat([]byte{1}, 32)
+			newKey := bytes.Repeat([]byte{2}, 32)
+			server1, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{TokenKeys: [][]byte{oldKey}}))
+			Expect(err).ToNot(HaveOccurred())
+			defer server1.Close()
+			go func() {
+				defer GinkgoRecover()
+				_, err := server1.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			tokenChan := make(chan *quic.Token, 100)
+			serverConf := getQuicConfig(&quic.Config{
+				TokenKeys: [][]byte{newKey, oldKey},
+				AcceptToken: func(addr net.Addr, token *quic.Token) bool {
+					tokenChan <- token
+					return true
+				},
+			})
+			server2, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConf)
+			Expect(err).ToNot(HaveOccurred())
+			defer server2.Close()
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := server2.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			// dial the first server and receive a token
+			gets := make(chan string, 100)
+			puts := make(chan string, 100)
+			quicConf := getQuicConfig(&quic.Config{TokenStore: newTokenStore(gets, puts)})
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server1.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				quicConf,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(puts).Should(Receive())
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+			// dial the second server, and verify that the token was accepted
+			sess, err = quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server2.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				quicConf,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			var token *quic.Token
+			Expect(tokenChan).To(Receive(&token))
+			Expect(token).ToNot(BeNil())
+			Expect(token.IsRetryToken).To(BeFalse())
+			Eventually(done).Should(BeClosed())
+		})
+
 		It("rejects invalid Retry token with the INVALID_TOKEN error", func() {
 			tokenChan := make(chan *quic.Token, 10)
 			serverConfig.AcceptToken = func(addr net.Addr, token *quic.Token) bool {
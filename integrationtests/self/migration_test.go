@@ -0,0 +1,152 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Migration", func() {
+	for _, v := range protocol.SupportedVersions {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			It("migrates a connection to a new path", func() {
+				ln, err := quic.ListenAddr(
+					"localhost:0",
+					getTLSConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				serverSessChan := make(chan quic.Session, 1)
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					serverSessChan <- sess
+					str, err := sess.AcceptStream(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					// echo all data
+					_, err = io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+					str.Close()
+				}()
+
+				udpAddr, err := net.ResolveUDPAddr("udp", "localhost:0")
+				Expect(err).ToNot(HaveOccurred())
+				conn, err := net.ListenUDP("udp", udpAddr)
+				Expect(err).ToNot(HaveOccurred())
+				defer conn.Close()
+				newConn, err := net.ListenUDP("udp", udpAddr)
+				Expect(err).ToNot(HaveOccurred())
+				defer newConn.Close()
+
+				sess, err := quic.Dial(
+					conn,
+					ln.Addr(),
+					"localhost",
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.CloseWithError(0, "")
+				str, err := sess.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write([]byte("foo"))
+				Expect(err).ToNot(HaveOccurred())
+				data := make([]byte, 3)
+				_, err = io.ReadFull(str, data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foo")))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				Expect(sess.MigrateTo(ctx, newConn)).To(Succeed())
+				Expect(sess.LocalAddr()).To(Equal(newConn.LocalAddr()))
+
+				_, err = str.Write([]byte("bar"))
+				Expect(err).ToNot(HaveOccurred())
+				_, err = io.ReadFull(str, data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("bar")))
+
+				// The server validates the client's new address, and then starts sending to it.
+				var serverSess quic.Session
+				Eventually(serverSessChan).Should(Receive(&serverSess))
+				Eventually(func() int { return serverSess.RemoteAddr().(*net.UDPAddr).Port }).Should(Equal(newConn.LocalAddr().(*net.UDPAddr).Port))
+				Expect(str.Close()).To(Succeed())
+				_, err = io.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("migrates to the server's preferred address", func() {
+				ln, err := quic.ListenAddr(
+					"127.0.0.1:0",
+					getTLSConfig(),
+					getQuicConfig(&quic.Config{
+						Versions:             []protocol.VersionNumber{version},
+						PreferredAddressIPv4: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)},
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				serverSessChan := make(chan quic.Session, 1)
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					serverSessChan <- sess
+					str, err := sess.AcceptStream(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					// echo all data
+					_, err = io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+					str.Close()
+				}()
+
+				sess, err := quic.DialAddr(
+					fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.CloseWithError(0, "")
+				str, err := sess.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write([]byte("foo"))
+				Expect(err).ToNot(HaveOccurred())
+				data := make([]byte, 3)
+				_, err = io.ReadFull(str, data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foo")))
+
+				// The client migrates to the preferred address once the handshake is confirmed.
+				Eventually(func() int { return sess.RemoteAddr().(*net.UDPAddr).Port }).ShouldNot(Equal(ln.Addr().(*net.UDPAddr).Port))
+				preferredPort := sess.RemoteAddr().(*net.UDPAddr).Port
+
+				_, err = str.Write([]byte("bar"))
+				Expect(err).ToNot(HaveOccurred())
+				_, err = io.ReadFull(str, data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("bar")))
+
+				// The server starts sending from the preferred address, once it validated the path.
+				var serverSess quic.Session
+				Eventually(serverSessChan).Should(Receive(&serverSess))
+				Eventually(func() int { return serverSess.LocalAddr().(*net.UDPAddr).Port }).Should(Equal(preferredPort))
+				Expect(str.Close()).To(Succeed())
+				_, err = io.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	}
+})
@@ -0,0 +1,183 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// countingPacketConn counts the packets sent.
+// Once broken, it drops all packets, simulating a link that went down.
+type countingPacketConn struct {
+	net.PacketConn
+	numSent int64
+	broken  int32
+}
+
+func (c *countingPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(b)
+		if err != nil || atomic.LoadInt32(&c.broken) == 0 {
+			return n, addr, err
+		}
+	}
+}
+
+func (c *countingPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if atomic.LoadInt32(&c.broken) != 0 {
+		return len(b), nil
+	}
+	atomic.AddInt64(&c.numSent, 1)
+	return c.PacketConn.WriteTo(b, addr)
+}
+
+func (c *countingPacketConn) NumSent() int64 { return atomic.LoadInt64(&c.numSent) }
+
+func (c *countingPacketConn) Break() { atomic.StoreInt32(&c.broken, 1) }
+
+var _ = Describe("Multipath", func() {
+	for _, v := range protocol.SupportedVersions {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			var (
+				ln   quic.Listener
+				conn *countingPacketConn
+				sess quic.Session
+			)
+
+			BeforeEach(func() {
+				var err error
+				ln, err = quic.ListenAddr(
+					"localhost:0",
+					getTLSConfig(),
+					getQuicConfig(&quic.Config{
+						Versions:        []protocol.VersionNumber{version},
+						EnableMultipath: true,
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					for {
+						str, err := sess.AcceptStream(context.Background())
+						if err != nil {
+							return
+						}
+						go func() {
+							defer GinkgoRecover()
+							// echo all data
+							_, err := io.Copy(str, str)
+							Expect(err).ToNot(HaveOccurred())
+							str.Close()
+						}()
+					}
+				}()
+
+				udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				conn = &countingPacketConn{PacketConn: udpConn}
+				sess, err = quic.Dial(
+					conn,
+					ln.Addr(),
+					"localhost",
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{
+						Versions:        []protocol.VersionNumber{version},
+						EnableMultipath: true,
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				sess.CloseWithError(0, "")
+				conn.Close()
+				ln.Close()
+			})
+
+			// echo sends data on a new stream, and checks that it is echoed back
+			echo := func(data []byte, duringTransfer func()) {
+				str, err := sess.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					_, err := str.Write(data[:len(data)/2])
+					Expect(err).ToNot(HaveOccurred())
+					duringTransfer()
+					_, err = str.Write(data[len(data)/2:])
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+				received, err := io.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bytes.Equal(received, data)).To(BeTrue())
+			}
+
+			addPath := func() (quic.Path, *countingPacketConn) {
+				udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				newConn := &countingPacketConn{PacketConn: udpConn}
+				// The handshake needs to be confirmed before paths can be added.
+				echo([]byte("foobar"), func() {})
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				path, err := sess.AddPath(ctx, newConn)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(path.LocalAddr()).To(Equal(newConn.LocalAddr()))
+				return path, newConn
+			}
+
+			It("sends data on multiple paths", func() {
+				_, newConn := addPath()
+				defer newConn.Close()
+				numSent := conn.NumSent()
+				echo(PRDataLong, func() {})
+				Expect(conn.NumSent()).To(BeNumerically(">", numSent))
+				Expect(newConn.NumSent()).To(BeNumerically(">", 5))
+			})
+
+			It("continues the transfer when a path is closed", func() {
+				path, newConn := addPath()
+				defer newConn.Close()
+				echo(PRDataLong, func() {
+					Expect(path.Close()).To(Succeed())
+				})
+				numSent := newConn.NumSent()
+				echo(PRData, func() {})
+				Expect(newConn.NumSent()).To(BeNumerically("<=", numSent+1))
+				Expect(path.SetStatus(quic.PathStatusStandby)).ToNot(Succeed())
+			})
+
+			It("fails over when a path breaks", func() {
+				_, newConn := addPath()
+				defer newConn.Close()
+				echo(PRDataLong, func() {
+					newConn.Break()
+				})
+			})
+
+			It("doesn't use standby paths when another path is available", func() {
+				path, newConn := addPath()
+				defer newConn.Close()
+				Expect(path.SetStatus(quic.PathStatusStandby)).To(Succeed())
+				numSent := newConn.NumSent()
+				echo(PRDataLong, func() {})
+				// Only a few ACKs are sent on the standby path.
+				Expect(newConn.NumSent() - numSent).To(BeNumerically("<", conn.NumSent()/4))
+			})
+		})
+	}
+})
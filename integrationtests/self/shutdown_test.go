@@ -0,0 +1,106 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Graceful Shutdown", func() {
+	for _, v := range protocol.SupportedVersions {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			var (
+				ln         quic.Listener
+				serverSess chan quic.Session
+			)
+
+			dial := func() (quic.Session, error) {
+				return quic.DialAddr(
+					fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+			}
+
+			BeforeEach(func() {
+				var err error
+				ln, err = quic.ListenAddr(
+					"localhost:0",
+					getTLSConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				serverSess = make(chan quic.Session, 1)
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					serverSess <- sess
+					str, err := sess.OpenStream()
+					Expect(err).ToNot(HaveOccurred())
+					// The write fails if the session is closed when shutting down the server.
+					if _, err := str.Write(PRDataLong); err == nil {
+						Expect(str.Close()).To(Succeed())
+					}
+				}()
+			})
+
+			AfterEach(func() {
+				ln.Close()
+			})
+
+			It("lets active sessions finish their transfers", func() {
+				sess, err := dial()
+				Expect(err).ToNot(HaveOccurred())
+				str, err := sess.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				// start the transfer
+				data := make([]byte, 1000)
+				_, err = io.ReadFull(str, data)
+				Expect(err).ToNot(HaveOccurred())
+
+				shutdownErr := make(chan error, 1)
+				go func() { shutdownErr <- ln.Shutdown(context.Background()) }()
+				Consistently(shutdownErr, scaleDuration(50*time.Millisecond)).ShouldNot(Receive())
+
+				// new connections are refused
+				_, err = dial()
+				Expect(err).To(HaveOccurred())
+				var transportErr *quic.TransportError
+				Expect(errors.As(err, &transportErr)).To(BeTrue())
+				Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+
+				rest, err := io.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bytes.Equal(append(data, rest...), PRDataLong)).To(BeTrue())
+				Consistently(shutdownErr, scaleDuration(50*time.Millisecond)).ShouldNot(Receive())
+				Expect(sess.CloseWithError(0, "")).To(Succeed())
+				Eventually(shutdownErr).Should(Receive(BeNil()))
+			})
+
+			It("closes active sessions when the context expires", func() {
+				sess, err := dial()
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.CloseWithError(0, "")
+				Eventually(serverSess).Should(Receive())
+
+				ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(100*time.Millisecond))
+				defer cancel()
+				Expect(ln.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+				Eventually(sess.Context().Done()).Should(BeClosed())
+			})
+		})
+	}
+})
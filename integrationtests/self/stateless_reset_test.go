@@ -1,21 +1,41 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/lucas-clemente/quic-go"
 	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
 	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+type statelessResetTracer struct {
+	tracer
+	sent, rcvd int32
+}
+
+func (t *statelessResetTracer) TracerForConnection(context.Context, logging.Perspective, logging.ConnectionID) logging.ConnectionTracer {
+	return nil
+}
+
+func (t *statelessResetTracer) SentStatelessReset(net.Addr, logging.StatelessResetToken) {
+	atomic.AddInt32(&t.sent, 1)
+}
+
+func (t *statelessResetTracer) ReceivedStatelessReset(net.Addr, logging.StatelessResetToken) {
+	atomic.AddInt32(&t.rcvd, 1)
+}
+
 var _ = Describe("Stateless Resets", func() {
 	connIDLens := []int{0, 10}
 
@@ -106,4 +126,82 @@ var _ = Describe("Stateless Resets", func() {
 			Eventually(acceptStopped).Should(BeClosed())
 		})
 	}
+	It("sends stateless resets using the previous key after a key rotation", func() {
+		oldKey := make([]byte, 32)
+		rand.Read(oldKey)
+		newKey := make([]byte, 32)
+		rand.Read(newKey)
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{StatelessResetKey: oldKey}))
+		Expect(err).ToNot(HaveOccurred())
+		serverPort := ln.Addr().(*net.UDPAddr).Port
+
+		closeServer := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			sess, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			<-closeServer
+			ln.Close()
+		}()
+
+		drop := utils.AtomicBool{}
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", serverPort),
+			DropPacket: func(quicproxy.Direction, []byte) bool { return drop.Get() },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		clientTracer := &statelessResetTracer{}
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				ConnectionIDLength: 10,
+				MaxIdleTimeout:     2 * time.Second,
+				Tracer:             clientTracer,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data := make([]byte, 6)
+		_, err = str.Read(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal([]byte("foobar")))
+
+		// make sure that the CONNECTION_CLOSE is dropped
+		drop.Set(true)
+		close(closeServer)
+		time.Sleep(100 * time.Millisecond)
+
+		// The new server uses the new key, but still knows the old key.
+		serverTracer := &statelessResetTracer{}
+		ln2, err := quic.ListenAddr(
+			fmt.Sprintf("localhost:%d", serverPort),
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{StatelessResetKey: oldKey, Tracer: serverTracer}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln2.Close()
+		Expect(ln2.RotateStatelessResetKey(newKey)).To(Succeed())
+		drop.Set(false)
+
+		// Trigger a packet to be sent that is large enough for stateless resets for both keys.
+		_, serr := str.Write(bytes.Repeat([]byte("Lorem ipsum dolor sit amet. "), 5))
+		if serr == nil {
+			_, serr = str.Read([]byte{0})
+		}
+		Expect(serr).To(HaveOccurred())
+		statelessResetErr := &quic.StatelessResetError{}
+		Expect(errors.As(serr, &statelessResetErr)).To(BeTrue())
+		// one stateless reset for each key
+		Expect(atomic.LoadInt32(&serverTracer.sent)).To(BeNumerically(">=", 2))
+		Expect(ln2.StatelessResetStats().Sent).To(BeNumerically(">=", 2))
+		Expect(atomic.LoadInt32(&clientTracer.rcvd)).To(BeEquivalentTo(1))
+	})
 })
@@ -243,6 +243,47 @@ var _ = Describe("0-RTT", func() {
 				})
 			}
 
+			It("transfers 0-RTT data, using session states imported from a different ClientSessionCache", func() {
+				ln, err := quic.ListenAddrEarly(
+					"localhost:0",
+					getTLSConfig(),
+					getQuicConfig(&quic.Config{
+						Versions:    []protocol.VersionNumber{version},
+						AcceptToken: func(_ net.Addr, _ *quic.Token) bool { return true },
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					<-sess.Context().Done()
+				}()
+
+				// dial the first session in order to receive a session ticket
+				cache := quic.NewClientSessionCache(10)
+				emptyCache := quic.NewClientSessionCache(10).Export()
+				clientConf := getTLSClientConfig()
+				clientConf.ClientSessionCache = cache
+				sess, err := quic.DialAddr(
+					fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+					clientConf,
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{version}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(cache.Export).ShouldNot(Equal(emptyCache))
+				data := cache.Export()
+				Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+				// import the session state, as a new process would do
+				cache = quic.NewClientSessionCache(10)
+				Expect(cache.Import(data)).To(Succeed())
+				clientConf = getTLSClientConfig()
+				clientConf.ClientSessionCache = cache
+				transfer0RTTData(ln, ln.Addr().(*net.UDPAddr).Port, clientConf, nil, PRData)
+			})
+
 			// Test that data intended to be sent with 1-RTT protection is not sent in 0-RTT packets.
 			It("waits until a session until the handshake is done", func() {
 				tlsConf, clientConf := dialAndReceiveSessionTicket(nil)
@@ -556,6 +597,101 @@ var _ = Describe("0-RTT", func() {
 				Expect(get0RTTPackets(tracer.getRcvdPackets())).To(BeEmpty())
 			})
 
+			It("rejects 0-RTT when the server doesn't allow it", func() {
+				tlsConf, clientConf := dialAndReceiveSessionTicket(nil)
+
+				tracer := newPacketTracer()
+				remoteAddrs := make(chan net.Addr, 1)
+				ln, err := quic.ListenAddrEarly(
+					"localhost:0",
+					tlsConf,
+					getQuicConfig(&quic.Config{
+						Versions:    []protocol.VersionNumber{version},
+						AcceptToken: func(_ net.Addr, _ *quic.Token) bool { return true },
+						Allow0RTT: func(addr net.Addr) bool {
+							remoteAddrs <- addr
+							return false
+						},
+						Tracer: newTracer(func() logging.ConnectionTracer { return tracer }),
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+				defer proxy.Close()
+
+				check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+				var remoteAddr net.Addr
+				Expect(remoteAddrs).To(Receive(&remoteAddr))
+				Expect(remoteAddr.(*net.UDPAddr).IP.IsLoopback()).To(BeTrue())
+
+				// The client should send 0-RTT packets, but the server doesn't process them.
+				num0RTT := atomic.LoadUint32(num0RTTPackets)
+				fmt.Fprintf(GinkgoWriter, "Sent %d 0-RTT packets.", num0RTT)
+				Expect(num0RTT).ToNot(BeZero())
+				Expect(get0RTTPackets(tracer.getRcvdPackets())).To(BeEmpty())
+			})
+
+			It("rejects 0-RTT when the client's first flight is replayed", func() {
+				tlsConf, clientConf := dialAndReceiveSessionTicket(nil)
+
+				serverConf := getQuicConfig(&quic.Config{
+					Versions:    []protocol.VersionNumber{version},
+					AcceptToken: func(_ net.Addr, _ *quic.Token) bool { return true },
+					AntiReplay:  quic.NewAntiReplay(time.Minute),
+				})
+				ln, err := quic.ListenAddrEarly("localhost:0", tlsConf, serverConf)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				// The attacker replays the packets to a second server, which shares the session ticket key and the AntiReplay.
+				tracer := newPacketTracer()
+				serverConf2 := serverConf.Clone()
+				serverConf2.Tracer = newTracer(func() logging.ConnectionTracer { return tracer })
+				ln2, err := quic.ListenAddrEarly("localhost:0", tlsConf, serverConf2)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln2.Close()
+
+				var mutex sync.Mutex
+				var longHeaderPackets [][]byte
+				proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+					RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+					DelayPacket: func(_ quicproxy.Direction, data []byte) time.Duration { return rtt / 2 },
+					DropPacket: func(dir quicproxy.Direction, data []byte) bool {
+						if dir == quicproxy.DirectionIncoming && data[0]&0x80 > 0 {
+							mutex.Lock()
+							longHeaderPackets = append(longHeaderPackets, append([]byte(nil), data...))
+							mutex.Unlock()
+						}
+						return false
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				defer proxy.Close()
+
+				transfer0RTTData(ln, proxy.LocalPort(), clientConf, nil, PRData)
+
+				conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				defer conn.Close()
+				mutex.Lock()
+				Expect(longHeaderPackets).ToNot(BeEmpty())
+				for _, p := range longHeaderPackets {
+					_, err := conn.WriteTo(p, ln2.Addr())
+					Expect(err).ToNot(HaveOccurred())
+				}
+				mutex.Unlock()
+
+				ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(100*time.Millisecond))
+				defer cancel()
+				// The handshake never completes, since the attacker can't complete it.
+				sess, err := ln2.Accept(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = sess.AcceptUniStream(ctx)
+				Expect(err).To(Equal(context.DeadlineExceeded))
+				Expect(sess.CloseWithError(0, "")).To(Succeed())
+				Expect(get0RTTPackets(tracer.getRcvdPackets())).To(BeEmpty())
+			})
+
 			DescribeTable("flow control limits",
 				func(addFlowControlLimit func(*quic.Config, uint64)) {
 					tracer := newPacketTracer()
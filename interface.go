@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -23,6 +24,8 @@ const (
 	VersionDraft29 = protocol.VersionDraft29
 	// Version1 is RFC 9000
 	Version1 = protocol.Version1
+	// Version2 is RFC 9369
+	Version2 = protocol.Version2
 )
 
 // Perspective determines if a session is acting as a server or client.
@@ -48,7 +51,8 @@ type Token struct {
 // A ClientToken is a token received by the client.
 // It can be used to skip address validation on future connection attempts.
 type ClientToken struct {
-	data []byte
+	data    []byte
+	rcvTime time.Time // only set by the file-backed TokenStore
 }
 
 type TokenStore interface {
@@ -62,6 +66,16 @@ type TokenStore interface {
 	Put(key string, token *ClientToken)
 }
 
+// A FileTokenStore is a TokenStore that persists tokens to a file.
+type FileTokenStore interface {
+	TokenStore
+
+	// Flush waits until all tokens have been written to the file.
+	// It must be called before the process exits, otherwise tokens received shortly before might be lost.
+	// It returns the error that occurred when writing the file, if any.
+	Flush() error
+}
+
 // Err0RTTRejected is the returned from:
 // * Open{Uni}Stream{Sync}
 // * Accept{Uni}Stream
@@ -145,6 +159,14 @@ type SendStream interface {
 	// some of the data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetPriority sets the priority of the stream, using the scheme defined in RFC 9218.
+	// The urgency ranges from 0 (highest priority) to 7 (lowest priority), larger values are treated as 7.
+	// Data on streams with a higher priority is sent before data on streams with a lower priority.
+	// Incremental streams of the same urgency share the available bandwidth,
+	// non-incremental streams of the same urgency are sent one after the other.
+	// By default, streams have an urgency of 3 and are not incremental, as defined in RFC 9218.
+	// Streams that share the bandwidth with other streams therefore need to be marked as incremental.
+	SetPriority(urgency uint8, incremental bool)
 }
 
 // A Session is a QUIC connection between two peers.
@@ -202,13 +224,137 @@ type Session interface {
 	// It blocks until the handshake completes.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// Stats returns a snapshot of the statistics of the session.
+	// After the session was closed, it returns the statistics at the time it was closed.
+	Stats() ConnectionStats
 
 	// SendMessage sends a message as a datagram.
+	// The message is queued for sending. If the send queue is full, the Config.DatagramDropPolicy applies.
 	// See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 	SendMessage([]byte) error
+	// SendMessageWithCallback sends a message as a datagram, like SendMessage.
+	// The callback is called with true when the datagram is acknowledged by the peer,
+	// and with false when it is declared lost, or dropped without being sent.
+	// It is called at most once: If a datagram that was declared lost is acknowledged later, this is not reported.
+	// If the datagram is dropped because the send queue is full, the callback is called from the SendMessage
+	// (or SendMessageWithCallback) call that caused the drop. Otherwise, it is called from the session's run loop.
+	// In any case, it must not block.
+	// If the session is closed before the fate of the datagram is known, the callback is not called.
+	SendMessageWithCallback(msg []byte, callback func(acked bool)) error
+	// SendMessages sends multiple messages as datagrams.
+	// If any of the messages is too large, none of them is sent.
+	// The messages are queued together: If the send queue is full, either all of them are queued, or none of them.
+	// With DatagramDropNone, SendMessages blocks until there's space for all messages.
+	// If the session is closed while waiting, none of the messages is queued.
+	// With DatagramDropNewest, all messages are dropped if they don't fit into the queue.
+	// With DatagramDropOldest, previously queued datagrams are dropped to make space for all messages.
+	// A batch that is larger than Config.DatagramSendQueueLen is queued once the queue is empty (DatagramDropNone),
+	// or after dropping all previously queued datagrams (DatagramDropOldest).
+	SendMessages([][]byte) error
+	// MaxDatagramSize returns the maximum size of a message that can be sent as a datagram.
+	// It depends on the peer's max_datagram_frame_size transport parameter and the current MTU.
+	// It returns 0 if datagrams are not supported, or if the peer's transport parameters are not yet known.
+	MaxDatagramSize() int
 	// ReceiveMessage gets a message received in a datagram.
 	// See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 	ReceiveMessage() ([]byte, error)
+
+	// MigrateTo migrates the connection to a new local address, using the given packet conn.
+	// The new path is validated (using PATH_CHALLENGE and PATH_RESPONSE frames) before it is used.
+	// If validation fails or the context is canceled, the session keeps using the current path.
+	// Migration is only possible for clients, after the handshake has been confirmed,
+	// and if the server didn't disable active migration.
+	// The packet conn is not closed when the session is closed.
+	// Warning: This API should not be considered stable and might change soon.
+	MigrateTo(context.Context, net.PacketConn) error
+	// AddPath adds a new path using the given packet conn, if both peers enabled multipath.
+	// The path is validated (using PATH_CHALLENGE and PATH_RESPONSE frames) before it is used.
+	// Once validated, packets are distributed across all paths by the Config.PathScheduler.
+	// A path is abandoned when it fails, i.e. after multiple consecutive probe timeouts, while other paths are still working.
+	// Adding paths is only possible for clients, after the handshake has been confirmed.
+	// The packet conn is not closed when the path or the session is closed.
+	// It must not be closed before the path is closed, since the session is closed if reading from the packet conn fails.
+	// Warning: This API is experimental and might change soon.
+	AddPath(context.Context, net.PacketConn) (Path, error)
+}
+
+// PathStatus is the status of a path, see draft-ietf-quic-multipath.
+type PathStatus uint8
+
+const (
+	// PathStatusAvailable means that the path may be used for sending.
+	PathStatusAvailable PathStatus = iota
+	// PathStatusStandby means that the path should only be used if no available path can be used.
+	PathStatusStandby
+)
+
+// A Path is an additional path of a multipath session.
+type Path interface {
+	LocalAddr() net.Addr
+	RemoteAddr() net.Addr
+	// SetStatus sets the status of the path, and informs the peer about it.
+	SetStatus(PathStatus) error
+	// Close abandons the path.
+	// Data that was sent on this path and is still outstanding is retransmitted on the remaining paths.
+	Close() error
+}
+
+// PathInfo describes a validated path of a multipath session.
+type PathInfo struct {
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	// Status is PathStatusStandby if either we or the peer marked the path as standby.
+	Status      PathStatus
+	SmoothedRTT time.Duration
+
+	CongestionWindow uint64
+	BytesInFlight    uint64
+	// CanSend is true if the congestion controller allows sending a packet on this path.
+	CanSend bool
+	// PotentiallyFailed is true if the path experienced a probe timeout,
+	// and no packet sent on it has been acknowledged since then.
+	PotentiallyFailed bool
+}
+
+// A PathScheduler decides which path a packet is sent on.
+type PathScheduler interface {
+	// SelectPath is called for every packet, except for packets only carrying acknowledgements,
+	// which are always sent on the path they acknowledge packets for.
+	// The first path is the one the handshake was performed on.
+	// It returns the index of the path to use, or -1 if no packet should be sent right now.
+	SelectPath(paths []PathInfo) int
+}
+
+// A TokenProtector encrypts and authenticates the tokens that a server sends in Retry packets and NEW_TOKEN frames.
+// Tokens are opaque to the client, and only need to be decodable by the servers that the client might connect to.
+type TokenProtector interface {
+	// NewToken encrypts and authenticates the token data.
+	NewToken(data []byte) ([]byte, error)
+	// DecodeToken decrypts a token. It returns an error if the token wasn't created by NewToken,
+	// or if it was modified.
+	DecodeToken(token []byte) ([]byte, error)
+}
+
+// A ConnectionIDGenerator generates connection IDs.
+type ConnectionIDGenerator interface {
+	// GenerateConnectionID generates a new connection ID.
+	// Connection IDs must be unique, and observers must not be able to link two connection IDs of the same connection.
+	// It may be called concurrently.
+	GenerateConnectionID() ([]byte, error)
+	// ConnectionIDLen returns the length of the connection IDs returned by GenerateConnectionID.
+	// It must return a constant value between 4 and 20.
+	ConnectionIDLen() int
+}
+
+// AntiReplay protects a server against replays of 0-RTT data.
+// 0-RTT data is not forward secure, and an attacker can replay it to the server (see RFC 8446, Section 8).
+// Every session ticket is identified by a unique ID. A server only accepts 0-RTT if Accept returns true.
+type AntiReplay interface {
+	// Accept is called when a client attempts 0-RTT using a session ticket.
+	// It should return false if a session ticket with the same ID was used before.
+	// The issue time of the session ticket allows limiting the time window for which IDs need to be stored.
+	// It may be called concurrently.
+	Accept(ticketID []byte, issued time.Time) bool
 }
 
 // An EarlySession is a session that is handshaking.
@@ -238,7 +384,12 @@ type Config struct {
 	// If used for dialing an address, a 0 byte connection ID will be used.
 	// If used for a server, or dialing on a packet conn, a 4 byte connection ID will be used.
 	// When dialing on a packet conn, the ConnectionIDLength value must be the same for every Dial call.
+	// If a ConnectionIDGenerator is set, the length is determined by the generator.
 	ConnectionIDLength int
+	// ConnectionIDGenerator generates the connection IDs that the peer uses to address us.
+	// It can be used to encode routing information into connection IDs, e.g. using QUIC-LB (see the quiclb package).
+	// If not set, random connection IDs of length ConnectionIDLength are used.
+	ConnectionIDGenerator ConnectionIDGenerator
 	// HandshakeIdleTimeout is the idle timeout before completion of the handshake.
 	// Specifically, if we don't receive any packet from the peer within this time, the connection attempt is aborted.
 	// If this value is zero, the timeout is set to 5 seconds.
@@ -257,10 +408,36 @@ type Config struct {
 	//   * else, that it was issued within the last 24 hours.
 	// This option is only valid for the server.
 	AcceptToken func(clientAddr net.Addr, token *Token) bool
+	// TokenKeys are the keys used to encrypt and authenticate the tokens sent in Retry packets and NEW_TOKEN frames.
+	// Every key must be 32 bytes long. The first key is used to create new tokens,
+	// all keys are used to verify tokens received from clients.
+	// This allows rotating keys: a new key is first added at the end of the list on all servers,
+	// then moved to the front, and the old key is removed once it's not needed any more.
+	// Servers sharing the same keys accept each other's tokens.
+	// If not set, a random key is generated for every Listener.
+	// This option is only valid for the server.
+	TokenKeys [][]byte
+	// TokenProtector encrypts and authenticates tokens.
+	// It can be used to integrate an external key management system.
+	// It must not be set at the same time as TokenKeys.
+	// This option is only valid for the server.
+	TokenProtector TokenProtector
+	// Allow0RTT determines if 0-RTT is accepted for a connection attempt from clientAddr.
+	// It is only called for 0-RTT attempts when listening using ListenEarly.
+	// If not set, 0-RTT is accepted whenever the session ticket allows it.
+	// This option is only valid for the server.
+	Allow0RTT func(clientAddr net.Addr) bool
+	// AntiReplay protects against replays of 0-RTT data.
+	// It is consulted after Allow0RTT. NewAntiReplay returns an in-memory implementation.
+	// If not set, 0-RTT data can be replayed by an attacker.
+	// Servers that share session ticket keys should also share the AntiReplay.
+	// This option is only valid for the server.
+	AntiReplay AntiReplay
 	// The TokenStore stores tokens received from the server.
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
 	// otherwise the token is associated with the server's IP address.
+	// NewLRUTokenStore returns an in-memory TokenStore, NewFileTokenStore one that persists tokens across process restarts.
 	TokenStore TokenStore
 	// InitialStreamReceiveWindow is the initial size of the stream-level flow control window for receiving data.
 	// If the application is consuming data quickly enough, the flow control auto-tuning algorithm
@@ -296,26 +473,136 @@ type Config struct {
 	// DisablePathMTUDiscovery disables Path MTU Discovery (RFC 8899).
 	// Packets will then be at most 1252 (IPv4) / 1232 (IPv6) bytes in size.
 	DisablePathMTUDiscovery bool
+	// DisableActiveMigration makes us send the disable_active_migration transport parameter (RFC 9000, Section 18.2),
+	// which tells the peer that it must not migrate the connection to a new address.
+	// A server then pins the connection to the client's address:
+	// Packets arriving from a new client address are dropped, instead of validating the address and migrating to it.
+	// Clients are still allowed to migrate to the server's preferred address.
+	DisableActiveMigration bool
 	// DisableVersionNegotiationPackets disables the sending of Version Negotiation packets.
 	// This can be useful if version information is exchanged out-of-band.
 	// It has no effect for a client.
 	DisableVersionNegotiationPackets bool
+	// PreferredAddressIPv4 and PreferredAddressIPv6 are the addresses that the server advertises in the
+	// preferred_address transport parameter (RFC 9000, Section 9.6).
+	// The server listens on these addresses in addition to the address passed to Listen,
+	// and clients migrate to the preferred address after the handshake has been confirmed.
+	// The addresses must be specific addresses, not wildcard addresses. If the port is 0, a random port is chosen.
+	// Closing the Listener closes the packet conns for the preferred addresses, which also closes all connections accepted by the Listener.
+	// These options are only valid for the server.
+	PreferredAddressIPv4 *net.UDPAddr
+	PreferredAddressIPv6 *net.UDPAddr
 	// See https://datatracker.ietf.org/doc/draft-ietf-quic-datagram/.
 	// Datagrams will only be available when both peers enable datagram support.
 	EnableDatagrams bool
-	Tracer          logging.Tracer
+	// DatagramSendQueueLen is the maximum number of datagrams queued for sending.
+	// If not set, it defaults to 32.
+	DatagramSendQueueLen int
+	// DatagramDropPolicy determines what happens when a datagram is sent while the send queue is full.
+	// By default, SendMessage blocks until there's space in the queue.
+	DatagramDropPolicy DatagramDropPolicy
+	// EnableAckFrequency enables the ACK Frequency extension.
+	// See https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/.
+	// When both peers enable it, we ask the peer to send fewer ACKs when the congestion window is large,
+	// and we follow the peer's requests to adjust our ACK rate.
+	EnableAckFrequency bool
+	// EnableMultipath enables the multipath extension.
+	// See https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/.
+	// When both peers enable it, the client can add paths using Session.AddPath.
+	// Multipath requires non-zero-length connection IDs, so the ConnectionIDLength defaults to 4 bytes
+	// when dialing an address.
+	// Warning: This API is experimental and might change soon.
+	EnableMultipath bool
+	// PathScheduler selects the path each packet is sent on, if multipath is used.
+	// If not set, packets are sent on the path with the lowest RTT that the congestion controller allows
+	// sending on, preferring available paths over standby paths, and paths that haven't experienced a probe timeout.
+	PathScheduler PathScheduler
+	// CongestionControl creates the congestion controller for a new connection.
+	// It is called again when the connection migrates to a new path,
+	// since the congestion controller state doesn't apply to the new path,
+	// and for every path that is added when using multipath.
+	// If not set, NewReno (RFC 9002, Section 7) is used.
+	// To use HyStart++ (RFC 9406) for leaving slow start, use congestion.NewRenoHyStartPlusPlus or congestion.NewCubicHyStartPlusPlus.
+	CongestionControl func(congestion.Parameters) congestion.CongestionControl
+	Tracer            logging.Tracer
 }
 
+// A DatagramDropPolicy determines which datagram is dropped when the datagram send queue is full.
+type DatagramDropPolicy uint8
+
+const (
+	// DatagramDropNone doesn't drop any datagrams.
+	// Sending a datagram blocks until there's space in the send queue.
+	DatagramDropNone DatagramDropPolicy = iota
+	// DatagramDropOldest drops the oldest queued datagram to make space for the new one.
+	DatagramDropOldest
+	// DatagramDropNewest drops the datagram that is being sent.
+	DatagramDropNewest
+)
+
 // ConnectionState records basic details about a QUIC connection
 type ConnectionState struct {
 	TLS               handshake.ConnectionState
 	SupportsDatagrams bool
 }
 
+// ConnectionStats contains statistics about a QUIC connection.
+// The RTT estimates and the congestion window apply to the path that is currently used.
+type ConnectionStats struct {
+	// The RTT estimates, see RFC 9002, Section 5.
+	MinRTT        time.Duration
+	LatestRTT     time.Duration
+	SmoothedRTT   time.Duration
+	MeanDeviation time.Duration
+
+	CongestionWindow uint64
+	BytesInFlight    uint64
+
+	// BytesSent and BytesReceived count the size of all QUIC packets sent and received.
+	BytesSent     uint64
+	BytesReceived uint64
+	// BytesRetransmitted is the size of the packets whose frames were retransmitted,
+	// either because the packets were declared lost, or to probe the path.
+	BytesRetransmitted uint64
+	PacketsSent        uint64
+	PacketsLost        uint64
+
+	// MaxDatagramSize is the maximum size of the packets sent, as determined by Path MTU Discovery.
+	MaxDatagramSize uint64
+	// KeyUpdates is the number of key updates, initiated by either peer.
+	KeyUpdates uint64
+	// DatagramsDropped is the number of datagrams that were dropped without being sent,
+	// either because the send queue was full, or because they didn't fit into a packet.
+	DatagramsDropped uint64
+}
+
+// StatelessResetStats contains statistics about the stateless resets sent and received by a Listener.
+type StatelessResetStats struct {
+	// Sent is the number of stateless resets sent in response to packets for unknown connections.
+	Sent uint64
+	// Received is the number of stateless resets received, i.e. the number of sessions that were closed by a stateless reset.
+	Received uint64
+}
+
 // A Listener for incoming QUIC connections
 type Listener interface {
 	// Close the server. All active sessions will be closed.
 	Close() error
+	// Shutdown gracefully shuts down the server.
+	// New connections are refused, and Accept returns ErrServerClosed.
+	// Sessions that weren't returned by Accept yet are closed with a CONNECTION_REFUSED error.
+	// Shutdown then waits until all active sessions are closed, or until the context expires.
+	// After that, the server is closed, closing all remaining sessions.
+	// If the context expired, the context's error is returned.
+	Shutdown(context.Context) error
+	// RotateStatelessResetKey starts using a new key to derive stateless reset tokens.
+	// The previous key is retained, so stateless resets are still sent for connection IDs issued before the rotation,
+	// as long as the received packet is large enough to respond with stateless resets for both keys.
+	// Any older key is discarded.
+	// If no StatelessResetKey was configured, this enables sending of stateless resets.
+	RotateStatelessResetKey(key []byte) error
+	// StatelessResetStats returns statistics about the stateless resets sent and received.
+	StatelessResetStats() StatelessResetStats
 	// Addr returns the local network addr that the server is listening on.
 	Addr() net.Addr
 	// Accept returns new sessions. It should be called in a loop.
@@ -327,6 +614,14 @@ type Listener interface {
 type EarlyListener interface {
 	// Close the server. All active sessions will be closed.
 	Close() error
+	// Shutdown gracefully shuts down the server.
+	// It works like Listener.Shutdown.
+	Shutdown(context.Context) error
+	// RotateStatelessResetKey starts using a new key to derive stateless reset tokens.
+	// It works like Listener.RotateStatelessResetKey.
+	RotateStatelessResetKey(key []byte) error
+	// StatelessResetStats returns statistics about the stateless resets sent and received.
+	StatelessResetStats() StatelessResetStats
 	// Addr returns the local network addr that the server is listening on.
 	Addr() net.Addr
 	// Accept returns new early sessions. It should be called in a loop.
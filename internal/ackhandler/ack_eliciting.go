@@ -5,8 +5,9 @@ import "github.com/lucas-clemente/quic-go/internal/wire"
 // IsFrameAckEliciting returns true if the frame is ack-eliciting.
 func IsFrameAckEliciting(f wire.Frame) bool {
 	_, isAck := f.(*wire.AckFrame)
+	_, isAckMP := f.(*wire.AckMPFrame)
 	_, isConnectionClose := f.(*wire.ConnectionCloseFrame)
-	return !isAck && !isConnectionClose
+	return !isAck && !isAckMP && !isConnectionClose
 }
 
 // HasAckElicitingFrames returns true if at least one frame is ack-eliciting.
@@ -0,0 +1,55 @@
+package ackhandler
+
+import (
+	"sort"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// The ackedPacketRanges are the packet numbers acknowledged by the peer, sorted in ascending order.
+// Acknowledged packets are removed from the sent packet history, and non-ack-eliciting packets are never added to it.
+// The ranges are used to tell if a gap between two packets in the history contains an acknowledged packet.
+type ackedPacketRanges []utils.PacketInterval
+
+// AddAckFrame adds the ranges acknowledged by an ACK frame.
+func (r *ackedPacketRanges) AddAckFrame(ack *wire.AckFrame) {
+	for _, ackRange := range ack.AckRanges {
+		r.add(ackRange.Smallest, ackRange.Largest)
+	}
+}
+
+func (r *ackedPacketRanges) add(start, end protocol.PacketNumber) {
+	ranges := *r
+	// the first range that overlaps with or is adjacent to [start, end]
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].End+1 >= start })
+	j := i
+	for ; j < len(ranges) && ranges[j].Start <= end+1; j++ {
+		start = utils.MinPacketNumber(start, ranges[j].Start)
+		end = utils.MaxPacketNumber(end, ranges[j].End)
+	}
+	merged := utils.PacketInterval{Start: start, End: end}
+	if i == j {
+		ranges = append(ranges, utils.PacketInterval{})
+		copy(ranges[i+1:], ranges[i:])
+		ranges[i] = merged
+	} else {
+		ranges[i] = merged
+		ranges = append(ranges[:i+1], ranges[j:]...)
+	}
+	*r = ranges
+}
+
+// DeleteBelow deletes all ranges that only contain packet numbers smaller than pn.
+func (r *ackedPacketRanges) DeleteBelow(pn protocol.PacketNumber) {
+	ranges := *r
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].End >= pn })
+	*r = append(ranges[:0], ranges[i:]...)
+}
+
+// AckedBetween says if a packet number larger than lower and smaller than upper was acknowledged.
+func (r ackedPacketRanges) AckedBetween(lower, upper protocol.PacketNumber) bool {
+	i := sort.Search(len(r), func(i int) bool { return r[i].End > lower })
+	return i < len(r) && r[i].Start < upper
+}
@@ -0,0 +1,59 @@
+package ackhandler
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ackedPacketRanges", func() {
+	var ranges ackedPacketRanges
+
+	BeforeEach(func() {
+		ranges = nil
+	})
+
+	It("adds the ranges of an ACK frame", func() {
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 10, Largest: 12}, {Smallest: 3, Largest: 5}}})
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 3, End: 5}, {Start: 10, End: 12}}))
+	})
+
+	It("inserts ranges between existing ranges", func() {
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 20, Largest: 20}, {Smallest: 1, Largest: 1}}})
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 10, Largest: 11}}})
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 1, End: 1}, {Start: 10, End: 11}, {Start: 20, End: 20}}))
+	})
+
+	It("merges overlapping and adjacent ranges", func() {
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 10, Largest: 12}, {Smallest: 3, Largest: 5}}})
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 13, Largest: 13}}})
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 3, End: 5}, {Start: 10, End: 13}}))
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 9}}})
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 3, End: 13}}))
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 20}}})
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 1, End: 20}}))
+	})
+
+	It("deletes ranges below a packet number", func() {
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 10, Largest: 12}, {Smallest: 3, Largest: 5}}})
+		ranges.DeleteBelow(5)
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 3, End: 5}, {Start: 10, End: 12}}))
+		ranges.DeleteBelow(6)
+		Expect(ranges).To(Equal(ackedPacketRanges{{Start: 10, End: 12}}))
+		ranges.DeleteBelow(13)
+		Expect(ranges).To(BeEmpty())
+	})
+
+	It("says if a packet between two packet numbers was acknowledged", func() {
+		ranges.AddAckFrame(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 10, Largest: 12}, {Smallest: 3, Largest: 5}}})
+		Expect(ranges.AckedBetween(0, 3)).To(BeFalse())
+		Expect(ranges.AckedBetween(0, 4)).To(BeTrue())
+		Expect(ranges.AckedBetween(5, 10)).To(BeFalse())
+		Expect(ranges.AckedBetween(4, 10)).To(BeTrue())
+		Expect(ranges.AckedBetween(9, 11)).To(BeTrue())
+		Expect(ranges.AckedBetween(12, 20)).To(BeFalse())
+		Expect(ranges).To(ContainElement(utils.PacketInterval{Start: 10, End: 12}))
+	})
+})
@@ -1,6 +1,7 @@
 package ackhandler
 
 import (
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -11,11 +12,37 @@ func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
+	newCongestionControl func(congestion.Parameters) congestion.CongestionControl,
+	enableECN bool,
 	pers protocol.Perspective,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
 	version protocol.VersionNumber,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, pers, tracer, logger)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, newCongestionControl, enableECN, pers, tracer, logger)
 	return sph, newReceivedPacketHandler(sph, rttStats, logger, version)
 }
+
+// NewPathAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler for an additional path,
+// when using the multipath extension.
+// Paths are only added after the handshake was confirmed, so only the application data packet number space is used.
+func NewPathAckHandler(
+	initialMaxDatagramSize protocol.ByteCount,
+	rttStats *utils.RTTStats,
+	newCongestionControl func(congestion.Parameters) congestion.CongestionControl,
+	enableECN bool,
+	pers protocol.Perspective,
+	logger utils.Logger,
+	version protocol.VersionNumber,
+) (SentPacketHandler, ReceivedPacketHandler) {
+	sph := newSentPacketHandler(0, initialMaxDatagramSize, rttStats, newCongestionControl, enableECN, pers, nil, logger)
+	sph.initialPackets = nil
+	sph.handshakePackets = nil
+	sph.peerAddressValidated = true
+	sph.peerCompletedAddressValidation = true
+	sph.handshakeConfirmed = true
+	rph := newReceivedPacketHandler(sph, rttStats, logger, version)
+	rph.DropPackets(protocol.EncryptionInitial)
+	rph.DropPackets(protocol.EncryptionHandshake)
+	return sph, rph
+}
@@ -0,0 +1,31 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Ack Handler", func() {
+	It("only uses the application data packet number space", func() {
+		sph, rph := NewPathAckHandler(1200, utils.NewRTTStats(), nil, false, protocol.PerspectiveServer, utils.DefaultLogger, protocol.Version1)
+		Expect(sph.SendMode()).To(Equal(SendAny))
+		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+		sph.SentPacket(&Packet{
+			PacketNumber:    pn,
+			Length:          1000,
+			EncryptionLevel: protocol.Encryption1RTT,
+			Frames:          []Frame{{Frame: &wire.PingFrame{}, OnLost: func(wire.Frame) {}}},
+			LargestAcked:    protocol.InvalidPacketNumber,
+			SendTime:        time.Now(),
+		})
+		Expect(sph.GetLossDetectionTimeout()).ToNot(BeZero())
+		Expect(rph.ReceivedPacket(0, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		Expect(rph.GetAckFrame(protocol.Encryption1RTT, false)).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,140 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	internalcongestion "github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// congestionControl makes a congestion.CongestionControl usable by the sentPacketHandler.
+// Optional congestion events are only passed on if the congestion.CongestionControl implements the respective interface.
+type congestionControl struct {
+	c congestion.CongestionControl
+}
+
+var (
+	_ internalcongestion.SendAlgorithmWithDebugInfos         = &congestionControl{}
+	_ internalcongestion.SpuriousLossHandler                 = &congestionControl{}
+	_ internalcongestion.PersistentCongestionHandler         = &congestionControl{}
+	_ internalcongestion.PacketNumberSpaceAwareSendAlgorithm = &congestionControl{}
+	_ internalcongestion.ECNCongestionHandler                = &ecnCongestionControl{}
+)
+
+// newCongestionControl wraps a congestion.CongestionControl.
+// The result only implements internalcongestion.ECNCongestionHandler if c implements congestion.ECNCongestionHandler,
+// since ECN is only used if the congestion controller reacts to ECN-CE marks.
+func newCongestionControl(c congestion.CongestionControl) internalcongestion.SendAlgorithmWithDebugInfos {
+	if ecn, ok := c.(congestion.ECNCongestionHandler); ok {
+		return &ecnCongestionControl{congestionControl: congestionControl{c: c}, ecn: ecn}
+	}
+	return &congestionControl{c: c}
+}
+
+func (c *congestionControl) TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time {
+	return c.c.TimeUntilSend(congestion.ByteCount(bytesInFlight))
+}
+
+func (c *congestionControl) HasPacingBudget() bool {
+	return c.c.HasPacingBudget()
+}
+
+func (c *congestionControl) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) {
+	c.c.OnPacketSent(sentTime, congestion.ByteCount(bytesInFlight), congestion.PacketNumber(packetNumber), congestion.ByteCount(bytes), isRetransmittable)
+}
+
+func (c *congestionControl) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return c.c.CanSend(congestion.ByteCount(bytesInFlight))
+}
+
+func (c *congestionControl) MaybeExitSlowStart() {
+	c.c.MaybeExitSlowStart()
+}
+
+func (c *congestionControl) OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time) {
+	c.c.OnPacketAcked(congestion.PacketNumber(number), congestion.ByteCount(ackedBytes), congestion.ByteCount(priorInFlight), eventTime)
+}
+
+func (c *congestionControl) OnPacketLost(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount) {
+	c.c.OnPacketLost(congestion.PacketNumber(number), congestion.ByteCount(lostBytes), congestion.ByteCount(priorInFlight))
+}
+
+func (c *congestionControl) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	c.c.OnRetransmissionTimeout(packetsRetransmitted)
+}
+
+func (c *congestionControl) OnApplicationLimited(bytesInFlight protocol.ByteCount) {
+	c.c.OnApplicationLimited(congestion.ByteCount(bytesInFlight))
+}
+
+func (c *congestionControl) SetMaxDatagramSize(s protocol.ByteCount) {
+	c.c.SetMaxDatagramSize(congestion.ByteCount(s))
+}
+
+func (c *congestionControl) InSlowStart() bool {
+	return c.c.InSlowStart()
+}
+
+func (c *congestionControl) InRecovery() bool {
+	return c.c.InRecovery()
+}
+
+func (c *congestionControl) GetCongestionWindow() protocol.ByteCount {
+	return protocol.ByteCount(c.c.GetCongestionWindow())
+}
+
+func (c *congestionControl) OnSpuriousLoss(number protocol.PacketNumber, lostBytes protocol.ByteCount) {
+	if h, ok := c.c.(congestion.SpuriousLossHandler); ok {
+		h.OnSpuriousLoss(congestion.PacketNumber(number), congestion.ByteCount(lostBytes))
+	}
+}
+
+func (c *congestionControl) OnPersistentCongestion() {
+	if h, ok := c.c.(congestion.PersistentCongestionHandler); ok {
+		h.OnPersistentCongestion()
+	}
+}
+
+func (c *congestionControl) OnPacketSentAtLevel(encLevel protocol.EncryptionLevel, sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) {
+	h, ok := c.c.(congestion.PacketNumberSpaceHandler)
+	if !ok {
+		c.OnPacketSent(sentTime, bytesInFlight, packetNumber, bytes, isRetransmittable)
+		return
+	}
+	h.OnPacketSentAtLevel(congestion.EncryptionLevel(encLevel), sentTime, congestion.ByteCount(bytesInFlight), congestion.PacketNumber(packetNumber), congestion.ByteCount(bytes), isRetransmittable)
+}
+
+func (c *congestionControl) OnPacketAckedAtLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time) {
+	h, ok := c.c.(congestion.PacketNumberSpaceHandler)
+	if !ok {
+		c.OnPacketAcked(number, ackedBytes, priorInFlight, eventTime)
+		return
+	}
+	h.OnPacketAckedAtLevel(congestion.EncryptionLevel(encLevel), congestion.PacketNumber(number), congestion.ByteCount(ackedBytes), congestion.ByteCount(priorInFlight), eventTime)
+}
+
+func (c *congestionControl) OnPacketLostAtLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount) {
+	h, ok := c.c.(congestion.PacketNumberSpaceHandler)
+	if !ok {
+		c.OnPacketLost(number, lostBytes, priorInFlight)
+		return
+	}
+	h.OnPacketLostAtLevel(congestion.EncryptionLevel(encLevel), congestion.PacketNumber(number), congestion.ByteCount(lostBytes), congestion.ByteCount(priorInFlight))
+}
+
+func (c *congestionControl) OnPacketsDropped(encLevel protocol.EncryptionLevel) {
+	if h, ok := c.c.(congestion.PacketNumberSpaceHandler); ok {
+		h.OnPacketsDropped(congestion.EncryptionLevel(encLevel))
+	}
+}
+
+// ecnCongestionControl wraps a congestion.CongestionControl that reacts to ECN-CE marks.
+type ecnCongestionControl struct {
+	congestionControl
+	ecn congestion.ECNCongestionHandler
+}
+
+func (c *ecnCongestionControl) OnECNCongestion(largestAcked protocol.PacketNumber, priorInFlight protocol.ByteCount) {
+	c.ecn.OnECNCongestion(congestion.PacketNumber(largestAcked), congestion.ByteCount(priorInFlight))
+}
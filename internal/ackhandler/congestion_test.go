@@ -0,0 +1,106 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	internalcongestion "github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/mocks"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type packetNumberSpaceCongestionControl struct {
+	*mocks.MockCongestionControl
+
+	sent, acked, lost, dropped []congestion.EncryptionLevel
+}
+
+var _ congestion.PacketNumberSpaceHandler = &packetNumberSpaceCongestionControl{}
+
+func (c *packetNumberSpaceCongestionControl) OnPacketSentAtLevel(encLevel congestion.EncryptionLevel, _ time.Time, _ congestion.ByteCount, _ congestion.PacketNumber, _ congestion.ByteCount, _ bool) {
+	c.sent = append(c.sent, encLevel)
+}
+
+func (c *packetNumberSpaceCongestionControl) OnPacketAckedAtLevel(encLevel congestion.EncryptionLevel, _ congestion.PacketNumber, _, _ congestion.ByteCount, _ time.Time) {
+	c.acked = append(c.acked, encLevel)
+}
+
+func (c *packetNumberSpaceCongestionControl) OnPacketLostAtLevel(encLevel congestion.EncryptionLevel, _ congestion.PacketNumber, _, _ congestion.ByteCount) {
+	c.lost = append(c.lost, encLevel)
+}
+
+func (c *packetNumberSpaceCongestionControl) OnPacketsDropped(encLevel congestion.EncryptionLevel) {
+	c.dropped = append(c.dropped, encLevel)
+}
+
+var _ = Describe("Congestion Control", func() {
+	It("converts the types", func() {
+		cong := mocks.NewMockCongestionControl(mockCtrl)
+		c := newCongestionControl(cong)
+		now := time.Now()
+		cong.EXPECT().OnPacketSent(now, congestion.ByteCount(1000), congestion.PacketNumber(42), congestion.ByteCount(100), true)
+		c.OnPacketSent(now, 1000, 42, 100, true)
+		cong.EXPECT().OnPacketAcked(congestion.PacketNumber(42), congestion.ByteCount(100), congestion.ByteCount(1000), now)
+		c.OnPacketAcked(42, 100, 1000, now)
+		cong.EXPECT().GetCongestionWindow().Return(congestion.ByteCount(1337))
+		Expect(c.GetCongestionWindow()).To(Equal(protocol.ByteCount(1337)))
+	})
+
+	It("only passes on the optional congestion events if the congestion controller handles them", func() {
+		cong := mocks.NewMockCongestionControl(mockCtrl)
+		c := newCongestionControl(cong)
+		_, ok := c.(internalcongestion.ECNCongestionHandler)
+		Expect(ok).To(BeFalse())
+		c.(internalcongestion.SpuriousLossHandler).OnSpuriousLoss(1, 100)
+		c.(internalcongestion.PersistentCongestionHandler).OnPersistentCongestion()
+		c.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm).OnPacketsDropped(protocol.EncryptionInitial)
+	})
+
+	It("falls back to the events without encryption level", func() {
+		cong := mocks.NewMockCongestionControl(mockCtrl)
+		c := newCongestionControl(cong).(internalcongestion.PacketNumberSpaceAwareSendAlgorithm)
+		now := time.Now()
+		cong.EXPECT().OnPacketSent(now, congestion.ByteCount(1000), congestion.PacketNumber(42), congestion.ByteCount(100), true)
+		c.OnPacketSentAtLevel(protocol.EncryptionHandshake, now, 1000, 42, 100, true)
+		cong.EXPECT().OnPacketAcked(congestion.PacketNumber(42), congestion.ByteCount(100), congestion.ByteCount(1000), now)
+		c.OnPacketAckedAtLevel(protocol.EncryptionHandshake, 42, 100, 1000, now)
+		cong.EXPECT().OnPacketLost(congestion.PacketNumber(43), congestion.ByteCount(100), congestion.ByteCount(1000))
+		c.OnPacketLostAtLevel(protocol.EncryptionHandshake, 43, 100, 1000)
+	})
+
+	It("passes the encryption level to congestion controllers that handle packet number spaces", func() {
+		cong := &packetNumberSpaceCongestionControl{MockCongestionControl: mocks.NewMockCongestionControl(mockCtrl)}
+		handler := newSentPacketHandler(
+			0,
+			protocol.InitialPacketSizeIPv4,
+			utils.NewRTTStats(),
+			func(congestion.Parameters) congestion.CongestionControl { return cong },
+			false,
+			protocol.PerspectiveClient,
+			nil,
+			utils.DefaultLogger,
+		)
+		for _, encLevel := range []protocol.EncryptionLevel{protocol.EncryptionInitial, protocol.EncryptionHandshake} {
+			handler.SentPacket(&Packet{
+				PacketNumber:    1,
+				EncryptionLevel: encLevel,
+				Length:          100,
+				SendTime:        time.Now(),
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+			})
+		}
+		Expect(cong.sent).To(Equal([]congestion.EncryptionLevel{congestion.EncryptionInitial, congestion.EncryptionHandshake}))
+		cong.EXPECT().MaybeExitSlowStart()
+		cong.EXPECT().GetCongestionWindow().AnyTimes()
+		_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.EncryptionHandshake, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cong.acked).To(Equal([]congestion.EncryptionLevel{congestion.EncryptionHandshake}))
+		handler.DropPackets(protocol.EncryptionInitial)
+		Expect(cong.dropped).To(Equal([]congestion.EncryptionLevel{congestion.EncryptionInitial}))
+	})
+})
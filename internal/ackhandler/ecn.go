@@ -0,0 +1,177 @@
+package ackhandler
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+type ecnState uint8
+
+const (
+	ecnStateInitial ecnState = iota
+	ecnStateTesting
+	ecnStateUnknown
+	ecnStateCapable
+	ecnStateFailed
+)
+
+// The number of packets sent with ECT(0) during the testing period, see RFC 9000, Section A.4.
+const numECNTestingPackets = 10
+
+// The ecnTracker validates that the path and the peer support ECN (RFC 9000, Section 13.4.2).
+// During the testing period, packets are marked with ECT(0).
+// Once the peer reports the ECN counts for these packets correctly, the path is ECN-capable.
+// If validation fails, packets are sent without ECN markings.
+type ecnTracker struct {
+	state ecnState
+
+	numSentTesting, numLostTesting uint8
+	firstTestingPacket             protocol.PacketNumber
+	lastTestingPacket              protocol.PacketNumber
+
+	numSentECT0 int64
+	// the ECN counts reported in the last ACK frame that was processed
+	numAckedECT0, numAckedECT1, numAckedECNCE int64
+
+	// set if the connection tracer implements the logging.ECNTracer interface
+	tracer logging.ECNTracer
+	logger utils.Logger
+}
+
+func newECNTracker(tracer logging.ConnectionTracer, logger utils.Logger) *ecnTracker {
+	e := &ecnTracker{
+		firstTestingPacket: protocol.InvalidPacketNumber,
+		lastTestingPacket:  protocol.InvalidPacketNumber,
+		logger:             logger,
+	}
+	if t, ok := tracer.(logging.ECNTracer); ok {
+		e.tracer = t
+	}
+	return e
+}
+
+// SentPacket is called for every 1-RTT packet that is sent.
+// It returns the ECN codepoint that the packet is marked with.
+func (e *ecnTracker) SentPacket(pn protocol.PacketNumber) protocol.ECN {
+	//nolint:exhaustive // These are the only states in which packets are marked.
+	switch e.state {
+	case ecnStateInitial:
+		e.setState(ecnStateTesting, logging.ECNTriggerNoTrigger)
+		fallthrough
+	case ecnStateTesting:
+		if e.numSentTesting == 0 {
+			e.firstTestingPacket = pn
+		}
+		e.lastTestingPacket = pn
+		e.numSentTesting++
+		if e.numSentTesting >= numECNTestingPackets {
+			e.setState(ecnStateUnknown, logging.ECNTriggerNoTrigger)
+		}
+	case ecnStateCapable:
+	default:
+		return protocol.ECNNon
+	}
+	e.numSentECT0++
+	return protocol.ECT0
+}
+
+// Mode returns the ECN codepoint that the next packet will be marked with.
+func (e *ecnTracker) Mode() protocol.ECN {
+	//nolint:exhaustive // These are the only states in which packets are marked.
+	switch e.state {
+	case ecnStateInitial, ecnStateTesting, ecnStateCapable:
+		return protocol.ECT0
+	default:
+		return protocol.ECNNon
+	}
+}
+
+// LostPacket is called when a packet that was sent with an ECN marking is declared lost.
+// If all packets sent during the testing period are lost, ECN validation fails,
+// since the ECN markings might have caused the packets to be dropped.
+func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
+	if e.state != ecnStateTesting && e.state != ecnStateUnknown {
+		return
+	}
+	if pn < e.firstTestingPacket || pn > e.lastTestingPacket {
+		return
+	}
+	e.numLostTesting++
+	if e.state == ecnStateUnknown && e.numLostTesting >= e.numSentTesting {
+		e.failValidation(logging.ECNFailedLostAllTestingPackets)
+	}
+}
+
+// HandleNewlyAcked is called with the packets newly acknowledged by an ACK frame,
+// and the ECN counts contained in that ACK frame.
+// It must only be called for ACK frames that increase the largest acknowledged packet number.
+// It returns true if the peer reported new ECN-CE marks, and the congestion controller should react.
+func (e *ecnTracker) HandleNewlyAcked(packets []*Packet, ect0, ect1, ecnce int64) (congested bool) {
+	if e.state == ecnStateInitial || e.state == ecnStateFailed {
+		return false
+	}
+	var ackedECT0 int64
+	for _, p := range packets {
+		if p.ECN == protocol.ECT0 {
+			ackedECT0++
+		}
+	}
+	if ackedECT0 > 0 && ect0 == 0 && ect1 == 0 && ecnce == 0 {
+		e.failValidation(logging.ECNFailedNoECNCounts)
+		return false
+	}
+	if ect0 < e.numAckedECT0 || ect1 < e.numAckedECT1 || ecnce < e.numAckedECNCE {
+		e.failValidation(logging.ECNFailedDecreasedECNCounts)
+		return false
+	}
+	// We never send packets marked ECT(1).
+	if ect1 > 0 {
+		e.failValidation(logging.ECNFailedManglingDetected)
+		return false
+	}
+	if ect0+ecnce > e.numSentECT0 {
+		e.failValidation(logging.ECNFailedMoreECNCountsThanSent)
+		return false
+	}
+	newECT0 := ect0 - e.numAckedECT0
+	newECNCE := ecnce - e.numAckedECNCE
+	e.numAckedECT0 = ect0
+	e.numAckedECT1 = ect1
+	e.numAckedECNCE = ecnce
+	// Every newly acknowledged packet that was sent with ECT(0) must be reported as either ECT(0) or ECN-CE.
+	// Otherwise, the ECN markings were removed on the path.
+	if newECT0+newECNCE < ackedECT0 {
+		e.failValidation(logging.ECNFailedTooFewECNCounts)
+		return false
+	}
+	if ackedECT0 > 0 && (e.state == ecnStateTesting || e.state == ecnStateUnknown) {
+		e.setState(ecnStateCapable, logging.ECNTriggerNoTrigger)
+	}
+	return newECNCE > 0
+}
+
+func (e *ecnTracker) failValidation(trigger logging.ECNStateTrigger) {
+	if e.logger.Debug() {
+		e.logger.Debugf("ECN validation failed (trigger: %d). Disabling ECN.", trigger)
+	}
+	e.setState(ecnStateFailed, trigger)
+}
+
+func (e *ecnTracker) setState(state ecnState, trigger logging.ECNStateTrigger) {
+	e.state = state
+	if e.tracer == nil {
+		return
+	}
+	//nolint:exhaustive // The initial state is never traced.
+	switch state {
+	case ecnStateTesting:
+		e.tracer.ECNStateUpdated(logging.ECNStateTesting, trigger)
+	case ecnStateUnknown:
+		e.tracer.ECNStateUpdated(logging.ECNStateUnknown, trigger)
+	case ecnStateCapable:
+		e.tracer.ECNStateUpdated(logging.ECNStateCapable, trigger)
+	case ecnStateFailed:
+		e.tracer.ECNStateUpdated(logging.ECNStateFailed, trigger)
+	}
+}
@@ -0,0 +1,160 @@
+package ackhandler
+
+import (
+	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECN tracker", func() {
+	var (
+		ecnTracker *ecnTracker
+		tracer     *mocklogging.MockECNTracer
+	)
+
+	getPackets := func(ecn protocol.ECN, pns ...protocol.PacketNumber) []*Packet {
+		packets := make([]*Packet, 0, len(pns))
+		for _, pn := range pns {
+			packets = append(packets, &Packet{PacketNumber: pn, ECN: ecn})
+		}
+		return packets
+	}
+
+	// sendTestingPackets sends the packets 0 to 9, and checks that they're all marked ECT(0).
+	sendTestingPackets := func() {
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
+		for i := 0; i < numECNTestingPackets-1; i++ {
+			Expect(ecnTracker.SentPacket(protocol.PacketNumber(i))).To(Equal(protocol.ECT0))
+		}
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.SentPacket(numECNTestingPackets - 1)).To(Equal(protocol.ECT0))
+	}
+
+	BeforeEach(func() {
+		tracer = mocklogging.NewMockECNTracer(mockCtrl)
+		ecnTracker = newECNTracker(struct {
+			logging.ConnectionTracer
+			logging.ECNTracer
+		}{mocklogging.NewMockConnectionTracer(mockCtrl), tracer}, utils.DefaultLogger)
+	})
+
+	It("sends a limited number of testing packets, and then waits for their acknowledgement", func() {
+		sendTestingPackets()
+		for i := numECNTestingPackets; i < 20; i++ {
+			Expect(ecnTracker.SentPacket(protocol.PacketNumber(i))).To(Equal(protocol.ECNNon))
+		}
+	})
+
+	It("fails validation if all testing packets are lost", func() {
+		sendTestingPackets()
+		for i := 0; i < numECNTestingPackets-1; i++ {
+			ecnTracker.LostPacket(protocol.PacketNumber(i))
+		}
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedLostAllTestingPackets)
+		ecnTracker.LostPacket(numECNTestingPackets - 1)
+		Expect(ecnTracker.SentPacket(20)).To(Equal(protocol.ECNNon))
+	})
+
+	It("only counts the loss of testing packets", func() {
+		sendTestingPackets()
+		for i := 0; i < numECNTestingPackets-1; i++ {
+			ecnTracker.LostPacket(protocol.PacketNumber(i))
+		}
+		ecnTracker.LostPacket(1337)
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 9), 1, 0, 0)).To(BeFalse())
+	})
+
+	It("becomes capable when testing packets are acknowledged with the correct ECN counts", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 3, 0, 0)).To(BeFalse())
+		// once capable, all packets are marked
+		for i := numECNTestingPackets; i < 20; i++ {
+			Expect(ecnTracker.SentPacket(protocol.PacketNumber(i))).To(Equal(protocol.ECT0))
+		}
+	})
+
+	It("becomes capable when the first testing packets are acknowledged, before the testing period ends", func() {
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.SentPacket(0)).To(Equal(protocol.ECT0))
+		Expect(ecnTracker.SentPacket(1)).To(Equal(protocol.ECT0))
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1), 1, 0, 1)).To(BeTrue())
+		for i := 2; i < 20; i++ {
+			Expect(ecnTracker.SentPacket(protocol.PacketNumber(i))).To(Equal(protocol.ECT0))
+		}
+	})
+
+	It("detects CE marks", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1), 2, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 2, 3), 3, 0, 1)).To(BeTrue())
+		// the CE count didn't increase
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 4), 4, 0, 1)).To(BeFalse())
+	})
+
+	It("doesn't use ECN if the connection doesn't send any 1-RTT packets", func() {
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECNNon, 0, 1), 0, 0, 0)).To(BeFalse())
+	})
+
+	It("fails validation if the ACK doesn't contain ECN counts", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedNoECNCounts)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 0, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.SentPacket(20)).To(Equal(protocol.ECNNon))
+	})
+
+	It("fails validation if the ECN counts decrease", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 2, 0, 1)).To(BeTrue())
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedDecreasedECNCounts)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 3), 4, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.SentPacket(20)).To(Equal(protocol.ECNNon))
+	})
+
+	It("fails validation if the peer reports ECT(1) marks", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedManglingDetected)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 2, 1, 0)).To(BeFalse())
+	})
+
+	It("fails validation if the peer reports more ECN-marked packets than were sent", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedMoreECNCountsThanSent)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 10, 0, 1)).To(BeFalse())
+	})
+
+	It("fails validation if the ECN markings are removed on the path", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedTooFewECNCounts)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 2, 0, 0)).To(BeFalse())
+	})
+
+	It("ignores CE marks after validation failed", func() {
+		sendTestingPackets()
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedTooFewECNCounts)
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0, 1, 2), 1, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 3, 4), 1, 0, 2)).To(BeFalse())
+	})
+
+	It("works without a tracer", func() {
+		ecnTracker = newECNTracker(nil, utils.DefaultLogger)
+		Expect(ecnTracker.SentPacket(0)).To(Equal(protocol.ECT0))
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0), 0, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.SentPacket(1)).To(Equal(protocol.ECNNon))
+	})
+
+	It("works with a tracer that doesn't trace ECN state changes", func() {
+		ecnTracker = newECNTracker(mocklogging.NewMockConnectionTracer(mockCtrl), utils.DefaultLogger)
+		Expect(ecnTracker.SentPacket(0)).To(Equal(protocol.ECT0))
+		Expect(ecnTracker.HandleNewlyAcked(getPackets(protocol.ECT0, 0), 0, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.SentPacket(1)).To(Equal(protocol.ECNNon))
+	})
+})
@@ -17,12 +17,32 @@ type Packet struct {
 	SendTime        time.Time
 
 	IsPathMTUProbePacket bool // We don't report the loss of Path MTU probe packets to the congestion controller.
+	// Path probe packets are sent on a path other than the current one.
+	// They are not congestion controlled, not ECN-marked, and they are not declared lost.
+	IsPathProbePacket bool
+	// The ECN codepoint the packet is sent with. It is set by SentPacket.
+	ECN protocol.ECN
 
 	includedInBytesInFlight bool
 	declaredLost            bool
+	lostByLossDetection     bool // declaredLost is also set when the frames are retransmitted in a probe packet
 	skippedPacket           bool
 }
 
+// Stats contains statistics about the packets sent and received.
+type Stats struct {
+	PacketsSent   uint64
+	PacketsLost   uint64
+	BytesSent     protocol.ByteCount
+	BytesReceived protocol.ByteCount
+	// BytesRetransmitted is the size of the packets whose frames were queued for retransmission.
+	BytesRetransmitted protocol.ByteCount
+	BytesInFlight      protocol.ByteCount
+	CongestionWindow   protocol.ByteCount
+	// PTOCount is the number of probe timeouts since the last acknowledgement.
+	PTOCount uint32
+}
+
 // SentPacketHandler handles ACKs received for outgoing packets
 type SentPacketHandler interface {
 	// SentPacket may modify the packet
@@ -40,16 +60,28 @@ type SentPacketHandler interface {
 	TimeUntilSend() time.Time
 	// HasPacingBudget says if the pacer allows sending of a (full size) packet at this moment.
 	HasPacingBudget() bool
+	// OnApplicationLimited is called when the send mode allowed sending a packet, but there was no data to send.
+	OnApplicationLimited()
+	// ECNMode is the ECN codepoint that the next 1-RTT packet will be marked with.
+	ECNMode() protocol.ECN
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// MigratedPath resets the congestion controller and the RTT estimate after a connection migration.
+	MigratedPath(initialMaxDatagramSize protocol.ByteCount)
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
+	// QueueAllForRetransmission declares all outstanding application data packets lost,
+	// and queues their frames for retransmission.
+	// It is used with multipath, when a path is abandoned or experiences a probe timeout.
+	QueueAllForRetransmission()
 
 	PeekPacketNumber(protocol.EncryptionLevel) (protocol.PacketNumber, protocol.PacketNumberLen)
 	PopPacketNumber(protocol.EncryptionLevel) protocol.PacketNumber
 
 	GetLossDetectionTimeout() time.Time
 	OnLossDetectionTimeout() error
+
+	GetStats() Stats
 }
 
 type sentPacketTracker interface {
@@ -62,6 +94,8 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, shouldInstigateAck bool) error
 	DropPackets(protocol.EncryptionLevel)
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame) error
+	ReceivedImmediateAckFrame()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
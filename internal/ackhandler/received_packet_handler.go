@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/qerr"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
@@ -17,6 +18,10 @@ type receivedPacketHandler struct {
 	appDataPackets   *receivedPacketTracker
 
 	lowest1RTTPacket protocol.PacketNumber
+
+	// the sequence number of the last ACK_FREQUENCY frame that was applied
+	receivedAckFrequencyFrame bool
+	ackFrequencySeqNum        uint64
 }
 
 var _ ReceivedPacketHandler = &receivedPacketHandler{}
@@ -66,6 +71,27 @@ func (h *receivedPacketHandler) ReceivedPacket(
 	return nil
 }
 
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max ack delay (%s) smaller than min_ack_delay (%s)", f.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	// ACK_FREQUENCY frames can be reordered. Only apply the most recent one.
+	if h.receivedAckFrequencyFrame && f.SequenceNumber <= h.ackFrequencySeqNum {
+		return nil
+	}
+	h.receivedAckFrequencyFrame = true
+	h.ackFrequencySeqNum = f.SequenceNumber
+	h.appDataPackets.SetAckFrequency(f.AckElicitingThreshold, f.RequestMaxAckDelay, f.ReorderingThreshold)
+	return nil
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAckFrame() {
+	h.appDataPackets.QueueImmediateAck()
+}
+
 func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	//nolint:exhaustive // 1-RTT packet number space is never dropped.
 	switch encLevel {
@@ -6,6 +6,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/qerr"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 
@@ -165,4 +166,63 @@ var _ = Describe("Received Packet Handler", func() {
 		Expect(handler.ReceivedPacket(4, protocol.ECNNon, protocol.Encryption1RTT, sendTime, true)).To(Succeed())
 		Expect(handler.IsPotentiallyDuplicate(4, protocol.Encryption1RTT)).To(BeTrue())
 	})
+	Context("ACK frequency", func() {
+		BeforeEach(func() {
+			sentPackets.EXPECT().ReceivedPacket(gomock.Any()).AnyTimes()
+			sentPackets.EXPECT().GetLowestPacketNotConfirmedAcked().AnyTimes()
+			// receive and acknowledge the first packet
+			Expect(handler.ReceivedPacket(1, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+			Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).ToNot(BeNil())
+		})
+
+		It("applies ACK_FREQUENCY frames", func() {
+			Expect(handler.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+				SequenceNumber:        1,
+				AckElicitingThreshold: 3,
+				RequestMaxAckDelay:    10 * time.Millisecond,
+				ReorderingThreshold:   1,
+			})).To(Succeed())
+			rcvTime := time.Now()
+			for pn := protocol.PacketNumber(2); pn <= 4; pn++ {
+				Expect(handler.ReceivedPacket(pn, protocol.ECNNon, protocol.Encryption1RTT, rcvTime, true)).To(Succeed())
+				Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).To(BeNil())
+			}
+			Expect(handler.GetAlarmTimeout()).To(Equal(rcvTime.Add(10 * time.Millisecond)))
+			Expect(handler.ReceivedPacket(5, protocol.ECNNon, protocol.Encryption1RTT, rcvTime, true)).To(Succeed())
+			Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).ToNot(BeNil())
+		})
+
+		It("ignores reordered ACK_FREQUENCY frames", func() {
+			Expect(handler.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+				SequenceNumber:        2,
+				AckElicitingThreshold: 3,
+				RequestMaxAckDelay:    10 * time.Millisecond,
+				ReorderingThreshold:   1,
+			})).To(Succeed())
+			Expect(handler.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+				SequenceNumber:        1,
+				AckElicitingThreshold: 0,
+				RequestMaxAckDelay:    10 * time.Millisecond,
+				ReorderingThreshold:   1,
+			})).To(Succeed())
+			Expect(handler.ReceivedPacket(2, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+			Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).To(BeNil())
+		})
+
+		It("errors if the requested max ack delay is smaller than the min_ack_delay", func() {
+			Expect(handler.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+				SequenceNumber:     1,
+				RequestMaxAckDelay: protocol.MinAckDelay - time.Microsecond,
+			})).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: "requested max ack delay (999µs) smaller than min_ack_delay (1ms)",
+			}))
+		})
+
+		It("sends an ACK immediately when receiving an IMMEDIATE_ACK frame", func() {
+			handler.ReceivedImmediateAckFrame()
+			Expect(handler.ReceivedPacket(2, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+			Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).ToNot(BeNil())
+		})
+	})
 })
@@ -8,8 +8,13 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
-// number of ack-eliciting packets received before sending an ack.
-const packetsBeforeAck = 2
+// The default number of ack-eliciting packets that can be received without sending an ACK.
+// This value can be changed by the peer using an ACK_FREQUENCY frame.
+const defaultAckElicitingThreshold = 1
+
+// The default reordering threshold.
+// This value can be changed by the peer using an ACK_FREQUENCY frame.
+const defaultReorderingThreshold = 1
 
 type receivedPacketTracker struct {
 	largestObserved             protocol.PacketNumber
@@ -19,8 +24,11 @@ type receivedPacketTracker struct {
 
 	packetHistory *receivedPacketHistory
 
-	maxAckDelay time.Duration
-	rttStats    *utils.RTTStats
+	maxAckDelay           time.Duration
+	ackElicitingThreshold uint64
+	// If 0, out-of-order packets don't cause an ACK to be sent immediately.
+	reorderingThreshold protocol.PacketNumber
+	rttStats            *utils.RTTStats
 
 	hasNewAck bool // true as soon as we received an ack-eliciting new packet
 	ackQueued bool // true once we received more than 2 (or later in the connection 10) ack-eliciting packets
@@ -40,11 +48,13 @@ func newReceivedPacketTracker(
 	version protocol.VersionNumber,
 ) *receivedPacketTracker {
 	return &receivedPacketTracker{
-		packetHistory: newReceivedPacketHistory(),
-		maxAckDelay:   protocol.MaxAckDelay,
-		rttStats:      rttStats,
-		logger:        logger,
-		version:       version,
+		packetHistory:         newReceivedPacketHistory(),
+		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		reorderingThreshold:   defaultReorderingThreshold,
+		rttStats:              rttStats,
+		logger:                logger,
+		version:               version,
 	}
 }
 
@@ -89,6 +99,24 @@ func (h *receivedPacketTracker) IgnoreBelow(p protocol.PacketNumber) {
 	}
 }
 
+// SetAckFrequency applies the values requested by the peer in an ACK_FREQUENCY frame.
+func (h *receivedPacketTracker) SetAckFrequency(ackElicitingThreshold uint64, maxAckDelay time.Duration, reorderingThreshold uint64) {
+	h.ackElicitingThreshold = ackElicitingThreshold
+	h.maxAckDelay = maxAckDelay
+	h.reorderingThreshold = protocol.PacketNumber(reorderingThreshold)
+	if h.logger.Debug() {
+		h.logger.Debugf("\tSetting ACK frequency: threshold %d, max ack delay %s, reordering threshold %d", ackElicitingThreshold, maxAckDelay, reorderingThreshold)
+	}
+}
+
+// QueueImmediateAck makes sure that an ACK is sent without delay.
+// It is used when the peer sends an IMMEDIATE_ACK frame.
+func (h *receivedPacketTracker) QueueImmediateAck() {
+	h.logger.Debugf("\tQueueing ACK because of an IMMEDIATE_ACK frame.")
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // isMissing says if a packet was reported missing in the last ACK.
 func (h *receivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	if h.lastAck == nil || p < h.ignoreBelow {
@@ -98,11 +126,11 @@ func (h *receivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 }
 
 func (h *receivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
 	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == h.reorderingThreshold
 }
 
 // maybeQueueAck queues an ACK, if necessary.
@@ -125,17 +153,17 @@ func (h *receivedPacketTracker) maybeQueueAck(pn protocol.PacketNumber, rcvTime
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ack, send an ACK immediately.
-	if wasMissing {
+	if wasMissing && h.reorderingThreshold > 0 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		h.ackQueued = true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once more than ackElicitingThreshold ack-eliciting packets were received
+	if uint64(h.ackElicitingPacketsReceivedSinceLastAck) > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		h.ackQueued = true
 	} else if h.ackAlarm.IsZero() {
@@ -189,6 +189,58 @@ var _ = Describe("Received Packet Tracker", func() {
 				tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
+
+			Context("ACK frequency", func() {
+				It("queues an ACK after the ack-eliciting threshold is exceeded", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(3, 50*time.Millisecond, 1)
+					rcvTime := time.Now()
+					for i := 11; i <= 13; i++ {
+						tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, rcvTime, true)
+						Expect(tracker.ackQueued).To(BeFalse())
+					}
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(50 * time.Millisecond)))
+					tracker.ReceivedPacket(14, protocol.ECNNon, rcvTime, true)
+					Expect(tracker.ackQueued).To(BeTrue())
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+				})
+
+				It("queues an ACK for every ack-eliciting packet, if the threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(0, protocol.MaxAckDelay, 1)
+					tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("doesn't queue an ACK for out-of-order packets, if the reordering threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(10, protocol.MaxAckDelay, 0)
+					tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeFalse())
+					tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeFalse())
+				})
+
+				It("queues an ACK once the reordering threshold is reached", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(10, protocol.MaxAckDelay, 2)
+					// 11 is missing
+					tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeFalse())
+					tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("queues an immediate ACK", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAlarmTimeout()).ToNot(BeZero())
+					tracker.QueueImmediateAck()
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+					Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
@@ -3,9 +3,11 @@ package ackhandler
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
-	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/congestion"
+	internalcongestion "github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
 	"github.com/lucas-clemente/quic-go/internal/utils"
@@ -16,13 +18,17 @@ import (
 const (
 	// Maximum reordering in time space before time based loss detection considers a packet lost.
 	// Specified as an RTT multiplier.
-	timeThreshold = 9.0 / 8
+	initialTimeThreshold = 9.0 / 8
+	// When spurious losses are detected, the time threshold is increased up to this value.
+	maxTimeThreshold = 2
 	// Maximum reordering in packets before packet threshold loss detection considers a packet lost.
-	packetThreshold = 3
-	// Before validating the client's address, the server won't send more than 3x bytes than it received.
-	amplificationFactor = 3
+	initialPacketThreshold = 3
+	// When spurious losses are detected, the packet threshold is increased up to this value.
+	maxPacketThreshold = 64
 	// We use Retry packets to derive an RTT estimate. Make sure we don't set the RTT to a super low value yet.
 	minRTTAfterRetry = 5 * time.Millisecond
+	// Persistent congestion is established if all packets sent during this many PTOs are lost (RFC 9002, Section 7.6).
+	persistentCongestionThreshold = 3
 )
 
 type packetNumberSpace struct {
@@ -34,6 +40,9 @@ type packetNumberSpace struct {
 
 	largestAcked protocol.PacketNumber
 	largestSent  protocol.PacketNumber
+
+	// the packet numbers acknowledged by the peer, used for persistent congestion detection
+	ackedRanges ackedPacketRanges
 }
 
 func newPacketNumberSpace(initialPN protocol.PacketNumber, skipPNs bool, rttStats *utils.RTTStats) *packetNumberSpace {
@@ -77,8 +86,28 @@ type sentPacketHandler struct {
 
 	bytesInFlight protocol.ByteCount
 
-	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	// statistics, see GetStats
+	packetsSent        uint64
+	packetsLost        uint64
+	bytesRetransmitted protocol.ByteCount
+
+	congestion internalcongestion.SendAlgorithmWithDebugInfos
+	// newCongestionControl creates the congestion controller.
+	// It is called again when the connection migrates to a new path.
+	newCongestionControl func(congestion.Parameters) congestion.CongestionControl
+	rttStats             *utils.RTTStats
+	// The time when the first RTT sample was taken.
+	// Packets sent before this time are not considered for persistent congestion detection.
+	firstRTTSampleTime time.Time
+
+	// The reordering thresholds used for loss detection.
+	// They are increased when packets that were declared lost are acknowledged later.
+	timeThreshold   float64
+	packetThreshold protocol.PacketNumber
+
+	enableECN bool
+	// nil if ECN is disabled, or if the congestion controller doesn't react to ECN-CE marks
+	ecnTracker *ecnTracker
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -105,30 +134,55 @@ func newSentPacketHandler(
 	initialPN protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
+	newCongestionControl func(congestion.Parameters) congestion.CongestionControl, // if nil, NewReno is used
+	enableECN bool,
 	pers protocol.Perspective,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
-
-	return &sentPacketHandler{
+	if newCongestionControl == nil {
+		newCongestionControl = congestion.NewReno
+	}
+	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
 		peerAddressValidated:           pers == protocol.PerspectiveClient,
 		initialPackets:                 newPacketNumberSpace(initialPN, false, rttStats),
 		handshakePackets:               newPacketNumberSpace(0, false, rttStats),
 		appDataPackets:                 newPacketNumberSpace(0, true, rttStats),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		newCongestionControl:           newCongestionControl,
+		timeThreshold:                  initialTimeThreshold,
+		packetThreshold:                initialPacketThreshold,
+		enableECN:                      enableECN,
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
+	h.congestion = h.createCongestionControl(initialMaxDatagramSize)
+	h.ecnTracker = h.createECNTracker()
+	return h
+}
+
+func (h *sentPacketHandler) createCongestionControl(initialMaxDatagramSize protocol.ByteCount) internalcongestion.SendAlgorithmWithDebugInfos {
+	return newCongestionControl(h.newCongestionControl(congestion.Parameters{
+		Perspective:            congestion.Perspective(h.perspective),
+		RTTStats:               h.rttStats,
+		InitialMaxDatagramSize: congestion.ByteCount(initialMaxDatagramSize),
+		Tracer:                 h.tracer,
+	}))
+}
+
+// createECNTracker creates the ecnTracker for the current congestion controller.
+// Marking packets as ECN-capable only makes sense if the congestion controller reduces its sending rate
+// when the peer reports ECN-CE marks, so ECN is disabled for congestion controllers that don't.
+func (h *sentPacketHandler) createECNTracker() *ecnTracker {
+	if !h.enableECN {
+		return nil
+	}
+	if _, ok := h.congestion.(internalcongestion.ECNCongestionHandler); !ok {
+		return nil
+	}
+	return newECNTracker(h.tracer, h.logger)
 }
 
 func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
@@ -188,6 +242,9 @@ func (h *sentPacketHandler) dropPackets(encLevel protocol.EncryptionLevel) {
 	default:
 		panic(fmt.Sprintf("Cannot drop keys for encryption level %s", encLevel))
 	}
+	if c, ok := h.congestion.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+		c.OnPacketsDropped(encLevel)
+	}
 	if h.tracer != nil && h.ptoCount != 0 {
 		h.tracer.UpdatedPTOCount(0)
 	}
@@ -225,10 +282,15 @@ func (h *sentPacketHandler) packetsInFlight() int {
 
 func (h *sentPacketHandler) SentPacket(packet *Packet) {
 	h.bytesSent += packet.Length
+	h.packetsSent++
 	// For the client, drop the Initial packet number space when the first Handshake packet is sent.
 	if h.perspective == protocol.PerspectiveClient && packet.EncryptionLevel == protocol.EncryptionHandshake && h.initialPackets != nil {
 		h.dropPackets(protocol.EncryptionInitial)
 	}
+	if packet.IsPathProbePacket {
+		h.sentPathProbePacket(packet)
+		return
+	}
 	isAckEliciting := h.sentPacketImpl(packet)
 	h.getPacketNumberSpace(packet.EncryptionLevel).history.SentPacket(packet, isAckEliciting)
 	if h.tracer != nil && isAckEliciting {
@@ -239,6 +301,18 @@ func (h *sentPacketHandler) SentPacket(packet *Packet) {
 	}
 }
 
+// sentPathProbePacket handles a packet sent on a path other than the current one.
+// The congestion controller, the RTT estimate and the ECN state belong to the current path,
+// so the packet doesn't count towards the bytes in flight, and it is sent without ECN marking.
+// The packet is not added to the history as an ack-eliciting packet. Its loss is never declared,
+// and it doesn't arm the PTO timer: The path probes are retransmitted by the probe timer of the path.
+func (h *sentPacketHandler) sentPathProbePacket(packet *Packet) {
+	pnSpace := h.getPacketNumberSpace(packet.EncryptionLevel)
+	pnSpace.largestSent = packet.PacketNumber
+	packet.ECN = protocol.ECNNon
+	pnSpace.history.SentPacket(packet, false)
+}
+
 func (h *sentPacketHandler) getPacketNumberSpace(encLevel protocol.EncryptionLevel) *packetNumberSpace {
 	switch encLevel {
 	case protocol.EncryptionInitial:
@@ -264,6 +338,11 @@ func (h *sentPacketHandler) sentPacketImpl(packet *Packet) bool /* is ack-elicit
 	pnSpace.largestSent = packet.PacketNumber
 	isAckEliciting := len(packet.Frames) > 0
 
+	// Only 1-RTT packets are marked, so that all ECN-marked packets are sent in separate datagrams.
+	if h.ecnTracker != nil && h.handshakeConfirmed && packet.EncryptionLevel == protocol.Encryption1RTT {
+		packet.ECN = h.ecnTracker.SentPacket(packet.PacketNumber)
+	}
+
 	if isAckEliciting {
 		pnSpace.lastAckElicitingPacketTime = packet.SendTime
 		packet.includedInBytesInFlight = true
@@ -272,7 +351,11 @@ func (h *sentPacketHandler) sentPacketImpl(packet *Packet) bool /* is ack-elicit
 			h.numProbesToSend--
 		}
 	}
-	h.congestion.OnPacketSent(packet.SendTime, h.bytesInFlight, packet.PacketNumber, packet.Length, isAckEliciting)
+	if c, ok := h.congestion.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+		c.OnPacketSentAtLevel(packet.EncryptionLevel, packet.SendTime, h.bytesInFlight, packet.PacketNumber, packet.Length, isAckEliciting)
+	} else {
+		h.congestion.OnPacketSent(packet.SendTime, h.bytesInFlight, packet.PacketNumber, packet.Length, isAckEliciting)
+	}
 
 	return isAckEliciting
 }
@@ -288,6 +371,7 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		}
 	}
 
+	prevLargestAcked := pnSpace.largestAcked
 	pnSpace.largestAcked = utils.MaxPacketNumber(pnSpace.largestAcked, largestAcked)
 
 	// Servers complete address validation when a protected packet is received.
@@ -301,9 +385,19 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 
 	priorInFlight := h.bytesInFlight
 	ackedPackets, err := h.detectAndRemoveAckedPackets(ack, encLevel)
-	if err != nil || len(ackedPackets) == 0 {
+	if err != nil {
 		return false, err
 	}
+	pnSpace.ackedRanges.AddAckFrame(ack)
+	// Acknowledged packets below the first packet in the history don't matter for persistent congestion detection.
+	if p := pnSpace.history.First(); p != nil {
+		pnSpace.ackedRanges.DeleteBelow(p.PacketNumber)
+	} else {
+		pnSpace.ackedRanges.DeleteBelow(pnSpace.largestSent + 1)
+	}
+	if len(ackedPackets) == 0 {
+		return false, nil
+	}
 	// update the RTT, if the largest acked is newly acknowledged
 	if len(ackedPackets) > 0 {
 		if p := ackedPackets[len(ackedPackets)-1]; p.PacketNumber == ack.LargestAcked() {
@@ -313,6 +407,9 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 				ackDelay = utils.MinDuration(ack.DelayTime, h.rttStats.MaxAckDelay())
 			}
 			h.rttStats.UpdateRTT(rcvTime.Sub(p.SendTime), ackDelay, rcvTime)
+			if h.firstRTTSampleTime.IsZero() {
+				h.firstRTTSampleTime = rcvTime
+			}
 			if h.logger.Debug() {
 				h.logger.Debugf("\tupdated RTT: %s (σ: %s)", h.rttStats.SmoothedRTT(), h.rttStats.MeanDeviation())
 			}
@@ -325,13 +422,32 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	var acked1RTTPacket bool
 	for _, p := range ackedPackets {
 		if p.includedInBytesInFlight && !p.declaredLost {
-			h.congestion.OnPacketAcked(p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			if c, ok := h.congestion.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+				c.OnPacketAckedAtLevel(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			} else {
+				h.congestion.OnPacketAcked(p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			}
+		}
+		if p.lostByLossDetection && !p.IsPathMTUProbePacket {
+			h.onSpuriousLoss(p, prevLargestAcked, rcvTime)
 		}
 		if p.EncryptionLevel == protocol.Encryption1RTT {
 			acked1RTTPacket = true
 		}
 		h.removeFromBytesInFlight(p)
 	}
+	// ECN counts are only validated for ACK frames that increase the largest acknowledged,
+	// since reordered ACK frames might contain outdated ECN counts (RFC 9000, Section 13.4.2.1).
+	if h.ecnTracker != nil && encLevel == protocol.Encryption1RTT && largestAcked > prevLargestAcked {
+		if h.ecnTracker.HandleNewlyAcked(ackedPackets, int64(ack.ECT0), int64(ack.ECT1), int64(ack.ECNCE)) {
+			if h.logger.Debug() {
+				h.logger.Debugf("	peer reported ECN-CE marks (CE count: %d)", ack.ECNCE)
+			}
+			if c, ok := h.congestion.(internalcongestion.ECNCongestionHandler); ok {
+				c.OnECNCongestion(largestAcked, priorInFlight)
+			}
+		}
+	}
 
 	// Reset the pto_count unless the client is unsure if the server has validated the client's address.
 	if h.peerCompletedAddressValidation {
@@ -355,6 +471,31 @@ func (h *sentPacketHandler) GetLowestPacketNotConfirmedAcked() protocol.PacketNu
 	return h.lowestNotConfirmedAcked
 }
 
+// onSpuriousLoss is called when a packet that was declared lost by loss detection is acknowledged.
+// The reordering thresholds are increased such that the packet wouldn't have been declared lost.
+func (h *sentPacketHandler) onSpuriousLoss(p *Packet, prevLargestAcked protocol.PacketNumber, rcvTime time.Time) {
+	if h.logger.Debug() {
+		h.logger.Debugf("\tspurious loss of packet %d (%s)", p.PacketNumber, p.EncryptionLevel)
+	}
+	if prevLargestAcked != protocol.InvalidPacketNumber && prevLargestAcked > p.PacketNumber {
+		if reordering := prevLargestAcked - p.PacketNumber + 1; reordering > h.packetThreshold {
+			h.packetThreshold = utils.MinPacketNumber(reordering, maxPacketThreshold)
+		}
+	}
+	maxRTT := float64(utils.MaxDuration(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT()))
+	if maxRTT > 0 {
+		// Double the fraction of the RTT that packets may be reordered by,
+		// until the packet wouldn't have been declared lost.
+		delay := float64(rcvTime.Sub(p.SendTime))
+		for h.timeThreshold*maxRTT < delay && h.timeThreshold < maxTimeThreshold {
+			h.timeThreshold = math.Min(1+2*(h.timeThreshold-1), maxTimeThreshold)
+		}
+	}
+	if c, ok := h.congestion.(internalcongestion.SpuriousLossHandler); ok {
+		c.OnSpuriousLoss(p.PacketNumber, p.Length)
+	}
+}
+
 // Packets are returned in ascending packet number order.
 func (h *sentPacketHandler) detectAndRemoveAckedPackets(ack *wire.AckFrame, encLevel protocol.EncryptionLevel) ([]*Packet, error) {
 	pnSpace := h.getPacketNumberSpace(encLevel)
@@ -555,7 +696,7 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 	pnSpace.lossTime = time.Time{}
 
 	maxRTT := float64(utils.MaxDuration(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT()))
-	lossDelay := time.Duration(timeThreshold * maxRTT)
+	lossDelay := time.Duration(h.timeThreshold * maxRTT)
 
 	// Minimum time of granularity before packets are deemed lost.
 	lossDelay = utils.MaxDuration(lossDelay, protocol.TimerGranularity)
@@ -563,12 +704,52 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 	// Packets sent before this time are deemed lost.
 	lostSendTime := now.Add(-lossDelay)
 
+	// Persistent congestion is established if all packets in a contiguous range of lost packets
+	// were sent over a period longer than the persistent congestion duration.
+	// Acknowledged packets are removed from the history, and non-ack-eliciting packets are never added to it.
+	// A gap in the packet numbers therefore only ends the range if one of the packets in the gap was acknowledged.
+	persistentCongestionDuration := persistentCongestionThreshold * h.rttStats.PTO(encLevel == protocol.Encryption1RTT)
+	var (
+		persistentCongestion bool
+		// the send time of the first packet in the current range of lost packets
+		lostRangeStart time.Time
+		// Does the current range contain a packet that was just declared lost?
+		lostRangeHasNewLoss bool
+		lastPacketNumber    = protocol.InvalidPacketNumber
+	)
+	addToLostRange := func(p *Packet, newlyLost bool) {
+		// Packets sent before the first RTT sample are not considered.
+		if h.firstRTTSampleTime.IsZero() || p.SendTime.Before(h.firstRTTSampleTime) {
+			return
+		}
+		if lostRangeStart.IsZero() {
+			lostRangeStart = p.SendTime
+		}
+		if newlyLost {
+			lostRangeHasNewLoss = true
+		}
+		if lostRangeHasNewLoss && p.SendTime.Sub(lostRangeStart) > persistentCongestionDuration {
+			persistentCongestion = true
+		}
+	}
+
 	priorInFlight := h.bytesInFlight
-	return pnSpace.history.Iterate(func(p *Packet) (bool, error) {
+	if err := pnSpace.history.Iterate(func(p *Packet) (bool, error) {
 		if p.PacketNumber > pnSpace.largestAcked {
 			return false, nil
 		}
-		if p.declaredLost || p.skippedPacket {
+		if lastPacketNumber != protocol.InvalidPacketNumber && pnSpace.ackedRanges.AckedBetween(lastPacketNumber, p.PacketNumber) {
+			lostRangeStart = time.Time{}
+			lostRangeHasNewLoss = false
+		}
+		lastPacketNumber = p.PacketNumber
+		if p.skippedPacket {
+			return true, nil
+		}
+		if p.declaredLost {
+			if !p.IsPathMTUProbePacket {
+				addToLostRange(p, false)
+			}
 			return true, nil
 		}
 
@@ -581,7 +762,7 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 			if h.tracer != nil {
 				h.tracer.LostPacket(p.EncryptionLevel, p.PacketNumber, logging.PacketLossTimeThreshold)
 			}
-		} else if pnSpace.largestAcked >= p.PacketNumber+packetThreshold {
+		} else if pnSpace.largestAcked >= p.PacketNumber+h.packetThreshold {
 			packetLost = true
 			if h.logger.Debug() {
 				h.logger.Debugf("\tlost packet %d (reordering threshold)", p.PacketNumber)
@@ -599,15 +780,40 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 		}
 		if packetLost {
 			p.declaredLost = true
+			p.lostByLossDetection = true
+			h.packetsLost++
 			// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 			h.removeFromBytesInFlight(p)
 			h.queueFramesForRetransmission(p)
 			if !p.IsPathMTUProbePacket {
-				h.congestion.OnPacketLost(p.PacketNumber, p.Length, priorInFlight)
+				if c, ok := h.congestion.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+					c.OnPacketLostAtLevel(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight)
+				} else {
+					h.congestion.OnPacketLost(p.PacketNumber, p.Length, priorInFlight)
+				}
+				addToLostRange(p, true)
+				if h.ecnTracker != nil && p.ECN != protocol.ECNNon {
+					h.ecnTracker.LostPacket(p.PacketNumber)
+				}
 			}
 		}
 		return true, nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	if persistentCongestion {
+		if h.logger.Debug() {
+			h.logger.Debugf("\tpersistent congestion detected (%s)", encLevel)
+		}
+		if t, ok := h.tracer.(logging.PersistentCongestionTracer); ok {
+			t.DetectedPersistentCongestion()
+		}
+		if c, ok := h.congestion.(internalcongestion.PersistentCongestionHandler); ok {
+			c.OnPersistentCongestion()
+		}
+	}
+	return nil
 }
 
 func (h *sentPacketHandler) OnLossDetectionTimeout() error {
@@ -746,15 +952,54 @@ func (h *sentPacketHandler) HasPacingBudget() bool {
 	return h.congestion.HasPacingBudget()
 }
 
+func (h *sentPacketHandler) ECNMode() protocol.ECN {
+	if h.ecnTracker == nil || !h.handshakeConfirmed {
+		return protocol.ECNNon
+	}
+	return h.ecnTracker.Mode()
+}
+
+func (h *sentPacketHandler) OnApplicationLimited() {
+	h.congestion.OnApplicationLimited(h.bytesInFlight)
+}
+
+func (h *sentPacketHandler) GetStats() Stats {
+	return Stats{
+		PacketsSent:        h.packetsSent,
+		PacketsLost:        h.packetsLost,
+		BytesSent:          h.bytesSent,
+		BytesReceived:      h.bytesReceived,
+		BytesRetransmitted: h.bytesRetransmitted,
+		BytesInFlight:      h.bytesInFlight,
+		CongestionWindow:   h.congestion.GetCongestionWindow(),
+		PTOCount:           h.ptoCount,
+	}
+}
+
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
 	h.congestion.SetMaxDatagramSize(s)
 }
 
+// MigratedPath is called when the connection migrated to a new path.
+// The congestion controller and the RTT estimate don't apply to the new path,
+// so they are reset to their initial values.
+func (h *sentPacketHandler) MigratedPath(initialMaxDatagramSize protocol.ByteCount) {
+	h.rttStats.OnConnectionMigration()
+	h.firstRTTSampleTime = time.Time{}
+	h.timeThreshold = initialTimeThreshold
+	h.packetThreshold = initialPacketThreshold
+	h.congestion = h.createCongestionControl(initialMaxDatagramSize)
+	// ECN support needs to be validated for the new path.
+	h.ecnTracker = h.createECNTracker()
+	h.ptoCount = 0
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) isAmplificationLimited() bool {
 	if h.peerAddressValidated {
 		return false
 	}
-	return h.bytesSent >= amplificationFactor*h.bytesReceived
+	return h.bytesSent >= protocol.AmplificationFactor*h.bytesReceived
 }
 
 func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel) bool {
@@ -771,10 +1016,26 @@ func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel)
 	return true
 }
 
+func (h *sentPacketHandler) QueueAllForRetransmission() {
+	h.appDataPackets.history.Iterate(func(p *Packet) (bool, error) {
+		if p.declaredLost || p.skippedPacket {
+			return true, nil
+		}
+		h.removeFromBytesInFlight(p)
+		if len(p.Frames) > 0 {
+			h.queueFramesForRetransmission(p)
+		}
+		p.declaredLost = true
+		return true, nil
+	})
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) queueFramesForRetransmission(p *Packet) {
 	if len(p.Frames) == 0 {
 		panic("no frames")
 	}
+	h.bytesRetransmitted += p.Length
 	for _, f := range p.Frames {
 		f.OnLost(f.Frame)
 	}
@@ -809,6 +1070,7 @@ func (h *sentPacketHandler) ResetForRetry() error {
 		// Don't set the RTT to a value lower than 5ms here.
 		now := time.Now()
 		h.rttStats.UpdateRTT(utils.MaxDuration(minRTTAfterRetry, now.Sub(firstPacketSendTime)), 0, now)
+		h.firstRTTSampleTime = now
 		if h.logger.Debug() {
 			h.logger.Debugf("\tupdated RTT: %s (σ: %s)", h.rttStats.SmoothedRTT(), h.rttStats.MeanDeviation())
 		}
@@ -818,6 +1080,10 @@ func (h *sentPacketHandler) ResetForRetry() error {
 	}
 	h.initialPackets = newPacketNumberSpace(h.initialPackets.pns.Pop(), false, h.rttStats)
 	h.appDataPackets = newPacketNumberSpace(h.appDataPackets.pns.Pop(), true, h.rttStats)
+	if c, ok := h.congestion.(internalcongestion.PacketNumberSpaceAwareSendAlgorithm); ok {
+		c.OnPacketsDropped(protocol.EncryptionInitial)
+		c.OnPacketsDropped(protocol.Encryption0RTT)
+	}
 	oldAlarm := h.alarm
 	h.alarm = time.Time{}
 	if h.tracer != nil {
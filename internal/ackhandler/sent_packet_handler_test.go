@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/mocks"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
@@ -29,7 +30,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, nil, false, perspective, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -483,10 +484,10 @@ var _ = Describe("SentPacketHandler", func() {
 	})
 
 	Context("congestion", func() {
-		var cong *mocks.MockSendAlgorithmWithDebugInfos
+		var cong *mocks.MockSendAlgorithmWithHandlers
 
 		JustBeforeEach(func() {
-			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			cong = mocks.NewMockSendAlgorithmWithHandlers(mockCtrl)
 			handler.congestion = cong
 		})
 
@@ -563,6 +564,73 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(BeZero())
 		})
 
+		It("notifies the congestion controller when a packet was declared lost spuriously", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+			for i := protocol.PacketNumber(1); i <= 4; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+			}
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketLost(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(4)),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(4), protocol.ByteCount(1), protocol.ByteCount(4), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			// Packet 1 was reordered. Don't EXPECT a call to OnPacketAcked for packet 1.
+			gomock.InOrder(
+				cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(1), protocol.ByteCount(1)),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
+			)
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}, {Smallest: 1, Largest: 2}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't require the congestion controller to handle spurious losses", func() {
+			c := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.congestion = c
+			c.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+			for i := protocol.PacketNumber(1); i <= 4; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+			}
+			c.EXPECT().MaybeExitSlowStart()
+			c.EXPECT().OnPacketLost(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(4))
+			c.EXPECT().OnPacketAcked(protocol.PacketNumber(4), protocol.ByteCount(1), protocol.ByteCount(4), gomock.Any())
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			c.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any())
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}, {Smallest: 1, Largest: 2}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.packetThreshold).To(BeNumerically(">", initialPacketThreshold))
+		})
+
+		It("doesn't treat packets retransmitted in a probe packet as lost spuriously", func() {
+			now := time.Now()
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-1100 * time.Millisecond)}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, SendTime: now.Add(-time.Second)}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(BeEmpty())
+			Expect(handler.QueueProbePacket(protocol.Encryption1RTT)).To(BeTrue())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			// The ACK for packet 1 arrives long after the probe packet was sent.
+			// Don't EXPECT a call to OnSpuriousLoss.
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now.Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.packetThreshold).To(BeEquivalentTo(initialPacketThreshold))
+			Expect(handler.timeThreshold).To(BeEquivalentTo(initialTimeThreshold))
+		})
+
 		It("calls OnPacketAcked and OnPacketLost with the right bytes_in_flight value", func() {
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
 			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
@@ -589,6 +657,269 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("doesn't pass path probe packets to the congestion controller", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(1), protocol.PacketNumber(2), protocol.ByteCount(1), true)
+			var probeDeclaredLost bool
+			handler.SentPacket(ackElicitingPacket(&Packet{
+				PacketNumber:      1,
+				SendTime:          time.Now().Add(-time.Hour),
+				IsPathProbePacket: true,
+				Frames:            []Frame{{Frame: &wire.PathChallengeFrame{}, OnLost: func(wire.Frame) { probeDeclaredLost = true }}},
+			}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2}))
+			// the probe packet would be declared lost, but don't EXPECT any calls to OnPacketLost()
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(1), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probeDeclaredLost).To(BeFalse())
+			Expect(handler.bytesInFlight).To(BeZero())
+		})
+
+		Context("ECN", func() {
+			JustBeforeEach(func() {
+				handler.enableECN = true
+				handler.ecnTracker = newECNTracker(nil, utils.DefaultLogger)
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+				cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			})
+
+			It("doesn't mark packets before the handshake is confirmed", func() {
+				p := ackElicitingPacket(&Packet{PacketNumber: 1})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECNNon))
+			})
+
+			It("marks 1-RTT packets", func() {
+				handler.SetHandshakeConfirmed()
+				p := ackElicitingPacket(&Packet{PacketNumber: 1})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECT0))
+			})
+
+			It("doesn't mark path probe packets, and doesn't use them for ECN validation", func() {
+				handler.SetHandshakeConfirmed()
+				probe := ackElicitingPacket(&Packet{PacketNumber: 1, IsPathProbePacket: true})
+				handler.SentPacket(probe)
+				Expect(probe.ECN).To(Equal(protocol.ECNNon))
+				p := ackElicitingPacket(&Packet{PacketNumber: 2})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECT0))
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}, ECT0: 1}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.ecnTracker.state).ToNot(Equal(ecnStateFailed))
+				p = ackElicitingPacket(&Packet{PacketNumber: 3})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECT0))
+			})
+
+			It("doesn't mark packets if ECN is disabled", func() {
+				handler.ecnTracker = nil
+				handler.SetHandshakeConfirmed()
+				p := ackElicitingPacket(&Packet{PacketNumber: 1})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECNNon))
+			})
+
+			It("notifies the congestion controller when the peer reports CE marks", func() {
+				handler.SetHandshakeConfirmed()
+				for i := protocol.PacketNumber(1); i <= 3; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+				}
+				cong.EXPECT().OnECNCongestion(protocol.PacketNumber(2), protocol.ByteCount(3))
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}, ECT0: 1, ECNCE: 1}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("disables ECN if the peer doesn't report ECN counts", func() {
+				handler.SetHandshakeConfirmed()
+				for i := protocol.PacketNumber(1); i <= 3; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+				}
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				p := ackElicitingPacket(&Packet{PacketNumber: 4})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECNNon))
+			})
+
+			It("doesn't validate ECN counts of ACKs that don't increase the largest acknowledged", func() {
+				handler.SetHandshakeConfirmed()
+				for i := protocol.PacketNumber(1); i <= 3; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+				}
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 3, Largest: 3}}, ECT0: 1}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				// This ACK is reordered. It newly acknowledges packet 1, but its ECN counts are outdated.
+				ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+				_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				p := ackElicitingPacket(&Packet{PacketNumber: 4})
+				handler.SentPacket(p)
+				Expect(p.ECN).To(Equal(protocol.ECT0))
+			})
+
+			It("restarts ECN validation after a migration", func() {
+				handler.SetHandshakeConfirmed()
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1}))
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.ecnTracker.state).To(Equal(ecnStateFailed))
+				handler.MigratedPath(protocol.InitialPacketSizeIPv4)
+				Expect(handler.ecnTracker.state).To(Equal(ecnStateInitial))
+			})
+		})
+
+		Context("persistent congestion", func() {
+			var now time.Time
+
+			JustBeforeEach(func() {
+				now = time.Now()
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+				// get an RTT sample of 100ms
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-10 * time.Second)}))
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(1), gomock.Any(), gomock.Any(), gomock.Any())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now.Add(-10*time.Second+100*time.Millisecond))
+				Expect(err).ToNot(HaveOccurred())
+				// The persistent congestion duration is 3 * (100ms + 4 * 50ms) = 900ms.
+				Expect(persistentCongestionThreshold * handler.rttStats.PTO(true)).To(Equal(900 * time.Millisecond))
+			})
+
+			It("detects persistent congestion", func() {
+				for i := 2; i <= 6; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-7) * 500 * time.Millisecond)}))
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 7, SendTime: now.Add(-100 * time.Millisecond)}))
+				gomock.InOrder(
+					cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5),
+					cong.EXPECT().OnPersistentCongestion(),
+					cong.EXPECT().OnPacketAcked(protocol.PacketNumber(7), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 7, Largest: 7}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lostPackets).To(Equal([]protocol.PacketNumber{2, 3, 4, 5, 6}))
+			})
+
+			It("doesn't detect persistent congestion if the lost packets were sent in a short period", func() {
+				for i := 2; i <= 6; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-8) * 100 * time.Millisecond)}))
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 7, SendTime: now.Add(-100 * time.Millisecond)}))
+				// don't EXPECT any call to OnPersistentCongestion
+				cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(7), gomock.Any(), gomock.Any(), gomock.Any())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 7, Largest: 7}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("doesn't detect persistent congestion if a packet sent in between was acknowledged", func() {
+				for i := 2; i <= 6; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-7) * 500 * time.Millisecond)}))
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 7, SendTime: now.Add(-100 * time.Millisecond)}))
+				// don't EXPECT any call to OnPersistentCongestion
+				cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(4), gomock.Any(), gomock.Any(), gomock.Any())
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(7), gomock.Any(), gomock.Any(), gomock.Any())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 7, Largest: 7}, {Smallest: 4, Largest: 4}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lostPackets).To(Equal([]protocol.PacketNumber{2, 3, 5, 6}))
+			})
+
+			It("detects persistent congestion if non-ack-eliciting packets were sent in between", func() {
+				for i := 2; i <= 10; i++ {
+					p := &Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-12) * 150 * time.Millisecond)}
+					if i%2 == 0 {
+						handler.SentPacket(ackElicitingPacket(p))
+					} else {
+						handler.SentPacket(nonAckElicitingPacket(p))
+					}
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 11, SendTime: now.Add(-100 * time.Millisecond)}))
+				gomock.InOrder(
+					cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5),
+					cong.EXPECT().OnPersistentCongestion(),
+					cong.EXPECT().OnPacketAcked(protocol.PacketNumber(11), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 11, Largest: 11}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lostPackets).To(Equal([]protocol.PacketNumber{2, 4, 6, 8, 10}))
+			})
+
+			It("doesn't detect persistent congestion if a non-ack-eliciting packet sent in between was acknowledged", func() {
+				for i := 2; i <= 10; i++ {
+					p := &Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-12) * 150 * time.Millisecond)}
+					if i%2 == 0 {
+						handler.SentPacket(ackElicitingPacket(p))
+					} else {
+						handler.SentPacket(nonAckElicitingPacket(p))
+					}
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 11, SendTime: now.Add(-100 * time.Millisecond)}))
+				// don't EXPECT any call to OnPersistentCongestion
+				cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(11), gomock.Any(), gomock.Any(), gomock.Any())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 11, Largest: 11}, {Smallest: 5, Largest: 5}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lostPackets).To(Equal([]protocol.PacketNumber{2, 4, 6, 8, 10}))
+			})
+
+			It("doesn't detect persistent congestion if a packet acknowledged by an earlier ACK was sent in between", func() {
+				for i := 2; i <= 10; i++ {
+					p := &Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-12) * 150 * time.Millisecond)}
+					if i%2 == 0 {
+						handler.SentPacket(ackElicitingPacket(p))
+					} else {
+						handler.SentPacket(nonAckElicitingPacket(p))
+					}
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 11, SendTime: now.Add(-100 * time.Millisecond)}))
+				// don't EXPECT any call to OnPersistentCongestion
+				cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(11), gomock.Any(), gomock.Any(), gomock.Any())
+				// This ACK only acknowledges a non-ack-eliciting packet.
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 11, Largest: 11}}}
+				_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lostPackets).To(Equal([]protocol.PacketNumber{2, 4, 6, 8, 10}))
+			})
+
+			It("doesn't consider packets sent before the first RTT sample", func() {
+				handler.MigratedPath(protocol.InitialPacketSizeIPv4)
+				handler.congestion = cong
+				for i := 2; i <= 6; i++ {
+					handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: protocol.PacketNumber(i), SendTime: now.Add(time.Duration(i-7) * 500 * time.Millisecond)}))
+				}
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 7, SendTime: now.Add(-100 * time.Millisecond)}))
+				// don't EXPECT any call to OnPersistentCongestion
+				cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(7), gomock.Any(), gomock.Any(), gomock.Any())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 7, Largest: 7}}}
+				_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		It("passes the bytes in flight to the congestion controller", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(42), gomock.Any(), protocol.ByteCount(42), true)
@@ -602,6 +933,13 @@ var _ = Describe("SentPacketHandler", func() {
 			handler.SendMode()
 		})
 
+		It("notifies the congestion controller when it is application-limited", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, Length: 42}))
+			cong.EXPECT().OnApplicationLimited(protocol.ByteCount(42))
+			handler.OnApplicationLimited()
+		})
+
 		It("allows sending of ACKs when congestion limited", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			cong.EXPECT().CanSend(gomock.Any()).Return(true)
@@ -644,6 +982,89 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	It("resets the congestion controller and the RTT estimate when migrating to a new path", func() {
+		updateRTT(time.Second)
+		handler.congestion.SetMaxDatagramSize(1400)
+		handler.ptoCount = 3
+		handler.packetThreshold = 10
+		handler.timeThreshold = 2
+		handler.MigratedPath(protocol.InitialPacketSizeIPv6)
+		Expect(handler.packetThreshold).To(BeEquivalentTo(initialPacketThreshold))
+		Expect(handler.timeThreshold).To(BeEquivalentTo(initialTimeThreshold))
+		Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+		Expect(handler.rttStats.MinRTT()).To(BeZero())
+		Expect(handler.congestion.GetCongestionWindow()).To(Equal(protocol.ByteCount(32) * protocol.InitialPacketSizeIPv6))
+		Expect(handler.ptoCount).To(BeZero())
+	})
+
+	It("uses the configured congestion controller, also after migrating to a new path", func() {
+		var params []congestion.Parameters
+		cong := mocks.NewMockCongestionControl(mockCtrl)
+		rttStats := utils.NewRTTStats()
+		handler = newSentPacketHandler(
+			42,
+			protocol.InitialPacketSizeIPv4,
+			rttStats,
+			func(p congestion.Parameters) congestion.CongestionControl {
+				params = append(params, p)
+				return cong
+			},
+			false,
+			protocol.PerspectiveClient,
+			nil,
+			utils.DefaultLogger,
+		)
+		Expect(params).To(HaveLen(1))
+		Expect(params[0].Perspective).To(Equal(congestion.PerspectiveClient))
+		Expect(params[0].RTTStats).To(Equal(rttStats))
+		Expect(params[0].InitialMaxDatagramSize).To(Equal(congestion.ByteCount(protocol.InitialPacketSizeIPv4)))
+		cong.EXPECT().HasPacingBudget().Return(true)
+		Expect(handler.HasPacingBudget()).To(BeTrue())
+		handler.MigratedPath(protocol.InitialPacketSizeIPv6)
+		Expect(params).To(HaveLen(2))
+		Expect(params[1].InitialMaxDatagramSize).To(Equal(congestion.ByteCount(protocol.InitialPacketSizeIPv6)))
+	})
+
+	Context("using ECN, depending on the congestion controller", func() {
+		sendPacketWithECN := func(newCongestionControl func(congestion.Parameters) congestion.CongestionControl) protocol.ECN {
+			handler = newSentPacketHandler(
+				0,
+				protocol.InitialPacketSizeIPv4,
+				utils.NewRTTStats(),
+				newCongestionControl,
+				true,
+				protocol.PerspectiveClient,
+				nil,
+				utils.DefaultLogger,
+			)
+			handler.SetHandshakeConfirmed()
+			p := ackElicitingPacket(&Packet{PacketNumber: 1})
+			handler.SentPacket(p)
+			return p.ECN
+		}
+
+		It("marks packets with ECT(0) if the congestion controller reacts to CE marks", func() {
+			Expect(sendPacketWithECN(congestion.NewReno)).To(Equal(protocol.ECT0))
+			Expect(sendPacketWithECN(congestion.NewCubic)).To(Equal(protocol.ECT0))
+		})
+
+		It("doesn't mark packets when using BBR, since it doesn't react to CE marks", func() {
+			Expect(sendPacketWithECN(congestion.NewBBR)).To(Equal(protocol.ECNNon))
+			Expect(handler.ecnTracker).To(BeNil())
+		})
+
+		It("doesn't mark packets if the congestion controller doesn't handle CE marks", func() {
+			cong := mocks.NewMockCongestionControl(mockCtrl)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			newCongestionControl := func(congestion.Parameters) congestion.CongestionControl { return cong }
+			Expect(sendPacketWithECN(newCongestionControl)).To(Equal(protocol.ECNNon))
+			Expect(handler.ecnTracker).To(BeNil())
+			// the congestion controller is created again after a migration
+			handler.MigratedPath(protocol.InitialPacketSizeIPv4)
+			Expect(handler.ecnTracker).To(BeNil())
+		})
+	})
+
 	It("doesn't set an alarm if there are no outstanding packets", func() {
 		handler.ReceivedPacket(protocol.EncryptionHandshake)
 		handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 10}))
@@ -674,6 +1095,20 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(queued).To(BeFalse())
 		})
 
+		It("queues all outstanding packets for retransmission", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			handler.SetHandshakeConfirmed()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 10}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 11}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 12}))
+			Expect(handler.QueueProbePacket(protocol.Encryption1RTT)).To(BeTrue())
+			Expect(handler.bytesInFlight).ToNot(BeZero())
+			handler.QueueAllForRetransmission()
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{10, 11, 12}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+		})
+
 		It("implements exponential backoff", func() {
 			handler.peerAddressValidated = true
 			handler.SetHandshakeConfirmed()
@@ -1142,6 +1577,78 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("spurious loss detection", func() {
+		It("increases the packet threshold", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			// packet 1 arrives after packet 6
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 6}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(6)))
+			// the same amount of reordering doesn't lead to packets being declared lost any more
+			for i := protocol.PacketNumber(7); i <= 12; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+			}
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 12, Largest: 12}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			expectInPacketHistory([]protocol.PacketNumber{7, 8, 9, 10, 11}, protocol.Encryption1RTT)
+		})
+
+		It("limits the packet threshold", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 1000; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1000, Largest: 1000}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1000}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.packetThreshold).To(BeEquivalentTo(maxPacketThreshold))
+		})
+
+		It("increases the time threshold", func() {
+			now := time.Now()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-2300 * time.Millisecond)}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, SendTime: now.Add(-2 * time.Second)}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now.Add(-time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(time.Second))
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			// The ACK for packet 1 arrives 1.4 RTTs after it was sent.
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now.Add(-900*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.timeThreshold).To(Equal(1.5))
+			Expect(handler.packetThreshold).To(BeEquivalentTo(initialPacketThreshold))
+		})
+
+		It("limits the time threshold", func() {
+			now := time.Now()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-5 * time.Second)}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, SendTime: now.Add(-2 * time.Second)}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now.Add(-time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.timeThreshold).To(BeEquivalentTo(maxTimeThreshold))
+		})
+	})
+
 	Context("crypto packets", func() {
 		It("rejects an ACK that acks packets with a higher encryption level", func() {
 			handler.SentPacket(ackElicitingPacket(&Packet{
@@ -1247,6 +1754,24 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(6)))
 		})
 
+		It("tells packet number space aware congestion controllers about encryption levels and dropped packets", func() {
+			cong := mocks.NewMockPacketNumberSpaceAwareSendAlgorithm(mockCtrl)
+			handler.congestion = cong
+			cong.EXPECT().OnPacketSentAtLevel(protocol.EncryptionInitial, gomock.Any(), gomock.Any(), protocol.PacketNumber(1), gomock.Any(), true)
+			handler.SentPacket(initialPacket(&Packet{PacketNumber: 1}))
+			cong.EXPECT().OnPacketSentAtLevel(protocol.EncryptionHandshake, gomock.Any(), gomock.Any(), protocol.PacketNumber(1), gomock.Any(), true)
+			handler.SentPacket(handshakePacket(&Packet{PacketNumber: 1}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAckedAtLevel(protocol.EncryptionHandshake, protocol.PacketNumber(1), gomock.Any(), gomock.Any(), gomock.Any()),
+			)
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.EncryptionHandshake, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			cong.EXPECT().OnPacketsDropped(protocol.EncryptionInitial)
+			handler.DropPackets(protocol.EncryptionInitial)
+		})
+
 		It("cancels the PTO when dropping a packet number space", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			now := time.Now()
@@ -1262,6 +1787,31 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("statistics", func() {
+		It("counts sent and lost packets", func() {
+			now := time.Now()
+			handler.ReceivedBytes(100)
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i, Length: 10}))
+			}
+			stats := handler.GetStats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(6))
+			Expect(stats.BytesSent).To(Equal(protocol.ByteCount(60)))
+			Expect(stats.BytesReceived).To(Equal(protocol.ByteCount(100)))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(60)))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			Expect(stats.PTOCount).To(BeZero())
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			stats = handler.GetStats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.BytesRetransmitted).To(Equal(protocol.ByteCount(30)))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(20)))
+		})
+	})
+
 	Context("peeking and popping packet number", func() {
 		It("peeks and pops the initial packet number", func() {
 			pn, _ := handler.PeekPacketNumber(protocol.EncryptionInitial)
@@ -61,6 +61,15 @@ func (h *sentPacketHistory) Iterate(cb func(*Packet) (cont bool, err error)) err
 	return nil
 }
 
+// First returns the first packet in the history.
+// This might be a packet that was declared lost, or a skipped packet number.
+func (h *sentPacketHistory) First() *Packet {
+	if el := h.packetList.Front(); el != nil {
+		return &el.Value
+	}
+	return nil
+}
+
 // FirstOutStanding returns the first outstanding packet.
 func (h *sentPacketHistory) FirstOutstanding() *Packet {
 	for el := h.packetList.Front(); el != nil; el = el.Next() {
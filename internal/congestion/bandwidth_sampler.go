@@ -0,0 +1,141 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// Packet numbers are only unique within a packet number space,
+// so packets are identified by their encryption level and their packet number.
+type sentPacketKey struct {
+	encLevel protocol.EncryptionLevel
+	pn       protocol.PacketNumber
+}
+
+// The sentPacketState is the state of the connection at the time a packet was sent.
+type sentPacketState struct {
+	sentTime time.Time
+	size     protocol.ByteCount
+	// the total number of bytes delivered when the packet was sent
+	delivered protocol.ByteCount
+	// the time when delivered was last updated
+	deliveredTime time.Time
+	// the send time of the packet that was most recently acknowledged when the packet was sent
+	firstSentTime time.Time
+	isAppLimited  bool
+}
+
+// A rateSample is a delivery rate sample, as defined in draft-cheng-iccrg-delivery-rate-estimation.
+type rateSample struct {
+	deliveryRate Bandwidth
+	// the total number of bytes delivered when the acknowledged packet was sent
+	priorDelivered protocol.ByteCount
+	// the interval over which the delivery rate was measured
+	interval     time.Duration
+	isAppLimited bool
+}
+
+// The bandwidthSampler estimates the delivery rate of a connection,
+// following the algorithm described in draft-cheng-iccrg-delivery-rate-estimation.
+// For every acknowledged packet, it generates a sample of the rate at which data was delivered to the peer.
+type bandwidthSampler struct {
+	packets map[sentPacketKey]*sentPacketState
+
+	// total number of bytes delivered so far
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	// The end of the application-limited period.
+	// Delivery rate samples for packets sent before delivered reaches this value are marked as application-limited.
+	// 0 if the connection is not application-limited.
+	appLimitedUntil protocol.ByteCount
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{packets: make(map[sentPacketKey]*sentPacketState)}
+}
+
+// OnPacketSent is called for every ack-eliciting packet sent.
+// bytesInFlight is the number of bytes in flight before sending this packet.
+func (s *bandwidthSampler) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, size protocol.ByteCount) {
+	// When starting to send after an idle period, the delivery rate is measured starting with this packet.
+	if bytesInFlight == 0 {
+		s.firstSentTime = sentTime
+		s.deliveredTime = sentTime
+	}
+	s.packets[sentPacketKey{encLevel: encLevel, pn: pn}] = &sentPacketState{
+		sentTime:      sentTime,
+		size:          size,
+		delivered:     s.delivered,
+		deliveredTime: s.deliveredTime,
+		firstSentTime: s.firstSentTime,
+		isAppLimited:  s.appLimitedUntil != 0,
+	}
+}
+
+// OnPacketAcked is called when a packet is acknowledged.
+// It returns false if no delivery rate sample could be generated.
+func (s *bandwidthSampler) OnPacketAcked(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, ackTime time.Time) (rateSample, bool) {
+	key := sentPacketKey{encLevel: encLevel, pn: pn}
+	p, ok := s.packets[key]
+	if !ok {
+		return rateSample{}, false
+	}
+	delete(s.packets, key)
+
+	s.delivered += p.size
+	s.deliveredTime = ackTime
+	if s.appLimitedUntil != 0 && s.delivered > s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+	// The send rate of packets sent from now on is measured starting at the send time of the most recently sent packet
+	// that has been acknowledged.
+	if p.sentTime.After(s.firstSentTime) {
+		s.firstSentTime = p.sentTime
+	}
+
+	// The delivery rate is the lower of the send rate and the ack rate.
+	// This takes into account that ACKs might be compressed and thereby artificially inflate the ack rate.
+	sendElapsed := p.sentTime.Sub(p.firstSentTime)
+	ackElapsed := ackTime.Sub(p.deliveredTime)
+	interval := utils.MaxDuration(sendElapsed, ackElapsed)
+	if interval <= 0 {
+		return rateSample{}, false
+	}
+	return rateSample{
+		deliveryRate:   BandwidthFromDelta(s.delivered-p.delivered, interval),
+		priorDelivered: p.delivered,
+		interval:       interval,
+		isAppLimited:   p.isAppLimited,
+	}, true
+}
+
+// OnPacketLost is called when a packet is declared lost.
+func (s *bandwidthSampler) OnPacketLost(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber) {
+	delete(s.packets, sentPacketKey{encLevel: encLevel, pn: pn})
+}
+
+// OnPacketsDropped is called when all packets sent at an encryption level are dropped.
+func (s *bandwidthSampler) OnPacketsDropped(encLevel protocol.EncryptionLevel) {
+	for key := range s.packets {
+		if key.encLevel == encLevel {
+			delete(s.packets, key)
+		}
+	}
+}
+
+// OnApplicationLimited is called when the connection is application-limited.
+// All delivery rate samples until the data currently in flight has been acknowledged are marked as application-limited.
+func (s *bandwidthSampler) OnApplicationLimited(bytesInFlight protocol.ByteCount) {
+	s.appLimitedUntil = s.delivered + bytesInFlight
+	if s.appLimitedUntil == 0 {
+		s.appLimitedUntil = 1
+	}
+}
+
+// Delivered returns the total number of bytes delivered.
+func (s *bandwidthSampler) Delivered() protocol.ByteCount {
+	return s.delivered
+}
@@ -0,0 +1,159 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth Sampler", func() {
+	const packetSize protocol.ByteCount = 1000
+
+	var (
+		sampler       *bandwidthSampler
+		now           time.Time
+		bytesInFlight protocol.ByteCount
+	)
+
+	BeforeEach(func() {
+		sampler = newBandwidthSampler()
+		now = time.Now()
+		bytesInFlight = 0
+	})
+
+	sendPacket := func(pn protocol.PacketNumber) {
+		sampler.OnPacketSent(now, bytesInFlight, protocol.Encryption1RTT, pn, packetSize)
+		bytesInFlight += packetSize
+	}
+
+	ackPacket := func(pn protocol.PacketNumber) (rateSample, bool) {
+		bytesInFlight -= packetSize
+		return sampler.OnPacketAcked(protocol.Encryption1RTT, pn, now)
+	}
+
+	It("measures the delivery rate", func() {
+		// Send a packet every 10ms. Every packet is acknowledged 100ms after it was sent.
+		// The delivery rate is one packet every 10ms.
+		var pn protocol.PacketNumber
+		for ; pn < 10; pn++ {
+			sendPacket(pn)
+			now = now.Add(10 * time.Millisecond)
+		}
+		for i := 0; i < 20; i++ {
+			sample, ok := ackPacket(pn - 10)
+			Expect(ok).To(BeTrue())
+			Expect(sample.isAppLimited).To(BeFalse())
+			if i >= 10 {
+				Expect(sample.deliveryRate).To(Equal(BandwidthFromDelta(packetSize, 10*time.Millisecond)))
+				Expect(sample.interval).To(Equal(100 * time.Millisecond))
+			}
+			sendPacket(pn)
+			pn++
+			now = now.Add(10 * time.Millisecond)
+		}
+		Expect(sampler.Delivered()).To(Equal(20 * packetSize))
+	})
+
+	It("uses the send rate if it is lower than the ack rate", func() {
+		// Send 10 packets, spaced by 10ms, and receive the acknowledgments for all of them at the same time.
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			sendPacket(pn)
+			now = now.Add(10 * time.Millisecond)
+		}
+		now = now.Add(50 * time.Millisecond)
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			_, ok := ackPacket(pn)
+			Expect(ok).To(BeTrue())
+		}
+		// The next flight is sent with the same spacing, but ACKs are compressed.
+		for pn := protocol.PacketNumber(10); pn < 20; pn++ {
+			sendPacket(pn)
+			now = now.Add(10 * time.Millisecond)
+		}
+		now = now.Add(50 * time.Millisecond)
+		var sample rateSample
+		for pn := protocol.PacketNumber(10); pn < 20; pn++ {
+			var ok bool
+			sample, ok = ackPacket(pn)
+			Expect(ok).To(BeTrue())
+		}
+		// The last sample was taken over 10 packets.
+		Expect(sample.deliveryRate).To(BeNumerically("<=", BandwidthFromDelta(packetSize, 10*time.Millisecond)))
+	})
+
+	It("doesn't generate samples for lost packets", func() {
+		sendPacket(1)
+		sendPacket(2)
+		now = now.Add(100 * time.Millisecond)
+		sampler.OnPacketLost(protocol.Encryption1RTT, 1)
+		bytesInFlight -= packetSize
+		_, ok := sampler.OnPacketAcked(protocol.Encryption1RTT, 1, now)
+		Expect(ok).To(BeFalse())
+		sample, ok := ackPacket(2)
+		Expect(ok).To(BeTrue())
+		Expect(sample.deliveryRate).To(Equal(BandwidthFromDelta(packetSize, 100*time.Millisecond)))
+		Expect(sampler.Delivered()).To(Equal(packetSize))
+	})
+
+	It("doesn't generate samples for unknown packets", func() {
+		_, ok := sampler.OnPacketAcked(protocol.Encryption1RTT, 1337, now)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("distinguishes packets sent in different packet number spaces", func() {
+		sampler.OnPacketSent(now, bytesInFlight, protocol.EncryptionInitial, 0, packetSize)
+		bytesInFlight += packetSize
+		now = now.Add(10 * time.Millisecond)
+		sampler.OnPacketSent(now, bytesInFlight, protocol.EncryptionHandshake, 0, 2*packetSize)
+		bytesInFlight += 2 * packetSize
+		now = now.Add(90 * time.Millisecond)
+		bytesInFlight -= packetSize
+		sample, ok := sampler.OnPacketAcked(protocol.EncryptionInitial, 0, now)
+		Expect(ok).To(BeTrue())
+		Expect(sample.deliveryRate).To(Equal(BandwidthFromDelta(packetSize, 100*time.Millisecond)))
+		Expect(sampler.Delivered()).To(Equal(packetSize))
+		_, ok = sampler.OnPacketAcked(protocol.EncryptionInitial, 0, now)
+		Expect(ok).To(BeFalse())
+		bytesInFlight -= 2 * packetSize
+		_, ok = sampler.OnPacketAcked(protocol.EncryptionHandshake, 0, now)
+		Expect(ok).To(BeTrue())
+		Expect(sampler.Delivered()).To(Equal(3 * packetSize))
+	})
+
+	It("drops packets", func() {
+		sampler.OnPacketSent(now, bytesInFlight, protocol.EncryptionInitial, 0, packetSize)
+		sampler.OnPacketSent(now, bytesInFlight, protocol.Encryption0RTT, 0, packetSize)
+		sampler.OnPacketSent(now, bytesInFlight, protocol.Encryption1RTT, 1, packetSize)
+		sampler.OnPacketsDropped(protocol.EncryptionInitial)
+		sampler.OnPacketsDropped(protocol.Encryption0RTT)
+		Expect(sampler.packets).To(HaveLen(1))
+		Expect(sampler.packets).To(HaveKey(sentPacketKey{encLevel: protocol.Encryption1RTT, pn: 1}))
+	})
+
+	It("marks samples as application-limited", func() {
+		sendPacket(1)
+		sendPacket(2)
+		sampler.OnApplicationLimited(bytesInFlight)
+		sendPacket(3)
+		now = now.Add(100 * time.Millisecond)
+		// packets 1 and 2 were sent before the connection became application-limited
+		sample, ok := ackPacket(1)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+		sample, ok = ackPacket(2)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+		sample, ok = ackPacket(3)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeTrue())
+		// all data that was in flight when the connection became application-limited was delivered
+		sendPacket(4)
+		now = now.Add(100 * time.Millisecond)
+		sample, ok = ackPacket(4)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+	})
+})
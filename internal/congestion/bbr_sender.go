@@ -0,0 +1,629 @@
+package congestion
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+const (
+	// The gain used in STARTUP, 2/ln(2).
+	// This is the smallest gain that allows the sending rate to double every round trip.
+	bbrHighGain = 2.885
+	// The gain used in DRAIN, to drain the queue created during STARTUP in a single round trip.
+	bbrDrainGain = 1 / bbrHighGain
+	// The congestion window gain used in PROBE_BW.
+	bbrCwndGain = 2
+	// The number of round trips that the maximum bandwidth filter keeps samples for.
+	bbrBandwidthWindowSize = uint64(len(bbrPacingGainCycle) + 2)
+	// If no smaller RTT was measured for this duration, BBR enters PROBE_RTT.
+	bbrMinRTTExpiry = 10 * time.Second
+	// The minimum time spent in PROBE_RTT.
+	bbrProbeRTTDuration = 200 * time.Millisecond
+	// The minimum congestion window, in packets. It is also used as the congestion window in PROBE_RTT.
+	bbrMinCongestionWindowPackets = 4
+	// STARTUP is left when the bandwidth doesn't grow by at least this factor
+	// for bbrStartupFullBandwidthRounds consecutive round trips.
+	bbrStartupGrowthTarget        = 1.25
+	bbrStartupFullBandwidthRounds = 3
+	// The fraction of the bytes in flight that can be lost in a single round trip before BBR reacts to the loss.
+	// BBRv1 ignores loss (apart from packet conservation during recovery) and therefore causes excessive loss
+	// when competing for a shallow buffer, while loss-based congestion controllers react to every single loss,
+	// which results in poor performance on lossy links.
+	// Following BBRv2, random losses up to this threshold are tolerated.
+	bbrLossThreshold = 0.02
+	// The RTT used to calculate the initial pacing rate, before the RTT was measured (RFC 9002, Section 6.2.2).
+	bbrInitialRTT = 100 * time.Millisecond
+)
+
+// The pacing gain cycle used in PROBE_BW.
+// During the first phase, BBR probes for more bandwidth, during the second phase it drains the queue created by probing.
+var bbrPacingGainCycle = [...]float64{1.25, 0.75, 1, 1, 1, 1, 1, 1}
+
+type bbrMode uint8
+
+const (
+	// In STARTUP, BBR grows the sending rate exponentially to quickly probe for the available bandwidth.
+	bbrModeStartup bbrMode = iota
+	// In DRAIN, BBR drains the queue that was created during STARTUP.
+	bbrModeDrain
+	// In PROBE_BW, BBR cycles through the pacing gains to probe for more bandwidth.
+	bbrModeProbeBW
+	// In PROBE_RTT, BBR reduces the congestion window to drain the queue in order to measure the minimum RTT.
+	bbrModeProbeRTT
+)
+
+type bbrRecoveryState uint8
+
+const (
+	bbrNotInRecovery bbrRecoveryState = iota
+	// During the first round trip of recovery, BBR uses packet conservation:
+	// it only sends as many bytes as were acknowledged.
+	bbrRecoveryConservation
+	// After the first round trip of recovery, BBR grows the recovery window like in slow start.
+	bbrRecoveryGrowth
+)
+
+// The bbrSender implements BBR, as described in draft-cardwell-iccrg-bbr-congestion-control.
+// It estimates the bottleneck bandwidth and the minimum RTT of the path, and paces packets at the estimated bandwidth.
+// Unlike BBRv1, it only reacts to losses if the loss rate exceeds bbrLossThreshold.
+type bbrSender struct {
+	clock    Clock
+	rttStats RTTStats
+	sampler  *bandwidthSampler
+	pacer    *Pacer
+
+	mode bbrMode
+
+	// the bandwidth estimate, the maximum of the delivery rate samples of the last round trips
+	maxBandwidth *maxBandwidthFilter
+	pacingRate   Bandwidth
+	pacingGain   float64
+	cwndGain     float64
+
+	largestSentPacketNumber protocol.PacketNumber
+	bytesInFlight           protocol.ByteCount
+
+	// Round trips are counted using the number of bytes delivered:
+	// A round trip ends when a packet that was sent after the start of the round trip is acknowledged.
+	roundCount         uint64
+	nextRoundDelivered protocol.ByteCount
+	roundStart         bool
+	// the number of bytes lost in the current round trip
+	lostInRound protocol.ByteCount
+
+	minRTT          time.Duration
+	minRTTTimestamp time.Time
+	minRTTExpired   bool
+
+	// used to determine when STARTUP is left
+	fullBandwidth          Bandwidth
+	fullBandwidthCount     int
+	fullBandwidthReached   bool
+	lastSampleIsAppLimited bool
+
+	// the current phase of the pacing gain cycle in PROBE_BW
+	cycleIndex int
+	cycleStart time.Time
+
+	probeRTTDoneTime      time.Time
+	probeRTTRoundDone     bool
+	priorCongestionWindow protocol.ByteCount
+
+	recoveryState bbrRecoveryState
+	// Recovery ends when a packet sent after this packet is acknowledged.
+	endRecoveryAt  protocol.PacketNumber
+	recoveryWindow protocol.ByteCount
+
+	congestionWindow        protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    logging.ConnectionTracer
+}
+
+// Just like BBR tolerates random packet loss, it doesn't respond to individual ECN-CE marks.
+// Its model of the path is based on the delivery rate and the minimum RTT instead.
+// It therefore doesn't implement OnECNCongestion, and packets are sent without ECN markings.
+var (
+	_ SendAlgorithm                       = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos         = &bbrSender{}
+	_ PacketNumberSpaceAwareSendAlgorithm = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer logging.ConnectionTracer,
+) *bbrSender {
+	b := &bbrSender{
+		clock:                   clock,
+		rttStats:                rttStats,
+		sampler:                 newBandwidthSampler(),
+		maxBandwidth:            newMaxBandwidthFilter(bbrBandwidthWindowSize),
+		largestSentPacketNumber: protocol.InvalidPacketNumber,
+		endRecoveryAt:           protocol.InvalidPacketNumber,
+		initialCongestionWindow: initialCongestionWindow * initialMaxDatagramSize,
+		congestionWindow:        initialCongestionWindow * initialMaxDatagramSize,
+		maxDatagramSize:         initialMaxDatagramSize,
+		tracer:                  tracer,
+	}
+	b.enterStartup()
+	initialRTT := rttStats.SmoothedRTT()
+	if initialRTT == 0 {
+		initialRTT = bbrInitialRTT
+	}
+	b.pacingRate = b.initialPacingRate(initialRTT)
+	b.pacer = newPacer(func() uint64 { return uint64(b.pacingRate / BytesPerSecond) })
+	b.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	if b.tracer != nil {
+		b.lastState = logging.CongestionStateSlowStart
+		b.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return b
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (b *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbrSender) HasPacingBudget() bool {
+	return b.pacer.Budget(b.clock.Now()) >= b.maxDatagramSize
+}
+
+// OnPacketSent is called when a packet is sent.
+// It's only called if the encryption level of the packet is unknown. The packet is treated as a 1-RTT packet.
+func (b *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	b.OnPacketSentAtLevel(protocol.Encryption1RTT, sentTime, bytesInFlight, packetNumber, bytes, isRetransmittable)
+}
+
+func (b *bbrSender) OnPacketSentAtLevel(
+	encLevel protocol.EncryptionLevel,
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	b.largestSentPacketNumber = packetNumber
+	b.bytesInFlight = bytesInFlight
+	b.sampler.OnPacketSent(sentTime, bytesInFlight-bytes, encLevel, packetNumber, bytes)
+}
+
+func (b *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < b.GetCongestionWindow()
+}
+
+// MaybeExitSlowStart is called when the RTT estimate was updated.
+// BBR leaves STARTUP when the bandwidth estimate stops growing, so there's nothing to do here.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+// OnPacketAcked is called when a packet is acknowledged.
+// Just like OnPacketSent, it treats the packet as a 1-RTT packet.
+func (b *bbrSender) OnPacketAcked(
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.OnPacketAckedAtLevel(protocol.Encryption1RTT, ackedPacketNumber, ackedBytes, priorInFlight, eventTime)
+}
+
+func (b *bbrSender) OnPacketAckedAtLevel(
+	encLevel protocol.EncryptionLevel,
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.removeFromBytesInFlight(ackedBytes)
+	sample, ok := b.sampler.OnPacketAcked(encLevel, ackedPacketNumber, eventTime)
+	b.updateRoundTripCounter(sample, ok)
+	b.updateMinRTT(eventTime)
+	if ok {
+		b.updateBandwidth(sample)
+	}
+	b.updateRecoveryState(ackedPacketNumber)
+
+	if b.mode == bbrModeProbeBW {
+		b.updateGainCyclePhase(priorInFlight, eventTime)
+	}
+	if b.roundStart && !b.fullBandwidthReached {
+		b.checkFullBandwidthReached()
+	}
+	b.maybeExitStartupOrDrain(eventTime)
+	b.maybeEnterOrExitProbeRTT(eventTime)
+
+	b.calculatePacingRate()
+	b.calculateCongestionWindow(ackedBytes)
+	b.calculateRecoveryWindow(ackedBytes)
+	b.maybeTraceStateChange(b.congestionState())
+}
+
+// OnPacketLost is called when a packet is declared lost.
+// Just like OnPacketSent, it treats the packet as a 1-RTT packet.
+func (b *bbrSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	b.OnPacketLostAtLevel(protocol.Encryption1RTT, packetNumber, lostBytes, priorInFlight)
+}
+
+func (b *bbrSender) OnPacketLostAtLevel(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	b.removeFromBytesInFlight(lostBytes)
+	b.sampler.OnPacketLost(encLevel, packetNumber)
+	b.lostInRound += lostBytes
+	if b.InRecovery() {
+		b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow-utils.MinByteCount(lostBytes, b.recoveryWindow), b.minCongestionWindow())
+	}
+	// Tolerate random loss, as long as the loss rate in this round trip stays below the threshold.
+	if float64(b.lostInRound) <= bbrLossThreshold*float64(priorInFlight) {
+		return
+	}
+
+	switch b.mode {
+	case bbrModeStartup:
+		// The bandwidth was probed too aggressively. Drain the queue.
+		b.fullBandwidthReached = true
+		b.enterDrain()
+	case bbrModeProbeBW:
+		// Stop probing for more bandwidth.
+		if b.pacingGain > 1 {
+			b.advanceCyclePhase(b.clock.Now())
+		}
+	}
+	b.endRecoveryAt = b.largestSentPacketNumber
+	if !b.InRecovery() {
+		b.recoveryState = bbrRecoveryConservation
+		b.recoveryWindow = utils.MaxByteCount(b.bytesInFlight, b.minCongestionWindow())
+		// Use packet conservation for a full round trip, starting now.
+		b.nextRoundDelivered = b.sampler.Delivered()
+		b.maybeTraceStateChange(logging.CongestionStateRecovery)
+	}
+}
+
+// OnPacketsDropped is called when the packets sent at an encryption level are dropped.
+// The bytes in flight are updated when the next packet is sent.
+func (b *bbrSender) OnPacketsDropped(encLevel protocol.EncryptionLevel) {
+	b.sampler.OnPacketsDropped(encLevel)
+}
+
+// OnSpuriousLoss is called when a packet that was declared lost is acknowledged.
+// The packet doesn't count towards the loss rate of the current round trip.
+// Recovery isn't undone, since BBR only enters recovery on excessive loss, and leaves it after a round trip.
+func (b *bbrSender) OnSpuriousLoss(_ protocol.PacketNumber, lostBytes protocol.ByteCount) {
+	b.lostInRound -= utils.MinByteCount(lostBytes, b.lostInRound)
+}
+
+// OnPersistentCongestion is called when persistent congestion was detected.
+// The bandwidth estimate is kept, but the congestion window is collapsed to the minimum congestion window,
+// since no packets were delivered for multiple round trips.
+func (b *bbrSender) OnPersistentCongestion() {
+	b.recoveryState = bbrNotInRecovery
+	b.congestionWindow = b.minCongestionWindow()
+	b.priorCongestionWindow = b.congestionWindow
+	b.maybeTraceStateChange(b.congestionState())
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout.
+// BBR doesn't reduce its congestion window on a retransmission timeout.
+func (b *bbrSender) OnRetransmissionTimeout(bool) {}
+
+// OnApplicationLimited is called when the connection doesn't have any data to send.
+// Delivery rate samples taken while the connection is application-limited underestimate the bandwidth.
+func (b *bbrSender) OnApplicationLimited(bytesInFlight protocol.ByteCount) {
+	if bytesInFlight >= b.GetCongestionWindow() {
+		return
+	}
+	b.sampler.OnApplicationLimited(bytesInFlight)
+	b.maybeTraceStateChange(logging.CongestionStateApplicationLimited)
+}
+
+func (b *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := b.congestionWindow == b.minCongestionWindow()
+	b.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		b.congestionWindow = b.minCongestionWindow()
+	}
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+func (b *bbrSender) InSlowStart() bool {
+	return b.mode == bbrModeStartup
+}
+
+func (b *bbrSender) InRecovery() bool {
+	return b.recoveryState != bbrNotInRecovery
+}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	if b.mode == bbrModeProbeRTT {
+		return b.minCongestionWindow()
+	}
+	if b.InRecovery() {
+		return utils.MinByteCount(b.congestionWindow, b.recoveryWindow)
+	}
+	return b.congestionWindow
+}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (b *bbrSender) BandwidthEstimate() Bandwidth {
+	return b.maxBandwidth.GetBest()
+}
+
+func (b *bbrSender) removeFromBytesInFlight(n protocol.ByteCount) {
+	b.bytesInFlight -= utils.MinByteCount(n, b.bytesInFlight)
+}
+
+func (b *bbrSender) updateRoundTripCounter(sample rateSample, ok bool) {
+	b.roundStart = false
+	if !ok || sample.priorDelivered < b.nextRoundDelivered {
+		return
+	}
+	b.nextRoundDelivered = b.sampler.Delivered()
+	b.roundCount++
+	b.roundStart = true
+	b.lostInRound = 0
+}
+
+func (b *bbrSender) updateMinRTT(now time.Time) {
+	rtt := b.rttStats.LatestRTT()
+	if rtt <= 0 {
+		return
+	}
+	b.minRTTExpired = b.minRTT != 0 && now.After(b.minRTTTimestamp.Add(bbrMinRTTExpiry))
+	if b.minRTTExpired || rtt < b.minRTT || b.minRTT == 0 {
+		b.minRTT = rtt
+		b.minRTTTimestamp = now
+	}
+}
+
+func (b *bbrSender) updateBandwidth(sample rateSample) {
+	// Samples that were taken over less than a round trip are not reliable.
+	if b.minRTT != 0 && sample.interval < b.minRTT {
+		return
+	}
+	b.lastSampleIsAppLimited = sample.isAppLimited
+	// Samples taken while the connection was application-limited underestimate the bandwidth,
+	// unless they are larger than the current estimate.
+	if !sample.isAppLimited || sample.deliveryRate >= b.maxBandwidth.GetBest() {
+		b.maxBandwidth.Update(sample.deliveryRate, b.roundCount)
+	}
+}
+
+func (b *bbrSender) updateRecoveryState(ackedPacketNumber protocol.PacketNumber) {
+	switch b.recoveryState {
+	case bbrNotInRecovery:
+		return
+	case bbrRecoveryConservation:
+		if b.roundStart {
+			b.recoveryState = bbrRecoveryGrowth
+		}
+	}
+	if ackedPacketNumber > b.endRecoveryAt {
+		b.recoveryState = bbrNotInRecovery
+	}
+}
+
+func (b *bbrSender) updateGainCyclePhase(priorInFlight protocol.ByteCount, now time.Time) {
+	// Each phase lasts (roughly) one minimum RTT.
+	shouldAdvance := now.Sub(b.cycleStart) > b.minRTT
+	// When probing for more bandwidth, keep probing until the bytes in flight reach the target.
+	if b.pacingGain > 1 && priorInFlight < b.targetCongestionWindow(b.pacingGain) {
+		shouldAdvance = false
+	}
+	// When draining the queue, the phase can end as soon as the queue is drained.
+	if b.pacingGain < 1 && b.bytesInFlight <= b.targetCongestionWindow(1) {
+		shouldAdvance = true
+	}
+	if shouldAdvance {
+		b.advanceCyclePhase(now)
+	}
+}
+
+func (b *bbrSender) advanceCyclePhase(now time.Time) {
+	b.cycleIndex = (b.cycleIndex + 1) % len(bbrPacingGainCycle)
+	b.cycleStart = now
+	b.pacingGain = bbrPacingGainCycle[b.cycleIndex]
+}
+
+func (b *bbrSender) checkFullBandwidthReached() {
+	if b.lastSampleIsAppLimited {
+		return
+	}
+	bw := b.maxBandwidth.GetBest()
+	if float64(bw) >= float64(b.fullBandwidth)*bbrStartupGrowthTarget {
+		b.fullBandwidth = bw
+		b.fullBandwidthCount = 0
+		return
+	}
+	b.fullBandwidthCount++
+	if b.fullBandwidthCount >= bbrStartupFullBandwidthRounds {
+		b.fullBandwidthReached = true
+	}
+}
+
+func (b *bbrSender) maybeExitStartupOrDrain(now time.Time) {
+	if b.mode == bbrModeStartup && b.fullBandwidthReached {
+		b.enterDrain()
+	}
+	if b.mode == bbrModeDrain && b.bytesInFlight <= b.targetCongestionWindow(1) {
+		b.enterProbeBW(now)
+	}
+}
+
+func (b *bbrSender) maybeEnterOrExitProbeRTT(now time.Time) {
+	if b.minRTTExpired && b.mode != bbrModeProbeRTT {
+		b.mode = bbrModeProbeRTT
+		b.pacingGain = 1
+		b.probeRTTDoneTime = time.Time{}
+		b.priorCongestionWindow = b.congestionWindow
+	}
+	if b.mode != bbrModeProbeRTT {
+		return
+	}
+	// Don't count the bandwidth samples taken in PROBE_RTT, since the sending rate is reduced.
+	b.sampler.OnApplicationLimited(b.bytesInFlight)
+	if b.probeRTTDoneTime.IsZero() {
+		// Wait until the bytes in flight drop to the reduced congestion window.
+		if b.bytesInFlight < b.minCongestionWindow()+b.maxDatagramSize {
+			b.probeRTTDoneTime = now.Add(bbrProbeRTTDuration)
+			b.probeRTTRoundDone = false
+			b.nextRoundDelivered = b.sampler.Delivered()
+		}
+		return
+	}
+	if b.roundStart {
+		b.probeRTTRoundDone = true
+	}
+	// Stay in PROBE_RTT for at least bbrProbeRTTDuration and one round trip.
+	if b.probeRTTRoundDone && !now.Before(b.probeRTTDoneTime) {
+		b.minRTTTimestamp = now
+		if b.fullBandwidthReached {
+			b.enterProbeBW(now)
+		} else {
+			b.enterStartup()
+		}
+		b.congestionWindow = utils.MaxByteCount(b.congestionWindow, b.priorCongestionWindow)
+	}
+}
+
+func (b *bbrSender) enterStartup() {
+	b.mode = bbrModeStartup
+	b.pacingGain = bbrHighGain
+	b.cwndGain = bbrHighGain
+}
+
+func (b *bbrSender) enterDrain() {
+	b.mode = bbrModeDrain
+	b.pacingGain = bbrDrainGain
+	b.cwndGain = bbrHighGain
+}
+
+func (b *bbrSender) enterProbeBW(now time.Time) {
+	b.mode = bbrModeProbeBW
+	b.cwndGain = bbrCwndGain
+	// Start at a random phase of the cycle, so that competing BBR flows don't probe at the same time.
+	// Don't start in the phase that drains the queue, since there's no queue to drain.
+	b.cycleIndex = rand.Intn(len(bbrPacingGainCycle) - 1)
+	if b.cycleIndex >= 1 {
+		b.cycleIndex++
+	}
+	b.cycleStart = now
+	b.pacingGain = bbrPacingGainCycle[b.cycleIndex]
+}
+
+func (b *bbrSender) calculatePacingRate() {
+	target := Bandwidth(b.pacingGain * float64(b.maxBandwidth.GetBest()))
+	if b.fullBandwidthReached {
+		// Keep the current pacing rate until there's a bandwidth estimate.
+		if target != 0 {
+			b.pacingRate = target
+		}
+		return
+	}
+	// During STARTUP, pace at least at the rate that allows sending the initial congestion window in one RTT.
+	if b.minRTT != 0 {
+		if initialRate := b.initialPacingRate(b.minRTT); initialRate > target {
+			target = initialRate
+		}
+	}
+	// Don't decrease the pacing rate during STARTUP.
+	if target > b.pacingRate {
+		b.pacingRate = target
+	}
+}
+
+func (b *bbrSender) calculateCongestionWindow(ackedBytes protocol.ByteCount) {
+	if b.mode == bbrModeProbeRTT {
+		return
+	}
+	target := b.targetCongestionWindow(b.cwndGain)
+	if b.mode == bbrModeProbeBW {
+		// Allow for a few more packets in flight, to account for delayed and aggregated ACKs.
+		target += maxBurstPackets * b.maxDatagramSize
+	}
+	if b.fullBandwidthReached {
+		b.congestionWindow = utils.MinByteCount(b.congestionWindow+ackedBytes, target)
+	} else if b.congestionWindow < target || b.sampler.Delivered() < b.initialCongestionWindow {
+		// Only grow the congestion window during STARTUP, and as long as the initial window hasn't been delivered.
+		b.congestionWindow += ackedBytes
+	}
+	b.congestionWindow = utils.MaxByteCount(b.congestionWindow, b.minCongestionWindow())
+	b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.maxCongestionWindow())
+}
+
+func (b *bbrSender) calculateRecoveryWindow(ackedBytes protocol.ByteCount) {
+	if !b.InRecovery() {
+		return
+	}
+	// In conservation, only the acknowledged bytes may be sent.
+	// In growth, send twice the number of acknowledged bytes, similar to slow start.
+	if b.recoveryState == bbrRecoveryGrowth {
+		b.recoveryWindow += ackedBytes
+	}
+	b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow, b.bytesInFlight+ackedBytes)
+	b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow, b.minCongestionWindow())
+}
+
+// targetCongestionWindow returns the bandwidth-delay product, multiplied by the gain.
+func (b *bbrSender) targetCongestionWindow(gain float64) protocol.ByteCount {
+	bw := b.maxBandwidth.GetBest()
+	var bdp protocol.ByteCount
+	if bw == 0 || b.minRTT == 0 {
+		// As long as there's no estimate for the bandwidth-delay product, use the initial congestion window.
+		bdp = b.initialCongestionWindow
+	} else {
+		bdp = protocol.ByteCount(float64(bw/BytesPerSecond) * b.minRTT.Seconds())
+	}
+	return utils.MaxByteCount(protocol.ByteCount(gain*float64(bdp)), b.minCongestionWindow())
+}
+
+// initialPacingRate is the pacing rate used in STARTUP, before the bandwidth has been measured.
+func (b *bbrSender) initialPacingRate(rtt time.Duration) Bandwidth {
+	return Bandwidth(bbrHighGain * float64(BandwidthFromDelta(b.initialCongestionWindow, rtt)))
+}
+
+func (b *bbrSender) minCongestionWindow() protocol.ByteCount {
+	return bbrMinCongestionWindowPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) maxCongestionWindow() protocol.ByteCount {
+	return protocol.MaxCongestionWindowPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) congestionState() logging.CongestionState {
+	if b.InRecovery() {
+		return logging.CongestionStateRecovery
+	}
+	if b.mode == bbrModeStartup {
+		return logging.CongestionStateSlowStart
+	}
+	return logging.CongestionStateCongestionAvoidance
+}
+
+func (b *bbrSender) maybeTraceStateChange(new logging.CongestionState) {
+	if b.tracer == nil || new == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(new)
+	b.lastState = new
+}
@@ -0,0 +1,207 @@
+package congestion
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBR Sender", func() {
+	const (
+		packetSize = protocol.ByteCount(1200)
+		bandwidth  = 10_000_000 * BitsPerSecond
+		rtt        = 50 * time.Millisecond
+		// the bandwidth-delay product of the simulated path
+		bdp = protocol.ByteCount(bandwidth / BytesPerSecond * Bandwidth(rtt) / Bandwidth(time.Second))
+	)
+
+	type simulatedPacket struct {
+		pn       protocol.PacketNumber
+		sentTime time.Time
+		ackTime  time.Time
+		lost     bool
+	}
+
+	var (
+		sender        *bbrSender
+		clock         mockClock
+		rttStats      *utils.RTTStats
+		bytesInFlight protocol.ByteCount
+		packetNumber  protocol.PacketNumber
+		inFlight      []*simulatedPacket
+		linkFreeAt    time.Time
+		// the modes the sender was in during the simulation
+		modes       map[bbrMode]bool
+		sawRecovery bool
+	)
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		sender = NewBBRSender(&clock, rttStats, packetSize, nil)
+		bytesInFlight = 0
+		packetNumber = 0
+		inFlight = nil
+		linkFreeAt = time.Time{}
+		modes = make(map[bbrMode]bool)
+		sawRecovery = false
+	})
+
+	// simulate simulates sending over a path with a bottleneck link and an unlimited buffer.
+	// A fraction lossRate of the packets is randomly dropped.
+	// The application provides data at appRate. If appRate is 0, the application always has data to send.
+	simulate := func(duration time.Duration, lossRate float64, appRate Bandwidth) {
+		end := clock.Now().Add(duration)
+		var appBudget protocol.ByteCount
+		for clock.Now().Before(end) {
+			now := clock.Now()
+			for len(inFlight) > 0 && !inFlight[0].ackTime.After(now) {
+				p := inFlight[0]
+				inFlight = inFlight[1:]
+				if p.lost {
+					sender.OnPacketLost(p.pn, packetSize, bytesInFlight)
+				} else {
+					rttStats.UpdateRTT(now.Sub(p.sentTime), 0, now)
+					sender.MaybeExitSlowStart()
+					sender.OnPacketAcked(p.pn, packetSize, bytesInFlight, now)
+				}
+				bytesInFlight -= packetSize
+			}
+			if appRate != 0 {
+				appBudget += protocol.ByteCount(appRate/BytesPerSecond) / 1000
+			}
+			for sender.CanSend(bytesInFlight) && sender.HasPacingBudget() {
+				if appRate != 0 && appBudget < packetSize {
+					sender.OnApplicationLimited(bytesInFlight)
+					break
+				}
+				appBudget -= utils.MinByteCount(appBudget, packetSize)
+				packetNumber++
+				bytesInFlight += packetSize
+				sender.OnPacketSent(now, bytesInFlight, packetNumber, packetSize, true)
+				// The packet is queued at the bottleneck link.
+				departure := utils.MaxTime(linkFreeAt, now).Add(time.Duration(packetSize) * time.Second / time.Duration(bandwidth/BytesPerSecond))
+				linkFreeAt = departure
+				inFlight = append(inFlight, &simulatedPacket{
+					pn:       packetNumber,
+					sentTime: now,
+					ackTime:  departure.Add(rtt),
+					lost:     rand.Float64() < lossRate,
+				})
+			}
+			modes[sender.mode] = true
+			if sender.InRecovery() {
+				sawRecovery = true
+			}
+			clock.Advance(time.Millisecond)
+		}
+	}
+
+	It("has the right values at startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		Expect(sender.CanSend(0)).To(BeTrue())
+		Expect(sender.HasPacingBudget()).To(BeTrue())
+		Expect(sender.TimeUntilSend(0)).To(BeZero())
+	})
+
+	It("paces packets during startup", func() {
+		for sender.HasPacingBudget() {
+			packetNumber++
+			bytesInFlight += packetSize
+			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, packetSize, true)
+		}
+		Expect(sender.CanSend(bytesInFlight)).To(BeTrue())
+		Expect(sender.TimeUntilSend(bytesInFlight)).To(BeTemporally(">", clock.Now()))
+	})
+
+	It("estimates the bandwidth and the minimum RTT", func() {
+		simulate(5*time.Second, 0, 0)
+		Expect(modes).To(HaveKey(bbrModeStartup))
+		Expect(modes).To(HaveKey(bbrModeDrain))
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", bandwidth, bandwidth/10))
+		Expect(sender.minRTT).To(BeNumerically("~", rtt, 2*time.Millisecond))
+		Expect(sawRecovery).To(BeFalse())
+	})
+
+	It("keeps the queue at the bottleneck small", func() {
+		simulate(5*time.Second, 0, 0)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", 3*bdp))
+		// the queue drains once every gain cycle, so the RTT doesn't grow significantly
+		Expect(rttStats.SmoothedRTT()).To(BeNumerically("<", 2*rtt))
+	})
+
+	It("tolerates random packet loss", func() {
+		simulate(5*time.Second, 0.01, 0)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically(">", bandwidth*8/10))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", bdp))
+	})
+
+	It("reacts to excessive packet loss", func() {
+		simulate(time.Second, 0.2, 0)
+		Expect(sawRecovery).To(BeTrue())
+		Expect(sender.fullBandwidthReached).To(BeTrue())
+		Expect(sender.InSlowStart()).To(BeFalse())
+	})
+
+	It("periodically enters PROBE_RTT", func() {
+		simulate(5*time.Second, 0, 0)
+		Expect(modes).ToNot(HaveKey(bbrModeProbeRTT))
+		simulate(7*time.Second, 0, 0)
+		Expect(modes).To(HaveKey(bbrModeProbeRTT))
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", bandwidth, bandwidth/10))
+	})
+
+	It("uses the minimum congestion window in PROBE_RTT", func() {
+		sender.mode = bbrModeProbeRTT
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * packetSize))
+	})
+
+	It("doesn't count spuriously lost packets towards the loss rate", func() {
+		sender.lostInRound = 3 * packetSize
+		sender.OnSpuriousLoss(1, packetSize)
+		Expect(sender.lostInRound).To(Equal(2 * packetSize))
+		sender.lostInRound = 0
+		sender.OnSpuriousLoss(2, packetSize)
+		Expect(sender.lostInRound).To(BeZero())
+	})
+
+	It("collapses the congestion window on persistent congestion", func() {
+		simulate(3*time.Second, 0, 0)
+		bw := sender.BandwidthEstimate()
+		sender.OnPersistentCongestion()
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * packetSize))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.BandwidthEstimate()).To(Equal(bw))
+		// the congestion window grows again once packets are acknowledged
+		simulate(time.Second, 0, 0)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", bdp))
+	})
+
+	It("doesn't reduce the bandwidth estimate when application-limited", func() {
+		simulate(3*time.Second, 0, 0)
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", bandwidth, bandwidth/10))
+		simulate(3*time.Second, 0, bandwidth/10)
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", bandwidth, bandwidth/10))
+	})
+
+	It("increases the congestion window when the max datagram size increases", func() {
+		sender.congestionWindow = sender.minCongestionWindow()
+		sender.SetMaxDatagramSize(packetSize + 100)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * (packetSize + 100)))
+	})
+
+	It("doesn't allow reductions of the maximum packet size", func() {
+		Expect(func() { sender.SetMaxDatagramSize(packetSize - 1) }).To(Panic())
+	})
+})
@@ -21,9 +21,11 @@ const (
 
 type cubicSender struct {
 	hybridSlowStart HybridSlowStart
-	rttStats        *utils.RTTStats
+	// If set, HyStart++ is used instead of the hybrid slow start.
+	hyStartPlusPlus *hyStartPlusPlus
+	rttStats        RTTStats
 	cubic           *Cubic
-	pacer           *pacer
+	pacer           *Pacer
 	clock           Clock
 
 	reno bool
@@ -50,6 +52,16 @@ type cubicSender struct {
 	// ACK counter for the Reno implementation.
 	numAckedPackets uint64
 
+	// The state before the last congestion window reduction.
+	// If all packets declared lost in the recovery period turn out to be lost spuriously, the reduction is undone.
+	// undoCongestionWindow is 0 if the reduction can't be undone.
+	undoCongestionWindow         protocol.ByteCount
+	undoSlowStartThreshold       protocol.ByteCount
+	undoLargestSentAtLastCutback protocol.PacketNumber
+	undoCubic                    Cubic
+	// the number of packets declared lost in the recovery period that haven't been acknowledged (yet)
+	undoNumLostPackets int
+
 	initialCongestionWindow    protocol.ByteCount
 	initialMaxCongestionWindow protocol.ByteCount
 
@@ -62,17 +74,19 @@ type cubicSender struct {
 var (
 	_ SendAlgorithm               = &cubicSender{}
 	_ SendAlgorithmWithDebugInfos = &cubicSender{}
+	_ SendAlgorithmWithHandlers   = &cubicSender{}
 )
 
 // NewCubicSender makes a new cubic sender
 func NewCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	initialMaxDatagramSize protocol.ByteCount,
 	reno bool,
+	useHyStartPlusPlus bool,
 	tracer logging.ConnectionTracer,
 ) *cubicSender {
-	return newCubicSender(
+	c := newCubicSender(
 		clock,
 		rttStats,
 		reno,
@@ -81,11 +95,15 @@ func NewCubicSender(
 		protocol.MaxCongestionWindowPackets*initialMaxDatagramSize,
 		tracer,
 	)
+	if useHyStartPlusPlus {
+		c.hyStartPlusPlus = newHyStartPlusPlus()
+	}
+	return c
 }
 
 func newCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	reno bool,
 	initialMaxDatagramSize,
 	initialCongestionWindow,
@@ -107,7 +125,7 @@ func newCubicSender(
 		tracer:                     tracer,
 		maxDatagramSize:            initialMaxDatagramSize,
 	}
-	c.pacer = newPacer(c.BandwidthEstimate)
+	c.pacer = NewPacer(c.BandwidthEstimate)
 	if c.tracer != nil {
 		c.lastState = logging.CongestionStateSlowStart
 		c.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
@@ -144,6 +162,10 @@ func (c *cubicSender) OnPacketSent(
 		return
 	}
 	c.largestSentPacketNumber = packetNumber
+	if c.hyStartPlusPlus != nil {
+		c.hyStartPlusPlus.OnPacketSent(packetNumber)
+		return
+	}
 	c.hybridSlowStart.OnPacketSent(packetNumber)
 }
 
@@ -164,6 +186,14 @@ func (c *cubicSender) GetCongestionWindow() protocol.ByteCount {
 }
 
 func (c *cubicSender) MaybeExitSlowStart() {
+	if c.hyStartPlusPlus != nil {
+		// HyStart++ uses the RTT samples to decide when to enter and leave Conservative Slow Start.
+		if c.InSlowStart() && !c.InRecovery() {
+			c.hyStartPlusPlus.OnRTTSample(c.rttStats.LatestRTT())
+			c.maybeTraceStateChange(c.slowStartState())
+		}
+		return
+	}
 	if c.InSlowStart() &&
 		c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/c.maxDatagramSize) {
 		// exit slow start
@@ -183,17 +213,64 @@ func (c *cubicSender) OnPacketAcked(
 		return
 	}
 	c.maybeIncreaseCwnd(ackedPacketNumber, ackedBytes, priorInFlight, eventTime)
-	if c.InSlowStart() {
+	if !c.InSlowStart() {
+		return
+	}
+	if c.hyStartPlusPlus == nil {
 		c.hybridSlowStart.OnPacketAcked(ackedPacketNumber)
+		return
+	}
+	c.hyStartPlusPlus.OnPacketAcked(ackedPacketNumber)
+	if c.hyStartPlusPlus.ShouldExitSlowStart() {
+		c.slowStartThreshold = c.congestionWindow
+		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
 	}
 }
 
+func (c *cubicSender) restartSlowStart() {
+	c.hybridSlowStart.Restart()
+	if c.hyStartPlusPlus != nil {
+		c.hyStartPlusPlus.Restart()
+	}
+}
+
+// slowStartState is the congestion state while in slow start.
+func (c *cubicSender) slowStartState() logging.CongestionState {
+	if c.hyStartPlusPlus != nil && c.hyStartPlusPlus.InConservativeSlowStart() {
+		return logging.CongestionStateConservativeSlowStart
+	}
+	return logging.CongestionStateSlowStart
+}
+
 func (c *cubicSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
 	// TCP NewReno (RFC6582) says that once a loss occurs, any losses in packets
 	// already sent should be treated as a single loss event, since it's expected.
 	if packetNumber <= c.largestSentAtLastCutback {
+		if c.undoCongestionWindow != 0 && packetNumber > c.undoLargestSentAtLastCutback {
+			c.undoNumLostPackets++
+		}
 		return
 	}
+	c.undoCongestionWindow = c.congestionWindow
+	c.undoSlowStartThreshold = c.slowStartThreshold
+	c.undoLargestSentAtLastCutback = c.largestSentAtLastCutback
+	c.undoCubic = *c.cubic
+	c.undoNumLostPackets = 1
+	c.reduceCongestionWindow()
+}
+
+// OnECNCongestion is called when the peer reports that packets were marked ECN-CE.
+// The congestion window is reduced in the same way as for a packet loss, once per recovery period.
+// Unlike a reduction caused by a packet loss, this reduction can't turn out to be spurious.
+func (c *cubicSender) OnECNCongestion(largestAcked protocol.PacketNumber, _ protocol.ByteCount) {
+	if largestAcked <= c.largestSentAtLastCutback {
+		return
+	}
+	c.undoCongestionWindow = 0
+	c.reduceCongestionWindow()
+}
+
+func (c *cubicSender) reduceCongestionWindow() {
 	c.lastCutbackExitedSlowstart = c.InSlowStart()
 	c.maybeTraceStateChange(logging.CongestionStateRecovery)
 
@@ -232,8 +309,13 @@ func (c *cubicSender) maybeIncreaseCwnd(
 	}
 	if c.InSlowStart() {
 		// TCP slow start, exponential growth, increase by one for each ACK.
-		c.congestionWindow += c.maxDatagramSize
-		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+		// In HyStart++'s Conservative Slow Start, the growth is slowed down.
+		if c.hyStartPlusPlus != nil {
+			c.congestionWindow += c.maxDatagramSize / c.hyStartPlusPlus.GrowthDivisor()
+		} else {
+			c.congestionWindow += c.maxDatagramSize
+		}
+		c.maybeTraceStateChange(c.slowStartState())
 		return
 	}
 	// Congestion avoidance
@@ -270,25 +352,70 @@ func (c *cubicSender) BandwidthEstimate() Bandwidth {
 	return BandwidthFromDelta(c.GetCongestionWindow(), srtt)
 }
 
+// OnSpuriousLoss is called when a packet that was declared lost is acknowledged.
+// Once all packets that were declared lost in the current recovery period have been acknowledged,
+// the congestion window reduction is undone.
+func (c *cubicSender) OnSpuriousLoss(packetNumber protocol.PacketNumber, _ protocol.ByteCount) {
+	if c.undoCongestionWindow == 0 || packetNumber <= c.undoLargestSentAtLastCutback || packetNumber > c.largestSentAtLastCutback {
+		return
+	}
+	c.undoNumLostPackets--
+	if c.undoNumLostPackets > 0 {
+		return
+	}
+	c.congestionWindow = utils.MaxByteCount(c.congestionWindow, c.undoCongestionWindow)
+	c.slowStartThreshold = utils.MaxByteCount(c.slowStartThreshold, c.undoSlowStartThreshold)
+	c.largestSentAtLastCutback = c.undoLargestSentAtLastCutback
+	*c.cubic = c.undoCubic
+	c.undoCongestionWindow = 0
+	if c.InSlowStart() {
+		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+	} else {
+		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
+	}
+}
+
+// OnPersistentCongestion is called when persistent congestion was detected.
+// The congestion window is collapsed to the minimum congestion window (RFC 9002, Section 7.6.2).
+func (c *cubicSender) OnPersistentCongestion() {
+	c.undoCongestionWindow = 0
+	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	c.restartSlowStart()
+	c.cubic.Reset()
+	c.numAckedPackets = 0
+	c.congestionWindow = c.minCongestionWindow()
+	if c.InSlowStart() {
+		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+	} else {
+		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
+	}
+}
+
 // OnRetransmissionTimeout is called on an retransmission timeout
 func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	c.undoCongestionWindow = 0
 	if !packetsRetransmitted {
 		return
 	}
-	c.hybridSlowStart.Restart()
+	c.restartSlowStart()
 	c.cubic.Reset()
 	c.slowStartThreshold = c.congestionWindow / 2
 	c.congestionWindow = c.minCongestionWindow()
 }
 
+// OnApplicationLimited is called when the connection doesn't have any data to send.
+// The cubicSender doesn't need to be notified, it detects application-limited periods when processing acknowledgments.
+func (c *cubicSender) OnApplicationLimited(protocol.ByteCount) {}
+
 // OnConnectionMigration is called when the connection is migrated (?)
 func (c *cubicSender) OnConnectionMigration() {
-	c.hybridSlowStart.Restart()
+	c.restartSlowStart()
 	c.largestSentPacketNumber = protocol.InvalidPacketNumber
 	c.largestAckedPacketNumber = protocol.InvalidPacketNumber
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
 	c.lastCutbackExitedSlowstart = false
+	c.undoCongestionWindow = 0
 	c.cubic.Reset()
 	c.numAckedPackets = 0
 	c.congestionWindow = c.initialCongestionWindow
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -315,6 +316,142 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 	})
 
+	It("undoes the congestion window reduction when all lost packets were lost spuriously", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		SendAvailableSendWindow()
+		cwnd := sender.GetCongestionWindow()
+		ssthresh := sender.slowStartThreshold
+		LosePacket(3)
+		LosePacket(4)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		sender.OnSpuriousLoss(3, maxDatagramSize)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		sender.OnSpuriousLoss(4, maxDatagramSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.slowStartThreshold).To(Equal(ssthresh))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.InSlowStart()).To(BeTrue())
+		// a loss after undoing the reduction leads to a new reduction
+		LosePacket(5)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+	})
+
+	It("doesn't undo the congestion window reduction if some packets were actually lost", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		SendAvailableSendWindow()
+		LosePacket(3)
+		LosePacket(4)
+		cwnd := sender.GetCongestionWindow()
+		sender.OnSpuriousLoss(4, maxDatagramSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InRecovery()).To(BeTrue())
+	})
+
+	It("doesn't undo the congestion window reduction for packets lost in an earlier recovery period", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		LosePacket(3)
+		// exit recovery
+		SendAvailableSendWindow()
+		AckNPackets(int(packetNumber - 1 - ackedPacketNumber))
+		Expect(sender.InRecovery()).To(BeFalse())
+		SendAvailableSendWindow()
+		LosePacket(packetNumber - 1)
+		cwnd := sender.GetCongestionWindow()
+		sender.OnSpuriousLoss(3, maxDatagramSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InRecovery()).To(BeTrue())
+	})
+
+	It("reduces the congestion window when packets are CE-marked", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		cwnd := sender.GetCongestionWindow()
+		sender.OnECNCongestion(ackedPacketNumber, bytesInFlight)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * renoBeta)))
+		// CE marks for packets sent before the reduction don't lead to another reduction
+		AckNPackets(1)
+		sender.OnECNCongestion(ackedPacketNumber, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * renoBeta)))
+		// neither do packet losses
+		LosePacket(packetNumber - 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * renoBeta)))
+		// the reduction is never undone
+		sender.OnSpuriousLoss(packetNumber-1, maxDatagramSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * renoBeta)))
+		// CE marks for packets sent after the reduction lead to another reduction
+		cwnd = sender.GetCongestionWindow()
+		SendAvailableSendWindow()
+		sender.OnECNCongestion(packetNumber-1, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+	})
+
+	It("collapses the congestion window on persistent congestion", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		SendAvailableSendWindow()
+		LoseNPackets(1)
+		Expect(sender.InRecovery()).To(BeTrue())
+		ssthresh := sender.slowStartThreshold
+
+		sender.OnPersistentCongestion()
+		Expect(sender.GetCongestionWindow()).To(Equal(2 * maxDatagramSize))
+		Expect(sender.slowStartThreshold).To(Equal(ssthresh))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.InSlowStart()).To(BeTrue())
+	})
+
+	It("uses HyStart++", func() {
+		sender.hyStartPlusPlus = newHyStartPlusPlus()
+		// ackWithRTT acknowledges one packet, and then sends as many packets as the congestion window allows.
+		ackWithRTT := func(rtt time.Duration) {
+			rttStats.UpdateRTT(rtt, 0, clock.Now())
+			sender.MaybeExitSlowStart()
+			ackedPacketNumber++
+			sender.OnPacketAcked(ackedPacketNumber, maxDatagramSize, bytesInFlight, clock.Now())
+			bytesInFlight -= maxDatagramSize
+			SendAvailableSendWindow()
+		}
+		SendAvailableSendWindow()
+		// In slow start, the congestion window grows by one packet for every ACK.
+		for i := 0; i < 20; i++ {
+			cwnd := sender.GetCongestionWindow()
+			ackWithRTT(60 * time.Millisecond)
+			Expect(sender.GetCongestionWindow()).To(Equal(cwnd + maxDatagramSize))
+		}
+		Expect(sender.slowStartState()).To(Equal(logging.CongestionStateSlowStart))
+		// The RTT increases, and HyStart++ enters Conservative Slow Start.
+		for !sender.hyStartPlusPlus.InConservativeSlowStart() {
+			ackWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.slowStartState()).To(Equal(logging.CongestionStateConservativeSlowStart))
+		cwnd := sender.GetCongestionWindow()
+		for i := 0; i < 4; i++ {
+			ackWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd + maxDatagramSize))
+		// After a few rounds in Conservative Slow Start, slow start is left.
+		for sender.InSlowStart() {
+			Expect(ackedPacketNumber).To(BeNumerically("<", 10000))
+			ackWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.slowStartThreshold).To(Equal(sender.GetCongestionWindow()))
+	})
+
+	It("restarts HyStart++ after a retransmission timeout", func() {
+		sender.hyStartPlusPlus = newHyStartPlusPlus()
+		sender.hyStartPlusPlus.inCSS = true
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.hyStartPlusPlus.InConservativeSlowStart()).To(BeFalse())
+	})
+
 	It("tcp cubic reset epoch on quiescence", func() {
 		const maxCongestionWindow = 50
 		const maxCongestionWindowBytes = maxCongestionWindow * maxDatagramSize
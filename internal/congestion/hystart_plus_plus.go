@@ -0,0 +1,135 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+const (
+	// The bounds of the RTT increase that causes HyStart++ to enter Conservative Slow Start.
+	hyStartPlusPlusMinRTTThresh = 4 * time.Millisecond
+	hyStartPlusPlusMaxRTTThresh = 16 * time.Millisecond
+	// The RTT increase threshold is the RTT of the last round divided by this value.
+	hyStartPlusPlusMinRTTDivisor = 8
+	// The number of RTT samples per round that are needed to make a decision.
+	hyStartPlusPlusNRTTSample = 8
+	// In Conservative Slow Start, the congestion window grows this many times slower than in slow start.
+	hyStartPlusPlusCSSGrowthDivisor = 4
+	// The number of rounds spent in Conservative Slow Start before entering congestion avoidance.
+	hyStartPlusPlusCSSRounds = 5
+)
+
+// hyStartPlusPlus implements HyStart++ (RFC 9406).
+// When the RTT increases during slow start, it enters Conservative Slow Start (CSS), which grows the
+// congestion window more slowly. If the RTT increase turns out to be spurious, slow start is resumed,
+// otherwise congestion avoidance is entered after a few rounds.
+// Unlike the Chromium hybrid slow start, this avoids exiting slow start prematurely due to RTT jitter.
+// Since the congestion controllers in this package pace packets, the growth of the congestion window is not limited per ACK.
+type hyStartPlusPlus struct {
+	// A round ends when a packet sent after the start of the round is acknowledged.
+	windowEnd            protocol.PacketNumber
+	lastSentPacketNumber protocol.PacketNumber
+
+	lastRoundMinRTT    time.Duration // 0 if there's no RTT sample from the last round
+	currentRoundMinRTT time.Duration // 0 if there's no RTT sample from the current round
+	rttSampleCount     int
+
+	// Conservative Slow Start
+	inCSS             bool
+	cssBaselineMinRTT time.Duration
+	cssRounds         int
+	// set once CSS lasted for hyStartPlusPlusCSSRounds rounds
+	exitSlowStart bool
+}
+
+func newHyStartPlusPlus() *hyStartPlusPlus {
+	return &hyStartPlusPlus{
+		windowEnd:            protocol.InvalidPacketNumber,
+		lastSentPacketNumber: protocol.InvalidPacketNumber,
+	}
+}
+
+// OnPacketSent is called when a packet was sent.
+func (h *hyStartPlusPlus) OnPacketSent(pn protocol.PacketNumber) {
+	h.lastSentPacketNumber = pn
+}
+
+// OnPacketAcked is called for every acknowledged packet, while in slow start.
+// It is called after OnRTTSample.
+func (h *hyStartPlusPlus) OnPacketAcked(pn protocol.PacketNumber) {
+	if h.windowEnd != protocol.InvalidPacketNumber && pn <= h.windowEnd {
+		return
+	}
+	// start a new round
+	h.windowEnd = h.lastSentPacketNumber
+	h.lastRoundMinRTT = h.currentRoundMinRTT
+	h.currentRoundMinRTT = 0
+	h.rttSampleCount = 0
+	if h.inCSS {
+		h.cssRounds++
+		if h.cssRounds >= hyStartPlusPlusCSSRounds {
+			h.exitSlowStart = true
+		}
+	}
+}
+
+// OnRTTSample is called when a new RTT sample was obtained, while in slow start.
+func (h *hyStartPlusPlus) OnRTTSample(latestRTT time.Duration) {
+	if h.currentRoundMinRTT == 0 || latestRTT < h.currentRoundMinRTT {
+		h.currentRoundMinRTT = latestRTT
+	}
+	h.rttSampleCount++
+	if h.rttSampleCount < hyStartPlusPlusNRTTSample {
+		return
+	}
+	if h.inCSS {
+		// The RTT increase was spurious. Resume slow start.
+		if h.currentRoundMinRTT < h.cssBaselineMinRTT {
+			h.inCSS = false
+			h.cssBaselineMinRTT = 0
+			h.cssRounds = 0
+		}
+		return
+	}
+	if h.lastRoundMinRTT == 0 {
+		return
+	}
+	rttThresh := utils.MaxDuration(hyStartPlusPlusMinRTTThresh, utils.MinDuration(h.lastRoundMinRTT/hyStartPlusPlusMinRTTDivisor, hyStartPlusPlusMaxRTTThresh))
+	if h.currentRoundMinRTT >= h.lastRoundMinRTT+rttThresh {
+		h.inCSS = true
+		h.cssBaselineMinRTT = h.currentRoundMinRTT
+		h.cssRounds = 0
+	}
+}
+
+// InConservativeSlowStart says if HyStart++ is in Conservative Slow Start.
+func (h *hyStartPlusPlus) InConservativeSlowStart() bool {
+	return h.inCSS
+}
+
+// GrowthDivisor is the factor by which the growth of the congestion window is reduced, compared to slow start.
+func (h *hyStartPlusPlus) GrowthDivisor() protocol.ByteCount {
+	if h.inCSS {
+		return hyStartPlusPlusCSSGrowthDivisor
+	}
+	return 1
+}
+
+// ShouldExitSlowStart says if slow start should be left and congestion avoidance should be entered.
+func (h *hyStartPlusPlus) ShouldExitSlowStart() bool {
+	return h.exitSlowStart
+}
+
+// Restart is called when slow start is entered again.
+func (h *hyStartPlusPlus) Restart() {
+	h.windowEnd = protocol.InvalidPacketNumber
+	h.lastRoundMinRTT = 0
+	h.currentRoundMinRTT = 0
+	h.rttSampleCount = 0
+	h.inCSS = false
+	h.cssBaselineMinRTT = 0
+	h.cssRounds = 0
+	h.exitSlowStart = false
+}
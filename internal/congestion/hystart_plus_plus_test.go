@@ -0,0 +1,120 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HyStart++", func() {
+	var (
+		h  *hyStartPlusPlus
+		pn protocol.PacketNumber
+	)
+
+	BeforeEach(func() {
+		h = newHyStartPlusPlus()
+		pn = 0
+	})
+
+	// sendAndAckRound sends 10 packets, and then acknowledges all of them, with the given RTT.
+	sendAndAckRound := func(rtt time.Duration) {
+		first := pn + 1
+		for i := 0; i < 10; i++ {
+			pn++
+			h.OnPacketSent(pn)
+		}
+		for p := first; p <= pn; p++ {
+			h.OnRTTSample(rtt)
+			h.OnPacketAcked(p)
+		}
+	}
+
+	It("starts in slow start", func() {
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+		Expect(h.GrowthDivisor()).To(Equal(protocol.ByteCount(1)))
+		Expect(h.ShouldExitSlowStart()).To(BeFalse())
+	})
+
+	It("doesn't enter Conservative Slow Start if the RTT increase is small", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		// the threshold is 100ms / 8 = 12.5ms
+		sendAndAckRound(112 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+	})
+
+	It("enters Conservative Slow Start when the RTT increases", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		sendAndAckRound(113 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+		Expect(h.GrowthDivisor()).To(Equal(protocol.ByteCount(hyStartPlusPlusCSSGrowthDivisor)))
+		Expect(h.ShouldExitSlowStart()).To(BeFalse())
+	})
+
+	It("uses the minimum RTT threshold", func() {
+		sendAndAckRound(10 * time.Millisecond)
+		sendAndAckRound(13 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+		sendAndAckRound(17 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("uses the maximum RTT threshold", func() {
+		sendAndAckRound(time.Second)
+		sendAndAckRound(time.Second + 16*time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("needs enough RTT samples", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		for i := 0; i < 10; i++ {
+			pn++
+			h.OnPacketSent(pn)
+		}
+		// The first ACK ends the previous round.
+		h.OnRTTSample(100 * time.Millisecond)
+		h.OnPacketAcked(pn - 9)
+		for i := 0; i < hyStartPlusPlusNRTTSample-1; i++ {
+			h.OnRTTSample(200 * time.Millisecond)
+			Expect(h.InConservativeSlowStart()).To(BeFalse())
+		}
+		h.OnRTTSample(200 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("resumes slow start if the RTT increase was spurious", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		sendAndAckRound(150 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+		sendAndAckRound(100 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+		Expect(h.GrowthDivisor()).To(Equal(protocol.ByteCount(1)))
+	})
+
+	It("exits slow start after a number of rounds in Conservative Slow Start", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		sendAndAckRound(150 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+		for i := 0; i < hyStartPlusPlusCSSRounds-1; i++ {
+			sendAndAckRound(150 * time.Millisecond)
+			Expect(h.ShouldExitSlowStart()).To(BeFalse())
+		}
+		sendAndAckRound(150 * time.Millisecond)
+		Expect(h.ShouldExitSlowStart()).To(BeTrue())
+	})
+
+	It("restarts", func() {
+		sendAndAckRound(100 * time.Millisecond)
+		sendAndAckRound(150 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeTrue())
+		h.Restart()
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+		Expect(h.ShouldExitSlowStart()).To(BeFalse())
+		// there's no RTT sample from the last round
+		sendAndAckRound(150 * time.Millisecond)
+		Expect(h.InConservativeSlowStart()).To(BeFalse())
+	})
+})
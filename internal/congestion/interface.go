@@ -16,6 +16,7 @@ type SendAlgorithm interface {
 	OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
 	OnPacketLost(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
 	OnRetransmissionTimeout(packetsRetransmitted bool)
+	OnApplicationLimited(bytesInFlight protocol.ByteCount)
 	SetMaxDatagramSize(protocol.ByteCount)
 }
 
@@ -26,3 +27,50 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A SpuriousLossHandler is a SendAlgorithm that wants to be notified of spurious losses.
+type SpuriousLossHandler interface {
+	OnSpuriousLoss(number protocol.PacketNumber, lostBytes protocol.ByteCount)
+}
+
+// An ECNCongestionHandler is a SendAlgorithm that reacts to ECN-CE marks.
+// ECN is only used if the SendAlgorithm implements this interface.
+type ECNCongestionHandler interface {
+	OnECNCongestion(largestAcked protocol.PacketNumber, priorInFlight protocol.ByteCount)
+}
+
+// A PersistentCongestionHandler is a SendAlgorithm that reacts to persistent congestion.
+type PersistentCongestionHandler interface {
+	OnPersistentCongestion()
+}
+
+// A SendAlgorithmWithHandlers is a SendAlgorithmWithDebugInfos that handles all optional congestion events.
+// The NewReno and CUBIC congestion controllers implement it.
+type SendAlgorithmWithHandlers interface {
+	SendAlgorithmWithDebugInfos
+	SpuriousLossHandler
+	ECNCongestionHandler
+	PersistentCongestionHandler
+}
+
+// RTTStats are the RTT statistics used by the congestion controllers.
+// It is implemented by *utils.RTTStats.
+type RTTStats interface {
+	MinRTT() time.Duration
+	LatestRTT() time.Duration
+	SmoothedRTT() time.Duration
+}
+
+// A PacketNumberSpaceAwareSendAlgorithm is a SendAlgorithm that keeps state for individual packets.
+// Packet numbers are only unique within a packet number space, so the sent packet handler passes it the encryption level
+// of every packet, by calling OnPacketSentAtLevel, OnPacketAckedAtLevel and OnPacketLostAtLevel
+// instead of OnPacketSent, OnPacketAcked and OnPacketLost.
+type PacketNumberSpaceAwareSendAlgorithm interface {
+	SendAlgorithmWithDebugInfos
+	OnPacketSentAtLevel(encLevel protocol.EncryptionLevel, sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
+	OnPacketAckedAtLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
+	OnPacketLostAtLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
+	// OnPacketsDropped is called when the packets sent at an encryption level are dropped without being acknowledged or declared lost,
+	// i.e. when the Initial or Handshake keys are dropped, when 0-RTT is rejected, or when a Retry is received.
+	OnPacketsDropped(encLevel protocol.EncryptionLevel)
+}
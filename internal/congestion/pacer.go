@@ -10,32 +10,40 @@ import (
 
 const maxBurstSizePackets = 10
 
-// The pacer implements a token bucket pacing algorithm.
-type pacer struct {
+// The Pacer implements a token bucket pacing algorithm.
+type Pacer struct {
 	budgetAtLastSent     protocol.ByteCount
 	maxDatagramSize      protocol.ByteCount
 	lastSentTime         time.Time
 	getAdjustedBandwidth func() uint64 // in bytes/s
 }
 
-func newPacer(getBandwidth func() Bandwidth) *pacer {
-	p := &pacer{
-		maxDatagramSize: initialMaxDatagramSize,
-		getAdjustedBandwidth: func() uint64 {
-			// Bandwidth is in bits/s. We need the value in bytes/s.
-			bw := uint64(getBandwidth() / BytesPerSecond)
-			// Use a slightly higher value than the actual measured bandwidth.
-			// RTT variations then won't result in under-utilization of the congestion window.
-			// Ultimately, this will  result in sending packets as acknowledgments are received rather than when timers fire,
-			// provided the congestion window is fully utilized and acknowledgments arrive at regular intervals.
-			return bw * 5 / 4
-		},
+// NewPacer creates a new Pacer.
+// getBandwidth returns the current bandwidth estimate of the congestion controller.
+func NewPacer(getBandwidth func() Bandwidth) *Pacer {
+	return newPacer(func() uint64 {
+		// Bandwidth is in bits/s. We need the value in bytes/s.
+		bw := uint64(getBandwidth() / BytesPerSecond)
+		// Use a slightly higher value than the actual measured bandwidth.
+		// RTT variations then won't result in under-utilization of the congestion window.
+		// Ultimately, this will  result in sending packets as acknowledgments are received rather than when timers fire,
+		// provided the congestion window is fully utilized and acknowledgments arrive at regular intervals.
+		return bw * 5 / 4
+	})
+}
+
+// newPacer creates a new Pacer that paces packets at exactly the rate returned by getPacingRate (in bytes/s).
+func newPacer(getPacingRate func() uint64) *Pacer {
+	p := &Pacer{
+		maxDatagramSize:      initialMaxDatagramSize,
+		getAdjustedBandwidth: getPacingRate,
 	}
 	p.budgetAtLastSent = p.maxBurstSize()
 	return p
 }
 
-func (p *pacer) SentPacket(sendTime time.Time, size protocol.ByteCount) {
+// SentPacket is called when a packet is sent.
+func (p *Pacer) SentPacket(sendTime time.Time, size protocol.ByteCount) {
 	budget := p.Budget(sendTime)
 	if size > budget {
 		p.budgetAtLastSent = 0
@@ -45,7 +53,8 @@ func (p *pacer) SentPacket(sendTime time.Time, size protocol.ByteCount) {
 	p.lastSentTime = sendTime
 }
 
-func (p *pacer) Budget(now time.Time) protocol.ByteCount {
+// Budget returns the number of bytes that can be sent right now.
+func (p *Pacer) Budget(now time.Time) protocol.ByteCount {
 	if p.lastSentTime.IsZero() {
 		return p.maxBurstSize()
 	}
@@ -53,7 +62,7 @@ func (p *pacer) Budget(now time.Time) protocol.ByteCount {
 	return utils.MinByteCount(p.maxBurstSize(), budget)
 }
 
-func (p *pacer) maxBurstSize() protocol.ByteCount {
+func (p *Pacer) maxBurstSize() protocol.ByteCount {
 	return utils.MaxByteCount(
 		protocol.ByteCount(uint64((protocol.MinPacingDelay+protocol.TimerGranularity).Nanoseconds())*p.getAdjustedBandwidth())/1e9,
 		maxBurstSizePackets*p.maxDatagramSize,
@@ -62,7 +71,7 @@ func (p *pacer) maxBurstSize() protocol.ByteCount {
 
 // TimeUntilSend returns when the next packet should be sent.
 // It returns the zero value of time.Time if a packet can be sent immediately.
-func (p *pacer) TimeUntilSend() time.Time {
+func (p *Pacer) TimeUntilSend() time.Time {
 	if p.budgetAtLastSent >= p.maxDatagramSize {
 		return time.Time{}
 	}
@@ -72,6 +81,7 @@ func (p *pacer) TimeUntilSend() time.Time {
 	))
 }
 
-func (p *pacer) SetMaxDatagramSize(s protocol.ByteCount) {
+// SetMaxDatagramSize sets the maximum size of a packet.
+func (p *Pacer) SetMaxDatagramSize(s protocol.ByteCount) {
 	p.maxDatagramSize = s
 }
@@ -0,0 +1,74 @@
+package congestion
+
+// A maxBandwidthFilter tracks the maximum bandwidth sample over a window of round trips.
+// It uses the windowed min/max algorithm by Kathleen Nichols, keeping track of the best,
+// the second best and the third best sample, which allows updating the maximum in constant time.
+type maxBandwidthFilter struct {
+	windowLength uint64 // in round trips
+	estimates    [3]bandwidthSample
+}
+
+type bandwidthSample struct {
+	bandwidth Bandwidth
+	round     uint64
+}
+
+func newMaxBandwidthFilter(windowLength uint64) *maxBandwidthFilter {
+	return &maxBandwidthFilter{windowLength: windowLength}
+}
+
+// Update adds a new bandwidth sample, measured in round trip round.
+func (f *maxBandwidthFilter) Update(bw Bandwidth, round uint64) {
+	sample := bandwidthSample{bandwidth: bw, round: round}
+	// Reset all estimates if the filter is empty, if the new sample is a new maximum,
+	// or if nothing was measured for a whole window.
+	if f.estimates[0].bandwidth == 0 || bw >= f.estimates[0].bandwidth || round-f.estimates[2].round > f.windowLength {
+		f.Reset(bw, round)
+		return
+	}
+	if bw >= f.estimates[1].bandwidth {
+		f.estimates[1] = sample
+		f.estimates[2] = sample
+	} else if bw >= f.estimates[2].bandwidth {
+		f.estimates[2] = sample
+	}
+
+	// Expire and update the estimates as necessary.
+	if round-f.estimates[0].round > f.windowLength {
+		// The best estimate hasn't been updated for an entire window, so promote the second and third best estimates.
+		f.estimates[0] = f.estimates[1]
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = sample
+		// Need to iterate one more time.
+		// Check if the new best estimate is outside the window as well,
+		// since it may also have been recorded a long time ago.
+		if round-f.estimates[0].round > f.windowLength {
+			f.estimates[0] = f.estimates[1]
+			f.estimates[1] = f.estimates[2]
+		}
+		return
+	}
+	if f.estimates[1].bandwidth == f.estimates[0].bandwidth && round-f.estimates[1].round > f.windowLength/4 {
+		// A quarter of the window has passed without a better sample, so the second best estimate is taken from the second quarter of the window.
+		f.estimates[1] = sample
+		f.estimates[2] = sample
+		return
+	}
+	if f.estimates[2].bandwidth == f.estimates[1].bandwidth && round-f.estimates[2].round > f.windowLength/2 {
+		// We've passed half of the window without a better estimate, so take a third best estimate from the second half of the window.
+		f.estimates[2] = sample
+	}
+}
+
+// Reset resets all estimates to a new sample.
+func (f *maxBandwidthFilter) Reset(bw Bandwidth, round uint64) {
+	sample := bandwidthSample{bandwidth: bw, round: round}
+	f.estimates[0] = sample
+	f.estimates[1] = sample
+	f.estimates[2] = sample
+}
+
+// GetBest returns the maximum bandwidth sample in the current window.
+func (f *maxBandwidthFilter) GetBest() Bandwidth {
+	return f.estimates[0].bandwidth
+}
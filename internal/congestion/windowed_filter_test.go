@@ -0,0 +1,55 @@
+package congestion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Max Bandwidth Filter", func() {
+	var f *maxBandwidthFilter
+
+	BeforeEach(func() {
+		f = newMaxBandwidthFilter(10)
+	})
+
+	It("is empty at the beginning", func() {
+		Expect(f.GetBest()).To(BeZero())
+	})
+
+	It("returns the maximum sample", func() {
+		f.Update(100, 1)
+		f.Update(300, 2)
+		f.Update(200, 3)
+		Expect(f.GetBest()).To(Equal(Bandwidth(300)))
+	})
+
+	It("expires the maximum after the window", func() {
+		f.Update(300, 1)
+		for i := uint64(2); i <= 11; i++ {
+			f.Update(200, i)
+			Expect(f.GetBest()).To(Equal(Bandwidth(300)))
+		}
+		f.Update(100, 12)
+		Expect(f.GetBest()).To(Equal(Bandwidth(200)))
+	})
+
+	It("uses the second best sample when the maximum expires", func() {
+		f.Update(300, 1)
+		f.Update(250, 5)
+		f.Update(100, 8)
+		f.Update(100, 12)
+		Expect(f.GetBest()).To(Equal(Bandwidth(250)))
+	})
+
+	It("resets all estimates if no sample was taken for a whole window", func() {
+		f.Update(300, 1)
+		f.Update(100, 20)
+		Expect(f.GetBest()).To(Equal(Bandwidth(100)))
+	})
+
+	It("resets", func() {
+		f.Update(300, 1)
+		f.Reset(100, 2)
+		Expect(f.GetBest()).To(Equal(Bandwidth(100)))
+	})
+})
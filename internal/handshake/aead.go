@@ -9,9 +9,40 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
-func createAEAD(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) cipher.AEAD {
-	key := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, "quic key", suite.KeyLen)
-	iv := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, "quic iv", suite.IVLen())
+// QUIC v2 uses different labels for deriving keys, see RFC 9369, Section 3.3.2.
+const (
+	hkdfLabelKeyV1 = "quic key"
+	hkdfLabelKeyV2 = "quicv2 key"
+	hkdfLabelIVV1  = "quic iv"
+	hkdfLabelIVV2  = "quicv2 iv"
+)
+
+func getKeyAndIVLabels(v protocol.VersionNumber) (keyLabel, ivLabel string) {
+	if v == protocol.Version2 {
+		return hkdfLabelKeyV2, hkdfLabelIVV2
+	}
+	return hkdfLabelKeyV1, hkdfLabelIVV1
+}
+
+func createAEAD(suite *qtls.CipherSuiteTLS13, trafficSecret []byte, v protocol.VersionNumber) cipher.AEAD {
+	keyLabel, ivLabel := getKeyAndIVLabels(v)
+	key := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, keyLabel, suite.KeyLen)
+	iv := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, ivLabel, suite.IVLen())
+	return suite.AEAD(key, iv)
+}
+
+// createPathAEAD creates the AEAD for a path when using multipath.
+// The nonce is formed by XORing the IV with the packet number and the path ID, which is encoded as a 32 bit integer
+// in front of the 64 bit packet number. Since the AEAD only takes the packet number, the path ID is XORed into the IV.
+func createPathAEAD(suite *qtls.CipherSuiteTLS13, trafficSecret []byte, pathID uint64, v protocol.VersionNumber) cipher.AEAD {
+	keyLabel, ivLabel := getKeyAndIVLabels(v)
+	key := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, keyLabel, suite.KeyLen)
+	iv := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, ivLabel, suite.IVLen())
+	var pathIDBytes [4]byte
+	binary.BigEndian.PutUint32(pathIDBytes[:], uint32(pathID))
+	for i, b := range pathIDBytes {
+		iv[len(iv)-12+i] ^= b
+	}
 	return suite.AEAD(key, iv)
 }
 
@@ -6,6 +6,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/tls"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -29,8 +30,8 @@ var _ = Describe("Long Header AEAD", func() {
 				aead, err := cipher.NewGCM(block)
 				Expect(err).ToNot(HaveOccurred())
 
-				return newLongHeaderSealer(aead, newHeaderProtector(cs, hpKey, true)),
-					newLongHeaderOpener(aead, newHeaderProtector(cs, hpKey, true))
+				return newLongHeaderSealer(aead, newHeaderProtector(cs, hpKey, true, protocol.Version1)),
+					newLongHeaderOpener(aead, newHeaderProtector(cs, hpKey, true, protocol.Version1))
 			}
 
 			Context("message encryption", func() {
@@ -150,7 +151,7 @@ var _ = Describe("Long Header AEAD", func() {
 		Expect(err).ToNot(HaveOccurred())
 		aead, err = cipher.NewGCM(block)
 		Expect(err).ToNot(HaveOccurred())
-		hp = newHeaderProtector(cipherSuites[0], hpKey, true)
+		hp = newHeaderProtector(cipherSuites[0], hpKey, true, protocol.Version1)
 	})
 
 	Context("for the server", func() {
@@ -196,3 +197,29 @@ var _ = Describe("Long Header AEAD", func() {
 		})
 	})
 })
+
+var _ = Describe("Path AEAD", func() {
+	It("uses the path ID and the packet number as the nonce", func() {
+		suite := cipherSuites[0]
+		secret := make([]byte, suite.Hash.Size())
+		rand.Read(secret)
+		keyLabel, ivLabel := getKeyAndIVLabels(protocol.Version1)
+		key := hkdfExpandLabel(suite.Hash, secret, []byte{}, keyLabel, suite.KeyLen)
+		iv := hkdfExpandLabel(suite.Hash, secret, []byte{}, ivLabel, suite.IVLen())
+		block, err := aes.NewCipher(key)
+		Expect(err).ToNot(HaveOccurred())
+		gcm, err := cipher.NewGCM(block)
+		Expect(err).ToNot(HaveOccurred())
+		nonce := make([]byte, 12)
+		binary.BigEndian.PutUint32(nonce[:4], 0xdeadbeef)
+		binary.BigEndian.PutUint64(nonce[4:], 0x1337)
+		for i := range nonce {
+			nonce[i] ^= iv[i]
+		}
+
+		aead := createPathAEAD(suite, secret, 0xdeadbeef, protocol.Version1)
+		pn := make([]byte, 8)
+		binary.BigEndian.PutUint64(pn, 0x1337)
+		Expect(aead.Seal(nil, pn, []byte("foobar"), []byte("ad"))).To(Equal(gcm.Seal(nil, nonce, []byte("foobar"), []byte("ad"))))
+	})
+})
@@ -2,6 +2,7 @@ package handshake
 
 import (
 	"bytes"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -93,7 +94,12 @@ type cryptoSetup struct {
 	extraConf *qtls.ExtraConfig
 	conn      *qtls.Conn
 
-	version protocol.VersionNumber
+	// The version might change during the handshake, if compatible version negotiation is used.
+	// 0-RTT packets always use the initial version.
+	version        protocol.VersionNumber
+	initialVersion protocol.VersionNumber
+	// the connection ID used to derive the Initial keys
+	initialConnID protocol.ConnectionID
 
 	messageChan               chan []byte
 	isReadingHandshakeMessage chan struct{}
@@ -113,10 +119,14 @@ type cryptoSetup struct {
 
 	zeroRTTParameters      *wire.TransportParameters
 	clientHelloWritten     bool
+	versionChanged         bool // only used by the server
 	clientHelloWrittenChan chan *wire.TransportParameters
 
 	rttStats *utils.RTTStats
 
+	// only used by the server, may be nil
+	allow0RTT func(ticketID []byte, issued time.Time) bool
+
 	tracer logging.ConnectionTracer
 	logger utils.Logger
 
@@ -195,6 +205,7 @@ func NewCryptoSetupServer(
 	runner handshakeRunner,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	allow0RTT func(ticketID []byte, issued time.Time) bool,
 	rttStats *utils.RTTStats,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
@@ -214,6 +225,7 @@ func NewCryptoSetupServer(
 		protocol.PerspectiveServer,
 		version,
 	)
+	cs.allow0RTT = allow0RTT
 	cs.conn = qtls.Server(newConn(localAddr, remoteAddr, version), cs.tlsConf, cs.extraConf)
 	return cs
 }
@@ -237,19 +249,17 @@ func newCryptoSetup(
 		tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveClient)
 		tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
 	}
-	extHandler := newExtensionHandler(tp.Marshal(perspective), perspective, version)
 	cs := &cryptoSetup{
 		tlsConf:                   tlsConf,
 		initialStream:             initialStream,
 		initialSealer:             initialSealer,
 		initialOpener:             initialOpener,
 		handshakeStream:           handshakeStream,
-		aead:                      newUpdatableAEAD(rttStats, tracer, logger),
+		aead:                      newUpdatableAEAD(rttStats, tracer, logger, version),
 		readEncLevel:              protocol.EncryptionInitial,
 		writeEncLevel:             protocol.EncryptionInitial,
 		runner:                    runner,
 		ourParams:                 tp,
-		paramsChan:                extHandler.TransportParameters(),
 		rttStats:                  rttStats,
 		tracer:                    tracer,
 		logger:                    logger,
@@ -261,7 +271,15 @@ func newCryptoSetup(
 		isReadingHandshakeMessage: make(chan struct{}),
 		closeChan:                 make(chan struct{}),
 		version:                   version,
+		initialVersion:            version,
+		initialConnID:             connID,
+	}
+	var handleClientParams func([]byte) []byte
+	if perspective == protocol.PerspectiveServer {
+		handleClientParams = cs.chooseVersion
 	}
+	extHandler := newExtensionHandler(tp.Marshal(perspective), perspective, version, handleClientParams)
+	cs.paramsChan = extHandler.TransportParameters()
 	var maxEarlyData uint32
 	if enable0RTT {
 		maxEarlyData = 0xffffffff
@@ -282,15 +300,64 @@ func newCryptoSetup(
 }
 
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
-	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
-	h.initialSealer = initialSealer
-	h.initialOpener = initialOpener
+	h.mutex.Lock()
+	h.initialConnID = id
+	h.initialSealer, h.initialOpener = NewInitialAEAD(id, h.perspective, h.version)
+	h.mutex.Unlock()
+	if h.tracer != nil {
+		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveClient)
+		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
+	}
+}
+
+// ChangeVersion is called by the client when the server switched to a compatible version.
+// It derives new Initial keys, and the new version will be used for all keys derived from now on.
+func (h *cryptoSetup) ChangeVersion(v protocol.VersionNumber) {
+	h.setVersion(v)
+}
+
+func (h *cryptoSetup) setVersion(v protocol.VersionNumber) {
+	h.mutex.Lock()
+	h.version = v
+	h.aead.version = v
+	h.initialSealer, h.initialOpener = NewInitialAEAD(h.initialConnID, h.perspective, v)
+	h.mutex.Unlock()
 	if h.tracer != nil {
 		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveClient)
 		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
 	}
 }
 
+// chooseVersion is called by the server when receiving the client's transport parameters.
+// It performs compatible version negotiation (RFC 9368): If the client supports a version that we prefer
+// over the current version, and that is compatible with it, the connection is switched to that version.
+// It returns the transport parameters that are sent to the client, or nil if the version wasn't changed.
+func (h *cryptoSetup) chooseVersion(data []byte) []byte {
+	if h.ourParams.VersionInformation == nil {
+		return nil
+	}
+	var tp wire.TransportParameters
+	// Invalid transport parameters are rejected when handling them.
+	if err := tp.Unmarshal(data, protocol.PerspectiveClient); err != nil || tp.VersionInformation == nil {
+		return nil
+	}
+	v, ok := protocol.ChooseCompatibleVersion(h.ourParams.VersionInformation.AvailableVersions, tp.VersionInformation.AvailableVersions, h.version)
+	if !ok || v == h.version {
+		return nil
+	}
+	h.logger.Debugf("Switching to QUIC version %s (compatible version negotiation).", v)
+	h.setVersion(v)
+	h.versionChanged = true
+
+	params := *h.ourParams
+	params.VersionInformation = &wire.VersionInformation{
+		ChosenVersion:     v,
+		AvailableVersions: h.ourParams.VersionInformation.AvailableVersions,
+	}
+	h.ourParams = &params
+	return params.Marshal(protocol.PerspectiveServer)
+}
+
 func (h *cryptoSetup) SetLargest1RTTAcked(pn protocol.PacketNumber) error {
 	return h.aead.SetLargestAcked(pn)
 }
@@ -409,6 +476,11 @@ func (h *cryptoSetup) handleTransportParameters(data []byte) {
 		})
 	}
 	h.peerParams = &tp
+	// chooseVersion was called on the TLS go routine, before the transport parameters were sent on the channel
+	if h.versionChanged {
+		h.versionChanged = false
+		h.runner.OnChangedVersion(h.version)
+	}
 	h.runner.OnReceivedParams(h.peerParams)
 }
 
@@ -456,9 +528,15 @@ func (h *cryptoSetup) GetSessionTicket() ([]byte, error) {
 	var appData []byte
 	// Save transport parameters to the session ticket if we're allowing 0-RTT.
 	if h.extraConf.MaxEarlyData > 0 {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
 		appData = (&sessionTicket{
 			Parameters: h.ourParams,
 			RTT:        h.rttStats.SmoothedRTT(),
+			ID:         id,
+			Issued:     time.Now(),
 		}).Marshal()
 	}
 	return h.conn.GetSessionTicket(appData)
@@ -472,14 +550,17 @@ func (h *cryptoSetup) accept0RTT(sessionTicketData []byte) bool {
 		h.logger.Debugf("Unmarshalling transport parameters from session ticket failed: %s", err.Error())
 		return false
 	}
-	valid := h.ourParams.ValidFor0RTT(t.Parameters)
-	if valid {
-		h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
-		h.rttStats.SetInitialRTT(t.RTT)
-	} else {
+	if !h.ourParams.ValidFor0RTT(t.Parameters) {
 		h.logger.Debugf("Transport parameters changed. Rejecting 0-RTT.")
+		return false
+	}
+	if h.allow0RTT != nil && !h.allow0RTT(t.ID, t.Issued) {
+		h.logger.Debugf("0-RTT not allowed for session ticket %x. Rejecting 0-RTT.", t.ID)
+		return false
 	}
-	return valid
+	h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
+	h.rttStats.SetInitialRTT(t.RTT)
+	return true
 }
 
 // rejected0RTT is called for the client when the server rejects 0-RTT.
@@ -554,8 +635,8 @@ func (h *cryptoSetup) SetReadKey(encLevel qtls.EncryptionLevel, suite *qtls.Ciph
 			panic("Received 0-RTT read key for the client")
 		}
 		h.zeroRTTOpener = newLongHeaderOpener(
-			createAEAD(suite, trafficSecret),
-			newHeaderProtector(suite, trafficSecret, true),
+			createAEAD(suite, trafficSecret, h.initialVersion),
+			newHeaderProtector(suite, trafficSecret, true, h.initialVersion),
 		)
 		h.mutex.Unlock()
 		h.logger.Debugf("Installed 0-RTT Read keys (using %s)", tls.CipherSuiteName(suite.ID))
@@ -566,8 +647,8 @@ func (h *cryptoSetup) SetReadKey(encLevel qtls.EncryptionLevel, suite *qtls.Ciph
 	case qtls.EncryptionHandshake:
 		h.readEncLevel = protocol.EncryptionHandshake
 		h.handshakeOpener = newHandshakeOpener(
-			createAEAD(suite, trafficSecret),
-			newHeaderProtector(suite, trafficSecret, true),
+			createAEAD(suite, trafficSecret, h.version),
+			newHeaderProtector(suite, trafficSecret, true, h.version),
 			h.dropInitialKeys,
 			h.perspective,
 		)
@@ -594,8 +675,8 @@ func (h *cryptoSetup) SetWriteKey(encLevel qtls.EncryptionLevel, suite *qtls.Cip
 			panic("Received 0-RTT write key for the server")
 		}
 		h.zeroRTTSealer = newLongHeaderSealer(
-			createAEAD(suite, trafficSecret),
-			newHeaderProtector(suite, trafficSecret, true),
+			createAEAD(suite, trafficSecret, h.initialVersion),
+			newHeaderProtector(suite, trafficSecret, true, h.initialVersion),
 		)
 		h.mutex.Unlock()
 		h.logger.Debugf("Installed 0-RTT Write keys (using %s)", tls.CipherSuiteName(suite.ID))
@@ -606,8 +687,8 @@ func (h *cryptoSetup) SetWriteKey(encLevel qtls.EncryptionLevel, suite *qtls.Cip
 	case qtls.EncryptionHandshake:
 		h.writeEncLevel = protocol.EncryptionHandshake
 		h.handshakeSealer = newHandshakeSealer(
-			createAEAD(suite, trafficSecret),
-			newHeaderProtector(suite, trafficSecret, true),
+			createAEAD(suite, trafficSecret, h.version),
+			newHeaderProtector(suite, trafficSecret, true, h.version),
 			h.dropInitialKeys,
 			h.perspective,
 		)
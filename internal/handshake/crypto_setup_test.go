@@ -96,6 +96,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -140,6 +141,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -181,6 +183,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			serverConf,
 			false,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -216,6 +219,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			NewMockHandshakeRunner(mockCtrl),
 			serverConf,
 			false,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -233,6 +237,12 @@ var _ = Describe("Crypto Setup TLS", func() {
 	})
 
 	Context("doing the handshake", func() {
+		var serverAllow0RTT func(ticketID []byte, issued time.Time) bool
+
+		BeforeEach(func() {
+			serverAllow0RTT = nil
+		})
+
 		generateCert := func() tls.Certificate {
 			priv, err := rsa.GenerateKey(rand.Reader, 2048)
 			Expect(err).ToNot(HaveOccurred())
@@ -360,6 +370,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				enable0RTT,
+				serverAllow0RTT,
 				serverRTTStats,
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -498,6 +509,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				false,
+				nil,
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -516,6 +528,124 @@ var _ = Describe("Crypto Setup TLS", func() {
 			Expect(sTransportParametersRcvd.MaxIdleTimeout).To(Equal(sTransportParameters.MaxIdleTimeout))
 		})
 
+		Context("compatible version negotiation", func() {
+			runHandshake := func(clientVersions, serverVersions []protocol.VersionNumber, onChangedVersion func(CryptoSetup, protocol.VersionNumber)) (client, server CryptoSetup, clientRcvdParams *wire.TransportParameters) {
+				cChunkChan, cInitialStream, cHandshakeStream := initStreams()
+				cRunner := NewMockHandshakeRunner(mockCtrl)
+				cRunner.EXPECT().OnReceivedParams(gomock.Any()).Do(func(tp *wire.TransportParameters) { clientRcvdParams = tp })
+				cRunner.EXPECT().OnHandshakeComplete()
+				client, _ = NewCryptoSetupClient(
+					cInitialStream,
+					cHandshakeStream,
+					protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8},
+					nil,
+					nil,
+					&wire.TransportParameters{
+						VersionInformation: &wire.VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: clientVersions},
+					},
+					cRunner,
+					clientConf,
+					false,
+					&utils.RTTStats{},
+					nil,
+					utils.DefaultLogger.WithPrefix("client"),
+					protocol.Version1,
+				)
+
+				sChunkChan, sInitialStream, sHandshakeStream := initStreams()
+				var token protocol.StatelessResetToken
+				sRunner := NewMockHandshakeRunner(mockCtrl)
+				if onChangedVersion != nil {
+					sRunner.EXPECT().OnChangedVersion(gomock.Any()).Do(func(v protocol.VersionNumber) { onChangedVersion(client, v) })
+				}
+				sRunner.EXPECT().OnReceivedParams(gomock.Any())
+				sRunner.EXPECT().OnHandshakeComplete()
+				server = NewCryptoSetupServer(
+					sInitialStream,
+					sHandshakeStream,
+					protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8},
+					nil,
+					nil,
+					&wire.TransportParameters{
+						StatelessResetToken: &token,
+						VersionInformation:  &wire.VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: serverVersions},
+					},
+					sRunner,
+					serverConf,
+					false,
+					nil,
+					&utils.RTTStats{},
+					nil,
+					utils.DefaultLogger.WithPrefix("server"),
+					protocol.Version1,
+				)
+
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					handshake(client, cChunkChan, server, sChunkChan)
+					close(done)
+				}()
+				Eventually(done).Should(BeClosed())
+				return
+			}
+
+			canSendPacket := func(client, server CryptoSetup) bool {
+				sealer, err := client.Get1RTTSealer()
+				Expect(err).ToNot(HaveOccurred())
+				opener, err := server.Get1RTTOpener()
+				Expect(err).ToNot(HaveOccurred())
+				sealed := sealer.Seal(nil, []byte("foobar"), 1, []byte("ad"))
+				_, err = opener.Open(nil, sealed, time.Now(), 1, protocol.KeyPhaseZero, []byte("ad"))
+				return err == nil
+			}
+
+			It("switches to a compatible version", func() {
+				var changedVersion protocol.VersionNumber
+				client, server, clientRcvdParams := runHandshake(
+					[]protocol.VersionNumber{protocol.Version1, protocol.Version2},
+					[]protocol.VersionNumber{protocol.Version2, protocol.Version1},
+					func(client CryptoSetup, v protocol.VersionNumber) {
+						changedVersion = v
+						client.ChangeVersion(v)
+					},
+				)
+				Expect(changedVersion).To(Equal(protocol.Version2))
+				Expect(clientRcvdParams.VersionInformation).ToNot(BeNil())
+				Expect(clientRcvdParams.VersionInformation.ChosenVersion).To(Equal(protocol.Version2))
+				Expect(clientRcvdParams.VersionInformation.AvailableVersions).To(Equal([]protocol.VersionNumber{protocol.Version2, protocol.Version1}))
+				Expect(canSendPacket(client, server)).To(BeTrue())
+			})
+
+			It("derives different keys if the client doesn't switch the version", func() {
+				client, server, _ := runHandshake(
+					[]protocol.VersionNumber{protocol.Version1, protocol.Version2},
+					[]protocol.VersionNumber{protocol.Version2, protocol.Version1},
+					func(CryptoSetup, protocol.VersionNumber) {},
+				)
+				Expect(canSendPacket(client, server)).To(BeFalse())
+			})
+
+			It("doesn't switch if the client doesn't support the version", func() {
+				client, server, clientRcvdParams := runHandshake(
+					[]protocol.VersionNumber{protocol.Version1},
+					[]protocol.VersionNumber{protocol.Version2, protocol.Version1},
+					nil,
+				)
+				Expect(clientRcvdParams.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+				Expect(canSendPacket(client, server)).To(BeTrue())
+			})
+
+			It("doesn't switch if the server prefers the current version", func() {
+				_, _, clientRcvdParams := runHandshake(
+					[]protocol.VersionNumber{protocol.Version1, protocol.Version2},
+					[]protocol.VersionNumber{protocol.Version1, protocol.Version2},
+					nil,
+				)
+				Expect(clientRcvdParams.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+			})
+		})
+
 		Context("with session tickets", func() {
 			It("errors when the NewSessionTicket is sent at the wrong encryption level", func() {
 				cChunkChan, cInitialStream, cHandshakeStream := initStreams()
@@ -553,6 +683,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -612,6 +743,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -820,6 +952,59 @@ var _ = Describe("Crypto Setup TLS", func() {
 				Expect(server.ConnectionState().Used0RTT).To(BeFalse())
 				Expect(client.ConnectionState().Used0RTT).To(BeFalse())
 			})
+
+			It("rejects 0-RTT, when the server doesn't allow it", func() {
+				csc := mocktls.NewMockClientSessionCache(mockCtrl)
+				var state *tls.ClientSessionState
+				receivedSessionTicket := make(chan struct{})
+				csc.EXPECT().Get(gomock.Any())
+				csc.EXPECT().Put(gomock.Any(), gomock.Any()).Do(func(_ string, css *tls.ClientSessionState) {
+					state = css
+					close(receivedSessionTicket)
+				})
+				clientConf.ClientSessionCache = csc
+				start := time.Now()
+				clientHelloWrittenChan, client, clientErr, server, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					true,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Eventually(receivedSessionTicket).Should(BeClosed())
+				Expect(server.ConnectionState().DidResume).To(BeFalse())
+				Expect(client.ConnectionState().DidResume).To(BeFalse())
+				Expect(clientHelloWrittenChan).To(Receive(BeNil()))
+
+				csc.EXPECT().Get(gomock.Any()).Return(state, true)
+				csc.EXPECT().Put(gomock.Any(), nil)
+				csc.EXPECT().Put(gomock.Any(), gomock.Any()).MaxTimes(1)
+
+				var ticketID []byte
+				var issued time.Time
+				serverAllow0RTT = func(id []byte, t time.Time) bool {
+					ticketID = id
+					issued = t
+					return false
+				}
+				clientHelloWrittenChan, client, clientErr, server, serverErr = handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					true,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(clientHelloWrittenChan).To(Receive(Not(BeNil())))
+				Expect(ticketID).To(HaveLen(16))
+				Expect(issued).To(BeTemporally("~", start, time.Second))
+
+				Expect(server.ConnectionState().DidResume).To(BeTrue())
+				Expect(client.ConnectionState().DidResume).To(BeTrue())
+				Expect(server.ConnectionState().Used0RTT).To(BeFalse())
+				Expect(client.ConnectionState().Used0RTT).To(BeFalse())
+			})
 		})
 	})
 })
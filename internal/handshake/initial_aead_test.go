@@ -1,6 +1,7 @@
 package handshake
 
 import (
+	"crypto"
 	"fmt"
 	"math/rand"
 
@@ -29,7 +30,7 @@ var _ = Describe("Initial AEAD using AES-GCM", func() {
 		It("computes the client key and IV", func() {
 			clientSecret, _ := computeSecrets(connID, version)
 			Expect(clientSecret).To(Equal(splitHexString("0088119288f1d866733ceeed15ff9d50 902cf82952eee27e9d4d4918ea371d87")))
-			key, iv := computeInitialKeyAndIV(clientSecret)
+			key, iv := computeInitialKeyAndIV(clientSecret, version)
 			Expect(key).To(Equal(splitHexString("175257a31eb09dea9366d8bb79ad80ba")))
 			Expect(iv).To(Equal(splitHexString("6b26114b9cba2b63a9e8dd4f")))
 		})
@@ -37,7 +38,7 @@ var _ = Describe("Initial AEAD using AES-GCM", func() {
 		It("computes the server key and IV", func() {
 			_, serverSecret := computeSecrets(connID, version)
 			Expect(serverSecret).To(Equal(splitHexString("006f881359244dd9ad1acf85f595bad6 7c13f9f5586f5e64e1acae1d9ea8f616")))
-			key, iv := computeInitialKeyAndIV(serverSecret)
+			key, iv := computeInitialKeyAndIV(serverSecret, version)
 			Expect(key).To(Equal(splitHexString("149d0b1662ab871fbe63c49b5e655a5d")))
 			Expect(iv).To(Equal(splitHexString("bab2b12a4c76016ace47856d")))
 		})
@@ -83,7 +84,7 @@ var _ = Describe("Initial AEAD using AES-GCM", func() {
 		It("computes the client key and IV", func() {
 			clientSecret, _ := computeSecrets(connID, version)
 			Expect(clientSecret).To(Equal(splitHexString("c00cf151ca5be075ed0ebfb5c80323c4 2d6b7db67881289af4008f1f6c357aea")))
-			key, iv := computeInitialKeyAndIV(clientSecret)
+			key, iv := computeInitialKeyAndIV(clientSecret, version)
 			Expect(key).To(Equal(splitHexString("1f369613dd76d5467730efcbe3b1a22d")))
 			Expect(iv).To(Equal(splitHexString("fa044b2f42a3fd3b46fb255c")))
 		})
@@ -91,7 +92,7 @@ var _ = Describe("Initial AEAD using AES-GCM", func() {
 		It("computes the server key and IV", func() {
 			_, serverSecret := computeSecrets(connID, version)
 			Expect(serverSecret).To(Equal(splitHexString("3c199828fd139efd216c155ad844cc81 fb82fa8d7446fa7d78be803acdda951b")))
-			key, iv := computeInitialKeyAndIV(serverSecret)
+			key, iv := computeInitialKeyAndIV(serverSecret, version)
 			Expect(key).To(Equal(splitHexString("cf3a5331653c364c88f0f379b6067e37")))
 			Expect(iv).To(Equal(splitHexString("0ac1493ca1905853b0bba03e")))
 		})
@@ -125,7 +126,47 @@ var _ = Describe("Initial AEAD using AES-GCM", func() {
 		})
 	})
 
-	for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1} {
+	// values taken from the Appendix of RFC 9369
+	Context("using the test vector from RFC 9369, for QUIC v2", func() {
+		const version = protocol.Version2
+		var connID protocol.ConnectionID
+
+		BeforeEach(func() {
+			connID = protocol.ConnectionID(splitHexString("0x8394c8f03e515708"))
+		})
+
+		It("computes the client key and IV", func() {
+			clientSecret, _ := computeSecrets(connID, version)
+			Expect(clientSecret).To(Equal(splitHexString("14ec9d6eb9fd7af83bf5a668bc17a7e2 83766aade7ecd0891f70f9ff7f4bf47b")))
+			key, iv := computeInitialKeyAndIV(clientSecret, version)
+			Expect(key).To(Equal(splitHexString("8b1a0bc121284290a29e0971b5cd045d")))
+			Expect(iv).To(Equal(splitHexString("91f73e2351d8fa91660e909f")))
+		})
+
+		It("computes the server key and IV", func() {
+			_, serverSecret := computeSecrets(connID, version)
+			Expect(serverSecret).To(Equal(splitHexString("0263db1782731bf4588e7e4d93b74639 07cb8cd8200b5da55a8bd488eafc37c1")))
+			key, iv := computeInitialKeyAndIV(serverSecret, version)
+			Expect(key).To(Equal(splitHexString("82db637861d55e1d011f19ea71d5d2a7")))
+			Expect(iv).To(Equal(splitHexString("dd13c276499c0249d3310652")))
+		})
+
+		It("derives the header protection keys", func() {
+			clientSecret, serverSecret := computeSecrets(connID, version)
+			Expect(hkdfExpandLabel(crypto.SHA256, clientSecret, []byte{}, hkdfHeaderProtectionLabel(version), 16)).To(Equal(splitHexString("45b95e15235d6f45a6b19cbcb0294ba9")))
+			Expect(hkdfExpandLabel(crypto.SHA256, serverSecret, []byte{}, hkdfHeaderProtectionLabel(version), 16)).To(Equal(splitHexString("edf6d05c83121201b436e16877593c3a")))
+		})
+
+		It("can't open packets sealed using QUIC v1", func() {
+			sealer, _ := NewInitialAEAD(connID, protocol.PerspectiveClient, protocol.Version1)
+			_, opener := NewInitialAEAD(connID, protocol.PerspectiveServer, version)
+			msg := sealer.Seal(nil, []byte("foobar"), 42, []byte("aad"))
+			_, err := opener.Open(nil, msg, 42, []byte("aad"))
+			Expect(err).To(MatchError(ErrDecryptionFailed))
+		})
+	})
+
+	for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1, protocol.Version2} {
 		v := ver
 
 		Context(fmt.Sprintf("using version %s", v), func() {
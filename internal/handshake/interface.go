@@ -42,6 +42,11 @@ type ShortHeaderOpener interface {
 	headerDecryptor
 	DecodePacketNumber(wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
 	Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
+	// DecodePathPacketNumber and OpenPath are used with the multipath extension.
+	// Every path has its own packet number space, identified by the sequence number of the connection ID the packet was sent to.
+	// For path 0, they are equivalent to DecodePacketNumber and Open.
+	DecodePathPacketNumber(pathID uint64, wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
+	OpenPath(dst, src []byte, rcvTime time.Time, pathID uint64, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
 }
 
 // LongHeaderSealer seals a long header packet
@@ -54,7 +59,12 @@ type LongHeaderSealer interface {
 // ShortHeaderSealer seals a short header packet
 type ShortHeaderSealer interface {
 	LongHeaderSealer
+	// SealPath seals a packet sent on a path when using the multipath extension.
+	// For path 0, it is equivalent to Seal.
+	SealPath(dst, src []byte, pathID uint64, packetNumber protocol.PacketNumber, associatedData []byte) []byte
 	KeyPhase() protocol.KeyPhaseBit
+	// NumKeyUpdates returns the number of key updates, initiated by either peer.
+	NumKeyUpdates() uint64
 }
 
 // A tlsExtensionHandler sends and received the QUIC TLS extension.
@@ -66,6 +76,7 @@ type tlsExtensionHandler interface {
 
 type handshakeRunner interface {
 	OnReceivedParams(*wire.TransportParameters)
+	OnChangedVersion(protocol.VersionNumber)
 	OnHandshakeComplete()
 	OnError(error)
 	DropKeys(protocol.EncryptionLevel)
@@ -76,6 +87,7 @@ type CryptoSetup interface {
 	RunHandshake()
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.VersionNumber)
 	GetSessionTicket() ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) bool
@@ -47,6 +47,18 @@ func (mr *MockHandshakeRunnerMockRecorder) DropKeys(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropKeys", reflect.TypeOf((*MockHandshakeRunner)(nil).DropKeys), arg0)
 }
 
+// OnChangedVersion mocks base method.
+func (m *MockHandshakeRunner) OnChangedVersion(arg0 protocol.VersionNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnChangedVersion", arg0)
+}
+
+// OnChangedVersion indicates an expected call of OnChangedVersion.
+func (mr *MockHandshakeRunnerMockRecorder) OnChangedVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnChangedVersion", reflect.TypeOf((*MockHandshakeRunner)(nil).OnChangedVersion), arg0)
+}
+
 // OnError mocks base method.
 func (m *MockHandshakeRunner) OnError(arg0 error) {
 	m.ctrl.T.Helper()
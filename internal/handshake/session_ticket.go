@@ -10,17 +10,24 @@ import (
 	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
-const sessionTicketRevision = 2
+const sessionTicketRevision = 3
 
 type sessionTicket struct {
 	Parameters *wire.TransportParameters
 	RTT        time.Duration // to be encoded in mus
+	// ID uniquely identifies the session ticket.
+	// It is used to detect replays of 0-RTT data.
+	ID     []byte
+	Issued time.Time // to be encoded in ms
 }
 
 func (t *sessionTicket) Marshal() []byte {
 	b := &bytes.Buffer{}
 	quicvarint.Write(b, sessionTicketRevision)
 	quicvarint.Write(b, uint64(t.RTT.Microseconds()))
+	quicvarint.Write(b, uint64(len(t.ID)))
+	b.Write(t.ID)
+	quicvarint.Write(b, uint64(t.Issued.UnixNano()/int64(time.Millisecond)))
 	t.Parameters.MarshalForSessionTicket(b)
 	return b.Bytes()
 }
@@ -38,11 +45,23 @@ func (t *sessionTicket) Unmarshal(b []byte) error {
 	if err != nil {
 		return errors.New("failed to read RTT")
 	}
+	idLen, err := quicvarint.Read(r)
+	if err != nil || idLen > uint64(r.Len()) {
+		return errors.New("failed to read ticket ID")
+	}
+	id := make([]byte, idLen)
+	r.Read(id)
+	issued, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read issue time")
+	}
 	var tp wire.TransportParameters
 	if err := tp.UnmarshalFromSessionTicket(r); err != nil {
 		return fmt.Errorf("unmarshaling transport parameters from session ticket failed: %s", err.Error())
 	}
 	t.Parameters = &tp
 	t.RTT = time.Duration(rtt) * time.Microsecond
+	t.ID = id
+	t.Issued = time.Unix(0, int64(issued)*int64(time.Millisecond))
 	return nil
 }
@@ -18,13 +18,17 @@ var _ = Describe("Session Ticket", func() {
 				InitialMaxStreamDataBidiLocal:  1,
 				InitialMaxStreamDataBidiRemote: 2,
 			},
-			RTT: 1337 * time.Microsecond,
+			RTT:    1337 * time.Microsecond,
+			ID:     []byte("foobar"),
+			Issued: time.Unix(1234567, 890*1e6),
 		}
 		var t sessionTicket
 		Expect(t.Unmarshal(ticket.Marshal())).To(Succeed())
 		Expect(t.Parameters.InitialMaxStreamDataBidiLocal).To(BeEquivalentTo(1))
 		Expect(t.Parameters.InitialMaxStreamDataBidiRemote).To(BeEquivalentTo(2))
 		Expect(t.RTT).To(Equal(1337 * time.Microsecond))
+		Expect(t.ID).To(Equal([]byte("foobar")))
+		Expect(t.Issued).To(Equal(time.Unix(1234567, 890*1e6)))
 	})
 
 	It("refuses to unmarshal if the ticket is too short for the revision", func() {
@@ -43,9 +47,31 @@ var _ = Describe("Session Ticket", func() {
 		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read RTT"))
 	})
 
+	It("refuses to unmarshal if the ticket ID cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		quicvarint.Write(b, 10)
+		b.Write([]byte("foobar"))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read ticket ID"))
+	})
+
+	It("refuses to unmarshal if the issue time cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read issue time"))
+	})
+
 	It("refuses to unmarshal if unmarshaling the transport parameters fails", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		quicvarint.Write(b, 42)
 		b.Write([]byte("foobar"))
 		err := (&sessionTicket{}).Unmarshal(b.Bytes())
 		Expect(err).To(HaveOccurred())
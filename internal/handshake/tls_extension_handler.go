@@ -16,22 +16,28 @@ type extensionHandler struct {
 
 	extensionType uint16
 
+	// Only used by the server.
+	// It is called with the client's transport parameters before the EncryptedExtensions are sent.
+	// If it returns a non-nil value, these transport parameters are sent instead of ourParams.
+	handleClientParams func([]byte) []byte
+
 	perspective protocol.Perspective
 }
 
 var _ tlsExtensionHandler = &extensionHandler{}
 
 // newExtensionHandler creates a new extension handler
-func newExtensionHandler(params []byte, pers protocol.Perspective, v protocol.VersionNumber) tlsExtensionHandler {
+func newExtensionHandler(params []byte, pers protocol.Perspective, v protocol.VersionNumber, handleClientParams func([]byte) []byte) tlsExtensionHandler {
 	et := uint16(quicTLSExtensionType)
-	if v != protocol.Version1 {
+	if v != protocol.Version1 && v != protocol.Version2 {
 		et = quicTLSExtensionTypeOldDrafts
 	}
 	return &extensionHandler{
-		ourParams:     params,
-		paramsChan:    make(chan []byte),
-		perspective:   pers,
-		extensionType: et,
+		ourParams:          params,
+		paramsChan:         make(chan []byte),
+		perspective:        pers,
+		extensionType:      et,
+		handleClientParams: handleClientParams,
 	}
 }
 
@@ -60,6 +66,11 @@ func (h *extensionHandler) ReceivedExtensions(msgType uint8, exts []qtls.Extensi
 		}
 	}
 
+	if h.perspective == protocol.PerspectiveServer && data != nil && h.handleClientParams != nil {
+		if params := h.handleClientParams(data); params != nil {
+			h.ourParams = params
+		}
+	}
 	h.paramsChan <- data
 }
 
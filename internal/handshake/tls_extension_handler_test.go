@@ -26,11 +26,13 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 			[]byte("foobar"),
 			protocol.PerspectiveServer,
 			version,
+			nil,
 		)
 		handlerClient = newExtensionHandler(
 			[]byte("raboof"),
 			protocol.PerspectiveClient,
 			version,
+			nil,
 		)
 	})
 
@@ -84,6 +86,29 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 				Expect(data).To(Equal([]byte("raboof")))
 			})
 
+			It("uses the transport parameters returned by the callback", func() {
+				var clientParams []byte
+				handlerServer = newExtensionHandler(
+					[]byte("foobar"),
+					protocol.PerspectiveServer,
+					version,
+					func(data []byte) []byte {
+						clientParams = data
+						return []byte("foobaz")
+					},
+				)
+				go func() {
+					defer GinkgoRecover()
+					handlerServer.ReceivedExtensions(uint8(typeClientHello), chExts)
+				}()
+
+				Eventually(handlerServer.TransportParameters()).Should(Receive())
+				Expect(clientParams).To(Equal([]byte("raboof")))
+				exts := handlerServer.GetExtensions(uint8(typeEncryptedExtensions))
+				Expect(exts).To(HaveLen(1))
+				Expect(exts[0].Data).To(Equal([]byte("foobaz")))
+			})
+
 			It("sends nil on the channel if the extension is missing", func() {
 				go func() {
 					defer GinkgoRecover()
@@ -36,18 +36,21 @@ type token struct {
 
 // A TokenGenerator generates tokens
 type TokenGenerator struct {
-	tokenProtector tokenProtector
+	tokenProtector TokenProtector
 }
 
 // NewTokenGenerator initializes a new TookenGenerator
 func NewTokenGenerator(rand io.Reader) (*TokenGenerator, error) {
-	tokenProtector, err := newTokenProtector(rand)
+	tokenProtector, err := NewTokenProtector(rand)
 	if err != nil {
 		return nil, err
 	}
-	return &TokenGenerator{
-		tokenProtector: tokenProtector,
-	}, nil
+	return NewTokenGeneratorWithProtector(tokenProtector), nil
+}
+
+// NewTokenGeneratorWithProtector initializes a new TokenGenerator that uses the given TokenProtector
+func NewTokenGeneratorWithProtector(tokenProtector TokenProtector) *TokenGenerator {
+	return &TokenGenerator{tokenProtector: tokenProtector}
 }
 
 // NewRetryToken generates a new token for a Retry for a given source address
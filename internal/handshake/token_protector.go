@@ -11,7 +11,7 @@ import (
 )
 
 // TokenProtector is used to create and verify a token
-type tokenProtector interface {
+type TokenProtector interface {
 	// NewToken creates a new token
 	NewToken([]byte) ([]byte, error)
 	// DecodeToken decodes a token
@@ -19,25 +19,38 @@ type tokenProtector interface {
 }
 
 const (
-	tokenSecretSize = 32
-	tokenNonceSize  = 32
+	// TokenKeySize is the size of the keys used by the token protector.
+	TokenKeySize   = 32
+	tokenNonceSize = 32
 )
 
 // tokenProtector is used to create and verify a token
 type tokenProtectorImpl struct {
-	rand   io.Reader
-	secret []byte
+	rand io.Reader
+	// The first secret is used to create new tokens.
+	// All secrets are used to decode tokens.
+	secrets [][]byte
 }
 
-// newTokenProtector creates a source for source address tokens
-func newTokenProtector(rand io.Reader) (tokenProtector, error) {
-	secret := make([]byte, tokenSecretSize)
-	if _, err := rand.Read(secret); err != nil {
-		return nil, err
+// NewTokenProtector creates a source for source address tokens.
+// The first key is used to create new tokens, all keys are tried when decoding tokens.
+// If no keys are passed, a random key is generated.
+func NewTokenProtector(rand io.Reader, keys ...[]byte) (TokenProtector, error) {
+	for _, key := range keys {
+		if len(key) != TokenKeySize {
+			return nil, fmt.Errorf("invalid token key length: %d", len(key))
+		}
+	}
+	if len(keys) == 0 {
+		secret := make([]byte, TokenKeySize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		keys = [][]byte{secret}
 	}
 	return &tokenProtectorImpl{
-		rand:   rand,
-		secret: secret,
+		rand:    rand,
+		secrets: keys,
 	}, nil
 }
 
@@ -47,7 +60,7 @@ func (s *tokenProtectorImpl) NewToken(data []byte) ([]byte, error) {
 	if _, err := s.rand.Read(nonce); err != nil {
 		return nil, err
 	}
-	aead, aeadNonce, err := s.createAEAD(nonce)
+	aead, aeadNonce, err := s.createAEAD(s.secrets[0], nonce)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +73,25 @@ func (s *tokenProtectorImpl) DecodeToken(p []byte) ([]byte, error) {
 		return nil, fmt.Errorf("token too short: %d", len(p))
 	}
 	nonce := p[:tokenNonceSize]
-	aead, aeadNonce, err := s.createAEAD(nonce)
-	if err != nil {
-		return nil, err
+	var firstErr error
+	for _, secret := range s.secrets {
+		aead, aeadNonce, err := s.createAEAD(secret, nonce)
+		if err != nil {
+			return nil, err
+		}
+		data, err := aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+		if err == nil {
+			return data, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
 	}
-	return aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+	return nil, firstErr
 }
 
-func (s *tokenProtectorImpl) createAEAD(nonce []byte) (cipher.AEAD, []byte, error) {
-	h := hkdf.New(sha256.New, s.secret, nonce, []byte("quic-go token source"))
+func (s *tokenProtectorImpl) createAEAD(secret, nonce []byte) (cipher.AEAD, []byte, error) {
+	h := hkdf.New(sha256.New, secret, nonce, []byte("quic-go token source"))
 	key := make([]byte, 32) // use a 32 byte key, in order to select AES-256
 	if _, err := io.ReadFull(h, key); err != nil {
 		return nil, nil, err
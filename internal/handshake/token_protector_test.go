@@ -17,25 +17,25 @@ func (r *zeroReader) Read(b []byte) (int, error) {
 }
 
 var _ = Describe("Token Protector", func() {
-	var tp tokenProtector
+	var tp TokenProtector
 
 	BeforeEach(func() {
 		var err error
-		tp, err = newTokenProtector(rand.Reader)
+		tp, err = NewTokenProtector(rand.Reader)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("uses the random source", func() {
-		tp1, err := newTokenProtector(&zeroReader{})
+		tp1, err := NewTokenProtector(&zeroReader{})
 		Expect(err).ToNot(HaveOccurred())
-		tp2, err := newTokenProtector(&zeroReader{})
+		tp2, err := NewTokenProtector(&zeroReader{})
 		Expect(err).ToNot(HaveOccurred())
 		t1, err := tp1.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		t2, err := tp2.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		Expect(t1).To(Equal(t2))
-		tp3, err := newTokenProtector(rand.Reader)
+		tp3, err := NewTokenProtector(rand.Reader)
 		Expect(err).ToNot(HaveOccurred())
 		t3, err := tp3.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
@@ -64,4 +64,52 @@ var _ = Describe("Token Protector", func() {
 		_, err := tp.DecodeToken([]byte("foobar"))
 		Expect(err).To(MatchError("token too short: 6"))
 	})
+	Context("using keys", func() {
+		key1 := make([]byte, TokenKeySize)
+		key2 := make([]byte, TokenKeySize)
+		key3 := make([]byte, TokenKeySize)
+		for i := 0; i < TokenKeySize; i++ {
+			key1[i] = 1
+			key2[i] = 2
+			key3[i] = 3
+		}
+
+		It("rejects keys of the wrong length", func() {
+			_, err := NewTokenProtector(rand.Reader, key1, []byte("foobar"))
+			Expect(err).To(MatchError("invalid token key length: 6"))
+		})
+
+		It("decodes tokens created by a different protector using the same key", func() {
+			tp1, err := NewTokenProtector(rand.Reader, key1)
+			Expect(err).ToNot(HaveOccurred())
+			tp2, err := NewTokenProtector(rand.Reader, key1)
+			Expect(err).ToNot(HaveOccurred())
+			token, err := tp1.NewToken([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tp2.DecodeToken(token)).To(Equal([]byte("foobar")))
+		})
+
+		It("uses the first key to create tokens, and all keys to decode tokens", func() {
+			tp1, err := NewTokenProtector(rand.Reader, key1)
+			Expect(err).ToNot(HaveOccurred())
+			tp2, err := NewTokenProtector(rand.Reader, key2, key1)
+			Expect(err).ToNot(HaveOccurred())
+			tp3, err := NewTokenProtector(rand.Reader, key3, key2)
+			Expect(err).ToNot(HaveOccurred())
+			token1, err := tp1.NewToken([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			token2, err := tp2.NewToken([]byte("bar"))
+			Expect(err).ToNot(HaveOccurred())
+			// tp2 accepts tokens from tp1, and its own tokens
+			Expect(tp2.DecodeToken(token1)).To(Equal([]byte("foo")))
+			Expect(tp2.DecodeToken(token2)).To(Equal([]byte("bar")))
+			// tp1 doesn't know the new key yet
+			_, err = tp1.DecodeToken(token2)
+			Expect(err).To(MatchError("cipher: message authentication failed"))
+			// tp3 retired the old key
+			Expect(tp3.DecodeToken(token2)).To(Equal([]byte("bar")))
+			_, err = tp3.DecodeToken(token1)
+			Expect(err).To(MatchError("cipher: message authentication failed"))
+		})
+	})
 })
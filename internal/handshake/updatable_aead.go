@@ -50,11 +50,22 @@ type updatableAEAD struct {
 	nextRcvTrafficSecret  []byte
 	nextSendTrafficSecret []byte
 
+	// The traffic secrets are needed to derive the AEADs for paths other than path 0, when using multipath.
+	rcvTrafficSecret     []byte
+	prevRcvTrafficSecret []byte
+	sendTrafficSecret    []byte
+	pathAEADs            map[uint64]*pathAEADs
+	// highest packet number received on paths other than path 0
+	pathHighestRcvdPN map[uint64]protocol.PacketNumber
+
 	headerDecrypter headerProtector
 	headerEncrypter headerProtector
 
 	rttStats *utils.RTTStats
 
+	// the QUIC version determines the labels used to derive the keys
+	version protocol.VersionNumber
+
 	tracer logging.ConnectionTracer
 	logger utils.Logger
 
@@ -62,13 +73,21 @@ type updatableAEAD struct {
 	nonceBuf []byte
 }
 
+// pathAEADs are the AEADs used on a path other than path 0.
+// The path ID is part of the nonce, see draft-ietf-quic-multipath, Section 10.1.
+// Since the AEAD only takes the packet number as a nonce, the path ID is applied to the IV instead.
+type pathAEADs struct {
+	rcv, prevRcv, nextRcv, send cipher.AEAD
+}
+
 var (
 	_ ShortHeaderOpener = &updatableAEAD{}
 	_ ShortHeaderSealer = &updatableAEAD{}
 )
 
-func newUpdatableAEAD(rttStats *utils.RTTStats, tracer logging.ConnectionTracer, logger utils.Logger) *updatableAEAD {
+func newUpdatableAEAD(rttStats *utils.RTTStats, tracer logging.ConnectionTracer, logger utils.Logger, version protocol.VersionNumber) *updatableAEAD {
 	return &updatableAEAD{
+		version:                 version,
 		firstPacketNumber:       protocol.InvalidPacketNumber,
 		largestAcked:            protocol.InvalidPacketNumber,
 		firstRcvdWithCurrentKey: protocol.InvalidPacketNumber,
@@ -97,11 +116,15 @@ func (a *updatableAEAD) rollKeys() {
 	a.prevRcvAEAD = a.rcvAEAD
 	a.rcvAEAD = a.nextRcvAEAD
 	a.sendAEAD = a.nextSendAEAD
+	a.prevRcvTrafficSecret = a.rcvTrafficSecret
+	a.rcvTrafficSecret = a.nextRcvTrafficSecret
+	a.sendTrafficSecret = a.nextSendTrafficSecret
+	a.pathAEADs = nil
 
 	a.nextRcvTrafficSecret = a.getNextTrafficSecret(a.suite.Hash, a.nextRcvTrafficSecret)
 	a.nextSendTrafficSecret = a.getNextTrafficSecret(a.suite.Hash, a.nextSendTrafficSecret)
-	a.nextRcvAEAD = createAEAD(a.suite, a.nextRcvTrafficSecret)
-	a.nextSendAEAD = createAEAD(a.suite, a.nextSendTrafficSecret)
+	a.nextRcvAEAD = createAEAD(a.suite, a.nextRcvTrafficSecret, a.version)
+	a.nextSendAEAD = createAEAD(a.suite, a.nextSendTrafficSecret, a.version)
 }
 
 func (a *updatableAEAD) startKeyDropTimer(now time.Time) {
@@ -111,33 +134,39 @@ func (a *updatableAEAD) startKeyDropTimer(now time.Time) {
 }
 
 func (a *updatableAEAD) getNextTrafficSecret(hash crypto.Hash, ts []byte) []byte {
-	return hkdfExpandLabel(hash, ts, []byte{}, "quic ku", hash.Size())
+	label := "quic ku"
+	if a.version == protocol.Version2 {
+		label = "quicv2 ku"
+	}
+	return hkdfExpandLabel(hash, ts, []byte{}, label, hash.Size())
 }
 
 // For the client, this function is called before SetWriteKey.
 // For the server, this function is called after SetWriteKey.
 func (a *updatableAEAD) SetReadKey(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) {
-	a.rcvAEAD = createAEAD(suite, trafficSecret)
-	a.headerDecrypter = newHeaderProtector(suite, trafficSecret, false)
+	a.rcvAEAD = createAEAD(suite, trafficSecret, a.version)
+	a.rcvTrafficSecret = trafficSecret
+	a.headerDecrypter = newHeaderProtector(suite, trafficSecret, false, a.version)
 	if a.suite == nil {
 		a.setAEADParameters(a.rcvAEAD, suite)
 	}
 
 	a.nextRcvTrafficSecret = a.getNextTrafficSecret(suite.Hash, trafficSecret)
-	a.nextRcvAEAD = createAEAD(suite, a.nextRcvTrafficSecret)
+	a.nextRcvAEAD = createAEAD(suite, a.nextRcvTrafficSecret, a.version)
 }
 
 // For the client, this function is called after SetReadKey.
 // For the server, this function is called before SetWriteKey.
 func (a *updatableAEAD) SetWriteKey(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) {
-	a.sendAEAD = createAEAD(suite, trafficSecret)
-	a.headerEncrypter = newHeaderProtector(suite, trafficSecret, false)
+	a.sendAEAD = createAEAD(suite, trafficSecret, a.version)
+	a.sendTrafficSecret = trafficSecret
+	a.headerEncrypter = newHeaderProtector(suite, trafficSecret, false, a.version)
 	if a.suite == nil {
 		a.setAEADParameters(a.sendAEAD, suite)
 	}
 
 	a.nextSendTrafficSecret = a.getNextTrafficSecret(suite.Hash, trafficSecret)
-	a.nextSendAEAD = createAEAD(suite, a.nextSendTrafficSecret)
+	a.nextSendAEAD = createAEAD(suite, a.nextSendTrafficSecret, a.version)
 }
 
 func (a *updatableAEAD) setAEADParameters(aead cipher.AEAD, suite *qtls.CipherSuiteTLS13) {
@@ -158,13 +187,21 @@ func (a *updatableAEAD) DecodePacketNumber(wirePN protocol.PacketNumber, wirePNL
 	return protocol.DecodePacketNumber(wirePNLen, a.highestRcvdPN, wirePN)
 }
 
+func (a *updatableAEAD) DecodePathPacketNumber(pathID uint64, wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber {
+	if pathID == 0 {
+		return a.DecodePacketNumber(wirePN, wirePNLen)
+	}
+	highest, ok := a.pathHighestRcvdPN[pathID]
+	if !ok {
+		highest = protocol.InvalidPacketNumber
+	}
+	return protocol.DecodePacketNumber(wirePNLen, highest, wirePN)
+}
+
 func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
 	dec, err := a.open(dst, src, rcvTime, pn, kp, ad)
-	if err == ErrDecryptionFailed {
-		a.invalidPacketCount++
-		if a.invalidPacketCount >= a.invalidPacketLimit {
-			return nil, &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
-		}
+	if err := a.checkInvalidPacketLimit(err); err != nil {
+		return nil, err
 	}
 	if err == nil {
 		a.highestRcvdPN = utils.MaxPacketNumber(a.highestRcvdPN, pn)
@@ -172,15 +209,112 @@ func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 	return dec, err
 }
 
-func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+func (a *updatableAEAD) OpenPath(dst, src []byte, rcvTime time.Time, pathID uint64, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	if pathID == 0 {
+		return a.Open(dst, src, rcvTime, pn, kp, ad)
+	}
+	dec, err := a.openPath(dst, src, rcvTime, pathID, pn, kp, ad)
+	if err := a.checkInvalidPacketLimit(err); err != nil {
+		return nil, err
+	}
+	if err == nil {
+		if a.pathHighestRcvdPN == nil {
+			a.pathHighestRcvdPN = make(map[uint64]protocol.PacketNumber)
+		}
+		if highest, ok := a.pathHighestRcvdPN[pathID]; !ok || pn > highest {
+			a.pathHighestRcvdPN[pathID] = pn
+		}
+	}
+	return dec, err
+}
+
+// checkInvalidPacketLimit counts packets that failed authentication,
+// and returns an AEAD_LIMIT_REACHED error when the limit for the cipher suite is reached.
+func (a *updatableAEAD) checkInvalidPacketLimit(err error) error {
+	if err != ErrDecryptionFailed {
+		return nil
+	}
+	a.invalidPacketCount++
+	if a.invalidPacketCount >= a.invalidPacketLimit {
+		return &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
+	}
+	return nil
+}
+
+func (a *updatableAEAD) maybeDropPrevKey(rcvTime time.Time) {
 	if a.prevRcvAEAD != nil && !a.prevRcvAEADExpiry.IsZero() && rcvTime.After(a.prevRcvAEADExpiry) {
 		a.prevRcvAEAD = nil
+		a.prevRcvTrafficSecret = nil
+		a.pathAEADs = nil
 		a.logger.Debugf("Dropping key phase %d", a.keyPhase-1)
 		a.prevRcvAEADExpiry = time.Time{}
 		if a.tracer != nil {
 			a.tracer.DroppedKey(a.keyPhase - 1)
 		}
 	}
+}
+
+// getPathAEADs returns the AEADs used on a path other than path 0.
+func (a *updatableAEAD) getPathAEADs(pathID uint64) *pathAEADs {
+	if p, ok := a.pathAEADs[pathID]; ok {
+		return p
+	}
+	if a.pathAEADs == nil {
+		a.pathAEADs = make(map[uint64]*pathAEADs)
+	}
+	p := &pathAEADs{
+		rcv:     createPathAEAD(a.suite, a.rcvTrafficSecret, pathID, a.version),
+		nextRcv: createPathAEAD(a.suite, a.nextRcvTrafficSecret, pathID, a.version),
+		send:    createPathAEAD(a.suite, a.sendTrafficSecret, pathID, a.version),
+	}
+	if a.prevRcvAEAD != nil {
+		p.prevRcv = createPathAEAD(a.suite, a.prevRcvTrafficSecret, pathID, a.version)
+	}
+	a.pathAEADs[pathID] = p
+	return p
+}
+
+// openPath opens a packet received on a path other than path 0.
+// The key update state is tracked using the packet numbers of path 0, since packet numbers of different paths can't be compared.
+// On other paths, a packet with a different key phase is first tried with the previous key, and then with the next key.
+func (a *updatableAEAD) openPath(dst, src []byte, rcvTime time.Time, pathID uint64, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.maybeDropPrevKey(rcvTime)
+	aeads := a.getPathAEADs(pathID)
+	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+	if kp == a.keyPhase.Bit() {
+		dec, err := aeads.rcv.Open(dst, a.nonceBuf, src, ad)
+		if err != nil {
+			return nil, ErrDecryptionFailed
+		}
+		a.numRcvdWithCurrentKey++
+		return dec, nil
+	}
+	if aeads.prevRcv != nil {
+		if dec, err := aeads.prevRcv.Open(dst, a.nonceBuf, src, ad); err == nil {
+			return dec, nil
+		}
+	}
+	dec, err := aeads.nextRcv.Open(dst, a.nonceBuf, src, ad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	if a.keyPhase > 0 && a.numSentWithCurrentKey == 0 {
+		return nil, &qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: "keys updated too quickly",
+		}
+	}
+	a.rollKeys()
+	a.logger.Debugf("Peer updated keys to %d", a.keyPhase)
+	a.startKeyDropTimer(rcvTime)
+	if a.tracer != nil {
+		a.tracer.UpdatedKey(a.keyPhase, true)
+	}
+	return dec, nil
+}
+
+func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.maybeDropPrevKey(rcvTime)
 	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
 	if kp != a.keyPhase.Bit() {
 		if a.keyPhase > 0 && a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber || pn < a.firstRcvdWithCurrentKey {
@@ -200,7 +334,7 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 			return nil, ErrDecryptionFailed
 		}
 		// Opening succeeded. Check if the peer was allowed to update.
-		if a.keyPhase > 0 && a.firstSentWithCurrentKey == protocol.InvalidPacketNumber {
+		if a.keyPhase > 0 && a.numSentWithCurrentKey == 0 {
 			return nil, &qerr.TransportError{
 				ErrorCode:    qerr.KeyUpdateError,
 				ErrorMessage: "keys updated too quickly",
@@ -250,6 +384,16 @@ func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byt
 	return a.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
 }
 
+func (a *updatableAEAD) SealPath(dst, src []byte, pathID uint64, pn protocol.PacketNumber, ad []byte) []byte {
+	if pathID == 0 {
+		return a.Seal(dst, src, pn, ad)
+	}
+	// The packet numbers used for key update bookkeeping are those of path 0.
+	a.numSentWithCurrentKey++
+	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+	return a.getPathAEADs(pathID).send.Seal(dst, a.nonceBuf, src, ad)
+}
+
 func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
 	if a.firstSentWithCurrentKey != protocol.InvalidPacketNumber &&
 		pn >= a.firstSentWithCurrentKey && a.numRcvdWithCurrentKey == 0 {
@@ -293,6 +437,10 @@ func (a *updatableAEAD) shouldInitiateKeyUpdate() bool {
 	return false
 }
 
+func (a *updatableAEAD) NumKeyUpdates() uint64 {
+	return uint64(a.keyPhase)
+}
+
 func (a *updatableAEAD) KeyPhase() protocol.KeyPhaseBit {
 	if a.shouldInitiateKeyUpdate() {
 		a.rollKeys()
@@ -20,7 +20,7 @@ import (
 var _ = Describe("Updatable AEAD", func() {
 	It("ChaCha test vector from the draft", func() {
 		secret := splitHexString("9ac312a7f877468ebe69422748ad00a1 5443f18203a07d6060f688f30f21632b")
-		aead := newUpdatableAEAD(&utils.RTTStats{}, nil, nil)
+		aead := newUpdatableAEAD(&utils.RTTStats{}, nil, nil, protocol.Version1)
 		chacha := cipherSuites[2]
 		Expect(chacha.ID).To(Equal(tls.TLS_CHACHA20_POLY1305_SHA256))
 		aead.SetWriteKey(chacha, secret)
@@ -53,8 +53,8 @@ var _ = Describe("Updatable AEAD", func() {
 				rand.Read(trafficSecret2)
 
 				rttStats = utils.NewRTTStats()
-				client = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger)
-				server = newUpdatableAEAD(rttStats, serverTracer, utils.DefaultLogger)
+				client = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger, protocol.Version1)
+				server = newUpdatableAEAD(rttStats, serverTracer, utils.DefaultLogger, protocol.Version1)
 				client.SetReadKey(cs, trafficSecret2)
 				client.SetWriteKey(cs, trafficSecret1)
 				server.SetReadKey(cs, trafficSecret1)
@@ -142,14 +142,93 @@ var _ = Describe("Updatable AEAD", func() {
 					Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.AEADLimitReached))
 				})
 
+				Context("multipath", func() {
+					It("uses the same nonce as without multipath for path 0", func() {
+						Expect(server.SealPath(nil, msg, 0, 0x1337, ad)).To(Equal(server.Seal(nil, msg, 0x1337, ad)))
+					})
+
+					It("encrypts and decrypts a message on a path", func() {
+						encrypted := server.SealPath(nil, msg, 3, 0x1337, ad)
+						Expect(encrypted).ToNot(Equal(server.Seal(nil, msg, 0x1337, ad)))
+						opened, err := client.OpenPath(nil, encrypted, time.Now(), 3, 0x1337, protocol.KeyPhaseZero, ad)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(opened).To(Equal(msg))
+					})
+
+					It("fails to open a message if the path ID is not the same", func() {
+						encrypted := server.SealPath(nil, msg, 3, 0x1337, ad)
+						_, err := client.OpenPath(nil, encrypted, time.Now(), 4, 0x1337, protocol.KeyPhaseZero, ad)
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+						_, err = client.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+					})
+
+					It("decodes packet numbers per path", func() {
+						encrypted := server.SealPath(nil, msg, 3, 0x1337, ad)
+						_, err := client.OpenPath(nil, encrypted, time.Now(), 3, 0x1337, protocol.KeyPhaseZero, ad)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(client.DecodePathPacketNumber(3, 0x38, protocol.PacketNumberLen1)).To(BeEquivalentTo(0x1338))
+						Expect(client.DecodePathPacketNumber(4, 0x38, protocol.PacketNumberLen1)).To(BeEquivalentTo(0x38))
+						Expect(client.DecodePathPacketNumber(0, 0x38, protocol.PacketNumberLen1)).To(BeEquivalentTo(0x38))
+					})
+
+					It("updates keys when receiving a packet with the next key phase on a path", func() {
+						now := time.Now()
+						_ = server.Seal(nil, msg, 0x1, ad)
+						client.rollKeys()
+						encrypted := client.SealPath(nil, msg, 2, 0x10, ad)
+						serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), true)
+						decrypted, err := server.OpenPath(nil, encrypted, now, 2, 0x10, protocol.KeyPhaseOne, ad)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(decrypted).To(Equal(msg))
+						Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+						// the key update also applies to path 0
+						encrypted = client.Seal(nil, msg, 0x42, ad)
+						decrypted, err = server.Open(nil, encrypted, now, 0x42, protocol.KeyPhaseOne, ad)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(decrypted).To(Equal(msg))
+					})
+
+					It("errors when the peer updates keys on a path before we sent a packet with the current keys", func() {
+						now := time.Now()
+						_ = server.Seal(nil, msg, 0x1, ad)
+						client.rollKeys()
+						serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), true)
+						_, err := server.OpenPath(nil, client.SealPath(nil, msg, 2, 0x10, ad), now, 2, 0x10, protocol.KeyPhaseOne, ad)
+						Expect(err).ToNot(HaveOccurred())
+						client.rollKeys()
+						_, err = server.OpenPath(nil, client.SealPath(nil, msg, 2, 0x11, ad), now, 2, 0x11, protocol.KeyPhaseZero, ad)
+						Expect(err).To(MatchError(&qerr.TransportError{
+							ErrorCode:    qerr.KeyUpdateError,
+							ErrorMessage: "keys updated too quickly",
+						}))
+					})
+
+					It("opens reordered packets with the previous key phase on a path", func() {
+						now := time.Now()
+						encryptedOld := client.SealPath(nil, msg, 2, 0x10, ad)
+						_ = server.Seal(nil, msg, 0x1, ad)
+						client.rollKeys()
+						encryptedNew := client.SealPath(nil, msg, 2, 0x11, ad)
+						serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), true)
+						_, err := server.OpenPath(nil, encryptedNew, now, 2, 0x11, protocol.KeyPhaseOne, ad)
+						Expect(err).ToNot(HaveOccurred())
+						decrypted, err := server.OpenPath(nil, encryptedOld, now, 2, 0x10, protocol.KeyPhaseZero, ad)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(decrypted).To(Equal(msg))
+					})
+				})
+
 				Context("key updates", func() {
 					Context("receiving key updates", func() {
 						It("updates keys", func() {
 							now := time.Now()
 							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+							Expect(server.NumKeyUpdates()).To(BeZero())
 							encrypted0 := server.Seal(nil, msg, 0x1337, ad)
 							server.rollKeys()
 							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+							Expect(server.NumKeyUpdates()).To(BeEquivalentTo(1))
 							encrypted1 := server.Seal(nil, msg, 0x1337, ad)
 							Expect(encrypted0).ToNot(Equal(encrypted1))
 							// expect opening to fail. The client didn't roll keys yet
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/lucas-clemente/quic-go/internal/congestion (interfaces: SendAlgorithmWithDebugInfos)
+// Source: github.com/lucas-clemente/quic-go/internal/congestion (interfaces: SendAlgorithmWithDebugInfos,SendAlgorithmWithHandlers,PacketNumberSpaceAwareSendAlgorithm)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -117,6 +117,18 @@ func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) MaybeExitSlowStart() *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).MaybeExitSlowStart))
 }
 
+// OnApplicationLimited mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnApplicationLimited(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnApplicationLimited", arg0)
+}
+
+// OnApplicationLimited indicates an expected call of OnApplicationLimited.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnApplicationLimited(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnApplicationLimited", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnApplicationLimited), arg0)
+}
+
 // OnPacketAcked mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
 	m.ctrl.T.Helper()
@@ -190,3 +202,469 @@ func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) TimeUntilSend(arg0 interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).TimeUntilSend), arg0)
 }
+
+// MockSendAlgorithmWithHandlers is a mock of SendAlgorithmWithHandlers interface.
+type MockSendAlgorithmWithHandlers struct {
+	ctrl     *gomock.Controller
+	recorder *MockSendAlgorithmWithHandlersMockRecorder
+}
+
+// MockSendAlgorithmWithHandlersMockRecorder is the mock recorder for MockSendAlgorithmWithHandlers.
+type MockSendAlgorithmWithHandlersMockRecorder struct {
+	mock *MockSendAlgorithmWithHandlers
+}
+
+// NewMockSendAlgorithmWithHandlers creates a new mock instance.
+func NewMockSendAlgorithmWithHandlers(ctrl *gomock.Controller) *MockSendAlgorithmWithHandlers {
+	mock := &MockSendAlgorithmWithHandlers{ctrl: ctrl}
+	mock.recorder = &MockSendAlgorithmWithHandlersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSendAlgorithmWithHandlers) EXPECT() *MockSendAlgorithmWithHandlersMockRecorder {
+	return m.recorder
+}
+
+// CanSend mocks base method.
+func (m *MockSendAlgorithmWithHandlers) CanSend(arg0 protocol.ByteCount) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanSend", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CanSend indicates an expected call of CanSend.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) CanSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanSend", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).CanSend), arg0)
+}
+
+// GetCongestionWindow mocks base method.
+func (m *MockSendAlgorithmWithHandlers) GetCongestionWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// GetCongestionWindow indicates an expected call of GetCongestionWindow.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) GetCongestionWindow() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionWindow", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).GetCongestionWindow))
+}
+
+// HasPacingBudget mocks base method.
+func (m *MockSendAlgorithmWithHandlers) HasPacingBudget() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPacingBudget")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasPacingBudget indicates an expected call of HasPacingBudget.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) HasPacingBudget() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).HasPacingBudget))
+}
+
+// InRecovery mocks base method.
+func (m *MockSendAlgorithmWithHandlers) InRecovery() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InRecovery")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InRecovery indicates an expected call of InRecovery.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) InRecovery() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InRecovery", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).InRecovery))
+}
+
+// InSlowStart mocks base method.
+func (m *MockSendAlgorithmWithHandlers) InSlowStart() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InSlowStart")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InSlowStart indicates an expected call of InSlowStart.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) InSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InSlowStart", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).InSlowStart))
+}
+
+// MaybeExitSlowStart mocks base method.
+func (m *MockSendAlgorithmWithHandlers) MaybeExitSlowStart() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MaybeExitSlowStart")
+}
+
+// MaybeExitSlowStart indicates an expected call of MaybeExitSlowStart.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) MaybeExitSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).MaybeExitSlowStart))
+}
+
+// OnApplicationLimited mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnApplicationLimited(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnApplicationLimited", arg0)
+}
+
+// OnApplicationLimited indicates an expected call of OnApplicationLimited.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnApplicationLimited(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnApplicationLimited", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnApplicationLimited), arg0)
+}
+
+// OnECNCongestion mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnECNCongestion(arg0 protocol.PacketNumber, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnECNCongestion", arg0, arg1)
+}
+
+// OnECNCongestion indicates an expected call of OnECNCongestion.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnECNCongestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnECNCongestion", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnECNCongestion), arg0, arg1)
+}
+
+// OnPacketAcked mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3)
+}
+
+// OnPacketAcked indicates an expected call of OnPacketAcked.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnPacketAcked), arg0, arg1, arg2, arg3)
+}
+
+// OnPacketLost mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnPacketLost(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketLost", arg0, arg1, arg2)
+}
+
+// OnPacketLost indicates an expected call of OnPacketLost.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnPacketLost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketLost", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnPacketLost), arg0, arg1, arg2)
+}
+
+// OnPacketSent mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnPacketSent(arg0 time.Time, arg1 protocol.ByteCount, arg2 protocol.PacketNumber, arg3 protocol.ByteCount, arg4 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketSent indicates an expected call of OnPacketSent.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPersistentCongestion mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnPersistentCongestion() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPersistentCongestion")
+}
+
+// OnPersistentCongestion indicates an expected call of OnPersistentCongestion.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnPersistentCongestion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPersistentCongestion", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnPersistentCongestion))
+}
+
+// OnRetransmissionTimeout mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnRetransmissionTimeout(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRetransmissionTimeout", arg0)
+}
+
+// OnRetransmissionTimeout indicates an expected call of OnRetransmissionTimeout.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnRetransmissionTimeout(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRetransmissionTimeout", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnRetransmissionTimeout), arg0)
+}
+
+// OnSpuriousLoss mocks base method.
+func (m *MockSendAlgorithmWithHandlers) OnSpuriousLoss(arg0 protocol.PacketNumber, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnSpuriousLoss", arg0, arg1)
+}
+
+// OnSpuriousLoss indicates an expected call of OnSpuriousLoss.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) OnSpuriousLoss(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnSpuriousLoss", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).OnSpuriousLoss), arg0, arg1)
+}
+
+// SetMaxDatagramSize mocks base method.
+func (m *MockSendAlgorithmWithHandlers) SetMaxDatagramSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxDatagramSize", arg0)
+}
+
+// SetMaxDatagramSize indicates an expected call of SetMaxDatagramSize.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) SetMaxDatagramSize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxDatagramSize", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).SetMaxDatagramSize), arg0)
+}
+
+// TimeUntilSend mocks base method.
+func (m *MockSendAlgorithmWithHandlers) TimeUntilSend(arg0 protocol.ByteCount) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeUntilSend", arg0)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// TimeUntilSend indicates an expected call of TimeUntilSend.
+func (mr *MockSendAlgorithmWithHandlersMockRecorder) TimeUntilSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockSendAlgorithmWithHandlers)(nil).TimeUntilSend), arg0)
+}
+
+// MockPacketNumberSpaceAwareSendAlgorithm is a mock of PacketNumberSpaceAwareSendAlgorithm interface.
+type MockPacketNumberSpaceAwareSendAlgorithm struct {
+	ctrl     *gomock.Controller
+	recorder *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder
+}
+
+// MockPacketNumberSpaceAwareSendAlgorithmMockRecorder is the mock recorder for MockPacketNumberSpaceAwareSendAlgorithm.
+type MockPacketNumberSpaceAwareSendAlgorithmMockRecorder struct {
+	mock *MockPacketNumberSpaceAwareSendAlgorithm
+}
+
+// NewMockPacketNumberSpaceAwareSendAlgorithm creates a new mock instance.
+func NewMockPacketNumberSpaceAwareSendAlgorithm(ctrl *gomock.Controller) *MockPacketNumberSpaceAwareSendAlgorithm {
+	mock := &MockPacketNumberSpaceAwareSendAlgorithm{ctrl: ctrl}
+	mock.recorder = &MockPacketNumberSpaceAwareSendAlgorithmMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) EXPECT() *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder {
+	return m.recorder
+}
+
+// CanSend mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) CanSend(arg0 protocol.ByteCount) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanSend", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CanSend indicates an expected call of CanSend.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) CanSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanSend", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).CanSend), arg0)
+}
+
+// GetCongestionWindow mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) GetCongestionWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// GetCongestionWindow indicates an expected call of GetCongestionWindow.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) GetCongestionWindow() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionWindow", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).GetCongestionWindow))
+}
+
+// HasPacingBudget mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) HasPacingBudget() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPacingBudget")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasPacingBudget indicates an expected call of HasPacingBudget.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) HasPacingBudget() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).HasPacingBudget))
+}
+
+// InRecovery mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) InRecovery() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InRecovery")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InRecovery indicates an expected call of InRecovery.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) InRecovery() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InRecovery", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).InRecovery))
+}
+
+// InSlowStart mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) InSlowStart() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InSlowStart")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InSlowStart indicates an expected call of InSlowStart.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) InSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InSlowStart", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).InSlowStart))
+}
+
+// MaybeExitSlowStart mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) MaybeExitSlowStart() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MaybeExitSlowStart")
+}
+
+// MaybeExitSlowStart indicates an expected call of MaybeExitSlowStart.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) MaybeExitSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).MaybeExitSlowStart))
+}
+
+// OnApplicationLimited mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnApplicationLimited(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnApplicationLimited", arg0)
+}
+
+// OnApplicationLimited indicates an expected call of OnApplicationLimited.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnApplicationLimited(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnApplicationLimited", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnApplicationLimited), arg0)
+}
+
+// OnPacketAcked mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3)
+}
+
+// OnPacketAcked indicates an expected call of OnPacketAcked.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketAcked), arg0, arg1, arg2, arg3)
+}
+
+// OnPacketAckedAtLevel mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketAckedAtLevel(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount, arg4 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAckedAtLevel", arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketAckedAtLevel indicates an expected call of OnPacketAckedAtLevel.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketAckedAtLevel(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAckedAtLevel", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketAckedAtLevel), arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketLost mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketLost(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketLost", arg0, arg1, arg2)
+}
+
+// OnPacketLost indicates an expected call of OnPacketLost.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketLost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketLost", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketLost), arg0, arg1, arg2)
+}
+
+// OnPacketLostAtLevel mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketLostAtLevel(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketLostAtLevel", arg0, arg1, arg2, arg3)
+}
+
+// OnPacketLostAtLevel indicates an expected call of OnPacketLostAtLevel.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketLostAtLevel(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketLostAtLevel", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketLostAtLevel), arg0, arg1, arg2, arg3)
+}
+
+// OnPacketSent mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketSent(arg0 time.Time, arg1 protocol.ByteCount, arg2 protocol.PacketNumber, arg3 protocol.ByteCount, arg4 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketSent indicates an expected call of OnPacketSent.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketSentAtLevel mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketSentAtLevel(arg0 protocol.EncryptionLevel, arg1 time.Time, arg2 protocol.ByteCount, arg3 protocol.PacketNumber, arg4 protocol.ByteCount, arg5 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketSentAtLevel", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// OnPacketSentAtLevel indicates an expected call of OnPacketSentAtLevel.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketSentAtLevel(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSentAtLevel", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketSentAtLevel), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// OnPacketsDropped mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnPacketsDropped(arg0 protocol.EncryptionLevel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketsDropped", arg0)
+}
+
+// OnPacketsDropped indicates an expected call of OnPacketsDropped.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnPacketsDropped(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketsDropped", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnPacketsDropped), arg0)
+}
+
+// OnRetransmissionTimeout mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) OnRetransmissionTimeout(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRetransmissionTimeout", arg0)
+}
+
+// OnRetransmissionTimeout indicates an expected call of OnRetransmissionTimeout.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) OnRetransmissionTimeout(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRetransmissionTimeout", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).OnRetransmissionTimeout), arg0)
+}
+
+// SetMaxDatagramSize mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) SetMaxDatagramSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxDatagramSize", arg0)
+}
+
+// SetMaxDatagramSize indicates an expected call of SetMaxDatagramSize.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) SetMaxDatagramSize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxDatagramSize", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).SetMaxDatagramSize), arg0)
+}
+
+// TimeUntilSend mocks base method.
+func (m *MockPacketNumberSpaceAwareSendAlgorithm) TimeUntilSend(arg0 protocol.ByteCount) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeUntilSend", arg0)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// TimeUntilSend indicates an expected call of TimeUntilSend.
+func (mr *MockPacketNumberSpaceAwareSendAlgorithmMockRecorder) TimeUntilSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockPacketNumberSpaceAwareSendAlgorithm)(nil).TimeUntilSend), arg0)
+}
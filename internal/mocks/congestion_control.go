@@ -0,0 +1,204 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go/congestion (interfaces: CongestionControl)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	congestion "github.com/lucas-clemente/quic-go/congestion"
+)
+
+// MockCongestionControl is a mock of CongestionControl interface.
+type MockCongestionControl struct {
+	ctrl     *gomock.Controller
+	recorder *MockCongestionControlMockRecorder
+}
+
+// MockCongestionControlMockRecorder is the mock recorder for MockCongestionControl.
+type MockCongestionControlMockRecorder struct {
+	mock *MockCongestionControl
+}
+
+// NewMockCongestionControl creates a new mock instance.
+func NewMockCongestionControl(ctrl *gomock.Controller) *MockCongestionControl {
+	mock := &MockCongestionControl{ctrl: ctrl}
+	mock.recorder = &MockCongestionControlMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCongestionControl) EXPECT() *MockCongestionControlMockRecorder {
+	return m.recorder
+}
+
+// CanSend mocks base method.
+func (m *MockCongestionControl) CanSend(arg0 congestion.ByteCount) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanSend", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CanSend indicates an expected call of CanSend.
+func (mr *MockCongestionControlMockRecorder) CanSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanSend", reflect.TypeOf((*MockCongestionControl)(nil).CanSend), arg0)
+}
+
+// GetCongestionWindow mocks base method.
+func (m *MockCongestionControl) GetCongestionWindow() congestion.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionWindow")
+	ret0, _ := ret[0].(congestion.ByteCount)
+	return ret0
+}
+
+// GetCongestionWindow indicates an expected call of GetCongestionWindow.
+func (mr *MockCongestionControlMockRecorder) GetCongestionWindow() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionWindow", reflect.TypeOf((*MockCongestionControl)(nil).GetCongestionWindow))
+}
+
+// HasPacingBudget mocks base method.
+func (m *MockCongestionControl) HasPacingBudget() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPacingBudget")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasPacingBudget indicates an expected call of HasPacingBudget.
+func (mr *MockCongestionControlMockRecorder) HasPacingBudget() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockCongestionControl)(nil).HasPacingBudget))
+}
+
+// InRecovery mocks base method.
+func (m *MockCongestionControl) InRecovery() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InRecovery")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InRecovery indicates an expected call of InRecovery.
+func (mr *MockCongestionControlMockRecorder) InRecovery() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InRecovery", reflect.TypeOf((*MockCongestionControl)(nil).InRecovery))
+}
+
+// InSlowStart mocks base method.
+func (m *MockCongestionControl) InSlowStart() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InSlowStart")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InSlowStart indicates an expected call of InSlowStart.
+func (mr *MockCongestionControlMockRecorder) InSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InSlowStart", reflect.TypeOf((*MockCongestionControl)(nil).InSlowStart))
+}
+
+// MaybeExitSlowStart mocks base method.
+func (m *MockCongestionControl) MaybeExitSlowStart() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MaybeExitSlowStart")
+}
+
+// MaybeExitSlowStart indicates an expected call of MaybeExitSlowStart.
+func (mr *MockCongestionControlMockRecorder) MaybeExitSlowStart() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockCongestionControl)(nil).MaybeExitSlowStart))
+}
+
+// OnApplicationLimited mocks base method.
+func (m *MockCongestionControl) OnApplicationLimited(arg0 congestion.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnApplicationLimited", arg0)
+}
+
+// OnApplicationLimited indicates an expected call of OnApplicationLimited.
+func (mr *MockCongestionControlMockRecorder) OnApplicationLimited(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnApplicationLimited", reflect.TypeOf((*MockCongestionControl)(nil).OnApplicationLimited), arg0)
+}
+
+// OnPacketAcked mocks base method.
+func (m *MockCongestionControl) OnPacketAcked(arg0 congestion.PacketNumber, arg1, arg2 congestion.ByteCount, arg3 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3)
+}
+
+// OnPacketAcked indicates an expected call of OnPacketAcked.
+func (mr *MockCongestionControlMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockCongestionControl)(nil).OnPacketAcked), arg0, arg1, arg2, arg3)
+}
+
+// OnPacketLost mocks base method.
+func (m *MockCongestionControl) OnPacketLost(arg0 congestion.PacketNumber, arg1, arg2 congestion.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketLost", arg0, arg1, arg2)
+}
+
+// OnPacketLost indicates an expected call of OnPacketLost.
+func (mr *MockCongestionControlMockRecorder) OnPacketLost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketLost", reflect.TypeOf((*MockCongestionControl)(nil).OnPacketLost), arg0, arg1, arg2)
+}
+
+// OnPacketSent mocks base method.
+func (m *MockCongestionControl) OnPacketSent(arg0 time.Time, arg1 congestion.ByteCount, arg2 congestion.PacketNumber, arg3 congestion.ByteCount, arg4 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketSent indicates an expected call of OnPacketSent.
+func (mr *MockCongestionControlMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockCongestionControl)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnRetransmissionTimeout mocks base method.
+func (m *MockCongestionControl) OnRetransmissionTimeout(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRetransmissionTimeout", arg0)
+}
+
+// OnRetransmissionTimeout indicates an expected call of OnRetransmissionTimeout.
+func (mr *MockCongestionControlMockRecorder) OnRetransmissionTimeout(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRetransmissionTimeout", reflect.TypeOf((*MockCongestionControl)(nil).OnRetransmissionTimeout), arg0)
+}
+
+// SetMaxDatagramSize mocks base method.
+func (m *MockCongestionControl) SetMaxDatagramSize(arg0 congestion.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxDatagramSize", arg0)
+}
+
+// SetMaxDatagramSize indicates an expected call of SetMaxDatagramSize.
+func (mr *MockCongestionControlMockRecorder) SetMaxDatagramSize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxDatagramSize", reflect.TypeOf((*MockCongestionControl)(nil).SetMaxDatagramSize), arg0)
+}
+
+// TimeUntilSend mocks base method.
+func (m *MockCongestionControl) TimeUntilSend(arg0 congestion.ByteCount) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeUntilSend", arg0)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// TimeUntilSend indicates an expected call of TimeUntilSend.
+func (mr *MockCongestionControlMockRecorder) TimeUntilSend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockCongestionControl)(nil).TimeUntilSend), arg0)
+}
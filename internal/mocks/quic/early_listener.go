@@ -78,3 +78,45 @@ func (mr *MockEarlyListenerMockRecorder) Close() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockEarlyListener)(nil).Close))
 }
+
+// RotateStatelessResetKey mocks base method.
+func (m *MockEarlyListener) RotateStatelessResetKey(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateStatelessResetKey", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateStatelessResetKey indicates an expected call of RotateStatelessResetKey.
+func (mr *MockEarlyListenerMockRecorder) RotateStatelessResetKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateStatelessResetKey", reflect.TypeOf((*MockEarlyListener)(nil).RotateStatelessResetKey), arg0)
+}
+
+// Shutdown mocks base method.
+func (m *MockEarlyListener) Shutdown(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockEarlyListenerMockRecorder) Shutdown(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockEarlyListener)(nil).Shutdown), arg0)
+}
+
+// StatelessResetStats mocks base method.
+func (m *MockEarlyListener) StatelessResetStats() quic.StatelessResetStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatelessResetStats")
+	ret0, _ := ret[0].(quic.StatelessResetStats)
+	return ret0
+}
+
+// StatelessResetStats indicates an expected call of StatelessResetStats.
+func (mr *MockEarlyListenerMockRecorder) StatelessResetStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatelessResetStats", reflect.TypeOf((*MockEarlyListener)(nil).StatelessResetStats))
+}
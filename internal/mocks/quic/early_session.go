@@ -68,6 +68,21 @@ func (mr *MockEarlySessionMockRecorder) AcceptUniStream(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUniStream", reflect.TypeOf((*MockEarlySession)(nil).AcceptUniStream), arg0)
 }
 
+// AddPath mocks base method.
+func (m *MockEarlySession) AddPath(arg0 context.Context, arg1 net.PacketConn) (quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0, arg1)
+	ret0, _ := ret[0].(quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlySessionMockRecorder) AddPath(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlySession)(nil).AddPath), arg0, arg1)
+}
+
 // CloseWithError mocks base method.
 func (m *MockEarlySession) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -138,6 +153,34 @@ func (mr *MockEarlySessionMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockEarlySession)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockEarlySession) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockEarlySessionMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockEarlySession)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockEarlySession) MigrateTo(arg0 context.Context, arg1 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockEarlySessionMockRecorder) MigrateTo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockEarlySession)(nil).MigrateTo), arg0, arg1)
+}
+
 // NextSession mocks base method.
 func (m *MockEarlySession) NextSession() quic.Session {
 	m.ctrl.T.Helper()
@@ -268,3 +311,45 @@ func (mr *MockEarlySessionMockRecorder) SendMessage(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockEarlySession)(nil).SendMessage), arg0)
 }
+
+// SendMessageWithCallback mocks base method.
+func (m *MockEarlySession) SendMessageWithCallback(arg0 []byte, arg1 func(bool)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageWithCallback", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageWithCallback indicates an expected call of SendMessageWithCallback.
+func (mr *MockEarlySessionMockRecorder) SendMessageWithCallback(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithCallback", reflect.TypeOf((*MockEarlySession)(nil).SendMessageWithCallback), arg0, arg1)
+}
+
+// SendMessages mocks base method.
+func (m *MockEarlySession) SendMessages(arg0 [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessages", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessages indicates an expected call of SendMessages.
+func (mr *MockEarlySessionMockRecorder) SendMessages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessages", reflect.TypeOf((*MockEarlySession)(nil).SendMessages), arg0)
+}
+
+// Stats mocks base method.
+func (m *MockEarlySession) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlySessionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlySession)(nil).Stats))
+}
@@ -49,6 +49,20 @@ func (mr *MockShortHeaderOpenerMockRecorder) DecodePacketNumber(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodePacketNumber", reflect.TypeOf((*MockShortHeaderOpener)(nil).DecodePacketNumber), arg0, arg1)
 }
 
+// DecodePathPacketNumber mocks base method.
+func (m *MockShortHeaderOpener) DecodePathPacketNumber(arg0 uint64, arg1 protocol.PacketNumber, arg2 protocol.PacketNumberLen) protocol.PacketNumber {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodePathPacketNumber", arg0, arg1, arg2)
+	ret0, _ := ret[0].(protocol.PacketNumber)
+	return ret0
+}
+
+// DecodePathPacketNumber indicates an expected call of DecodePathPacketNumber.
+func (mr *MockShortHeaderOpenerMockRecorder) DecodePathPacketNumber(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodePathPacketNumber", reflect.TypeOf((*MockShortHeaderOpener)(nil).DecodePathPacketNumber), arg0, arg1, arg2)
+}
+
 // DecryptHeader mocks base method.
 func (m *MockShortHeaderOpener) DecryptHeader(arg0 []byte, arg1 *byte, arg2 []byte) {
 	m.ctrl.T.Helper()
@@ -75,3 +89,18 @@ func (mr *MockShortHeaderOpenerMockRecorder) Open(arg0, arg1, arg2, arg3, arg4,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockShortHeaderOpener)(nil).Open), arg0, arg1, arg2, arg3, arg4, arg5)
 }
+
+// OpenPath mocks base method.
+func (m *MockShortHeaderOpener) OpenPath(arg0, arg1 []byte, arg2 time.Time, arg3 uint64, arg4 protocol.PacketNumber, arg5 protocol.KeyPhaseBit, arg6 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPath", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPath indicates an expected call of OpenPath.
+func (mr *MockShortHeaderOpenerMockRecorder) OpenPath(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPath", reflect.TypeOf((*MockShortHeaderOpener)(nil).OpenPath), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
@@ -60,6 +60,20 @@ func (mr *MockShortHeaderSealerMockRecorder) KeyPhase() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyPhase", reflect.TypeOf((*MockShortHeaderSealer)(nil).KeyPhase))
 }
 
+// NumKeyUpdates mocks base method.
+func (m *MockShortHeaderSealer) NumKeyUpdates() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NumKeyUpdates")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// NumKeyUpdates indicates an expected call of NumKeyUpdates.
+func (mr *MockShortHeaderSealerMockRecorder) NumKeyUpdates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NumKeyUpdates", reflect.TypeOf((*MockShortHeaderSealer)(nil).NumKeyUpdates))
+}
+
 // Overhead mocks base method.
 func (m *MockShortHeaderSealer) Overhead() int {
 	m.ctrl.T.Helper()
@@ -87,3 +101,17 @@ func (mr *MockShortHeaderSealerMockRecorder) Seal(arg0, arg1, arg2, arg3 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockShortHeaderSealer)(nil).Seal), arg0, arg1, arg2, arg3)
 }
+
+// SealPath mocks base method.
+func (m *MockShortHeaderSealer) SealPath(arg0, arg1 []byte, arg2 uint64, arg3 protocol.PacketNumber, arg4 []byte) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealPath", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// SealPath indicates an expected call of SealPath.
+func (mr *MockShortHeaderSealerMockRecorder) SealPath(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealPath", reflect.TypeOf((*MockShortHeaderSealer)(nil).SealPath), arg0, arg1, arg2, arg3, arg4)
+}
@@ -108,6 +108,15 @@ const DefaultHandshakeIdleTimeout = 5 * time.Second
 // DefaultHandshakeTimeout is the default timeout for a connection until the crypto handshake succeeds.
 const DefaultHandshakeTimeout = 10 * time.Second
 
+// MinPathValidationTimeout is the minimum time we wait for a PATH_RESPONSE, before abandoning a new path.
+// RFC 9000 recommends 3 PTOs, with the PTO calculated using an initial RTT of 333ms.
+const MinPathValidationTimeout = 3 * time.Second
+
+// MaxPaths is the maximum number of paths that can be added to a multipath connection,
+// in addition to the path the handshake was performed on.
+// Every path uses a separate connection ID, so it is limited by the number of connection IDs the peer issues.
+const MaxPaths = MaxActiveConnectionIDs - 1
+
 // MaxKeepAliveInterval is the maximum time until we send a packet to keep a connection alive.
 // It should be shorter than the time that NATs clear their mapping.
 const MaxKeepAliveInterval = 20 * time.Second
@@ -137,6 +146,9 @@ const MaxAckFrameSize ByteCount = 1000
 // The size is chosen such that a DATAGRAM frame fits into a QUIC packet.
 const MaxDatagramFrameSize ByteCount = 1220
 
+// DatagramSendQueueLen is the default length of the send queue for DATAGRAM frames.
+const DatagramSendQueueLen = 32
+
 // DatagramRcvQueueLen is the length of the receive queue for DATAGRAM frames.
 // See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 const DatagramRcvQueueLen = 128
@@ -179,6 +191,14 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the min_ack_delay we advertise when using the ACK Frequency extension.
+// The peer can't ask us to delay ACKs by less than this value.
+const MinAckDelay = TimerGranularity
+
+// MaxAckElicitingThreshold is the maximum number of ack-eliciting packets we ask the peer to receive
+// before it sends an ACK, when using the ACK Frequency extension.
+const MaxAckElicitingThreshold = 32
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
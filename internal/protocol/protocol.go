@@ -61,6 +61,10 @@ type StatelessResetToken [16]byte
 // Ethernet's max packet size is 1500 bytes,  1500 - 48 = 1452.
 const MaxPacketBufferSize ByteCount = 1452
 
+// MaxLargePacketBufferSize is the size of the buffers used to send multiple packets using a single syscall (GSO).
+// The kernel limits the number of segments per send call to 64, and the total size to 64 KB.
+const MaxLargePacketBufferSize = 20 * MaxPacketBufferSize
+
 // MinInitialPacketSize is the minimum size an Initial packet is required to have.
 const MinInitialPacketSize = 1200
 
@@ -71,6 +75,10 @@ const MinUnknownVersionPacketSize = MinInitialPacketSize
 // MinStatelessResetSize is the minimum size of a stateless reset packet that we send
 const MinStatelessResetSize = 1 /* first byte */ + 20 /* max. conn ID length */ + 4 /* max. packet number length */ + 1 /* min. payload length */ + 16 /* token */
 
+// AmplificationFactor is the anti-amplification limit (RFC 9000, Section 8):
+// Before a peer's address is validated, we don't send more than this many times the number of bytes received from it.
+const AmplificationFactor = 3
+
 // MinConnectionIDLenInitial is the minimum length of the destination connection ID on an Initial packet.
 const MinConnectionIDLenInitial = 8
 
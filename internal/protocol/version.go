@@ -23,11 +23,12 @@ const (
 	VersionUnknown  VersionNumber = math.MaxUint32
 	VersionDraft29  VersionNumber = 0xff00001d
 	Version1        VersionNumber = 0x1
+	Version2        VersionNumber = 0x6b3343cf
 )
 
 // SupportedVersions lists the versions that the server supports
 // must be in sorted descending order
-var SupportedVersions = []VersionNumber{Version1, VersionDraft29}
+var SupportedVersions = []VersionNumber{Version1, VersionDraft29, Version2}
 
 // IsValidVersion says if the version is known to quic-go
 func IsValidVersion(v VersionNumber) bool {
@@ -50,6 +51,8 @@ func (vn VersionNumber) String() string {
 		return "draft-29"
 	case Version1:
 		return "v1"
+	case Version2:
+		return "v2"
 	default:
 		if vn.isGQUIC() {
 			return fmt.Sprintf("gQUIC %d", vn.toGQUICVersion())
@@ -91,6 +94,34 @@ func ChooseSupportedVersion(ours, theirs []VersionNumber) (VersionNumber, bool)
 	return 0, false
 }
 
+// AreCompatibleVersions says if a connection can be switched from one version to the other
+// using compatible version negotiation (RFC 9368).
+// QUIC v1 and QUIC v2 are compatible with each other.
+func AreCompatibleVersions(v1, v2 VersionNumber) bool {
+	if v1 == v2 {
+		return true
+	}
+	return (v1 == Version1 && v2 == Version2) || (v1 == Version2 && v2 == Version1)
+}
+
+// ChooseCompatibleVersion finds the best version in the overlap of ours and theirs
+// that the connection can be switched to from the current version.
+// ours is a slice of versions that we support, sorted by our preference (descending).
+// The bool returned indicates if a matching version was found.
+func ChooseCompatibleVersion(ours, theirs []VersionNumber, current VersionNumber) (VersionNumber, bool) {
+	for _, ourVer := range ours {
+		if !AreCompatibleVersions(current, ourVer) {
+			continue
+		}
+		for _, theirVer := range theirs {
+			if ourVer == theirVer {
+				return ourVer, true
+			}
+		}
+	}
+	return 0, false
+}
+
 // generateReservedVersion generates a reserved version number (v & 0x0f0f0f0f == 0x0a0a0a0a)
 func generateReservedVersion() VersionNumber {
 	b := make([]byte, 4)
@@ -16,6 +16,7 @@ var _ = Describe("Version", func() {
 		Expect(IsValidVersion(VersionUnknown)).To(BeFalse())
 		Expect(IsValidVersion(VersionDraft29)).To(BeTrue())
 		Expect(IsValidVersion(Version1)).To(BeTrue())
+		Expect(IsValidVersion(Version2)).To(BeTrue())
 		Expect(IsValidVersion(1234)).To(BeFalse())
 	})
 
@@ -28,6 +29,7 @@ var _ = Describe("Version", func() {
 		Expect(VersionUnknown.String()).To(Equal("unknown"))
 		Expect(VersionDraft29.String()).To(Equal("draft-29"))
 		Expect(Version1.String()).To(Equal("v1"))
+		Expect(Version2.String()).To(Equal("v2"))
 		// check with unsupported version numbers from the wiki
 		Expect(VersionNumber(0x51303039).String()).To(Equal("gQUIC 9"))
 		Expect(VersionNumber(0x51303133).String()).To(Equal("gQUIC 13"))
@@ -81,6 +83,30 @@ var _ = Describe("Version", func() {
 		})
 	})
 
+	Context("compatible versions", func() {
+		It("says which versions are compatible", func() {
+			Expect(AreCompatibleVersions(Version1, Version2)).To(BeTrue())
+			Expect(AreCompatibleVersions(Version2, Version1)).To(BeTrue())
+			Expect(AreCompatibleVersions(Version1, Version1)).To(BeTrue())
+			Expect(AreCompatibleVersions(Version1, VersionDraft29)).To(BeFalse())
+			Expect(AreCompatibleVersions(VersionDraft29, Version2)).To(BeFalse())
+		})
+
+		It("picks the preferred compatible version", func() {
+			ver, ok := ChooseCompatibleVersion([]VersionNumber{Version2, Version1}, []VersionNumber{Version1, Version2}, Version1)
+			Expect(ok).To(BeTrue())
+			Expect(ver).To(Equal(Version2))
+		})
+
+		It("doesn't pick incompatible versions", func() {
+			ver, ok := ChooseCompatibleVersion([]VersionNumber{VersionDraft29, Version1}, []VersionNumber{VersionDraft29, Version1}, Version1)
+			Expect(ok).To(BeTrue())
+			Expect(ver).To(Equal(Version1))
+			_, ok = ChooseCompatibleVersion([]VersionNumber{Version2}, []VersionNumber{Version1}, Version1)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	Context("reserved versions", func() {
 		It("adds a greased version if passed an empty slice", func() {
 			greased := GetGreasedVersions([]VersionNumber{})
@@ -7,7 +7,9 @@ import (
 	"crypto"
 	"crypto/cipher"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
+	"time"
 	"unsafe"
 
 	"github.com/marten-seemann/qtls-go1-17"
@@ -98,3 +100,33 @@ func CipherSuiteTLS13ByID(id uint16) *CipherSuiteTLS13 {
 		Hash:   cs.Hash,
 	}
 }
+
+// ClientSessionStateData contains the fields of a ClientSessionState,
+// which is opaque otherwise. It has the same memory layout as qtls' clientSessionState.
+type ClientSessionStateData struct {
+	SessionTicket      []uint8
+	Vers               uint16
+	CipherSuite        uint16
+	MasterSecret       []byte
+	ServerCertificates []*x509.Certificate
+	VerifiedChains     [][]*x509.Certificate
+	ReceivedAt         time.Time
+	OCSPResponse       []byte
+	SCTs               [][]byte
+	// TLS 1.3 fields
+	Nonce  []byte
+	UseBy  time.Time
+	AgeAdd uint32
+}
+
+// ToClientSessionStateData returns a copy of the fields of a ClientSessionState.
+func ToClientSessionStateData(s *ClientSessionState) *ClientSessionStateData {
+	d := *(*ClientSessionStateData)(unsafe.Pointer(s))
+	return &d
+}
+
+// FromClientSessionStateData creates a ClientSessionState.
+func FromClientSessionStateData(d *ClientSessionStateData) *ClientSessionState {
+	s := *d
+	return (*ClientSessionState)(unsafe.Pointer(&s))
+}
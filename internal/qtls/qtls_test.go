@@ -2,6 +2,8 @@ package qtls
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,4 +16,23 @@ var _ = Describe("qtls wrapper", func() {
 			Expect(cs.ID).To(Equal(id))
 		}
 	})
+
+	It("converts ClientSessionStates", func() {
+		d := &ClientSessionStateData{
+			SessionTicket:      []byte("ticket"),
+			Vers:               tls.VersionTLS13,
+			CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+			MasterSecret:       []byte("secret"),
+			ServerCertificates: []*x509.Certificate{{Raw: []byte("cert")}},
+			ReceivedAt:         time.Now(),
+			Nonce:              []byte("nonce"),
+			UseBy:              time.Now().Add(time.Hour),
+			AgeAdd:             1337,
+		}
+		s := FromClientSessionStateData(d)
+		Expect(ToClientSessionStateData(s)).To(Equal(d))
+		// modifying the data doesn't modify the ClientSessionState
+		d.AgeAdd = 42
+		Expect(ToClientSessionStateData(s).AgeAdd).To(BeEquivalentTo(1337))
+	})
 })
@@ -27,8 +27,12 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 	if err != nil {
 		return nil, err
 	}
-	ecn := typeByte&0x1 > 0
+	return parseAckFrameBody(r, typeByte&0x1 > 0, ackDelayExponent)
+}
 
+// parseAckFrameBody reads the fields of an ACK frame following the frame type.
+// It is shared between the ACK and the ACK_MP frame.
+func parseAckFrameBody(r *bytes.Reader, ecn bool, ackDelayExponent uint8) (*AckFrame, error) {
 	frame := &AckFrame{}
 
 	la, err := quicvarint.Read(r)
@@ -94,12 +98,16 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 		return nil, errInvalidAckRanges
 	}
 
-	// parse (and skip) the ECN section
+	// parse the ECN section
 	if ecn {
-		for i := 0; i < 3; i++ {
-			if _, err := quicvarint.Read(r); err != nil {
-				return nil, err
-			}
+		if frame.ECT0, err = quicvarint.Read(r); err != nil {
+			return nil, err
+		}
+		if frame.ECT1, err = quicvarint.Read(r); err != nil {
+			return nil, err
+		}
+		if frame.ECNCE, err = quicvarint.Read(r); err != nil {
+			return nil, err
 		}
 	}
 
@@ -108,12 +116,21 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 
 // Write writes an ACK frame.
 func (f *AckFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
-	hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
-	if hasECN {
+	if f.hasECN() {
 		b.WriteByte(0x3)
 	} else {
 		b.WriteByte(0x2)
 	}
+	f.writeBody(b)
+	return nil
+}
+
+func (f *AckFrame) hasECN() bool {
+	return f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
+}
+
+// writeBody writes all fields of the frame following the frame type.
+func (f *AckFrame) writeBody(b *bytes.Buffer) {
 	quicvarint.Write(b, uint64(f.LargestAcked()))
 	quicvarint.Write(b, encodeAckDelay(f.DelayTime))
 
@@ -131,20 +148,23 @@ func (f *AckFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
 		quicvarint.Write(b, len)
 	}
 
-	if hasECN {
+	if f.hasECN() {
 		quicvarint.Write(b, f.ECT0)
 		quicvarint.Write(b, f.ECT1)
 		quicvarint.Write(b, f.ECNCE)
 	}
-	return nil
 }
 
 // Length of a written frame
 func (f *AckFrame) Length(version protocol.VersionNumber) protocol.ByteCount {
+	return 1 + f.bodyLength()
+}
+
+func (f *AckFrame) bodyLength() protocol.ByteCount {
 	largestAcked := f.AckRanges[0].Largest
 	numRanges := f.numEncodableAckRanges()
 
-	length := 1 + quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
+	length := quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
 
 	length += quicvarint.Len(uint64(numRanges - 1))
 	lowestInFirstRange := f.AckRanges[0].Smallest
@@ -155,7 +175,7 @@ func (f *AckFrame) Length(version protocol.VersionNumber) protocol.ByteCount {
 		length += quicvarint.Len(gap)
 		length += quicvarint.Len(len)
 	}
-	if f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0 {
+	if f.hasECN() {
 		length += quicvarint.Len(f.ECT0)
 		length += quicvarint.Len(f.ECT1)
 		length += quicvarint.Len(f.ECNCE)
@@ -0,0 +1,67 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const ackFrequencyFrameType = 0xaf
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame, see https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/.
+type AckFrequencyFrame struct {
+	SequenceNumber        uint64
+	AckElicitingThreshold uint64
+	RequestMaxAckDelay    time.Duration
+	ReorderingThreshold   uint64
+}
+
+func parseAckFrequencyFrame(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrequencyFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	aeth, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	mad, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	// prevents overflows if the peer sends a very large value
+	if mad > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+		return nil, errors.New("invalid value for Request Max Ack Delay")
+	}
+	rth, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &AckFrequencyFrame{
+		SequenceNumber:        seq,
+		AckElicitingThreshold: aeth,
+		RequestMaxAckDelay:    time.Duration(mad) * time.Microsecond,
+		ReorderingThreshold:   rth,
+	}, nil
+}
+
+func (f *AckFrequencyFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	quicvarint.Write(b, ackFrequencyFrameType)
+	quicvarint.Write(b, f.SequenceNumber)
+	quicvarint.Write(b, f.AckElicitingThreshold)
+	quicvarint.Write(b, uint64(f.RequestMaxAckDelay/time.Microsecond))
+	quicvarint.Write(b, f.ReorderingThreshold)
+	return nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(ackFrequencyFrameType) + quicvarint.Len(f.SequenceNumber) + quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestMaxAckDelay/time.Microsecond)) + quicvarint.Len(f.ReorderingThreshold)
+}
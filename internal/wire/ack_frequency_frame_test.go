@@ -0,0 +1,86 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("parsing", func() {
+		It("accepts sample frame", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(0xdeadbeef)...) // Sequence Number
+			data = append(data, encodeVarInt(0xcafe)...)     // Ack-Eliciting Threshold
+			data = append(data, encodeVarInt(1337)...)       // Request Max Ack Delay
+			data = append(data, encodeVarInt(3)...)          // Reordering Threshold
+			b := bytes.NewReader(data)
+			frame, err := parseAckFrequencyFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SequenceNumber).To(Equal(uint64(0xdeadbeef)))
+			Expect(frame.AckElicitingThreshold).To(BeEquivalentTo(0xcafe))
+			Expect(frame.RequestMaxAckDelay).To(Equal(1337 * time.Microsecond))
+			Expect(frame.ReorderingThreshold).To(BeEquivalentTo(3))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors when the Request Max Ack Delay is too large", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(1)...)
+			data = append(data, encodeVarInt(1)...)
+			data = append(data, encodeVarInt(uint64(protocol.MaxMaxAckDelay/time.Microsecond)+1)...)
+			data = append(data, encodeVarInt(1)...)
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("invalid value for Request Max Ack Delay"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(0xdeadbeef)...)
+			data = append(data, encodeVarInt(0xcafe)...)
+			data = append(data, encodeVarInt(1337)...)
+			data = append(data, encodeVarInt(3)...)
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckFrequencyFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a sample frame", func() {
+			b := &bytes.Buffer{}
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0xdecafbad,
+				AckElicitingThreshold: 0xcafe,
+				RequestMaxAckDelay:    1337 * time.Microsecond,
+				ReorderingThreshold:   1,
+			}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			expected := encodeVarInt(0xaf)
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(1337)...)
+			expected = append(expected, encodeVarInt(1)...)
+			Expect(b.Bytes()).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			b := &bytes.Buffer{}
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0xdecafbad,
+				AckElicitingThreshold: 0xcafe,
+				RequestMaxAckDelay:    12 * time.Millisecond,
+				ReorderingThreshold:   0x1337,
+			}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			Expect(f.Length(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+		})
+	})
+})
@@ -0,0 +1,54 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const (
+	ackMPFrameType    = 0x15228c00
+	ackMPECNFrameType = 0x15228c01
+)
+
+// An AckMPFrame is an ACK_MP frame, see https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/.
+// It acknowledges packets sent in the packet number space of the path identified by PathIdentifier,
+// which is the sequence number of the connection ID the acknowledged packets were sent to.
+type AckMPFrame struct {
+	PathIdentifier uint64
+	AckFrame
+}
+
+func parseAckMPFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNumber) (*AckMPFrame, error) {
+	typ, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	ack, err := parseAckFrameBody(r, typ == ackMPECNFrameType, ackDelayExponent)
+	if err != nil {
+		return nil, err
+	}
+	return &AckMPFrame{PathIdentifier: pathID, AckFrame: *ack}, nil
+}
+
+// Write writes an ACK_MP frame.
+func (f *AckMPFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	if f.hasECN() {
+		quicvarint.Write(b, ackMPECNFrameType)
+	} else {
+		quicvarint.Write(b, ackMPFrameType)
+	}
+	quicvarint.Write(b, f.PathIdentifier)
+	f.writeBody(b)
+	return nil
+}
+
+// Length of a written frame
+func (f *AckMPFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(ackMPFrameType) + quicvarint.Len(f.PathIdentifier) + f.bodyLength()
+}
@@ -0,0 +1,90 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_MP frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x15228c00)
+			data = append(data, encodeVarInt(7)...)   // Packet Number Space Identifier
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			b := bytes.NewReader(data)
+			frame, err := parseAckMPFrame(b, protocol.AckDelayExponent, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(BeEquivalentTo(7))
+			Expect(frame.LargestAcked()).To(Equal(protocol.PacketNumber(100)))
+			Expect(frame.LowestAcked()).To(Equal(protocol.PacketNumber(90)))
+			Expect(frame.HasMissingRanges()).To(BeFalse())
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("parses the ECN section", func() {
+			data := encodeVarInt(0x15228c01)
+			data = append(data, encodeVarInt(1)...)   // Packet Number Space Identifier
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(0x12345)...)
+			data = append(data, encodeVarInt(0x12345678)...)
+			b := bytes.NewReader(data)
+			frame, err := parseAckMPFrame(b, protocol.AckDelayExponent, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.ECT0).To(BeEquivalentTo(0x42))
+			Expect(frame.ECT1).To(BeEquivalentTo(0x12345))
+			Expect(frame.ECNCE).To(BeEquivalentTo(0x12345678))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0x15228c00)
+			data = append(data, encodeVarInt(7)...)
+			data = append(data, encodeVarInt(1000)...)
+			data = append(data, encodeVarInt(0)...)
+			data = append(data, encodeVarInt(0)...)
+			data = append(data, encodeVarInt(100)...)
+			_, err := parseAckMPFrame(bytes.NewReader(data), protocol.AckDelayExponent, versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckMPFrame(bytes.NewReader(data[0:i]), protocol.AckDelayExponent, versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a frame that can be parsed again", func() {
+			f := &AckMPFrame{
+				PathIdentifier: 0x1337,
+				AckFrame: AckFrame{
+					AckRanges: []AckRange{
+						{Smallest: 400, Largest: 1000},
+						{Smallest: 100, Largest: 200},
+					},
+					DelayTime: 10 * time.Millisecond,
+					ECT0:      13,
+				},
+			}
+			b := &bytes.Buffer{}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			Expect(f.Length(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+			r := bytes.NewReader(b.Bytes())
+			frame, err := parseAckMPFrame(r, protocol.AckDelayExponent, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(f))
+			Expect(r.Len()).To(BeZero())
+		})
+	})
+})
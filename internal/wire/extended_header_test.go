@@ -132,6 +132,26 @@ var _ = Describe("Header", func() {
 				expected = append(expected, token...)
 				Expect(buf.Bytes()).To(Equal(expected))
 			})
+
+			It("uses the QUIC v2 packet type encoding", func() {
+				for packetType, typeBits := range map[protocol.PacketType]byte{
+					protocol.PacketTypeInitial:   0x1,
+					protocol.PacketType0RTT:      0x2,
+					protocol.PacketTypeHandshake: 0x3,
+					protocol.PacketTypeRetry:     0x0,
+				} {
+					buf.Reset()
+					Expect((&ExtendedHeader{
+						Header: Header{
+							IsLongHeader: true,
+							Version:      protocol.Version2,
+							Type:         packetType,
+						},
+						PacketNumberLen: protocol.PacketNumberLen1,
+					}).Write(buf, protocol.Version2)).To(Succeed())
+					Expect(buf.Bytes()[0] & 0x30 >> 4).To(Equal(typeBits))
+				}
+			})
 		})
 
 		Context("short header", func() {
@@ -4,25 +4,31 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
 type frameParser struct {
 	ackDelayExponent uint8
 
-	supportsDatagrams bool
+	supportsDatagrams    bool
+	supportsAckFrequency bool
+	supportsMultipath    bool
 
 	version protocol.VersionNumber
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool, v protocol.VersionNumber) FrameParser {
+func NewFrameParser(supportsDatagrams, supportsAckFrequency, supportsMultipath bool, v protocol.VersionNumber) FrameParser {
 	return &frameParser{
-		supportsDatagrams: supportsDatagrams,
-		version:           v,
+		supportsDatagrams:    supportsDatagrams,
+		supportsAckFrequency: supportsAckFrequency,
+		supportsMultipath:    supportsMultipath,
+		version:              v,
 	}
 }
 
@@ -36,10 +42,29 @@ func (p *frameParser) ParseNext(r *bytes.Reader, encLevel protocol.EncryptionLev
 		}
 		r.UnreadByte()
 
-		f, err := p.parseFrame(r, typeByte, encLevel)
+		// The frame type is a varint. Most frame types are encoded in a single byte.
+		startLen := r.Len()
+		frameType, err := quicvarint.Read(r)
 		if err != nil {
 			return nil, &qerr.TransportError{
-				FrameType:    uint64(typeByte),
+				ErrorCode:    qerr.FrameEncodingError,
+				ErrorMessage: err.Error(),
+			}
+		}
+		typeLen := startLen - r.Len()
+		if typeLen != int(quicvarint.Len(frameType)) {
+			return nil, &qerr.TransportError{
+				FrameType:    frameType,
+				ErrorCode:    qerr.FrameEncodingError,
+				ErrorMessage: "frame type not minimally encoded",
+			}
+		}
+		r.Seek(-int64(typeLen), io.SeekCurrent)
+
+		f, err := p.parseFrame(r, frameType, encLevel)
+		if err != nil {
+			return nil, &qerr.TransportError{
+				FrameType:    frameType,
 				ErrorCode:    qerr.FrameEncodingError,
 				ErrorMessage: err.Error(),
 			}
@@ -49,13 +74,13 @@ func (p *frameParser) ParseNext(r *bytes.Reader, encLevel protocol.EncryptionLev
 	return nil, nil
 }
 
-func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protocol.EncryptionLevel) (Frame, error) {
+func (p *frameParser) parseFrame(r *bytes.Reader, frameType uint64, encLevel protocol.EncryptionLevel) (Frame, error) {
 	var frame Frame
 	var err error
-	if typeByte&0xf8 == 0x8 {
+	if frameType&0xf8 == 0x8 {
 		frame, err = parseStreamFrame(r, p.version)
 	} else {
-		switch typeByte {
+		switch frameType {
 		case 0x1:
 			frame, err = parsePingFrame(r, p.version)
 		case 0x2, 0x3:
@@ -96,6 +121,36 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 			frame, err = parseConnectionCloseFrame(r, p.version)
 		case 0x1e:
 			frame, err = parseHandshakeDoneFrame(r, p.version)
+		case immediateAckFrameType:
+			if p.supportsAckFrequency {
+				frame, err = parseImmediateAckFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case ackFrequencyFrameType:
+			if p.supportsAckFrequency {
+				frame, err = parseAckFrequencyFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case ackMPFrameType, ackMPECNFrameType:
+			if p.supportsMultipath {
+				frame, err = parseAckMPFrame(r, p.ackDelayExponent, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case pathAbandonFrameType:
+			if p.supportsMultipath {
+				frame, err = parsePathAbandonFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case pathStatusFrameType:
+			if p.supportsMultipath {
+				frame, err = parsePathStatusFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, err = parseDatagramFrame(r, p.version)
@@ -126,7 +181,7 @@ func (p *frameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 		}
 	case protocol.Encryption0RTT:
 		switch f.(type) {
-		case *CryptoFrame, *AckFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
+		case *CryptoFrame, *AckFrame, *AckMPFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
 			return false
 		default:
 			return true
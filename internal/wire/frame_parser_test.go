@@ -6,6 +6,8 @@ import (
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -18,7 +20,7 @@ var _ = Describe("Frame parsing", func() {
 
 	BeforeEach(func() {
 		buf = &bytes.Buffer{}
-		parser = NewFrameParser(true, versionIETFFrames)
+		parser = NewFrameParser(true, true, true, versionIETFFrames)
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -289,8 +291,104 @@ var _ = Describe("Frame parsing", func() {
 		Expect(frame).To(Equal(f))
 	})
 
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        1337,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    42 * time.Millisecond,
+			ReorderingThreshold:   3,
+		}
+		buf := &bytes.Buffer{}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		f := &ImmediateAckFrame{}
+		buf := &bytes.Buffer{}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("unpacks ACK_MP frames", func() {
+		f := &AckMPFrame{
+			PathIdentifier: 3,
+			AckFrame:       AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 0x13}}},
+		}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(BeAssignableToTypeOf(f))
+		Expect(frame.(*AckMPFrame).PathIdentifier).To(BeEquivalentTo(3))
+		Expect(frame.(*AckMPFrame).LargestAcked()).To(Equal(protocol.PacketNumber(0x13)))
+	})
+
+	It("unpacks PATH_ABANDON frames", func() {
+		f := &PathAbandonFrame{
+			PathIdentifier: 2,
+			ErrorCode:      0x42,
+			ReasonPhrase:   "foobar",
+		}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("unpacks PATH_STATUS frames", func() {
+		f := &PathStatusFrame{
+			PathIdentifier: 2,
+			SequenceNumber: 7,
+			Status:         PathStatusStandby,
+		}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("errors when the multipath extension is not supported", func() {
+		parser = NewFrameParser(true, true, false, versionIETFFrames)
+		for _, f := range []Frame{
+			&AckMPFrame{AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 2}}}},
+			&PathAbandonFrame{},
+			&PathStatusFrame{Status: PathStatusAvailable},
+		} {
+			b := &bytes.Buffer{}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			_, err := parser.ParseNext(bytes.NewReader(b.Bytes()), protocol.Encryption1RTT)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
+			Expect(err.(*qerr.TransportError).ErrorMessage).To(Equal("unknown frame type"))
+		}
+	})
+
+	It("errors when the ACK Frequency extension is not supported", func() {
+		parser = NewFrameParser(true, false, true, versionIETFFrames)
+		buf := &bytes.Buffer{}
+		Expect((&AckFrequencyFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		_, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0xaf,
+			ErrorMessage: "unknown frame type",
+		}))
+		buf.Reset()
+		Expect((&ImmediateAckFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		_, err = parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x1f,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
 	It("errors when DATAGRAM frames are not supported", func() {
-		parser = NewFrameParser(false, versionIETFFrames)
+		parser = NewFrameParser(false, true, true, versionIETFFrames)
 		f := &DatagramFrame{Data: []byte("foobar")}
 		buf := &bytes.Buffer{}
 		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
@@ -303,14 +401,34 @@ var _ = Describe("Frame parsing", func() {
 	})
 
 	It("errors on invalid type", func() {
-		_, err := parser.ParseNext(bytes.NewReader([]byte{0x42}), protocol.Encryption1RTT)
+		_, err := parser.ParseNext(bytes.NewReader([]byte{0x3f}), protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x3f,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("errors on invalid multi-byte types", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, 0x1337)
+		_, err := parser.ParseNext(bytes.NewReader(b.Bytes()), protocol.Encryption1RTT)
 		Expect(err).To(MatchError(&qerr.TransportError{
 			ErrorCode:    qerr.FrameEncodingError,
-			FrameType:    0x42,
+			FrameType:    0x1337,
 			ErrorMessage: "unknown frame type",
 		}))
 	})
 
+	It("errors on frame types that are not minimally encoded", func() {
+		_, err := parser.ParseNext(bytes.NewReader([]byte{0x40, 0x01}), protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x1,
+			ErrorMessage: "frame type not minimally encoded",
+		}))
+	})
+
 	It("errors on invalid frames", func() {
 		f := &MaxStreamDataFrame{
 			StreamID:          0x1337,
@@ -345,6 +463,11 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&AckFrequencyFrame{},
+			&ImmediateAckFrame{},
+			&AckMPFrame{AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 42}}}},
+			&PathAbandonFrame{},
+			&PathStatusFrame{Status: PathStatusAvailable},
 		}
 
 		var framesSerialized [][]byte
@@ -386,11 +509,11 @@ var _ = Describe("Frame parsing", func() {
 			}
 		})
 
-		It("rejects all frames but ACK, CRYPTO, CONNECTION_CLOSE, NEW_TOKEN, PATH_RESPONSE and RETIRE_CONNECTION_ID in 0-RTT packets", func() {
+		It("rejects ACK, ACK_MP, CRYPTO, CONNECTION_CLOSE, NEW_TOKEN, PATH_RESPONSE and RETIRE_CONNECTION_ID in 0-RTT packets", func() {
 			for i, b := range framesSerialized {
 				_, err := parser.ParseNext(bytes.NewReader(b), protocol.Encryption0RTT)
 				switch frames[i].(type) {
-				case *AckFrame, *ConnectionCloseFrame, *CryptoFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
+				case *AckFrame, *AckMPFrame, *ConnectionCloseFrame, *CryptoFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
 					Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
 					Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
 					Expect(err.(*qerr.TransportError).ErrorMessage).To(ContainSubstring("not allowed at encryption level 0-RTT"))
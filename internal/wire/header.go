@@ -53,10 +53,60 @@ func Is0RTTPacket(b []byte) bool {
 	if b[0]&0x80 == 0 {
 		return false
 	}
-	if !protocol.IsSupportedVersion(protocol.SupportedVersions, protocol.VersionNumber(binary.BigEndian.Uint32(b[1:5]))) {
+	version := protocol.VersionNumber(binary.BigEndian.Uint32(b[1:5]))
+	if !protocol.IsSupportedVersion(protocol.SupportedVersions, version) {
 		return false
 	}
-	return b[0]&0x30>>4 == 0x1
+	return decodeLongHeaderType(b[0], version) == protocol.PacketType0RTT
+}
+
+// decodeLongHeaderType decodes the packet type from the first byte of a long header packet.
+// QUIC v2 uses a different encoding than QUIC v1, see RFC 9369, Section 3.2.
+func decodeLongHeaderType(typeByte byte, v protocol.VersionNumber) protocol.PacketType {
+	typeBits := (typeByte & 0x30) >> 4
+	if v == protocol.Version2 {
+		switch typeBits {
+		case 0x1:
+			return protocol.PacketTypeInitial
+		case 0x2:
+			return protocol.PacketType0RTT
+		case 0x3:
+			return protocol.PacketTypeHandshake
+		default:
+			return protocol.PacketTypeRetry
+		}
+	}
+	switch typeBits {
+	case 0x0:
+		return protocol.PacketTypeInitial
+	case 0x1:
+		return protocol.PacketType0RTT
+	case 0x2:
+		return protocol.PacketTypeHandshake
+	default:
+		return protocol.PacketTypeRetry
+	}
+}
+
+// encodeLongHeaderType encodes the packet type for the first byte of a long header packet.
+func encodeLongHeaderType(t protocol.PacketType, v protocol.VersionNumber) uint8 {
+	var typeBits uint8
+	//nolint:exhaustive // Only long header packet types are encoded.
+	switch t {
+	case protocol.PacketTypeInitial:
+		typeBits = 0x0
+	case protocol.PacketType0RTT:
+		typeBits = 0x1
+	case protocol.PacketTypeHandshake:
+		typeBits = 0x2
+	case protocol.PacketTypeRetry:
+		typeBits = 0x3
+	}
+	if v == protocol.Version2 {
+		// v2 rotates the packet types by one: Initial is 0b01, ..., Retry is 0b00
+		typeBits = (typeBits + 1) % 4
+	}
+	return typeBits << 4
 }
 
 var ErrUnsupportedVersion = errors.New("unsupported version")
@@ -179,16 +229,7 @@ func (h *Header) parseLongHeader(b *bytes.Reader) error {
 		return ErrUnsupportedVersion
 	}
 
-	switch (h.typeByte & 0x30) >> 4 {
-	case 0x0:
-		h.Type = protocol.PacketTypeInitial
-	case 0x1:
-		h.Type = protocol.PacketType0RTT
-	case 0x2:
-		h.Type = protocol.PacketTypeHandshake
-	case 0x3:
-		h.Type = protocol.PacketTypeRetry
-	}
+	h.Type = decodeLongHeaderType(h.typeByte, h.Version)
 
 	if h.Type == protocol.PacketTypeRetry {
 		tokenLen := b.Len() - 16
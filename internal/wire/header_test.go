@@ -109,6 +109,14 @@ var _ = Describe("Header Parsing", func() {
 			Expect(Is0RTTPacket(zeroRTTHeader)).To(BeTrue())
 			Expect(Is0RTTPacket(append(zeroRTTHeader, []byte("foobar")...))).To(BeTrue())
 		})
+
+		It("recognizes QUIC v2 0-RTT packets", func() {
+			b := []byte{0x80 | 0x2<<4}
+			b = appendVersion(b, protocol.Version2)
+			Expect(Is0RTTPacket(b)).To(BeTrue())
+			b[0] = 0x80 | 0x1<<4 // Initial packet
+			Expect(Is0RTTPacket(b)).To(BeFalse())
+		})
 	})
 
 	Context("Identifying Version Negotiation Packets", func() {
@@ -264,6 +272,42 @@ var _ = Describe("Header Parsing", func() {
 			Expect(rest).To(BeEmpty())
 		})
 
+		It("parses QUIC v2 packet types", func() {
+			for typeBits, packetType := range map[byte]protocol.PacketType{
+				0x1: protocol.PacketTypeInitial,
+				0x2: protocol.PacketType0RTT,
+				0x3: protocol.PacketTypeHandshake,
+			} {
+				data := []byte{0xc0 | typeBits<<4}
+				data = appendVersion(data, protocol.Version2)
+				data = append(data, 0x4)                   // dest conn id length
+				data = append(data, []byte{1, 2, 3, 4}...) // dest conn id
+				data = append(data, 0x0)                   // src conn id length
+				if packetType == protocol.PacketTypeInitial {
+					data = append(data, encodeVarInt(0)...) // token length
+				}
+				data = append(data, encodeVarInt(4)...)          // length
+				data = append(data, []byte{0, 0, 0xbe, 0xef}...) // packet number
+				hdr, _, _, err := ParsePacket(data, 0)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hdr.Version).To(Equal(protocol.Version2))
+				Expect(hdr.Type).To(Equal(packetType))
+			}
+		})
+
+		It("parses a QUIC v2 Retry packet", func() {
+			data := []byte{0xc0}
+			data = appendVersion(data, protocol.Version2)
+			data = append(data, []byte{4, 1, 2, 3, 4}...) // dest conn ID
+			data = append(data, []byte{0}...)             // src conn ID len
+			data = append(data, []byte("foobar")...)      // token
+			data = append(data, make([]byte, 16)...)      // integrity tag
+			hdr, _, _, err := ParsePacket(data, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hdr.Type).To(Equal(protocol.PacketTypeRetry))
+			Expect(hdr.Token).To(Equal([]byte("foobar")))
+		})
+
 		It("errors if the Retry packet is too short for the integrity tag", func() {
 			data := []byte{0xc0 | 0x3<<4 | (10 - 3) /* connection ID length */}
 			data = appendVersion(data, versionIETFFrames)
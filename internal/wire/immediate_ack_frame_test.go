@@ -0,0 +1,31 @@
+package wire
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMMEDIATE_ACK frame", func() {
+	It("parses", func() {
+		b := bytes.NewReader([]byte{0x1f})
+		f, err := parseImmediateAckFrame(b, versionIETFFrames)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f).To(Equal(&ImmediateAckFrame{}))
+		Expect(b.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		_, err := parseImmediateAckFrame(bytes.NewReader(nil), versionIETFFrames)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("writes", func() {
+		b := &bytes.Buffer{}
+		f := &ImmediateAckFrame{}
+		Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+		Expect(b.Bytes()).To(Equal([]byte{0x1f}))
+		Expect(f.Length(versionIETFFrames)).To(BeEquivalentTo(1))
+	})
+})
@@ -0,0 +1,64 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const pathAbandonFrameType = 0x15228c05
+
+// A PathAbandonFrame is a PATH_ABANDON frame, see https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/.
+// The PathIdentifier is the sequence number of the connection ID the receiver of the frame uses to send on the path.
+type PathAbandonFrame struct {
+	PathIdentifier uint64
+	ErrorCode      uint64
+	ReasonPhrase   string
+}
+
+func parsePathAbandonFrame(r *bytes.Reader, _ protocol.VersionNumber) (*PathAbandonFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	ec, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	reasonPhraseLen, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if int(reasonPhraseLen) > r.Len() {
+		return nil, io.EOF
+	}
+	reasonPhrase := make([]byte, reasonPhraseLen)
+	if _, err := io.ReadFull(r, reasonPhrase); err != nil {
+		return nil, err
+	}
+	return &PathAbandonFrame{
+		PathIdentifier: pathID,
+		ErrorCode:      ec,
+		ReasonPhrase:   string(reasonPhrase),
+	}, nil
+}
+
+func (f *PathAbandonFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	quicvarint.Write(b, pathAbandonFrameType)
+	quicvarint.Write(b, f.PathIdentifier)
+	quicvarint.Write(b, f.ErrorCode)
+	quicvarint.Write(b, uint64(len(f.ReasonPhrase)))
+	b.WriteString(f.ReasonPhrase)
+	return nil
+}
+
+// Length of a written frame
+func (f *PathAbandonFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(pathAbandonFrameType) + quicvarint.Len(f.PathIdentifier) + quicvarint.Len(f.ErrorCode) +
+		quicvarint.Len(uint64(len(f.ReasonPhrase))) + protocol.ByteCount(len(f.ReasonPhrase))
+}
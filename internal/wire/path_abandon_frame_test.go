@@ -0,0 +1,80 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_ABANDON frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x15228c05)
+			data = append(data, encodeVarInt(3)...)    // Path Identifier
+			data = append(data, encodeVarInt(0x42)...) // Error Code
+			data = append(data, encodeVarInt(6)...)    // Reason Phrase Length
+			data = append(data, []byte("foobar")...)   // Reason Phrase
+			b := bytes.NewReader(data)
+			frame, err := parsePathAbandonFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(BeEquivalentTo(3))
+			Expect(frame.ErrorCode).To(BeEquivalentTo(0x42))
+			Expect(frame.ReasonPhrase).To(Equal("foobar"))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("rejects long reason phrases", func() {
+			data := encodeVarInt(0x15228c05)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(0xffff)...)
+			_, err := parsePathAbandonFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0x15228c05)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(6)...)
+			data = append(data, []byte("foobar")...)
+			_, err := parsePathAbandonFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parsePathAbandonFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a sample frame", func() {
+			b := &bytes.Buffer{}
+			f := &PathAbandonFrame{
+				PathIdentifier: 0x1337,
+				ErrorCode:      0xdead,
+				ReasonPhrase:   "foo",
+			}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			expected := encodeVarInt(0x15228c05)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xdead)...)
+			expected = append(expected, encodeVarInt(3)...)
+			expected = append(expected, []byte("foo")...)
+			Expect(b.Bytes()).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			b := &bytes.Buffer{}
+			f := &PathAbandonFrame{
+				PathIdentifier: 0xdecafbad,
+				ErrorCode:      0x42,
+				ReasonPhrase:   "lorem ipsum",
+			}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			Expect(f.Length(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+		})
+	})
+})
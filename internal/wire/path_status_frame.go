@@ -0,0 +1,64 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const pathStatusFrameType = 0x15228c06
+
+// The PathStatus values carried in a PATH_STATUS frame.
+const (
+	PathStatusStandby   uint64 = 1
+	PathStatusAvailable uint64 = 2
+)
+
+// A PathStatusFrame is a PATH_STATUS frame, see https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/.
+// The PathIdentifier is the sequence number of the connection ID the receiver of the frame uses to send on the path.
+type PathStatusFrame struct {
+	PathIdentifier uint64
+	SequenceNumber uint64
+	Status         uint64
+}
+
+func parsePathStatusFrame(r *bytes.Reader, _ protocol.VersionNumber) (*PathStatusFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	status, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if status != PathStatusStandby && status != PathStatusAvailable {
+		return nil, errors.New("invalid path status")
+	}
+	return &PathStatusFrame{
+		PathIdentifier: pathID,
+		SequenceNumber: seq,
+		Status:         status,
+	}, nil
+}
+
+func (f *PathStatusFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	quicvarint.Write(b, pathStatusFrameType)
+	quicvarint.Write(b, f.PathIdentifier)
+	quicvarint.Write(b, f.SequenceNumber)
+	quicvarint.Write(b, f.Status)
+	return nil
+}
+
+// Length of a written frame
+func (f *PathStatusFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(pathStatusFrameType) + quicvarint.Len(f.PathIdentifier) + quicvarint.Len(f.SequenceNumber) + quicvarint.Len(f.Status)
+}
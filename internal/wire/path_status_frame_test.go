@@ -0,0 +1,66 @@
+package wire
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_STATUS frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x15228c06)
+			data = append(data, encodeVarInt(3)...)  // Path Identifier
+			data = append(data, encodeVarInt(42)...) // Path Status sequence number
+			data = append(data, encodeVarInt(1)...)  // Path Status
+			b := bytes.NewReader(data)
+			frame, err := parsePathStatusFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(BeEquivalentTo(3))
+			Expect(frame.SequenceNumber).To(BeEquivalentTo(42))
+			Expect(frame.Status).To(Equal(PathStatusStandby))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors on invalid status values", func() {
+			data := encodeVarInt(0x15228c06)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(42)...)
+			data = append(data, encodeVarInt(3)...)
+			_, err := parsePathStatusFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("invalid path status"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0x15228c06)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(42)...)
+			data = append(data, encodeVarInt(2)...)
+			_, err := parsePathStatusFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parsePathStatusFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a sample frame", func() {
+			b := &bytes.Buffer{}
+			f := &PathStatusFrame{
+				PathIdentifier: 0x1337,
+				SequenceNumber: 0xdecafbad,
+				Status:         PathStatusAvailable,
+			}
+			Expect(f.Write(b, versionIETFFrames)).To(Succeed())
+			expected := encodeVarInt(0x15228c06)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			expected = append(expected, encodeVarInt(2)...)
+			Expect(b.Bytes()).To(Equal(expected))
+			Expect(f.Length(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+		})
+	})
+})
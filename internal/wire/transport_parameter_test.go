@@ -41,6 +41,7 @@ var _ = Describe("Transport Parameters", func() {
 	}
 
 	It("has a string representation", func() {
+		minAckDelay := 2 * time.Millisecond
 		p := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   1234,
 			InitialMaxStreamDataBidiRemote:  2345,
@@ -57,8 +58,13 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			ActiveConnectionIDLimit:         123,
 			MaxDatagramFrameSize:            876,
+			MinAckDelay:                     &minAckDelay,
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.VersionNumber{protocol.Version2, protocol.Version1},
+			},
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, MinAckDelay: 2ms, VersionInformation: {ChosenVersion: v1, AvailableVersions: [v2 v1]}}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -121,6 +127,31 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.MaxAckDelay).To(Equal(42 * time.Millisecond))
 		Expect(p.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		Expect(p.MinAckDelay).To(BeNil())
+		Expect(p.EnableMultipath).To(BeFalse())
+	})
+
+	It("marshals and unmarshals enable_multipath", func() {
+		data := (&TransportParameters{
+			EnableMultipath:     true,
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.EnableMultipath).To(BeTrue())
+	})
+
+	It("marshals and unmarshals the min_ack_delay", func() {
+		minAckDelay := 1234 * time.Microsecond
+		data := (&TransportParameters{
+			MaxAckDelay:         42 * time.Millisecond,
+			MinAckDelay:         &minAckDelay,
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.MinAckDelay).ToNot(BeNil())
+		Expect(*p.MinAckDelay).To(Equal(1234 * time.Microsecond))
 	})
 
 	It("doesn't marshal a retry_source_connection_id, if no Retry was performed", func() {
@@ -176,6 +207,17 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when enable_multipath has content", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(enableMultipathParameterID))
+		quicvarint.Write(b, 1)
+		b.WriteByte(1)
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for enable_multipath: 1 (expected empty)",
+		}))
+	})
+
 	It("errors when the server doesn't set the original_destination_connection_id", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, uint64(statelessResetTokenParameterID))
@@ -207,6 +249,74 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("marshals and unmarshals the version_information", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.VersionNumber{protocol.Version2, protocol.Version1},
+			},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.VersionInformation).To(Equal(&VersionInformation{
+			ChosenVersion:     protocol.Version1,
+			AvailableVersions: []protocol.VersionNumber{protocol.Version2, protocol.Version1},
+		}))
+	})
+
+	It("errors when the version_information has an invalid length", func() {
+		b := &bytes.Buffer{}
+		addInitialSourceConnectionID(b)
+		quicvarint.Write(b, uint64(versionInformationParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte{0, 0, 0, 1, 0, 0})
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(b.Bytes(), protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid length for version_information: 6",
+		}))
+	})
+
+	It("errors when the chosen version is 0", func() {
+		data := (&TransportParameters{
+			VersionInformation: &VersionInformation{AvailableVersions: []protocol.VersionNumber{protocol.Version1}},
+		}).Marshal(protocol.PerspectiveClient)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid chosen version in version_information: 0",
+		}))
+	})
+
+	It("errors when the min_ack_delay is too large", func() {
+		minAckDelay := 1 << 14 * time.Millisecond
+		data := (&TransportParameters{
+			MaxAckDelay:         protocol.MaxMaxAckDelay,
+			MinAckDelay:         &minAckDelay,
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid value for min_ack_delay: 16384000us (maximum 16383000us)",
+		}))
+	})
+
+	It("errors when the min_ack_delay is larger than the max_ack_delay", func() {
+		minAckDelay := 26 * time.Millisecond
+		data := (&TransportParameters{
+			MaxAckDelay:         25 * time.Millisecond,
+			MinAckDelay:         &minAckDelay,
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "min_ack_delay (26ms) larger than max_ack_delay (25ms)",
+		}))
+	})
+
 	It("doesn't send the max_ack_delay, if it has the default value", func() {
 		const num = 1000
 		var defaultLen, dataLen int
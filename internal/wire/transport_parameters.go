@@ -42,8 +42,14 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368
+	versionInformationParameterID transportParameterID = 0x11
 	// https://datatracker.ietf.org/doc/draft-ietf-quic-datagram/
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/
+	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/
+	enableMultipathParameterID transportParameterID = 0x0f739bbc1b666d04
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -56,6 +62,12 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter
+type VersionInformation struct {
+	ChosenVersion     protocol.VersionNumber
+	AvailableVersions []protocol.VersionNumber
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -85,6 +97,14 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	// MinAckDelay is nil if the peer doesn't support the ACK Frequency extension
+	MinAckDelay *time.Duration
+
+	EnableMultipath bool
+
+	// VersionInformation is nil if the peer didn't send the version_information transport parameter
+	VersionInformation *VersionInformation
 }
 
 // Unmarshal the transport parameters
@@ -137,6 +157,7 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			maxAckDelayParameterID,
 			activeConnectionIDLimitParameterID,
 			maxDatagramFrameSizeParameterID,
+			minAckDelayParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(r, paramID, int(paramLen)); err != nil {
 				return err
@@ -153,6 +174,11 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case enableMultipathParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for enable_multipath: %d (expected empty)", paramLen)
+			}
+			p.EnableMultipath = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -178,6 +204,10 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			}
 			connID, _ := protocol.ReadConnectionID(r, int(paramLen))
 			p.RetrySourceConnectionID = &connID
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(r, int(paramLen)); err != nil {
+				return err
+			}
 		default:
 			r.Seek(int64(paramLen), io.SeekCurrent)
 		}
@@ -195,6 +225,10 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 		}
 	}
 
+	if p.MinAckDelay != nil && *p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", *p.MinAckDelay, p.MaxAckDelay)
+	}
+
 	// check that every transport parameter was sent at most once
 	sort.Slice(parameterIDs, func(i, j int) bool { return parameterIDs[i] < parameterIDs[j] })
 	for i := 0; i < len(parameterIDs)-1; i++ {
@@ -251,6 +285,29 @@ func (p *TransportParameters) readPreferredAddress(r *bytes.Reader, expectedLen
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(r *bytes.Reader, expectedLen int) error {
+	if expectedLen < 4 || expectedLen%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", expectedLen)
+	}
+	readVersion := func() protocol.VersionNumber {
+		v, _ := utils.BigEndian.ReadUint32(r)
+		return protocol.VersionNumber(v)
+	}
+	vi := &VersionInformation{ChosenVersion: readVersion()}
+	if vi.ChosenVersion == 0 {
+		return errors.New("invalid chosen version in version_information: 0")
+	}
+	for i := 4; i < expectedLen; i += 4 {
+		v := readVersion()
+		if v == 0 {
+			return errors.New("invalid available version in version_information: 0")
+		}
+		vi.AvailableVersions = append(vi.AvailableVersions, v)
+	}
+	p.VersionInformation = vi
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(
 	r *bytes.Reader,
 	paramID transportParameterID,
@@ -305,6 +362,12 @@ func (p *TransportParameters) readNumericTransportParameter(
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case minAckDelayParameterID:
+		if val > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus (maximum %dus)", val, protocol.MaxMaxAckDelay/time.Microsecond)
+		}
+		minAckDelay := time.Duration(val) * time.Microsecond
+		p.MinAckDelay = &minAckDelay
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -394,6 +457,22 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	if p.MinAckDelay != nil {
+		p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
+	}
+	if p.EnableMultipath {
+		quicvarint.Write(b, uint64(enableMultipathParameterID))
+		quicvarint.Write(b, 0)
+	}
+	// version_information
+	if p.VersionInformation != nil {
+		quicvarint.Write(b, uint64(versionInformationParameterID))
+		quicvarint.Write(b, uint64(4*(1+len(p.VersionInformation.AvailableVersions))))
+		utils.BigEndian.WriteUint32(b, uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			utils.BigEndian.WriteUint32(b, uint32(v))
+		}
+	}
 	return b.Bytes()
 }
 
@@ -471,6 +550,17 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.MinAckDelay != nil {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, *p.MinAckDelay)
+	}
+	if p.EnableMultipath {
+		logString += ", EnableMultipath: true"
+	}
+	if p.VersionInformation != nil {
+		logString += ", VersionInformation: {ChosenVersion: %s, AvailableVersions: %s}"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
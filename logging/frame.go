@@ -12,12 +12,18 @@ type AckRange = wire.AckRange
 type (
 	// An AckFrame is an ACK frame.
 	AckFrame = wire.AckFrame
+	// An AckMPFrame is an ACK_MP frame.
+	AckMPFrame = wire.AckMPFrame
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+	AckFrequencyFrame = wire.AckFrequencyFrame
 	// A ConnectionCloseFrame is a CONNECTION_CLOSE frame.
 	ConnectionCloseFrame = wire.ConnectionCloseFrame
 	// A DataBlockedFrame is a DATA_BLOCKED frame.
 	DataBlockedFrame = wire.DataBlockedFrame
 	// A HandshakeDoneFrame is a HANDSHAKE_DONE frame.
 	HandshakeDoneFrame = wire.HandshakeDoneFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
+	ImmediateAckFrame = wire.ImmediateAckFrame
 	// A MaxDataFrame is a MAX_DATA frame.
 	MaxDataFrame = wire.MaxDataFrame
 	// A MaxStreamDataFrame is a MAX_STREAM_DATA frame.
@@ -32,6 +38,10 @@ type (
 	PathChallengeFrame = wire.PathChallengeFrame
 	// A PathResponseFrame is a PATH_RESPONSE frame.
 	PathResponseFrame = wire.PathResponseFrame
+	// A PathAbandonFrame is a PATH_ABANDON frame.
+	PathAbandonFrame = wire.PathAbandonFrame
+	// A PathStatusFrame is a PATH_STATUS frame.
+	PathStatusFrame = wire.PathStatusFrame
 	// A PingFrame is a PING frame.
 	PingFrame = wire.PingFrame
 	// A ResetStreamFrame is a RESET_STREAM frame.
@@ -102,6 +102,25 @@ type Tracer interface {
 	DroppedPacket(net.Addr, PacketType, ByteCount, PacketDropReason)
 }
 
+// A StatelessResetTracer traces stateless resets.
+// A Tracer can implement this interface, in order to be notified about stateless resets.
+type StatelessResetTracer interface {
+	SentStatelessReset(net.Addr, StatelessResetToken)
+	ReceivedStatelessReset(net.Addr, StatelessResetToken)
+}
+
+// A PersistentCongestionTracer traces persistent congestion.
+// A ConnectionTracer can implement this interface, in order to be notified when persistent congestion is detected.
+type PersistentCongestionTracer interface {
+	DetectedPersistentCongestion()
+}
+
+// An ECNTracer traces the state of ECN validation.
+// A ConnectionTracer can implement this interface, in order to be notified when the ECN state changes.
+type ECNTracer interface {
+	ECNStateUpdated(state ECNState, trigger ECNStateTrigger)
+}
+
 // A ConnectionTracer records events.
 type ConnectionTracer interface {
 	StartedConnection(local, remote net.Addr, srcConnID, destConnID ConnectionID)
@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go/logging (interfaces: ECNTracer)
+
+// Package logging is a generated GoMock package.
+package logging
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockECNTracer is a mock of ECNTracer interface.
+type MockECNTracer struct {
+	ctrl     *gomock.Controller
+	recorder *MockECNTracerMockRecorder
+}
+
+// MockECNTracerMockRecorder is the mock recorder for MockECNTracer.
+type MockECNTracerMockRecorder struct {
+	mock *MockECNTracer
+}
+
+// NewMockECNTracer creates a new mock instance.
+func NewMockECNTracer(ctrl *gomock.Controller) *MockECNTracer {
+	mock := &MockECNTracer{ctrl: ctrl}
+	mock.recorder = &MockECNTracerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockECNTracer) EXPECT() *MockECNTracerMockRecorder {
+	return m.recorder
+}
+
+// ECNStateUpdated mocks base method.
+func (m *MockECNTracer) ECNStateUpdated(arg0 ECNState, arg1 ECNStateTrigger) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ECNStateUpdated", arg0, arg1)
+}
+
+// ECNStateUpdated indicates an expected call of ECNStateUpdated.
+func (mr *MockECNTracerMockRecorder) ECNStateUpdated(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ECNStateUpdated", reflect.TypeOf((*MockECNTracer)(nil).ECNStateUpdated), arg0, arg1)
+}
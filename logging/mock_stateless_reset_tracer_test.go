@@ -0,0 +1,60 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go/logging (interfaces: StatelessResetTracer)
+
+// Package logging is a generated GoMock package.
+package logging
+
+import (
+	net "net"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// MockStatelessResetTracer is a mock of StatelessResetTracer interface.
+type MockStatelessResetTracer struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatelessResetTracerMockRecorder
+}
+
+// MockStatelessResetTracerMockRecorder is the mock recorder for MockStatelessResetTracer.
+type MockStatelessResetTracerMockRecorder struct {
+	mock *MockStatelessResetTracer
+}
+
+// NewMockStatelessResetTracer creates a new mock instance.
+func NewMockStatelessResetTracer(ctrl *gomock.Controller) *MockStatelessResetTracer {
+	mock := &MockStatelessResetTracer{ctrl: ctrl}
+	mock.recorder = &MockStatelessResetTracerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatelessResetTracer) EXPECT() *MockStatelessResetTracerMockRecorder {
+	return m.recorder
+}
+
+// ReceivedStatelessReset mocks base method.
+func (m *MockStatelessResetTracer) ReceivedStatelessReset(arg0 net.Addr, arg1 protocol.StatelessResetToken) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedStatelessReset", arg0, arg1)
+}
+
+// ReceivedStatelessReset indicates an expected call of ReceivedStatelessReset.
+func (mr *MockStatelessResetTracerMockRecorder) ReceivedStatelessReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedStatelessReset", reflect.TypeOf((*MockStatelessResetTracer)(nil).ReceivedStatelessReset), arg0, arg1)
+}
+
+// SentStatelessReset mocks base method.
+func (m *MockStatelessResetTracer) SentStatelessReset(arg0 net.Addr, arg1 protocol.StatelessResetToken) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SentStatelessReset", arg0, arg1)
+}
+
+// SentStatelessReset indicates an expected call of SentStatelessReset.
+func (mr *MockStatelessResetTracerMockRecorder) SentStatelessReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentStatelessReset", reflect.TypeOf((*MockStatelessResetTracer)(nil).SentStatelessReset), arg0, arg1)
+}
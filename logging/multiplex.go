@@ -10,7 +10,10 @@ type tracerMultiplexer struct {
 	tracers []Tracer
 }
 
-var _ Tracer = &tracerMultiplexer{}
+var (
+	_ Tracer               = &tracerMultiplexer{}
+	_ StatelessResetTracer = &tracerMultiplexer{}
+)
 
 // NewMultiplexedTracer creates a new tracer that multiplexes events to multiple tracers.
 func NewMultiplexedTracer(tracers ...Tracer) Tracer {
@@ -45,11 +48,31 @@ func (m *tracerMultiplexer) DroppedPacket(remote net.Addr, typ PacketType, size
 	}
 }
 
+func (m *tracerMultiplexer) SentStatelessReset(remote net.Addr, token StatelessResetToken) {
+	for _, t := range m.tracers {
+		if st, ok := t.(StatelessResetTracer); ok {
+			st.SentStatelessReset(remote, token)
+		}
+	}
+}
+
+func (m *tracerMultiplexer) ReceivedStatelessReset(remote net.Addr, token StatelessResetToken) {
+	for _, t := range m.tracers {
+		if st, ok := t.(StatelessResetTracer); ok {
+			st.ReceivedStatelessReset(remote, token)
+		}
+	}
+}
+
 type connTracerMultiplexer struct {
 	tracers []ConnectionTracer
 }
 
-var _ ConnectionTracer = &connTracerMultiplexer{}
+var (
+	_ ConnectionTracer           = &connTracerMultiplexer{}
+	_ PersistentCongestionTracer = &connTracerMultiplexer{}
+	_ ECNTracer                  = &connTracerMultiplexer{}
+)
 
 // NewMultiplexedConnectionTracer creates a new connection tracer that multiplexes events to multiple tracers.
 func NewMultiplexedConnectionTracer(tracers ...ConnectionTracer) ConnectionTracer {
@@ -158,6 +181,22 @@ func (m *connTracerMultiplexer) LostPacket(encLevel EncryptionLevel, pn PacketNu
 	}
 }
 
+func (m *connTracerMultiplexer) DetectedPersistentCongestion() {
+	for _, t := range m.tracers {
+		if pt, ok := t.(PersistentCongestionTracer); ok {
+			pt.DetectedPersistentCongestion()
+		}
+	}
+}
+
+func (m *connTracerMultiplexer) ECNStateUpdated(state ECNState, trigger ECNStateTrigger) {
+	for _, t := range m.tracers {
+		if et, ok := t.(ECNTracer); ok {
+			et.ECNStateUpdated(state, trigger)
+		}
+	}
+}
+
 func (m *connTracerMultiplexer) UpdatedPTOCount(value uint32) {
 	for _, t := range m.tracers {
 		t.UpdatedPTOCount(value)
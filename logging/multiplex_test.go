@@ -87,6 +87,33 @@ var _ = Describe("Tracing", func() {
 				tr2.EXPECT().DroppedPacket(remote, PacketTypeRetry, ByteCount(1024), PacketDropDuplicate)
 				tracer.DroppedPacket(remote, PacketTypeRetry, 1024, PacketDropDuplicate)
 			})
+
+			Context("stateless resets", func() {
+				var resetTracer *MockStatelessResetTracer
+
+				BeforeEach(func() {
+					resetTracer = NewMockStatelessResetTracer(mockCtrl)
+					// tr1 doesn't implement the StatelessResetTracer interface
+					tracer = NewMultiplexedTracer(tr1, struct {
+						Tracer
+						StatelessResetTracer
+					}{tr2, resetTracer})
+				})
+
+				It("traces the StatelessResetSent event", func() {
+					remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1)}
+					token := StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+					resetTracer.EXPECT().SentStatelessReset(remote, token)
+					tracer.(StatelessResetTracer).SentStatelessReset(remote, token)
+				})
+
+				It("traces the StatelessResetReceived event", func() {
+					remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1)}
+					token := StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+					resetTracer.EXPECT().ReceivedStatelessReset(remote, token)
+					tracer.(StatelessResetTracer).ReceivedStatelessReset(remote, token)
+				})
+			})
 		})
 	})
 
@@ -208,6 +235,28 @@ var _ = Describe("Tracing", func() {
 			tracer.LostPacket(EncryptionHandshake, 42, PacketLossReorderingThreshold)
 		})
 
+		It("traces the DetectedPersistentCongestion event", func() {
+			pt := NewMockPersistentCongestionTracer(mockCtrl)
+			// tr1 doesn't implement the PersistentCongestionTracer interface
+			tracer = NewMultiplexedConnectionTracer(tr1, struct {
+				ConnectionTracer
+				PersistentCongestionTracer
+			}{tr2, pt})
+			pt.EXPECT().DetectedPersistentCongestion()
+			tracer.(PersistentCongestionTracer).DetectedPersistentCongestion()
+		})
+
+		It("traces the ECNStateUpdated event", func() {
+			et := NewMockECNTracer(mockCtrl)
+			// tr1 doesn't implement the ECNTracer interface
+			tracer = NewMultiplexedConnectionTracer(tr1, struct {
+				ConnectionTracer
+				ECNTracer
+			}{tr2, et})
+			et.EXPECT().ECNStateUpdated(ECNStateFailed, ECNFailedNoECNCounts)
+			tracer.(ECNTracer).ECNStateUpdated(ECNStateFailed, ECNFailedNoECNCounts)
+		})
+
 		It("traces the UpdatedPTOCount event", func() {
 			tr1.EXPECT().UpdatedPTOCount(uint32(88))
 			tr2.EXPECT().UpdatedPTOCount(uint32(88))
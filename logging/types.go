@@ -91,4 +91,40 @@ const (
 	CongestionStateRecovery
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited
+	// CongestionStateConservativeSlowStart is the Conservative Slow Start phase of HyStart++
+	CongestionStateConservativeSlowStart
+)
+
+// ECNState is the state of the ECN validation (RFC 9000, Section 13.4.2)
+type ECNState uint8
+
+const (
+	// ECNStateTesting is the testing period, during which packets are sent with ECN markings
+	ECNStateTesting ECNState = iota
+	// ECNStateUnknown is used after the testing period, while waiting for the peer to acknowledge the testing packets
+	ECNStateUnknown
+	// ECNStateFailed means that ECN validation failed, and that packets are sent without ECN markings
+	ECNStateFailed
+	// ECNStateCapable means that ECN validation succeeded, and that the path supports ECN
+	ECNStateCapable
+)
+
+// ECNStateTrigger is the reason why the ECN state changed
+type ECNStateTrigger uint8
+
+const (
+	// ECNTriggerNoTrigger is used for state changes that are part of the regular validation process
+	ECNTriggerNoTrigger ECNStateTrigger = iota
+	// ECNFailedNoECNCounts is used when an ACK acknowledges ECN-marked packets, but doesn't contain any ECN counts
+	ECNFailedNoECNCounts
+	// ECNFailedDecreasedECNCounts is used when the ECN counts reported by the peer decreased
+	ECNFailedDecreasedECNCounts
+	// ECNFailedLostAllTestingPackets is used when all packets sent during the testing period were declared lost
+	ECNFailedLostAllTestingPackets
+	// ECNFailedMoreECNCountsThanSent is used when the peer reported more ECN-marked packets than we sent
+	ECNFailedMoreECNCountsThanSent
+	// ECNFailedTooFewECNCounts is used when the ECN counts increased by less than the number of newly acknowledged ECN-marked packets
+	ECNFailedTooFewECNCounts
+	// ECNFailedManglingDetected is used when the peer reports ECN markings that we didn't send
+	ECNFailedManglingDetected
 )
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go (interfaces: AntiReplay)
+
+// Package quic is a generated GoMock package.
+package quic
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAntiReplay is a mock of AntiReplay interface.
+type MockAntiReplay struct {
+	ctrl     *gomock.Controller
+	recorder *MockAntiReplayMockRecorder
+}
+
+// MockAntiReplayMockRecorder is the mock recorder for MockAntiReplay.
+type MockAntiReplayMockRecorder struct {
+	mock *MockAntiReplay
+}
+
+// NewMockAntiReplay creates a new mock instance.
+func NewMockAntiReplay(ctrl *gomock.Controller) *MockAntiReplay {
+	mock := &MockAntiReplay{ctrl: ctrl}
+	mock.recorder = &MockAntiReplayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAntiReplay) EXPECT() *MockAntiReplayMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockAntiReplay) Accept(arg0 []byte, arg1 time.Time) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockAntiReplayMockRecorder) Accept(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockAntiReplay)(nil).Accept), arg0, arg1)
+}
@@ -37,6 +37,21 @@ func (m *MockPacker) EXPECT() *MockPackerMockRecorder {
 	return m.recorder
 }
 
+// AppendPacket mocks base method.
+func (m *MockPacker) AppendPacket(arg0 *packetBuffer) (*packedPacket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendPacket", arg0)
+	ret0, _ := ret[0].(*packedPacket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendPacket indicates an expected call of AppendPacket.
+func (mr *MockPackerMockRecorder) AppendPacket(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendPacket", reflect.TypeOf((*MockPacker)(nil).AppendPacket), arg0)
+}
+
 // HandleTransportParameters mocks base method.
 func (m *MockPacker) HandleTransportParameters(arg0 *wire.TransportParameters) {
 	m.ctrl.T.Helper()
@@ -49,6 +64,20 @@ func (mr *MockPackerMockRecorder) HandleTransportParameters(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTransportParameters", reflect.TypeOf((*MockPacker)(nil).HandleTransportParameters), arg0)
 }
 
+// MaxPacketSize mocks base method.
+func (m *MockPacker) MaxPacketSize() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxPacketSize")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// MaxPacketSize indicates an expected call of MaxPacketSize.
+func (mr *MockPackerMockRecorder) MaxPacketSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxPacketSize", reflect.TypeOf((*MockPacker)(nil).MaxPacketSize))
+}
+
 // MaybePackAckPacket mocks base method.
 func (m *MockPacker) MaybePackAckPacket(handshakeConfirmed bool) (*packedPacket, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +183,21 @@ func (mr *MockPackerMockRecorder) PackPacket() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPacket", reflect.TypeOf((*MockPacker)(nil).PackPacket))
 }
 
+// PackPathProbePacket mocks base method.
+func (m *MockPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, maxSize protocol.ByteCount) (*packedPacket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PackPathProbePacket", connID, frames, maxSize)
+	ret0, _ := ret[0].(*packedPacket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PackPathProbePacket indicates an expected call of PackPathProbePacket.
+func (mr *MockPackerMockRecorder) PackPathProbePacket(connID, frames, maxSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPathProbePacket", reflect.TypeOf((*MockPacker)(nil).PackPathProbePacket), connID, frames, maxSize)
+}
+
 // SetMaxPacketSize mocks base method.
 func (m *MockPacker) SetMaxPacketSize(arg0 protocol.ByteCount) {
 	m.ctrl.T.Helper()
@@ -177,3 +221,15 @@ func (mr *MockPackerMockRecorder) SetToken(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetToken", reflect.TypeOf((*MockPacker)(nil).SetToken), arg0)
 }
+
+// SetVersion mocks base method.
+func (m *MockPacker) SetVersion(arg0 protocol.VersionNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVersion", arg0)
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockPackerMockRecorder) SetVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockPacker)(nil).SetVersion), arg0)
+}
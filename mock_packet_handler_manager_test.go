@@ -74,6 +74,18 @@ func (mr *MockPacketHandlerManagerMockRecorder) AddWithConnID(arg0, arg1, arg2 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithConnID", reflect.TypeOf((*MockPacketHandlerManager)(nil).AddWithConnID), arg0, arg1, arg2)
 }
 
+// CloseIfUnused mocks base method.
+func (m *MockPacketHandlerManager) CloseIfUnused() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseIfUnused")
+}
+
+// CloseIfUnused indicates an expected call of CloseIfUnused.
+func (mr *MockPacketHandlerManagerMockRecorder) CloseIfUnused() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseIfUnused", reflect.TypeOf((*MockPacketHandlerManager)(nil).CloseIfUnused))
+}
+
 // CloseServer mocks base method.
 func (m *MockPacketHandlerManager) CloseServer() {
 	m.ctrl.T.Helper()
@@ -162,6 +174,18 @@ func (mr *MockPacketHandlerManagerMockRecorder) Retire(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retire", reflect.TypeOf((*MockPacketHandlerManager)(nil).Retire), arg0)
 }
 
+// RotateStatelessResetKey mocks base method.
+func (m *MockPacketHandlerManager) RotateStatelessResetKey(arg0 []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RotateStatelessResetKey", arg0)
+}
+
+// RotateStatelessResetKey indicates an expected call of RotateStatelessResetKey.
+func (mr *MockPacketHandlerManagerMockRecorder) RotateStatelessResetKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateStatelessResetKey", reflect.TypeOf((*MockPacketHandlerManager)(nil).RotateStatelessResetKey), arg0)
+}
+
 // SetServer mocks base method.
 func (m *MockPacketHandlerManager) SetServer(arg0 unknownPacketHandler) {
 	m.ctrl.T.Helper()
@@ -173,3 +197,17 @@ func (mr *MockPacketHandlerManagerMockRecorder) SetServer(arg0 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServer", reflect.TypeOf((*MockPacketHandlerManager)(nil).SetServer), arg0)
 }
+
+// StatelessResetStats mocks base method.
+func (m *MockPacketHandlerManager) StatelessResetStats() StatelessResetStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatelessResetStats")
+	ret0, _ := ret[0].(StatelessResetStats)
+	return ret0
+}
+
+// StatelessResetStats indicates an expected call of StatelessResetStats.
+func (mr *MockPacketHandlerManagerMockRecorder) StatelessResetStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatelessResetStats", reflect.TypeOf((*MockPacketHandlerManager)(nil).StatelessResetStats))
+}
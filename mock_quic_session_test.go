@@ -66,6 +66,21 @@ func (mr *MockQuicSessionMockRecorder) AcceptUniStream(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUniStream", reflect.TypeOf((*MockQuicSession)(nil).AcceptUniStream), arg0)
 }
 
+// AddPath mocks base method.
+func (m *MockQuicSession) AddPath(arg0 context.Context, arg1 net.PacketConn) (Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0, arg1)
+	ret0, _ := ret[0].(Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQuicSessionMockRecorder) AddPath(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQuicSession)(nil).AddPath), arg0, arg1)
+}
+
 // CloseWithError mocks base method.
 func (m *MockQuicSession) CloseWithError(arg0 ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -150,6 +165,34 @@ func (mr *MockQuicSessionMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockQuicSession)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockQuicSession) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockQuicSessionMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockQuicSession)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockQuicSession) MigrateTo(arg0 context.Context, arg1 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockQuicSessionMockRecorder) MigrateTo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockQuicSession)(nil).MigrateTo), arg0, arg1)
+}
+
 // NextSession mocks base method.
 func (m *MockQuicSession) NextSession() Session {
 	m.ctrl.T.Helper()
@@ -281,6 +324,60 @@ func (mr *MockQuicSessionMockRecorder) SendMessage(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockQuicSession)(nil).SendMessage), arg0)
 }
 
+// SendMessageWithCallback mocks base method.
+func (m *MockQuicSession) SendMessageWithCallback(msg []byte, callback func(bool)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageWithCallback", msg, callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageWithCallback indicates an expected call of SendMessageWithCallback.
+func (mr *MockQuicSessionMockRecorder) SendMessageWithCallback(msg, callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithCallback", reflect.TypeOf((*MockQuicSession)(nil).SendMessageWithCallback), msg, callback)
+}
+
+// SendMessages mocks base method.
+func (m *MockQuicSession) SendMessages(arg0 [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessages", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessages indicates an expected call of SendMessages.
+func (mr *MockQuicSessionMockRecorder) SendMessages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessages", reflect.TypeOf((*MockQuicSession)(nil).SendMessages), arg0)
+}
+
+// Stats mocks base method.
+func (m *MockQuicSession) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQuicSessionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQuicSession)(nil).Stats))
+}
+
+// closeWithTransportError mocks base method.
+func (m *MockQuicSession) closeWithTransportError(arg0 TransportErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "closeWithTransportError", arg0)
+}
+
+// closeWithTransportError indicates an expected call of closeWithTransportError.
+func (mr *MockQuicSessionMockRecorder) closeWithTransportError(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "closeWithTransportError", reflect.TypeOf((*MockQuicSession)(nil).closeWithTransportError), arg0)
+}
+
 // destroy mocks base method.
 func (m *MockQuicSession) destroy(arg0 error) {
 	m.ctrl.T.Helper()
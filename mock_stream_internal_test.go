@@ -119,6 +119,18 @@ func (mr *MockStreamIMockRecorder) SetDeadline(t interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeadline", reflect.TypeOf((*MockStreamI)(nil).SetDeadline), t)
 }
 
+// SetPriority mocks base method.
+func (m *MockStreamI) SetPriority(urgency uint8, incremental bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", urgency, incremental)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamIMockRecorder) SetPriority(urgency, incremental interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStreamI)(nil).SetPriority), urgency, incremental)
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(t time.Time) error {
 	m.ctrl.T.Helper()
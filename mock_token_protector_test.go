@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go (interfaces: TokenProtector)
+
+// Package quic is a generated GoMock package.
+package quic
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokenProtector is a mock of TokenProtector interface.
+type MockTokenProtector struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenProtectorMockRecorder
+}
+
+// MockTokenProtectorMockRecorder is the mock recorder for MockTokenProtector.
+type MockTokenProtectorMockRecorder struct {
+	mock *MockTokenProtector
+}
+
+// NewMockTokenProtector creates a new mock instance.
+func NewMockTokenProtector(ctrl *gomock.Controller) *MockTokenProtector {
+	mock := &MockTokenProtector{ctrl: ctrl}
+	mock.recorder = &MockTokenProtectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenProtector) EXPECT() *MockTokenProtectorMockRecorder {
+	return m.recorder
+}
+
+// DecodeToken mocks base method.
+func (m *MockTokenProtector) DecodeToken(arg0 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeToken", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeToken indicates an expected call of DecodeToken.
+func (mr *MockTokenProtectorMockRecorder) DecodeToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeToken", reflect.TypeOf((*MockTokenProtector)(nil).DecodeToken), arg0)
+}
+
+// NewToken mocks base method.
+func (m *MockTokenProtector) NewToken(arg0 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewToken", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewToken indicates an expected call of NewToken.
+func (mr *MockTokenProtectorMockRecorder) NewToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockTokenProtector)(nil).NewToken), arg0)
+}
@@ -49,3 +49,18 @@ func (mr *MockUnpackerMockRecorder) Unpack(hdr, rcvTime, data interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unpack", reflect.TypeOf((*MockUnpacker)(nil).Unpack), hdr, rcvTime, data)
 }
+
+// UnpackPath mocks base method.
+func (m *MockUnpacker) UnpackPath(pathID uint64, hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpackPath", pathID, hdr, rcvTime, data)
+	ret0, _ := ret[0].(*unpackedPacket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnpackPath indicates an expected call of UnpackPath.
+func (mr *MockUnpackerMockRecorder) UnpackPath(pathID, hdr, rcvTime, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpackPath", reflect.TypeOf((*MockUnpacker)(nil).UnpackPath), pathID, hdr, rcvTime, data)
+}
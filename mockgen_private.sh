@@ -43,7 +43,7 @@ AUX_FILES=$(IFS=, ; echo "${AUX[*]}")
 
 ## create a public alias for the interface, so that mockgen can process it
 echo -e "package $1\n" > $TMPFILE
-echo "$INTERFACE" | sed "s/$ORIG_INTERFACE_NAME/$INTERFACE_NAME/" >> $TMPFILE
+echo "$INTERFACE" | sed "s/^type $ORIG_INTERFACE_NAME interface/type $INTERFACE_NAME interface/" >> $TMPFILE
 goimports -w $TMPFILE
 mockgen -package $1 -self_package $3 -destination $DEST -source=$TMPFILE -aux_files $AUX_FILES
 goimports -w $DEST
@@ -0,0 +1,902 @@
+package quic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/handshake"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+// pathFailurePTOCount is the number of consecutive probe timeouts after which a path is considered failed,
+// as long as there's another path that didn't experience a probe timeout.
+const pathFailurePTOCount = 3
+
+// A multipathPath is a path of a multipath connection, in addition to the path the handshake was performed on.
+// See https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/.
+// Every path uses its own packet number space, RTT estimate and congestion controller.
+// Stream data and control frames are shared between all paths.
+//
+// A path is identified by the sequence numbers of the connection IDs used on it:
+// the sequence number of the peer's connection ID we send to (the sending path ID, connID.SequenceNumber),
+// and the sequence number of our connection ID the peer sends to (the receiving path ID).
+type multipathPath struct {
+	*path
+
+	// rcvPathID is the sequence number of our connection ID that the peer sends to on this path.
+	// The client only learns it when it receives the first packet on this path.
+	rcvPathID      uint64
+	rcvPathIDKnown bool
+
+	validated bool
+	// abandoned is set once we sent a PATH_ABANDON frame for this path.
+	// The path is removed when the peer abandons it as well, or when the deadline expires.
+	abandoned bool
+
+	status     PathStatus
+	statusSeq  uint64
+	peerStatus peerPathStatus
+
+	rttStats              *utils.RTTStats
+	sentPacketHandler     ackhandler.SentPacketHandler
+	receivedPacketHandler ackhandler.ReceivedPacketHandler
+	packer                packer
+	sendQueue             sender
+
+	done chan struct{} // closed when the path is removed
+}
+
+// sendPathID returns the path ID used for sending, i.e. the sequence number of the peer's connection ID used on this path.
+func (p *multipathPath) sendPathID() uint64 {
+	return p.connID.SequenceNumber
+}
+
+// nextTimeout returns the time when the timer of the session needs to fire for this path.
+// It returns the zero value if no timer is set.
+func (p *multipathPath) nextTimeout() time.Time {
+	deadline := p.receivedPacketHandler.GetAlarmTimeout()
+	if lossTime := p.sentPacketHandler.GetLossDetectionTimeout(); !lossTime.IsZero() {
+		deadline = utils.MinNonZeroTime(deadline, lossTime)
+	}
+	if p.abandoned || !p.validated {
+		deadline = utils.MinNonZeroTime(deadline, p.deadline)
+	}
+	if !p.validated {
+		deadline = utils.MinNonZeroTime(deadline, p.nextProbe)
+	}
+	return deadline
+}
+
+// peerPathStatus is the status of a path, as announced by the peer in PATH_STATUS frames.
+type peerPathStatus struct {
+	status   PathStatus
+	received bool
+	seq      uint64
+}
+
+func (s *peerPathStatus) update(f *wire.PathStatusFrame) {
+	// PATH_STATUS frames might be reordered.
+	if s.received && f.SequenceNumber <= s.seq {
+		return
+	}
+	s.received = true
+	s.seq = f.SequenceNumber
+	s.status = PathStatusAvailable
+	if f.Status == wire.PathStatusStandby {
+		s.status = PathStatusStandby
+	}
+}
+
+// A pathUpdate is a request from the application to change the status of a path, or to close it.
+type pathUpdate struct {
+	path    *path
+	status  PathStatus
+	abandon bool
+	result  chan error // buffered. nil if no result is needed.
+}
+
+// sessionPath implements the Path returned by AddPath.
+type sessionPath struct {
+	s *session
+	p *path
+}
+
+var _ Path = &sessionPath{}
+
+func (p *sessionPath) LocalAddr() net.Addr  { return p.p.conn.LocalAddr() }
+func (p *sessionPath) RemoteAddr() net.Addr { return p.p.conn.RemoteAddr() }
+
+func (p *sessionPath) SetStatus(status PathStatus) error {
+	return p.s.updatePath(pathUpdate{path: p.p, status: status})
+}
+
+func (p *sessionPath) Close() error {
+	return p.s.updatePath(pathUpdate{path: p.p, abandon: true})
+}
+
+func (s *session) AddPath(ctx context.Context, conn net.PacketConn) (Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("only clients can add paths")
+	}
+	if !s.config.EnableMultipath {
+		return nil, errors.New("multipath not enabled")
+	}
+	runner, err := getMultiplexer().AddConn(conn, s.srcConnIDLen, s.config.StatelessResetKey, s.config.Tracer)
+	if err != nil {
+		return nil, err
+	}
+	p := newPath(ctx, newSendPconn(conn, s.RemoteAddr()), runner)
+	select {
+	case s.pathAdditions <- p:
+	case <-ctx.Done():
+		runner.CloseIfUnused()
+		return nil, ctx.Err()
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return nil, errSessionClosed
+	}
+	select {
+	case err := <-p.result:
+		if err != nil {
+			runner.CloseIfUnused()
+			return nil, err
+		}
+		return &sessionPath{s: s, p: p}, nil
+	case <-ctx.Done():
+		// make sure the run loop notices that the path was abandoned
+		s.scheduleSending()
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return nil, errSessionClosed
+	}
+	// Wait for the run loop to remove the path, and to unregister it from the packet conn.
+	// Path validation might have succeeded in the meantime.
+	select {
+	case err := <-p.result:
+		if err != nil {
+			runner.CloseIfUnused()
+			return nil, ctx.Err()
+		}
+		return &sessionPath{s: s, p: p}, nil
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return nil, errSessionClosed
+	}
+}
+
+func (s *session) updatePath(u pathUpdate) error {
+	u.result = make(chan error, 1)
+	select {
+	case s.pathUpdates <- u:
+	case <-s.ctx.Done():
+		return errSessionClosed
+	}
+	select {
+	case err := <-u.result:
+		return err
+	case <-s.ctx.Done():
+		return errSessionClosed
+	}
+}
+
+// sendsOnMultiplePaths says if packets are currently distributed across multiple paths.
+func (s *session) sendsOnMultiplePaths() bool {
+	for _, p := range s.paths {
+		if p.validated && !p.abandoned {
+			return true
+		}
+	}
+	return false
+}
+
+// addPath is called by the client when the application adds a new path.
+func (s *session) addPath(p *path) {
+	if !s.handshakeConfirmed {
+		p.result <- errors.New("can't add a path before the handshake is confirmed")
+		return
+	}
+	if !s.multipath {
+		p.result <- errors.New("multipath was not negotiated")
+		return
+	}
+	if len(s.paths) >= protocol.MaxPaths {
+		p.result <- errors.New("too many paths")
+		return
+	}
+	// Packets received on a new path are matched to the path by the local address.
+	if addrsEqual(p.conn.LocalAddr(), s.conn.LocalAddr()) {
+		p.result <- errors.New("packet conn already used by this session")
+		return
+	}
+	for _, mp := range s.paths {
+		if addrsEqual(p.conn.LocalAddr(), mp.conn.LocalAddr()) {
+			p.result <- errors.New("packet conn already used by this session")
+			return
+		}
+	}
+	connID, ok := s.connIDManager.GetConnIDForPath()
+	if !ok {
+		p.result <- errors.New("no unused connection ID available")
+		return
+	}
+	if err := s.addRunnerForPath(p); err != nil {
+		s.connIDManager.RetireConnIDForPath(connID)
+		p.result <- err
+		return
+	}
+	s.logger.Debugf("Adding path %s.", p)
+	mp := s.newMultipathPath(p, connID)
+	mp.deadline = s.pathValidationDeadline(time.Now())
+	s.startPath(mp)
+}
+
+// acceptPath is called by the server when it receives a packet for a new path.
+// It returns nil if the path can't be added.
+func (s *session) acceptPath(rp *receivedPacket) *multipathPath {
+	connID, ok := s.connIDManager.GetConnIDForPath()
+	if !ok || connID.ConnectionID.Len() == 0 {
+		s.logger.Debugf("Not accepting a new path from %s, since no unused connection ID is available.", rp.remoteAddr)
+		return nil
+	}
+	// Responses have to be sent from the address that the packet was sent to.
+	var conn sendConn
+	if rp.rcvConn != nil && rp.rcvConn != s.rcvConn {
+		conn = newSendConn(rp.rcvConn, rp.remoteAddr, rp.info)
+	} else {
+		conn = s.conn.WithRemoteAddr(rp.remoteAddr, rp.info)
+	}
+	mp := s.newMultipathPath(newPeerPath(conn, rp.rcvConn), connID)
+	s.logger.Debugf("Accepting new path %s.", mp)
+	mp.deadline = s.pathValidationDeadline(rp.rcvTime)
+	s.startPath(mp)
+	return mp
+}
+
+func (s *session) newMultipathPath(p *path, connID utils.NewConnectionID) *multipathPath {
+	p.connID = connID
+	p.reuseConnID = false
+	mp := &multipathPath{
+		path:     p,
+		rttStats: &utils.RTTStats{},
+		done:     make(chan struct{}),
+	}
+	// The RTT of the new path is likely similar to the RTT of the initial path.
+	mp.rttStats.SetInitialRTT(s.rttStats.SmoothedRTT())
+	mp.rttStats.SetMaxAckDelay(s.peerParams.MaxAckDelay)
+	remoteAddr := p.conn.RemoteAddr()
+	mp.sentPacketHandler, mp.receivedPacketHandler = ackhandler.NewPathAckHandler(
+		getMaxPacketSize(remoteAddr),
+		mp.rttStats,
+		s.newPathCongestionControlFunc(remoteAddr),
+		p.conn.capabilities().ECN,
+		s.perspective,
+		s.logger,
+		s.version,
+	)
+	if s.lastAckFrequencyFrame != nil {
+		// The frame was already validated when it was received.
+		_ = mp.receivedPacketHandler.ReceivedAckFrequencyFrame(s.lastAckFrequencyFrame)
+	}
+	mp.packer = newPathPacketPacker(
+		connID.ConnectionID,
+		connID.SequenceNumber,
+		func() uint64 { return mp.rcvPathID },
+		mp.sentPacketHandler,
+		s.retransmissionQueue,
+		remoteAddr,
+		s.cryptoStreamHandler,
+		s.framer,
+		mp.receivedPacketHandler,
+		s.datagramQueue,
+		s.perspective,
+		s.version,
+	)
+	mp.packer.HandleTransportParameters(s.peerParams)
+	mp.sendQueue = newSendQueue(p.conn)
+	return mp
+}
+
+// newPathCongestionControlFunc returns the function used to create the congestion controller for a path.
+// It returns nil if no congestion controller is configured, in which case the default is used.
+func (s *session) newPathCongestionControlFunc(remoteAddr net.Addr) func(congestion.Parameters) congestion.CongestionControl {
+	if s.config.CongestionControl == nil {
+		return nil
+	}
+	return func(p congestion.Parameters) congestion.CongestionControl {
+		p.RemoteAddr = remoteAddr
+		return s.config.CongestionControl(p)
+	}
+}
+
+func (s *session) startPath(p *multipathPath) {
+	s.paths = append(s.paths, p)
+	s.updateMaxDatagramSize()
+	go s.runPathSendQueue(p)
+}
+
+// runPathSendQueue runs the send queue of a path.
+// Unlike for the initial path, an error sending on the path doesn't close the session, but abandons the path.
+func (s *session) runPathSendQueue(p *multipathPath) {
+	go func() {
+		for {
+			select {
+			case <-p.sendQueue.Available():
+				s.scheduleSending()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	if err := p.sendQueue.Run(); err != nil {
+		s.logger.Debugf("Sending on path %s failed: %s", p, err)
+		select {
+		case s.pathUpdates <- pathUpdate{path: p.path, abandon: true}:
+		case <-p.done:
+		case <-s.ctx.Done():
+		}
+	}
+}
+
+// removePath removes a path. Data sent on the path that is still outstanding is retransmitted on the other paths.
+func (s *session) removePath(p *multipathPath, err error) {
+	s.logger.Debugf("Removing path %s: %s", p, err)
+	for i, mp := range s.paths {
+		if mp == p {
+			s.paths = append(s.paths[:i], s.paths[i+1:]...)
+			break
+		}
+	}
+	p.sentPacketHandler.QueueAllForRetransmission()
+	s.connIDManager.RetireConnIDForPath(p.connID)
+	if p.addedRunner {
+		for _, c := range s.connIDGenerator.ActiveConnIDs() {
+			p.runner.Remove(c)
+		}
+		s.runners.RemoveRunner(p.runner)
+	}
+	close(p.done)
+	// Closing the send queue blocks until all queued packets are sent.
+	go p.sendQueue.Close()
+	// The result of path validation was already reported for validated paths.
+	if !p.validated {
+		p.Done(err)
+	}
+	s.updateMaxDatagramSize()
+	s.scheduleSending()
+}
+
+// abandonMultipathPath sends a PATH_ABANDON frame for a path.
+// The path isn't used for sending any more, and data sent on it is retransmitted on the other paths.
+func (s *session) abandonMultipathPath(p *multipathPath, now time.Time) {
+	if !p.rcvPathIDKnown {
+		// The peer doesn't know about this path yet.
+		s.removePath(p, errors.New("path closed"))
+		return
+	}
+	s.logger.Debugf("Abandoning path %s.", p)
+	p.abandoned = true
+	p.sentPacketHandler.QueueAllForRetransmission()
+	s.queueControlFrame(&wire.PathAbandonFrame{PathIdentifier: p.rcvPathID})
+	// Wait for the peer to abandon the path as well, but not forever.
+	p.deadline = now.Add(3 * p.rttStats.PTO(true))
+}
+
+func (s *session) handlePathUpdate(u pathUpdate) {
+	var p *multipathPath
+	for _, mp := range s.paths {
+		if mp.path == u.path {
+			p = mp
+			break
+		}
+	}
+	var err error
+	switch {
+	case p == nil || p.abandoned:
+		err = errors.New("path closed")
+	case u.abandon:
+		s.abandonMultipathPath(p, time.Now())
+	case u.status != p.status:
+		p.status = u.status
+		status := wire.PathStatusAvailable
+		if u.status == PathStatusStandby {
+			status = wire.PathStatusStandby
+		}
+		s.queueControlFrame(&wire.PathStatusFrame{
+			PathIdentifier: p.rcvPathID,
+			SequenceNumber: p.statusSeq,
+			Status:         status,
+		})
+		p.statusSeq++
+	}
+	if u.result != nil {
+		u.result <- err
+	}
+}
+
+// getPathForPacket returns the path a packet with the given path ID was received on.
+func (s *session) getPathForPacket(rp *receivedPacket, pathID uint64) *multipathPath {
+	for _, p := range s.paths {
+		if p.rcvPathIDKnown && p.rcvPathID == pathID {
+			return p
+		}
+	}
+	// The client learns the path ID when it receives the first packet on the packet conn of the path.
+	if s.perspective == protocol.PerspectiveClient && rp.rcvConn != nil {
+		for _, p := range s.paths {
+			if !p.rcvPathIDKnown && addrsEqual(p.conn.LocalAddr(), rp.rcvConn.LocalAddr()) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// getPathBySendPathID returns the path on which we send using the peer's connection ID with the given sequence number.
+func (s *session) getPathBySendPathID(pathID uint64) *multipathPath {
+	for _, p := range s.paths {
+		if p.sendPathID() == pathID {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *session) getPathWithChallenge(data [8]byte) *multipathPath {
+	for _, p := range s.paths {
+		if !p.validated && p.HasChallenge(data) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *session) pathValidated(p *multipathPath) {
+	s.logger.Debugf("Validated path %s.", p)
+	p.validated = true
+	p.amplificationLimited = false
+	p.Done(nil)
+	s.scheduleSending()
+}
+
+// handleMultipathPacket handles a packet received on a path other than the initial path.
+func (s *session) handleMultipathPacket(rp *receivedPacket, hdr *wire.Header, pathID uint64) bool /* was the packet successfully processed */ {
+	p := s.getPathForPacket(rp, pathID)
+	if p == nil && (s.perspective == protocol.PerspectiveClient || !s.handshakeConfirmed || len(s.paths) >= protocol.MaxPaths) {
+		if s.tracer != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, rp.Size(), logging.PacketDropUnknownConnectionID)
+		}
+		s.logger.Debugf("Dropping packet (%d bytes) for unknown path %d.", rp.Size(), pathID)
+		return false
+	}
+	packet, err := s.unpacker.UnpackPath(pathID, hdr, rp.rcvTime, rp.data)
+	if err != nil {
+		if err == handshake.ErrKeysNotYetAvailable {
+			s.logger.Debugf("Dropping packet (%d bytes) for path %d, since 1-RTT keys are not yet available.", rp.Size(), pathID)
+			return false
+		}
+		s.handleUnpackError(err, rp, hdr)
+		return false
+	}
+	// Only create new paths for packets that could be decrypted.
+	if p == nil {
+		if p = s.acceptPath(rp); p == nil {
+			return false
+		}
+	}
+	if !p.rcvPathIDKnown {
+		p.rcvPathID = pathID
+		p.rcvPathIDKnown = true
+	}
+	p.ReceivedBytes(rp.Size())
+
+	if s.logger.Debug() {
+		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, path %d", packet.packetNumber, rp.Size(), hdr.DestConnectionID, pathID)
+		packet.hdr.Log(s.logger)
+	}
+	if p.receivedPacketHandler.IsPotentiallyDuplicate(packet.packetNumber, protocol.Encryption1RTT) {
+		s.logger.Debugf("Dropping (potentially) duplicate packet.")
+		if s.tracer != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, rp.Size(), logging.PacketDropDuplicate)
+		}
+		return false
+	}
+	if len(packet.data) == 0 {
+		s.closeLocal(&qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "empty packet",
+		})
+		return false
+	}
+	s.lastPacketReceivedTime = rp.rcvTime
+	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
+	s.keepAlivePingSent = false
+
+	isAckEliciting, _, err := s.handleFrames(packet, rp.Size(), p.path)
+	if err == nil {
+		err = p.receivedPacketHandler.ReceivedPacket(packet.packetNumber, rp.ecn, protocol.Encryption1RTT, rp.rcvTime, isAckEliciting)
+	}
+	if err != nil {
+		s.closeLocal(err)
+		return false
+	}
+	return true
+}
+
+func (s *session) handleMultipathFrame(f wire.Frame) error {
+	if !s.multipath {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "received a multipath frame, although multipath was not negotiated",
+		}
+	}
+	switch frame := f.(type) {
+	case *wire.AckMPFrame:
+		return s.handleAckMPFrame(frame)
+	case *wire.PathAbandonFrame:
+		s.handlePathAbandonFrame(frame)
+	case *wire.PathStatusFrame:
+		s.handlePathStatusFrame(frame)
+	}
+	return nil
+}
+
+func (s *session) handleAckMPFrame(f *wire.AckMPFrame) error {
+	if f.PathIdentifier == 0 {
+		return s.handleAckFrame(&f.AckFrame, protocol.Encryption1RTT)
+	}
+	p := s.getPathBySendPathID(f.PathIdentifier)
+	if p == nil {
+		// The path might already have been removed.
+		s.logger.Debugf("Ignoring ACK_MP frame for unknown path %d.", f.PathIdentifier)
+		return nil
+	}
+	_, err := p.sentPacketHandler.ReceivedAck(&f.AckFrame, protocol.Encryption1RTT, s.lastPacketReceivedTime)
+	return err
+}
+
+func (s *session) handlePathAbandonFrame(f *wire.PathAbandonFrame) {
+	if f.PathIdentifier == 0 {
+		s.logger.Debugf("Ignoring PATH_ABANDON frame for the initial path.")
+		return
+	}
+	p := s.getPathBySendPathID(f.PathIdentifier)
+	if p == nil {
+		return
+	}
+	// Abandon the path in the other direction as well.
+	if !p.abandoned && p.rcvPathIDKnown {
+		s.queueControlFrame(&wire.PathAbandonFrame{PathIdentifier: p.rcvPathID})
+	}
+	s.removePath(p, errors.New("path abandoned by the peer"))
+}
+
+func (s *session) handlePathStatusFrame(f *wire.PathStatusFrame) {
+	if f.PathIdentifier == 0 {
+		s.initialPathPeerStatus.update(f)
+		return
+	}
+	if p := s.getPathBySendPathID(f.PathIdentifier); p != nil {
+		p.peerStatus.update(f)
+	}
+}
+
+func (s *session) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if err := s.receivedPacketHandler.ReceivedAckFrequencyFrame(f); err != nil {
+		return err
+	}
+	for _, p := range s.paths {
+		if err := p.receivedPacketHandler.ReceivedAckFrequencyFrame(f); err != nil {
+			return err
+		}
+	}
+	if s.multipath {
+		s.lastAckFrequencyFrame = f
+	}
+	return nil
+}
+
+// maybeSendMultipathProbes validates new paths and answers PATH_CHALLENGEs received on additional paths.
+// It also removes paths that couldn't be validated, and abandoned paths the peer didn't abandon in time.
+func (s *session) maybeSendMultipathProbes(now time.Time) error {
+	for _, p := range append([]*multipathPath{}, s.paths...) {
+		if p.abandoned {
+			if !now.Before(p.deadline) {
+				s.removePath(p, errors.New("path abandoned"))
+			}
+			continue
+		}
+		if !p.validated {
+			if err := p.ctx.Err(); err != nil {
+				s.removePath(p, err)
+				continue
+			}
+			if !now.Before(p.deadline) {
+				s.removePath(p, errors.New("path validation timed out"))
+				continue
+			}
+		}
+		var sendChallenge bool
+		if !p.validated && !now.Before(p.nextProbe) {
+			sendChallenge = true
+			// back off exponentially, like we do for PTO probe packets
+			p.nextProbe = now.Add(p.rttStats.PTO(true) << p.NumProbes())
+		}
+		if !sendChallenge && len(p.responses) == 0 {
+			continue
+		}
+		if err := s.sendMultipathProbe(p, sendChallenge, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendMultipathProbe sends a packet probing a path, using the packet number space of that path.
+func (s *session) sendMultipathProbe(p *multipathPath, sendChallenge bool, now time.Time) error {
+	frames, challenge, err := p.ProbeFrames(sendChallenge)
+	if err != nil {
+		return err
+	}
+	packet, err := p.packer.PackPathProbePacket(p.connID.ConnectionID, frames, p.SendBudget())
+	if err != nil {
+		return err
+	}
+	if packet == nil {
+		s.logger.Debugf("Not sending path probe packet to %s. Blocked by the anti-amplification limit.", p.conn.RemoteAddr())
+		return nil
+	}
+	p.responses = nil
+	if sendChallenge {
+		p.AddChallenge(challenge)
+	}
+	ecn := s.registerPackedPacket(packet, p.sentPacketHandler, now)
+	p.SentBytes(protocol.ByteCount(len(packet.buffer.Data)))
+	err = p.conn.Write(packet.buffer.Data, 0, ecn)
+	packet.buffer.Release()
+	if err != nil {
+		s.logger.Debugf("Sending path probe packet to %s failed: %s", p.conn.RemoteAddr(), err)
+	}
+	return nil
+}
+
+// A pathSender is a path that packets can be sent on.
+type pathSender struct {
+	path *multipathPath // nil for the initial path
+
+	conn              sendConn
+	rttStats          *utils.RTTStats
+	sentPacketHandler ackhandler.SentPacketHandler
+	packer            packer
+	sendQueue         sender
+	status            PathStatus
+}
+
+func (p *pathSender) connID(s *session) protocol.ConnectionID {
+	if p.path == nil {
+		return s.connIDManager.Get()
+	}
+	return p.path.connID.ConnectionID
+}
+
+func (p *pathSender) canSend() bool {
+	return p.sentPacketHandler.SendMode() == ackhandler.SendAny && p.sentPacketHandler.HasPacingBudget() && !p.sendQueue.WouldBlock()
+}
+
+func (p *pathSender) info() PathInfo {
+	stats := p.sentPacketHandler.GetStats()
+	return PathInfo{
+		LocalAddr:         p.conn.LocalAddr(),
+		RemoteAddr:        p.conn.RemoteAddr(),
+		Status:            p.status,
+		SmoothedRTT:       p.rttStats.SmoothedRTT(),
+		CongestionWindow:  uint64(stats.CongestionWindow),
+		BytesInFlight:     uint64(stats.BytesInFlight),
+		CanSend:           p.canSend(),
+		PotentiallyFailed: stats.PTOCount > 0,
+	}
+}
+
+// pathSenders returns the initial path and all validated paths that haven't been abandoned.
+func (s *session) pathSenders() []*pathSender {
+	senders := make([]*pathSender, 1, len(s.paths)+1)
+	senders[0] = &pathSender{
+		conn:              s.conn,
+		rttStats:          s.rttStats,
+		sentPacketHandler: s.sentPacketHandler,
+		packer:            s.packer,
+		sendQueue:         s.sendQueue,
+		status:            s.initialPathPeerStatus.status,
+	}
+	for _, p := range s.paths {
+		if !p.validated || p.abandoned {
+			continue
+		}
+		status := p.status
+		if p.peerStatus.status == PathStatusStandby {
+			status = PathStatusStandby
+		}
+		senders = append(senders, &pathSender{
+			path:              p,
+			conn:              p.conn,
+			rttStats:          p.rttStats,
+			sentPacketHandler: p.sentPacketHandler,
+			packer:            p.packer,
+			sendQueue:         p.sendQueue,
+			status:            status,
+		})
+	}
+	return senders
+}
+
+// sendPacketsMultipath sends packets when using multiple paths.
+// The path scheduler selects the path for every packet.
+// Packets that only contain acknowledgements are sent on the path they acknowledge packets for.
+func (s *session) sendPacketsMultipath() error {
+	s.pacingDeadline = time.Time{}
+	now := time.Now()
+
+	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
+		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
+	}
+	s.windowUpdateQueue.QueueAll()
+
+	senders := s.pathSenders()
+	for _, p := range senders {
+		if err := s.maybeSendPTOProbes(p, senders, now); err != nil {
+			return err
+		}
+	}
+	// Paths might have been abandoned when sending the probe packets.
+	senders = s.pathSenders()
+	if !s.config.DisablePathMTUDiscovery && senders[0].canSend() && s.mtuDiscoverer.ShouldSendProbe(now) {
+		packet, err := s.packer.PackMTUProbePacket(s.mtuDiscoverer.GetPing())
+		if err != nil {
+			return err
+		}
+		s.sendPackedPacket(packet, now)
+	}
+
+	scheduler := s.config.PathScheduler
+	if scheduler == nil {
+		scheduler = &minRTTScheduler{}
+	}
+	sentPacket := make([]bool, len(senders))
+	infos := make([]PathInfo, len(senders))
+	for {
+		for i, p := range senders {
+			infos[i] = p.info()
+		}
+		i := scheduler.SelectPath(infos)
+		if i < 0 || i >= len(senders) || !infos[i].CanSend {
+			break
+		}
+		p := senders[i]
+		packet, err := p.packer.PackPacket()
+		if err != nil {
+			return err
+		}
+		if packet == nil {
+			for _, p := range senders {
+				if p.sentPacketHandler.SendMode() == ackhandler.SendAny {
+					p.sentPacketHandler.OnApplicationLimited()
+				}
+			}
+			break
+		}
+		ecn := s.registerPackedPacket(packet, p.sentPacketHandler, now)
+		p.sendQueue.Send(packet.buffer, 0, ecn)
+		sentPacket[i] = true
+		// Prioritize receiving of packets over sending out more packets.
+		if len(s.receivedPackets) > 0 {
+			s.pacingDeadline = deadlineSendImmediately
+			return nil
+		}
+	}
+
+	for i, p := range senders {
+		// Send acknowledgements on paths that no packet was sent on,
+		// e.g. because they're congestion limited, or because the scheduler didn't select them.
+		if !sentPacket[i] && p.sentPacketHandler.SendMode() != ackhandler.SendNone && !p.sendQueue.WouldBlock() {
+			packet, err := p.packer.MaybePackAckPacket(true)
+			if err != nil {
+				return err
+			}
+			if packet != nil {
+				ecn := s.registerPackedPacket(packet, p.sentPacketHandler, now)
+				p.sendQueue.Send(packet.buffer, 0, ecn)
+			}
+		}
+		if p.sentPacketHandler.SendMode() == ackhandler.SendAny && !p.sentPacketHandler.HasPacingBudget() {
+			deadline := p.sentPacketHandler.TimeUntilSend()
+			if deadline.IsZero() {
+				deadline = deadlineSendImmediately
+			}
+			s.pacingDeadline = utils.MinNonZeroTime(s.pacingDeadline, deadline)
+		}
+	}
+	return nil
+}
+
+// maybeSendPTOProbes sends probe packets on a path that experienced a probe timeout (RFC 9002, Section 6.2.4).
+// Just like on a single path, the probe packets retransmit data sent on this path, or contain a PING frame.
+// Outstanding data is only retransmitted on the other paths once the path has failed.
+func (s *session) maybeSendPTOProbes(p *pathSender, senders []*pathSender, now time.Time) error {
+	if p.sentPacketHandler.SendMode() != ackhandler.SendPTOAppData {
+		return nil
+	}
+	failed := p.sentPacketHandler.GetStats().PTOCount >= pathFailurePTOCount && hasWorkingPath(senders)
+	if failed {
+		s.logger.Debugf("Path to %s failed.", p.conn.RemoteAddr())
+		if p.path != nil {
+			s.abandonMultipathPath(p.path, now)
+			return nil
+		}
+		// The initial path can't be abandoned.
+		// Its data is retransmitted on the other paths, and it is only probed using PING frames.
+		p.sentPacketHandler.QueueAllForRetransmission()
+	}
+	for p.sentPacketHandler.SendMode() == ackhandler.SendPTOAppData && !p.sendQueue.WouldBlock() {
+		var packet *packedPacket
+		var err error
+		if failed {
+			ping := ackhandler.Frame{
+				Frame: &wire.PingFrame{},
+				// A new probe packet is sent when the PTO timer fires again.
+				OnLost: func(wire.Frame) {},
+			}
+			packet, err = p.packer.PackPathProbePacket(p.connID(s), []ackhandler.Frame{ping}, p.packer.MaxPacketSize())
+		} else {
+			packet, err = s.packPathProbePacket(p)
+		}
+		if err != nil {
+			return err
+		}
+		if packet == nil {
+			return nil
+		}
+		ecn := s.registerPackedPacket(packet, p.sentPacketHandler, now)
+		p.sendQueue.Send(packet.buffer, 0, ecn)
+	}
+	return nil
+}
+
+// hasWorkingPath says if any of the paths didn't experience a probe timeout.
+func hasWorkingPath(senders []*pathSender) bool {
+	for _, p := range senders {
+		if p.sentPacketHandler.GetStats().PTOCount == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *session) packPathProbePacket(p *pathSender) (*packedPacket, error) {
+	// Queue probe packets until we actually pack a packet,
+	// or until there are no more packets to queue.
+	for p.sentPacketHandler.QueueProbePacket(protocol.Encryption1RTT) {
+		packet, err := p.packer.MaybePackProbePacket(protocol.Encryption1RTT)
+		if err != nil {
+			return nil, err
+		}
+		if packet != nil {
+			return packet, nil
+		}
+	}
+	s.retransmissionQueue.AddAppData(&wire.PingFrame{})
+	packet, err := p.packer.MaybePackProbePacket(protocol.Encryption1RTT)
+	if err != nil {
+		return nil, err
+	}
+	if packet == nil || packet.packetContents == nil {
+		return nil, errors.New("session BUG: couldn't pack 1-RTT probe packet on path")
+	}
+	return packet, nil
+}
@@ -71,12 +71,17 @@ type packetHandlerMap struct {
 	deleteRetiredSessionsAfter time.Duration
 	zeroRTTQueueDuration       time.Duration
 
-	statelessResetEnabled bool
 	statelessResetMutex   sync.Mutex
-	statelessResetHasher  hash.Hash
+	statelessResetEnabled bool
+	// The first hasher uses the current stateless reset key, and is used to generate new tokens.
+	// After a key rotation, the second hasher uses the previous key.
+	statelessResetHashers []hash.Hash
+	statelessResetStats   StatelessResetStats
 
 	tracer logging.Tracer
-	logger utils.Logger
+	// set if the tracer implements the logging.StatelessResetTracer interface
+	statelessResetTracer logging.StatelessResetTracer
+	logger               utils.Logger
 }
 
 var _ packetHandlerManager = &packetHandlerMap{}
@@ -138,10 +143,13 @@ func newPacketHandlerMap(
 		deleteRetiredSessionsAfter: protocol.RetiredConnectionIDDeleteTimeout,
 		zeroRTTQueueDuration:       protocol.Max0RTTQueueingDuration,
 		statelessResetEnabled:      len(statelessResetKey) > 0,
-		statelessResetHasher:       hmac.New(sha256.New, statelessResetKey),
+		statelessResetHashers:      []hash.Hash{hmac.New(sha256.New, statelessResetKey)},
 		tracer:                     tracer,
 		logger:                     logger,
 	}
+	if t, ok := tracer.(logging.StatelessResetTracer); ok {
+		m.statelessResetTracer = t
+	}
 	go m.listen()
 
 	if logger.Debug() {
@@ -298,6 +306,29 @@ func (h *packetHandlerMap) Destroy() error {
 	return nil
 }
 
+// CloseIfUnused stops reading from the packet conn and removes it from the multiplexer,
+// if it's not used by any session or by a server. Unlike Destroy, it doesn't close the packet conn.
+// It is used to release packet conns passed to MigrateTo and AddPath that the session didn't start using.
+func (h *packetHandlerMap) CloseIfUnused() {
+	h.mutex.Lock()
+	if h.closed || h.server != nil || len(h.handlers) > 0 || len(h.resetTokens) > 0 {
+		h.mutex.Unlock()
+		return
+	}
+	h.closed = true
+	h.mutex.Unlock()
+
+	if err := getMultiplexer().RemoveConn(h.conn); err != nil {
+		h.logger.Debugf("Removing packet conn from the multiplexer failed: %s", err)
+	}
+	// make the ReadPacket call in listen() return
+	if err := h.conn.SetReadDeadline(time.Now()); err != nil {
+		h.logger.Debugf("Setting the read deadline failed: %s", err)
+	}
+	<-h.listening
+	h.conn.SetReadDeadline(time.Time{})
+}
+
 func (h *packetHandlerMap) close(e error) error {
 	h.mutex.Lock()
 	if h.closed {
@@ -327,6 +358,9 @@ func (h *packetHandlerMap) listen() {
 	defer close(h.listening)
 	for {
 		p, err := h.conn.ReadPacket()
+		if err != nil && h.isClosed() { // CloseIfUnused was called
+			return
+		}
 		if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
 			h.logger.Debugf("Temporary error reading from conn: %w", err)
 			continue
@@ -335,10 +369,17 @@ func (h *packetHandlerMap) listen() {
 			h.close(err)
 			return
 		}
+		p.rcvConn = h.conn
 		h.handlePacket(p)
 	}
 }
 
+func (h *packetHandlerMap) isClosed() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.closed
+}
+
 func (h *packetHandlerMap) handlePacket(p *receivedPacket) {
 	connID, err := wire.ParseConnectionID(p.data, h.connIDLen)
 	if err != nil {
@@ -353,7 +394,7 @@ func (h *packetHandlerMap) handlePacket(p *receivedPacket) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
-	if isStatelessReset := h.maybeHandleStatelessReset(p.data); isStatelessReset {
+	if isStatelessReset := h.maybeHandleStatelessReset(p); isStatelessReset {
 		return
 	}
 
@@ -409,26 +450,51 @@ func (h *packetHandlerMap) handlePacket(p *receivedPacket) {
 	h.server.handlePacket(p)
 }
 
-func (h *packetHandlerMap) maybeHandleStatelessReset(data []byte) bool {
+func (h *packetHandlerMap) maybeHandleStatelessReset(p *receivedPacket) bool {
 	// stateless resets are always short header packets
-	if data[0]&0x80 != 0 {
+	if p.data[0]&0x80 != 0 {
 		return false
 	}
-	if len(data) < 17 /* type byte + 16 bytes for the reset token */ {
+	if len(p.data) < 17 /* type byte + 16 bytes for the reset token */ {
 		return false
 	}
 
 	var token protocol.StatelessResetToken
-	copy(token[:], data[len(data)-16:])
+	copy(token[:], p.data[len(p.data)-16:])
 	if sess, ok := h.resetTokens[token]; ok {
 		h.logger.Debugf("Received a stateless reset with token %#x. Closing session.", token)
+		h.statelessResetMutex.Lock()
+		h.statelessResetStats.Received++
+		h.statelessResetMutex.Unlock()
+		if h.statelessResetTracer != nil {
+			h.statelessResetTracer.ReceivedStatelessReset(p.remoteAddr, token)
+		}
 		go sess.destroy(&StatelessResetError{Token: token})
 		return true
 	}
 	return false
 }
 
+// RotateStatelessResetKey starts using a new stateless reset key.
+// Stateless resets are still sent for tokens that were derived from the previous key,
+// as long as the received packet is large enough.
+func (h *packetHandlerMap) RotateStatelessResetKey(key []byte) {
+	h.statelessResetMutex.Lock()
+	defer h.statelessResetMutex.Unlock()
+
+	hasher := hmac.New(sha256.New, key)
+	if h.statelessResetEnabled {
+		h.statelessResetHashers = []hash.Hash{hasher, h.statelessResetHashers[0]}
+	} else {
+		h.statelessResetHashers = []hash.Hash{hasher}
+	}
+	h.statelessResetEnabled = true
+}
+
 func (h *packetHandlerMap) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	h.statelessResetMutex.Lock()
+	defer h.statelessResetMutex.Unlock()
+
 	var token protocol.StatelessResetToken
 	if !h.statelessResetEnabled {
 		// Return a random stateless reset token.
@@ -437,31 +503,61 @@ func (h *packetHandlerMap) GetStatelessResetToken(connID protocol.ConnectionID)
 		rand.Read(token[:])
 		return token
 	}
+	return h.getStatelessResetToken(h.statelessResetHashers[0], connID)
+}
+
+func (h *packetHandlerMap) StatelessResetStats() StatelessResetStats {
 	h.statelessResetMutex.Lock()
-	h.statelessResetHasher.Write(connID.Bytes())
-	copy(token[:], h.statelessResetHasher.Sum(nil))
-	h.statelessResetHasher.Reset()
-	h.statelessResetMutex.Unlock()
+	defer h.statelessResetMutex.Unlock()
+
+	return h.statelessResetStats
+}
+
+func (h *packetHandlerMap) getStatelessResetToken(hasher hash.Hash, connID protocol.ConnectionID) protocol.StatelessResetToken {
+	var token protocol.StatelessResetToken
+	hasher.Write(connID.Bytes())
+	copy(token[:], hasher.Sum(nil))
+	hasher.Reset()
 	return token
 }
 
 func (h *packetHandlerMap) maybeSendStatelessReset(p *receivedPacket, connID protocol.ConnectionID) {
 	defer p.buffer.Release()
-	if !h.statelessResetEnabled {
-		return
-	}
 	// Don't send a stateless reset in response to very small packets.
 	// This includes packets that could be stateless resets.
 	if len(p.data) <= protocol.MinStatelessResetSize {
 		return
 	}
-	token := h.GetStatelessResetToken(connID)
-	h.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
-	data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
-	rand.Read(data)
-	data[0] = (data[0] & 0x7f) | 0x40
-	data = append(data, token[:]...)
-	if _, err := h.conn.WritePacket(data, p.remoteAddr, p.info.OOB()); err != nil {
-		h.logger.Debugf("Error sending Stateless Reset: %s", err)
+	// We don't know which key was used to derive the token for this connection ID.
+	// We always send a stateless reset for the current key.
+	// After a key rotation, we also send one for the previous key,
+	// but only if both stateless resets combined are still smaller than the received packet.
+	// Otherwise, stateless resets could be used for amplification.
+	var tokens []protocol.StatelessResetToken
+	h.statelessResetMutex.Lock()
+	if h.statelessResetEnabled {
+		tokens = append(tokens, h.getStatelessResetToken(h.statelessResetHashers[0], connID))
+		if len(h.statelessResetHashers) > 1 && len(p.data) > 2*protocol.MinStatelessResetSize {
+			tokens = append(tokens, h.getStatelessResetToken(h.statelessResetHashers[1], connID))
+		}
+	}
+	h.statelessResetMutex.Unlock()
+
+	for _, token := range tokens {
+		h.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
+		data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
+		rand.Read(data)
+		data[0] = (data[0] & 0x7f) | 0x40
+		data = append(data, token[:]...)
+		if _, err := h.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNNon); err != nil {
+			h.logger.Debugf("Error sending Stateless Reset: %s", err)
+			return
+		}
+		h.statelessResetMutex.Lock()
+		h.statelessResetStats.Sent++
+		h.statelessResetMutex.Unlock()
+		if h.statelessResetTracer != nil {
+			h.statelessResetTracer.SentStatelessReset(p.remoteAddr, token)
+		}
 	}
 }
@@ -27,10 +27,11 @@ var _ = Describe("Packet Handler Map", func() {
 	}
 
 	var (
-		handler    *packetHandlerMap
-		conn       *MockPacketConn
-		tracer     *mocklogging.MockTracer
-		packetChan chan packetToRead
+		handler     *packetHandlerMap
+		conn        *MockPacketConn
+		tracer      *mocklogging.MockTracer
+		resetTracer *mocklogging.MockStatelessResetTracer
+		packetChan  chan packetToRead
 
 		connIDLen         int
 		statelessResetKey []byte
@@ -59,6 +60,7 @@ var _ = Describe("Packet Handler Map", func() {
 		statelessResetKey = nil
 		connIDLen = 0
 		tracer = mocklogging.NewMockTracer(mockCtrl)
+		resetTracer = mocklogging.NewMockStatelessResetTracer(mockCtrl)
 		packetChan = make(chan packetToRead, 10)
 	})
 
@@ -72,7 +74,10 @@ var _ = Describe("Packet Handler Map", func() {
 			}
 			return copy(b, p.data), p.addr, p.err
 		}).AnyTimes()
-		phm, err := newPacketHandlerMap(conn, connIDLen, statelessResetKey, tracer, utils.DefaultLogger)
+		phm, err := newPacketHandlerMap(conn, connIDLen, statelessResetKey, struct {
+			logging.Tracer
+			logging.StatelessResetTracer
+		}{tracer, resetTracer}, utils.DefaultLogger)
 		Expect(err).ToNot(HaveOccurred())
 		handler = phm.(*packetHandlerMap)
 	})
@@ -101,6 +106,28 @@ var _ = Describe("Packet Handler Map", func() {
 		Eventually(handler.listening).Should(BeClosed())
 	})
 
+	It("stops reading from the packet conn when it's unused, without closing it", func() {
+		getMultiplexer() // make the sync.Once execute
+		mockMultiplexer := NewMockMultiplexer(mockCtrl)
+		origMultiplexer := connMuxer
+		connMuxer = mockMultiplexer
+
+		defer func() {
+			connMuxer = origMultiplexer
+		}()
+
+		mockMultiplexer.EXPECT().RemoveConn(gomock.Any())
+		gomock.InOrder(
+			conn.EXPECT().SetReadDeadline(gomock.Any()).Do(func(t time.Time) {
+				Expect(t).ToNot(BeZero())
+				packetChan <- packetToRead{err: errors.New("i/o timeout")}
+			}),
+			conn.EXPECT().SetReadDeadline(time.Time{}),
+		)
+		handler.CloseIfUnused()
+		Expect(handler.listening).To(BeClosed())
+	})
+
 	Context("other operations", func() {
 		AfterEach(func() {
 			// delete sessions and the server before closing
@@ -221,6 +248,12 @@ var _ = Describe("Packet Handler Map", func() {
 				time.Sleep(50 * time.Millisecond)
 			})
 
+			It("doesn't stop reading from the packet conn when it's used by a session", func() {
+				handler.Add(protocol.ConnectionID{1, 2, 3, 4, 5}, NewMockPacketHandler(mockCtrl))
+				handler.CloseIfUnused()
+				Consistently(handler.listening).ShouldNot(BeClosed())
+			})
+
 			It("says if a connection ID is already taken", func() {
 				connID := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
 				Expect(handler.Add(connID, NewMockPacketHandler(mockCtrl))).To(BeTrue())
@@ -369,6 +402,7 @@ var _ = Describe("Packet Handler Map", func() {
 					destroyed := make(chan struct{})
 					packet := append([]byte{0x40} /* short header packet */, make([]byte, 50)...)
 					packet = append(packet, token[:]...)
+					resetTracer.EXPECT().ReceivedStatelessReset(gomock.Any(), token)
 					packetHandler.EXPECT().destroy(gomock.Any()).Do(func(err error) {
 						defer GinkgoRecover()
 						defer close(destroyed)
@@ -380,6 +414,7 @@ var _ = Describe("Packet Handler Map", func() {
 					})
 					packetChan <- packetToRead{data: packet}
 					Eventually(destroyed).Should(BeClosed())
+					Expect(handler.StatelessResetStats()).To(Equal(StatelessResetStats{Received: 1}))
 				})
 
 				It("handles stateless resets for 0-length connection IDs", func() {
@@ -390,6 +425,7 @@ var _ = Describe("Packet Handler Map", func() {
 					destroyed := make(chan struct{})
 					packet := append([]byte{0x40} /* short header packet */, make([]byte, 50)...)
 					packet = append(packet, token[:]...)
+					resetTracer.EXPECT().ReceivedStatelessReset(gomock.Any(), token)
 					packetHandler.EXPECT().destroy(gomock.Any()).Do(func(err error) {
 						defer GinkgoRecover()
 						Expect(err).To(HaveOccurred())
@@ -451,17 +487,90 @@ var _ = Describe("Packet Handler Map", func() {
 					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
 					p := append([]byte{40}, make([]byte, 100)...)
 					done := make(chan struct{})
+					var token protocol.StatelessResetToken
 					conn.EXPECT().WriteTo(gomock.Any(), addr).Do(func(b []byte, _ net.Addr) {
-						defer close(done)
 						Expect(b[0] & 0x80).To(BeZero()) // short header packet
 						Expect(b).To(HaveLen(protocol.MinStatelessResetSize))
+						copy(token[:], b[len(b)-16:])
+					})
+					resetTracer.EXPECT().SentStatelessReset(addr, gomock.Any()).Do(func(_ net.Addr, t protocol.StatelessResetToken) {
+						defer close(done)
+						Expect(t).To(Equal(token))
+					})
+					handler.handlePacket(&receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: addr,
+						data:       p,
 					})
+					Eventually(done).Should(BeClosed())
+					Expect(token).To(Equal(handler.GetStatelessResetToken(p[1 : 1+connIDLen])))
+				})
+
+				It("sends stateless resets for the current and the previous key after a rotation", func() {
+					connID := protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef, 0x42}
+					token1 := handler.GetStatelessResetToken(connID)
+					key2 := make([]byte, 32)
+					rand.Read(key2)
+					handler.RotateStatelessResetKey(key2)
+					token2 := handler.GetStatelessResetToken(connID)
+					Expect(token2).ToNot(Equal(token1))
+					key3 := make([]byte, 32)
+					rand.Read(key3)
+					handler.RotateStatelessResetKey(key3)
+					token3 := handler.GetStatelessResetToken(connID)
+					Expect(token3).ToNot(Equal(token1))
+					Expect(token3).ToNot(Equal(token2))
+
+					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+					p := append([]byte{0x40}, connID...)
+					p = append(p, make([]byte, 100)...)
+					var tokens []protocol.StatelessResetToken
+					conn.EXPECT().WriteTo(gomock.Any(), addr).Do(func(b []byte, _ net.Addr) {
+						Expect(b).To(HaveLen(protocol.MinStatelessResetSize))
+						var token protocol.StatelessResetToken
+						copy(token[:], b[len(b)-16:])
+						tokens = append(tokens, token)
+					}).Times(2)
+					done := make(chan struct{})
+					gomock.InOrder(
+						resetTracer.EXPECT().SentStatelessReset(addr, token3),
+						resetTracer.EXPECT().SentStatelessReset(addr, token2).Do(func(net.Addr, protocol.StatelessResetToken) { close(done) }),
+					)
 					handler.handlePacket(&receivedPacket{
 						buffer:     getPacketBuffer(),
 						remoteAddr: addr,
 						data:       p,
 					})
 					Eventually(done).Should(BeClosed())
+					Expect(tokens).To(Equal([]protocol.StatelessResetToken{token3, token2}))
+					Expect(handler.StatelessResetStats()).To(Equal(StatelessResetStats{Sent: 2}))
+				})
+
+				It("only sends a stateless reset for the current key if the packet is too small for two stateless resets", func() {
+					connID := protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef, 0x42}
+					key := make([]byte, 32)
+					rand.Read(key)
+					handler.RotateStatelessResetKey(key)
+					token := handler.GetStatelessResetToken(connID)
+
+					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+					p := append([]byte{0x40}, connID...)
+					p = append(p, make([]byte, 2*protocol.MinStatelessResetSize-len(p))...)
+					conn.EXPECT().WriteTo(gomock.Any(), addr).Do(func(b []byte, _ net.Addr) {
+						Expect(b).To(HaveLen(protocol.MinStatelessResetSize))
+						Expect(b[len(b)-16:]).To(Equal(token[:]))
+					})
+					done := make(chan struct{})
+					resetTracer.EXPECT().SentStatelessReset(addr, token).Do(func(net.Addr, protocol.StatelessResetToken) { close(done) })
+					handler.handlePacket(&receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: addr,
+						data:       p,
+					})
+					Eventually(done).Should(BeClosed())
+					// make sure no second stateless reset is sent
+					time.Sleep(50 * time.Millisecond)
+					Expect(handler.StatelessResetStats()).To(Equal(StatelessResetStats{Sent: 1}))
 				})
 
 				It("doesn't send stateless resets for small packets", func() {
@@ -489,6 +598,29 @@ var _ = Describe("Packet Handler Map", func() {
 					// make sure there are no Write calls on the packet conn
 					time.Sleep(50 * time.Millisecond)
 				})
+
+				It("starts sending stateless resets when a key is set", func() {
+					connID := protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef, 0x42}
+					Expect(handler.GetStatelessResetToken(connID)).ToNot(Equal(handler.GetStatelessResetToken(connID)))
+					key := make([]byte, 32)
+					rand.Read(key)
+					handler.RotateStatelessResetKey(key)
+					token := handler.GetStatelessResetToken(connID)
+					Expect(handler.GetStatelessResetToken(connID)).To(Equal(token))
+
+					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+					p := append([]byte{0x40}, connID...)
+					p = append(p, make([]byte, 100)...)
+					conn.EXPECT().WriteTo(gomock.Any(), addr)
+					done := make(chan struct{})
+					resetTracer.EXPECT().SentStatelessReset(addr, token).Do(func(net.Addr, protocol.StatelessResetToken) { close(done) })
+					handler.handlePacket(&receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: addr,
+						data:       p,
+					})
+					Eventually(done).Should(BeClosed())
+				})
 			})
 		})
 	})
@@ -18,16 +18,20 @@ import (
 type packer interface {
 	PackCoalescedPacket() (*coalescedPacket, error)
 	PackPacket() (*packedPacket, error)
+	AppendPacket(*packetBuffer) (*packedPacket, error)
 	MaybePackProbePacket(protocol.EncryptionLevel) (*packedPacket, error)
 	MaybePackAckPacket(handshakeConfirmed bool) (*packedPacket, error)
 	PackConnectionClose(*qerr.TransportError) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError) (*coalescedPacket, error)
 
 	SetMaxPacketSize(protocol.ByteCount)
+	MaxPacketSize() protocol.ByteCount
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error)
+	PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, maxSize protocol.ByteCount) (*packedPacket, error)
 
 	HandleTransportParameters(*wire.TransportParameters)
 	SetToken([]byte)
+	SetVersion(protocol.VersionNumber)
 }
 
 type sealer interface {
@@ -52,7 +56,8 @@ type packetContents struct {
 
 	length protocol.ByteCount
 
-	isMTUProbePacket bool
+	isMTUProbePacket  bool
+	isPathProbePacket bool
 }
 
 type coalescedPacket struct {
@@ -108,6 +113,7 @@ func (p *packetContents) ToAckHandlerPacket(now time.Time, q *retransmissionQueu
 		EncryptionLevel:      encLevel,
 		SendTime:             now,
 		IsPathMTUProbePacket: p.isMTUProbePacket,
+		IsPathProbePacket:    p.isPathProbePacket,
 	}
 }
 
@@ -153,7 +159,10 @@ type packetPacker struct {
 
 	perspective protocol.Perspective
 	version     protocol.VersionNumber
-	cryptoSetup sealingManager
+	// 0-RTT packets are always sent using the initial version,
+	// even if the version was changed by compatible version negotiation
+	initialVersion protocol.VersionNumber
+	cryptoSetup    sealingManager
 
 	initialStream   cryptoStream
 	handshakeStream cryptoStream
@@ -168,6 +177,13 @@ type packetPacker struct {
 
 	maxPacketSize          protocol.ByteCount
 	numNonAckElicitingAcks int
+
+	// Only set for packers of additional paths, when using multipath.
+	// sendPathID is the path ID used to seal packets, i.e. the sequence number of the destination connection ID.
+	// getAckPathID returns the path ID used in ACK_MP frames, i.e. the sequence number of the connection ID
+	// the peer sends to on this path.
+	sendPathID   uint64
+	getAckPathID func() uint64
 }
 
 var _ packer = &packetPacker{}
@@ -197,6 +213,7 @@ func newPacketPacker(
 		datagramQueue:       datagramQueue,
 		perspective:         perspective,
 		version:             version,
+		initialVersion:      version,
 		framer:              framer,
 		acks:                acks,
 		pnManager:           packetNumberManager,
@@ -204,6 +221,44 @@ func newPacketPacker(
 	}
 }
 
+// newPathPacketPacker creates a packer for an additional path of a multipath connection.
+// All packets are 1-RTT packets, sent to the destination connection ID of the path,
+// using the packet number space of the path.
+// Acknowledgements are sent in ACK_MP frames.
+func newPathPacketPacker(
+	connID protocol.ConnectionID,
+	sendPathID uint64,
+	getAckPathID func() uint64,
+	packetNumberManager packetNumberManager,
+	retransmissionQueue *retransmissionQueue,
+	remoteAddr net.Addr,
+	cryptoSetup sealingManager,
+	framer frameSource,
+	acks ackFrameSource,
+	datagramQueue *datagramQueue,
+	perspective protocol.Perspective,
+	version protocol.VersionNumber,
+) *packetPacker {
+	p := newPacketPacker(
+		nil,
+		func() protocol.ConnectionID { return connID },
+		nil,
+		nil,
+		packetNumberManager,
+		retransmissionQueue,
+		remoteAddr,
+		cryptoSetup,
+		framer,
+		acks,
+		datagramQueue,
+		perspective,
+		version,
+	)
+	p.sendPathID = sendPathID
+	p.getAckPathID = getAckPathID
+	return p
+}
+
 // PackConnectionClose packs a packet that closes the connection with a transport error.
 func (p *packetPacker) PackConnectionClose(e *qerr.TransportError) (*coalescedPacket, error) {
 	var reason string
@@ -342,7 +397,7 @@ func (p *packetPacker) MaybePackAckPacket(handshakeConfirmed bool) (*packedPacke
 	}
 	payload := &payload{
 		ack:    ack,
-		length: ack.Length(p.version),
+		length: p.ackFrame(ack).Length(p.version),
 	}
 
 	sealer, hdr, err := p.getSealerAndHeader(encLevel)
@@ -464,11 +519,22 @@ func (p *packetPacker) PackCoalescedPacket() (*coalescedPacket, error) {
 // PackPacket packs a packet in the application data packet number space.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) PackPacket() (*packedPacket, error) {
+	buffer := getPacketBuffer()
+	packet, err := p.AppendPacket(buffer)
+	if packet == nil || err != nil {
+		buffer.Release()
+	}
+	return packet, err
+}
+
+// AppendPacket packs a packet in the application data packet number space,
+// and appends it to the buffer. The buffer must have space for a packet of the maximum packet size.
+// It is used to pack multiple packets into a single buffer when sending with GSO.
+func (p *packetPacker) AppendPacket(buffer *packetBuffer) (*packedPacket, error) {
 	sealer, hdr, payload := p.maybeGetAppDataPacket(p.maxPacketSize, 0)
 	if payload == nil {
 		return nil, nil
 	}
-	buffer := getPacketBuffer()
 	encLevel := protocol.Encryption1RTT
 	if hdr.IsLongHeader {
 		encLevel = protocol.Encryption0RTT
@@ -563,12 +629,12 @@ func (p *packetPacker) maybeGetAppDataPacket(maxPacketSize, currentSize protocol
 	}
 
 	maxPayloadSize := maxPacketSize - hdr.GetLength(p.version) - protocol.ByteCount(sealer.Overhead())
-	payload := p.maybeGetAppDataPacketWithEncLevel(maxPayloadSize, encLevel == protocol.Encryption1RTT && currentSize == 0)
+	payload := p.maybeGetAppDataPacketWithEncLevel(maxPayloadSize, currentSize, encLevel == protocol.Encryption1RTT && currentSize == 0)
 	return sealer, hdr, payload
 }
 
-func (p *packetPacker) maybeGetAppDataPacketWithEncLevel(maxPayloadSize protocol.ByteCount, ackAllowed bool) *payload {
-	payload := p.composeNextPacket(maxPayloadSize, ackAllowed)
+func (p *packetPacker) maybeGetAppDataPacketWithEncLevel(maxPayloadSize, coalescedSize protocol.ByteCount, ackAllowed bool) *payload {
+	payload := p.composeNextPacket(maxPayloadSize, coalescedSize, ackAllowed)
 
 	// check if we have anything to send
 	if len(payload.frames) == 0 {
@@ -591,31 +657,31 @@ func (p *packetPacker) maybeGetAppDataPacketWithEncLevel(maxPayloadSize protocol
 	return payload
 }
 
-func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, ackAllowed bool) *payload {
+// coalescedSize is the size of the packets that this packet is coalesced with.
+func (p *packetPacker) composeNextPacket(maxFrameSize, coalescedSize protocol.ByteCount, ackAllowed bool) *payload {
 	payload := &payload{frames: make([]ackhandler.Frame, 0, 1)}
 
-	var hasDatagram bool
-	if p.datagramQueue != nil {
-		if datagram := p.datagramQueue.Get(); datagram != nil {
-			payload.frames = append(payload.frames, ackhandler.Frame{
-				Frame: datagram,
-				// set it to a no-op. Then we won't set the default callback, which would retransmit the frame.
-				OnLost: func(wire.Frame) {},
-			})
-			payload.length += datagram.Length(p.version)
-			hasDatagram = true
-		}
-	}
+	hasDatagram := p.datagramQueue != nil && p.datagramQueue.HasData()
 
 	var ack *wire.AckFrame
 	hasData := p.framer.HasData()
 	hasRetransmission := p.retransmissionQueue.HasAppData()
-	// TODO: make sure ACKs are sent when a lot of DATAGRAMs are queued
-	if !hasDatagram && ackAllowed {
-		ack = p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData)
+	if ackAllowed {
+		ack = p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData && !hasDatagram)
 		if ack != nil {
 			payload.ack = ack
-			payload.length += ack.Length(p.version)
+			payload.length += p.ackFrame(ack).Length(p.version)
+		}
+	}
+
+	if hasDatagram {
+		// If the DATAGRAM frame doesn't even fit into an empty packet that's not coalesced with other packets,
+		// it is dropped. There's no point in retrying, since the packet size is unlikely to increase.
+		// If it didn't fit because of the ACK frame, or because this packet is coalesced with other packets,
+		// it will be sent in the next packet.
+		if f, ok := p.datagramQueue.PopIfFits(maxFrameSize-payload.length, maxFrameSize+coalescedSize, p.version); ok {
+			payload.frames = append(payload.frames, f)
+			payload.length += f.Frame.Length(p.version)
 		}
 	}
 
@@ -676,7 +742,7 @@ func (p *packetPacker) MaybePackProbePacket(encLevel protocol.EncryptionLevel) (
 		}
 		sealer = oneRTTSealer
 		hdr = p.getShortHeader(oneRTTSealer.KeyPhase())
-		payload = p.maybeGetAppDataPacketWithEncLevel(p.maxPacketSize-protocol.ByteCount(sealer.Overhead())-hdr.GetLength(p.version), true)
+		payload = p.maybeGetAppDataPacketWithEncLevel(p.maxPacketSize-protocol.ByteCount(sealer.Overhead())-hdr.GetLength(p.version), 0, true)
 	default:
 		panic("unknown encryption level")
 	}
@@ -722,6 +788,41 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	}, nil
 }
 
+// PackPathProbePacket packs a packet that is sent on a new path.
+// It uses the connection ID that was set aside for this path, and it is padded to
+// at least 1200 bytes, as required by RFC 9000, Section 8.2.1, unless that would exceed maxSize.
+// This is the case if the anti-amplification limit of an unvalidated peer address doesn't permit sending a full-size packet.
+// If the packet doesn't fit into maxSize at all, nil is returned.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, maxSize protocol.ByteCount) (*packedPacket, error) {
+	payload := &payload{frames: frames}
+	for _, f := range frames {
+		payload.length += f.Length(p.version)
+	}
+	sealer, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	hdr := p.getShortHeaderWithConnID(sealer.KeyPhase(), connID)
+	size := p.packetLength(hdr, payload) + protocol.ByteCount(sealer.Overhead())
+	if size > maxSize {
+		return nil, nil
+	}
+	var padding protocol.ByteCount
+	if size < protocol.MinInitialPacketSize {
+		padding = utils.MinByteCount(protocol.MinInitialPacketSize, maxSize) - size
+	}
+	buffer := getPacketBuffer()
+	contents, err := p.appendPacket(buffer, hdr, payload, padding, protocol.Encryption1RTT, sealer, false)
+	if err != nil {
+		return nil, err
+	}
+	contents.isPathProbePacket = true
+	return &packedPacket{
+		buffer:         buffer,
+		packetContents: contents,
+	}, nil
+}
+
 func (p *packetPacker) getSealerAndHeader(encLevel protocol.EncryptionLevel) (sealer, *wire.ExtendedHeader, error) {
 	switch encLevel {
 	case protocol.EncryptionInitial:
@@ -758,11 +859,15 @@ func (p *packetPacker) getSealerAndHeader(encLevel protocol.EncryptionLevel) (se
 }
 
 func (p *packetPacker) getShortHeader(kp protocol.KeyPhaseBit) *wire.ExtendedHeader {
+	return p.getShortHeaderWithConnID(kp, p.getDestConnID())
+}
+
+func (p *packetPacker) getShortHeaderWithConnID(kp protocol.KeyPhaseBit, connID protocol.ConnectionID) *wire.ExtendedHeader {
 	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	hdr := &wire.ExtendedHeader{}
 	hdr.PacketNumber = pn
 	hdr.PacketNumberLen = pnLen
-	hdr.DestConnectionID = p.getDestConnID()
+	hdr.DestConnectionID = connID
 	hdr.KeyPhase = kp
 	return hdr
 }
@@ -787,6 +892,7 @@ func (p *packetPacker) getLongHeader(encLevel protocol.EncryptionLevel) *wire.Ex
 		hdr.Type = protocol.PacketTypeHandshake
 	case protocol.Encryption0RTT:
 		hdr.Type = protocol.PacketType0RTT
+		hdr.Version = p.initialVersion
 	}
 	return hdr
 }
@@ -832,7 +938,7 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 	payloadOffset := buf.Len()
 
 	if payload.ack != nil {
-		if err := payload.ack.Write(buf, p.version); err != nil {
+		if err := p.ackFrame(payload.ack).Write(buf, p.version); err != nil {
 			return nil, err
 		}
 	}
@@ -849,7 +955,7 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 		return nil, fmt.Errorf("PacketPacker BUG: payload size inconsistent (expected %d, got %d bytes)", payload.length, payloadSize)
 	}
 	if !isMTUProbePacket {
-		if size := protocol.ByteCount(buf.Len() + sealer.Overhead()); size > p.maxPacketSize {
+		if size := protocol.ByteCount(buf.Len() - int(hdrOffset) + sealer.Overhead()); size > p.maxPacketSize {
 			return nil, fmt.Errorf("PacketPacker BUG: packet too large (%d bytes, allowed %d bytes)", size, p.maxPacketSize)
 		}
 	}
@@ -857,7 +963,11 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 	raw := buffer.Data
 	// encrypt the packet
 	raw = raw[:buf.Len()]
-	_ = sealer.Seal(raw[payloadOffset:payloadOffset], raw[payloadOffset:], header.PacketNumber, raw[hdrOffset:payloadOffset])
+	if s, ok := sealer.(handshake.ShortHeaderSealer); ok && p.getAckPathID != nil {
+		_ = s.SealPath(raw[payloadOffset:payloadOffset], raw[payloadOffset:], p.sendPathID, header.PacketNumber, raw[hdrOffset:payloadOffset])
+	} else {
+		_ = sealer.Seal(raw[payloadOffset:payloadOffset], raw[payloadOffset:], header.PacketNumber, raw[hdrOffset:payloadOffset])
+	}
 	raw = raw[0 : buf.Len()+sealer.Overhead()]
 	// apply header protection
 	pnOffset := payloadOffset - int(header.PacketNumberLen)
@@ -876,15 +986,34 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 	}, nil
 }
 
+// ackFrame returns the frame used to send an acknowledgement.
+// On additional paths of a multipath connection, acknowledgements are sent in ACK_MP frames.
+func (p *packetPacker) ackFrame(ack *wire.AckFrame) wire.Frame {
+	if p.getAckPathID == nil {
+		return ack
+	}
+	return &wire.AckMPFrame{PathIdentifier: p.getAckPathID(), AckFrame: *ack}
+}
+
 func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
 
+// SetVersion is called when the version is changed during the handshake (RFC 9368).
+func (p *packetPacker) SetVersion(v protocol.VersionNumber) {
+	p.version = v
+}
+
 // When a higher MTU is discovered, use it.
 func (p *packetPacker) SetMaxPacketSize(s protocol.ByteCount) {
 	p.maxPacketSize = s
 }
 
+// MaxPacketSize returns the maximum size of packets that are currently sent.
+func (p *packetPacker) MaxPacketSize() protocol.ByteCount {
+	return p.maxPacketSize
+}
+
 // If the peer sets a max_packet_size that's smaller than the size we're currently using,
 // we need to reduce the size of packets we send.
 func (p *packetPacker) HandleTransportParameters(params *wire.TransportParameters) {
@@ -84,14 +84,14 @@ var _ = Describe("Packet packer", func() {
 		rand.Seed(GinkgoRandomSeed())
 		retransmissionQueue = newRetransmissionQueue(version)
 		mockSender := NewMockStreamSender(mockCtrl)
-		mockSender.EXPECT().onHasStreamData(gomock.Any()).AnyTimes()
+		mockSender.EXPECT().onHasStreamData(gomock.Any(), gomock.Any()).AnyTimes()
 		initialStream = NewMockCryptoStream(mockCtrl)
 		handshakeStream = NewMockCryptoStream(mockCtrl)
 		framer = NewMockFrameSource(mockCtrl)
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, protocol.DatagramSendQueueLen, DatagramDropNone, utils.DefaultLogger)
 
 		packer = newPacketPacker(
 			protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8},
@@ -470,6 +470,30 @@ var _ = Describe("Packet packer", func() {
 				Expect(hdrs[0].Type).To(Equal(protocol.PacketTypeInitial))
 				Expect(hdrs[1].Type).To(Equal(protocol.PacketType0RTT))
 			})
+
+			It("uses the initial version for 0-RTT packets, after the version was changed", func() {
+				packer.perspective = protocol.PerspectiveClient
+				packer.SetVersion(protocol.Version2)
+				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(1), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(1))
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption0RTT).Return(protocol.PacketNumber(2), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption0RTT).Return(protocol.PacketNumber(2))
+				sealingManager.EXPECT().GetInitialSealer().Return(getSealer(), nil)
+				sealingManager.EXPECT().GetHandshakeSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+				sealingManager.EXPECT().Get0RTTSealer().Return(getSealer(), nil)
+				sealingManager.EXPECT().Get1RTTSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+				p, err := packer.PackApplicationClose(&qerr.ApplicationError{ErrorCode: 0x1337})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.packets).To(HaveLen(2))
+				Expect(p.packets[0].header.Version).To(Equal(protocol.Version2))
+				Expect(p.packets[1].header.Version).To(Equal(version))
+				hdrs := parsePacket(p.buffer.Data)
+				Expect(hdrs).To(HaveLen(2))
+				Expect(hdrs[0].Type).To(Equal(protocol.PacketTypeInitial))
+				Expect(hdrs[0].Version).To(Equal(protocol.Version2))
+				Expect(hdrs[1].Type).To(Equal(protocol.PacketType0RTT))
+				Expect(hdrs[1].Version).To(Equal(version))
+			})
 		})
 
 		Context("packing normal packets", func() {
@@ -505,6 +529,31 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.buffer.Data).To(ContainSubstring(b.String()))
 			})
 
+			It("appends multiple packets to the same buffer", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2)
+				framer.EXPECT().HasData().Return(true).Times(2)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(2)
+				framer.EXPECT().AppendControlFrames(gomock.Any(), gomock.Any()).Times(2)
+				framer.EXPECT().AppendStreamFrames(gomock.Any(), gomock.Any()).DoAndReturn(func(fs []ackhandler.Frame, maxLen protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
+					f := &wire.StreamFrame{StreamID: 5}
+					f.Data = make([]byte, f.MaxDataLen(maxLen, packer.version))
+					return append(fs, ackhandler.Frame{Frame: f}), f.Length(packer.version)
+				}).Times(2)
+				buffer := getLargePacketBuffer()
+				p1, err := packer.AppendPacket(buffer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p1.length).To(Equal(packer.MaxPacketSize()))
+				p2, err := packer.AppendPacket(buffer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p2.length).To(Equal(packer.MaxPacketSize()))
+				Expect(buffer.Len()).To(Equal(2 * packer.MaxPacketSize()))
+				Expect(p2.buffer).To(Equal(buffer))
+			})
+
 			It("stores the encryption level a packet was sealed with", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -554,6 +603,7 @@ var _ = Describe("Packet packer", func() {
 			})
 
 			It("packs DATAGRAM frames", func() {
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -561,15 +611,7 @@ var _ = Describe("Packet packer", func() {
 					DataLenPresent: true,
 					Data:           []byte("foobar"),
 				}
-				done := make(chan struct{})
-				go func() {
-					defer GinkgoRecover()
-					defer close(done)
-					datagramQueue.AddAndWait(f)
-				}()
-				// make sure the DATAGRAM has actually been queued
-				time.Sleep(scaleDuration(20 * time.Millisecond))
-
+				Expect(datagramQueue.Add(f)).To(Succeed())
 				framer.EXPECT().HasData()
 				p, err := packer.PackPacket()
 				Expect(p).ToNot(BeNil())
@@ -577,7 +619,127 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.frames).To(HaveLen(1))
 				Expect(p.frames[0].Frame).To(Equal(f))
 				Expect(p.buffer.Data).ToNot(BeEmpty())
-				Eventually(done).Should(BeClosed())
+				Expect(datagramQueue.HasData()).To(BeFalse())
+			})
+
+			It("packs DATAGRAM frames together with ACK frames", func() {
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 100}}}
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Return(ack)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           []byte("foobar"),
+				}
+				Expect(datagramQueue.Add(f)).To(Succeed())
+				framer.EXPECT().HasData()
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.ack).To(Equal(ack))
+				Expect(p.frames).To(HaveLen(1))
+				Expect(p.frames[0].Frame).To(Equal(f))
+			})
+
+			It("drops DATAGRAM frames that don't fit into a packet", func() {
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, maxPacketSize),
+				}
+				Expect(datagramQueue.Add(f)).To(Succeed())
+				framer.EXPECT().HasData()
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).To(BeNil())
+				Expect(datagramQueue.HasData()).To(BeFalse())
+				Expect(datagramQueue.NumDropped()).To(BeEquivalentTo(1))
+			})
+
+			It("sends DATAGRAM frames that don't fit next to an ACK frame in the next packet", func() {
+				ack := &wire.AckFrame{}
+				for i := 0; i < 200; i++ {
+					pn := protocol.PacketNumber(2000 - 4*i)
+					ack.AckRanges = append(ack.AckRanges, wire.AckRange{Smallest: pn, Largest: pn})
+				}
+				Expect(ack.Length(packer.version)).To(BeNumerically(">", 100))
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Return(ack)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, maxPacketSize-100),
+				}
+				Expect(datagramQueue.Add(f)).To(Succeed())
+				framer.EXPECT().HasData()
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.ack).To(Equal(ack))
+				Expect(p.frames).To(BeEmpty())
+				Expect(datagramQueue.HasData()).To(BeTrue())
+				Expect(datagramQueue.NumDropped()).To(BeZero())
+			})
+
+			It("only packs DATAGRAM frames that fit, while the oldest frames are concurrently dropped", func() {
+				queue := newDatagramQueue(func() {}, 1, DatagramDropOldest, utils.DefaultLogger)
+				packer.datagramQueue = queue
+				ack := &wire.AckFrame{}
+				for i := 0; i < 200; i++ {
+					pn := protocol.PacketNumber(2000 - 4*i)
+					ack.AckRanges = append(ack.AckRanges, wire.AckRange{Smallest: pn, Largest: pn})
+				}
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any()).Return(ack).AnyTimes()
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).AnyTimes()
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42)).AnyTimes()
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).AnyTimes()
+				framer.EXPECT().HasData().AnyTimes()
+
+				// The small frame fits next to the ACK frame, the large frame only fits into a packet on its own.
+				small := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar")}
+				large := &wire.DatagramFrame{DataLenPresent: true, Data: make([]byte, maxPacketSize-100)}
+				const numSenders = 4
+				done := make(chan struct{})
+				sendersDone := make(chan struct{}, numSenders)
+				for i := 0; i < numSenders; i++ {
+					f := small
+					if i%2 == 0 {
+						f = large
+					}
+					go func() {
+						defer GinkgoRecover()
+						defer func() { sendersDone <- struct{}{} }()
+						for {
+							select {
+							case <-done:
+								return
+							default:
+								Expect(queue.Add(f)).To(Succeed())
+							}
+						}
+					}()
+				}
+				defer func() {
+					close(done)
+					for i := 0; i < numSenders; i++ {
+						Eventually(sendersDone).Should(Receive())
+					}
+				}()
+				for i := 0; i < 1000; i++ {
+					p, err := packer.PackPacket()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p).ToNot(BeNil())
+					Expect(p.ack).To(Equal(ack))
+					for _, f := range p.frames {
+						if df, ok := f.Frame.(*wire.DatagramFrame); ok {
+							Expect(df).To(Equal(small))
+						}
+					}
+				}
 			})
 
 			It("accounts for the space consumed by control frames", func() {
@@ -631,7 +793,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -668,7 +830,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true, packer.version)
+				frameParser := wire.NewFrameParser(true, false, false, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -1167,6 +1329,38 @@ var _ = Describe("Packet packer", func() {
 				Expect(rest).To(BeEmpty())
 			})
 
+			It("doesn't drop DATAGRAM frames that only don't fit into a coalesced 1-RTT packet", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x24), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x24))
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().GetInitialSealer().Return(nil, handshake.ErrKeysDropped)
+				sealingManager.EXPECT().GetHandshakeSealer().Return(getSealer(), nil)
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				framer.EXPECT().HasData().Return(true)
+				ackFramer.EXPECT().GetAckFrame(protocol.EncryptionHandshake, false)
+				handshakeStream.EXPECT().HasData().Return(true).Times(2)
+				handshakeStream.EXPECT().PopCryptoFrame(gomock.Any()).DoAndReturn(func(size protocol.ByteCount) *wire.CryptoFrame {
+					return &wire.CryptoFrame{Offset: 0x1337, Data: make([]byte, 500)}
+				})
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, maxPacketSize-200),
+				}
+				Expect(datagramQueue.Add(f)).To(Succeed())
+				expectAppendControlFrames()
+				expectAppendStreamFrames(ackhandler.Frame{Frame: &wire.StreamFrame{Data: []byte("foobar")}})
+				p, err := packer.PackCoalescedPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.packets).To(HaveLen(2))
+				Expect(p.packets[1].EncryptionLevel()).To(Equal(protocol.Encryption1RTT))
+				Expect(p.packets[1].frames).To(HaveLen(1))
+				Expect(p.packets[1].frames[0].Frame.(*wire.StreamFrame).Data).To(Equal([]byte("foobar")))
+				// the DATAGRAM frame will be sent in the next packet
+				Expect(datagramQueue.HasData()).To(BeTrue())
+				Expect(datagramQueue.NumDropped()).To(BeZero())
+			})
+
 			It("doesn't add a coalesced packet if the remaining size is smaller than MaxCoalescedPacketSize", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x24), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x24))
@@ -1221,7 +1415,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1494,6 +1688,140 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.packetContents.isMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, err := packer.PackPathProbePacket(connID, []ackhandler.Frame{challenge}, protocol.MaxByteCount)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.header.IsLongHeader).To(BeFalse())
+				Expect(p.header.DestConnectionID).To(Equal(connID))
+				Expect(p.header.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.frames).To(Equal([]ackhandler.Frame{challenge}))
+				Expect(p.length).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+				Expect(p.buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.packetContents.isMTUProbePacket).To(BeFalse())
+				Expect(p.packetContents.isPathProbePacket).To(BeTrue())
+			})
+
+			It("only pads path probe packets as much as the size limit allows", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				frames := []ackhandler.Frame{
+					{Frame: &wire.PathResponseFrame{Data: [8]byte{8, 7, 6, 5, 4, 3, 2, 1}}},
+					{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+				}
+				p, err := packer.PackPathProbePacket(protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}, frames, 500)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.frames).To(Equal(frames))
+				Expect(p.buffer.Data).To(HaveLen(500))
+			})
+
+			It("doesn't pack a path probe packet if it doesn't fit into the size limit", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, err := packer.PackPathProbePacket(protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}, []ackhandler.Frame{challenge}, 20)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).To(BeNil())
+			})
+		})
+
+		Context("packing packets for additional paths", func() {
+			pathConnID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+
+			BeforeEach(func() {
+				packer = newPathPacketPacker(
+					pathConnID,
+					3,
+					func() uint64 { return 5 },
+					pnManager,
+					retransmissionQueue,
+					&net.TCPAddr{},
+					sealingManager,
+					framer,
+					ackFramer,
+					datagramQueue,
+					protocol.PerspectiveClient,
+					version,
+				)
+				packer.maxPacketSize = maxPacketSize
+			})
+
+			getPathSealer := func(pathID uint64) *mocks.MockShortHeaderSealer {
+				sealer := mocks.NewMockShortHeaderSealer(mockCtrl)
+				sealer.EXPECT().KeyPhase().Return(protocol.KeyPhaseOne).AnyTimes()
+				sealer.EXPECT().Overhead().Return(7).AnyTimes()
+				sealer.EXPECT().EncryptHeader(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+				sealer.EXPECT().SealPath(gomock.Any(), gomock.Any(), pathID, gomock.Any(), gomock.Any()).DoAndReturn(func(dst, src []byte, _ uint64, pn protocol.PacketNumber, associatedData []byte) []byte {
+					return append(src, bytes.Repeat([]byte{'s'}, sealer.Overhead())...)
+				})
+				return sealer
+			}
+
+			parseFrames := func(data []byte) []wire.Frame {
+				hdr, _, _, err := wire.ParsePacket(data, pathConnID.Len())
+				Expect(err).ToNot(HaveOccurred())
+				r := bytes.NewReader(data)
+				_, err = hdr.ParseExtended(r, version)
+				Expect(err).ToNot(HaveOccurred())
+				payload := data[len(data)-r.Len() : len(data)-7]
+				parser := wire.NewFrameParser(false, false, true, version)
+				var frames []wire.Frame
+				for len(payload) > 0 {
+					r := bytes.NewReader(payload)
+					f, err := parser.ParseNext(r, protocol.Encryption1RTT)
+					Expect(err).ToNot(HaveOccurred())
+					if f == nil {
+						break
+					}
+					frames = append(frames, f)
+					payload = payload[len(payload)-r.Len():]
+				}
+				return frames
+			}
+
+			It("sends ACK_MP frames", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getPathSealer(3), nil)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				p, err := packer.MaybePackAckPacket(true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.header.DestConnectionID).To(Equal(pathConnID))
+				Expect(p.ack).To(Equal(ack))
+				Expect(p.length).To(BeEquivalentTo(len(p.buffer.Data)))
+				frames := parseFrames(p.buffer.Data)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0]).To(BeAssignableToTypeOf(&wire.AckMPFrame{}))
+				ackMP := frames[0].(*wire.AckMPFrame)
+				Expect(ackMP.PathIdentifier).To(BeEquivalentTo(5))
+				Expect(ackMP.LargestAcked()).To(Equal(protocol.PacketNumber(10)))
+			})
+
+			It("packs normal packets, using the path's packet number space", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getPathSealer(3), nil)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				framer.EXPECT().HasData().Return(true)
+				expectAppendControlFrames()
+				f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				expectAppendStreamFrames(ackhandler.Frame{Frame: f})
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.header.DestConnectionID).To(Equal(pathConnID))
+				Expect(p.header.PacketNumber).To(Equal(protocol.PacketNumber(0x42)))
+				Expect(p.frames).To(HaveLen(1))
+				Expect(p.frames[0].Frame).To(Equal(f))
+			})
 		})
 	})
 })
@@ -1531,6 +1859,14 @@ var _ = Describe("Converting to AckHandler packets", func() {
 		Expect(packet.ToAckHandlerPacket(time.Now(), nil).IsPathMTUProbePacket).To(BeTrue())
 	})
 
+	It("marks path probe packets", func() {
+		packet := &packetContents{
+			header:            &wire.ExtendedHeader{Header: wire.Header{}},
+			isPathProbePacket: true,
+		}
+		Expect(packet.ToAckHandlerPacket(time.Now(), nil).IsPathProbePacket).To(BeTrue())
+	})
+
 	DescribeTable(
 		"doesn't overwrite the OnLost callback, if it is set",
 		func(hdr wire.Header) {
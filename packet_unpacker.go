@@ -97,7 +97,7 @@ func (u *packetUnpacker) Unpack(hdr *wire.Header, rcvTime time.Time, data []byte
 		if err != nil {
 			return nil, err
 		}
-		extHdr, decrypted, err = u.unpackShortHeaderPacket(opener, hdr, rcvTime, data)
+		extHdr, decrypted, err = u.unpackShortHeaderPacket(opener, 0, hdr, rcvTime, data)
 		if err != nil {
 			return nil, err
 		}
@@ -111,6 +111,29 @@ func (u *packetUnpacker) Unpack(hdr *wire.Header, rcvTime time.Time, data []byte
 	}, nil
 }
 
+// UnpackPath unpacks a 1-RTT packet received on an additional path of a multipath connection.
+// The pathID is the sequence number of the destination connection ID of the packet.
+// Errors are returned in the same way as for Unpack.
+func (u *packetUnpacker) UnpackPath(pathID uint64, hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error) {
+	if hdr.IsLongHeader {
+		return nil, fmt.Errorf("unexpected long header packet on path %d", pathID)
+	}
+	opener, err := u.cs.Get1RTTOpener()
+	if err != nil {
+		return nil, err
+	}
+	extHdr, decrypted, err := u.unpackShortHeaderPacket(opener, pathID, hdr, rcvTime, data)
+	if err != nil {
+		return nil, err
+	}
+	return &unpackedPacket{
+		hdr:             extHdr,
+		packetNumber:    extHdr.PacketNumber,
+		encryptionLevel: protocol.Encryption1RTT,
+		data:            decrypted,
+	}, nil
+}
+
 func (u *packetUnpacker) unpackLongHeaderPacket(opener handshake.LongHeaderOpener, hdr *wire.Header, data []byte) (*wire.ExtendedHeader, []byte, error) {
 	extHdr, parseErr := u.unpackHeader(opener, hdr, data)
 	// If the reserved bits are set incorrectly, we still need to continue unpacking.
@@ -133,6 +156,7 @@ func (u *packetUnpacker) unpackLongHeaderPacket(opener handshake.LongHeaderOpene
 
 func (u *packetUnpacker) unpackShortHeaderPacket(
 	opener handshake.ShortHeaderOpener,
+	pathID uint64,
 	hdr *wire.Header,
 	rcvTime time.Time,
 	data []byte,
@@ -144,9 +168,16 @@ func (u *packetUnpacker) unpackShortHeaderPacket(
 	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
 		return nil, nil, parseErr
 	}
-	extHdr.PacketNumber = opener.DecodePacketNumber(extHdr.PacketNumber, extHdr.PacketNumberLen)
 	extHdrLen := extHdr.ParsedLen()
-	decrypted, err := opener.Open(data[extHdrLen:extHdrLen], data[extHdrLen:], rcvTime, extHdr.PacketNumber, extHdr.KeyPhase, data[:extHdrLen])
+	var decrypted []byte
+	var err error
+	if pathID == 0 {
+		extHdr.PacketNumber = opener.DecodePacketNumber(extHdr.PacketNumber, extHdr.PacketNumberLen)
+		decrypted, err = opener.Open(data[extHdrLen:extHdrLen], data[extHdrLen:], rcvTime, extHdr.PacketNumber, extHdr.KeyPhase, data[:extHdrLen])
+	} else {
+		extHdr.PacketNumber = opener.DecodePathPacketNumber(pathID, extHdr.PacketNumber, extHdr.PacketNumberLen)
+		decrypted, err = opener.OpenPath(data[extHdrLen:extHdrLen], data[extHdrLen:], rcvTime, pathID, extHdr.PacketNumber, extHdr.KeyPhase, data[:extHdrLen])
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,188 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// A path is a new network path that the session might migrate to.
+// For a client, this is a new local address the client wants to use.
+// For a server, this is a new peer address that packets were received from,
+// e.g. because the client migrated or its NAT binding changed.
+// Before the session starts using it, the path is validated using PATH_CHALLENGE frames.
+type path struct {
+	ctx  context.Context
+	conn sendConn
+	// runner is the sessionRunner for the packet conn that this path receives packets on.
+	// It is only set for paths that a client migrates to.
+	runner sessionRunner
+	// addedRunner is true if the session registered its connection IDs with the runner when starting to probe this path.
+	addedRunner bool
+	// rcvConn is the packet conn that packets on this path are received on.
+	// It is only set for paths to a new peer address.
+	rcvConn connection
+
+	// connID is the connection ID used on this path
+	connID utils.NewConnectionID
+	// reuseConnID is set if the current connection ID is used on this path.
+	// This happens when answering PATH_CHALLENGEs received from a new peer address, and when the peer didn't provide an unused connection ID.
+	// RFC 9000 allows this if the peer's address changed due to NAT rebinding.
+	reuseConnID bool
+
+	// amplificationLimited is set for paths to peer addresses that haven't been validated yet.
+	// We're not allowed to send more than 3 times the number of bytes received on these paths.
+	amplificationLimited bool
+	bytesReceived        protocol.ByteCount
+	bytesSent            protocol.ByteCount
+
+	challenges [][8]byte
+	// responses are the data of PATH_CHALLENGE frames received on this path, that still need to be answered
+	responses [][8]byte
+	nextProbe time.Time
+	deadline  time.Time
+
+	result chan error // buffered, receives the result of path validation. nil for paths to a new peer address.
+}
+
+func newPath(ctx context.Context, conn sendConn, runner sessionRunner) *path {
+	return &path{
+		ctx:    ctx,
+		conn:   conn,
+		runner: runner,
+		result: make(chan error, 1),
+	}
+}
+
+func newPeerPath(conn sendConn, rcvConn connection) *path {
+	return &path{
+		ctx:                  context.Background(),
+		conn:                 conn,
+		rcvConn:              rcvConn,
+		reuseConnID:          true,
+		amplificationLimited: true,
+	}
+}
+
+// AddChallenge adds data sent in a PATH_CHALLENGE frame on this path.
+func (p *path) AddChallenge(data [8]byte) {
+	p.challenges = append(p.challenges, data)
+}
+
+// HasChallenge says if we sent a PATH_CHALLENGE with this data on this path.
+func (p *path) HasChallenge(data [8]byte) bool {
+	for _, c := range p.challenges {
+		if c == data {
+			return true
+		}
+	}
+	return false
+}
+
+// NumProbes returns the number of PATH_CHALLENGE frames sent on this path.
+func (p *path) NumProbes() int {
+	return len(p.challenges)
+}
+
+// AddResponse queues a PATH_RESPONSE for a PATH_CHALLENGE received on this path.
+func (p *path) AddResponse(data [8]byte) {
+	p.responses = append(p.responses, data)
+}
+
+// ProbeFrames returns the frames for a packet probing this path.
+// It contains PATH_RESPONSE frames for the PATH_CHALLENGEs received on this path,
+// and, if sendChallenge is set, a new PATH_CHALLENGE.
+// The caller needs to call AddChallenge once the packet is sent.
+func (p *path) ProbeFrames(sendChallenge bool) ([]ackhandler.Frame, [8]byte, error) {
+	frames := make([]ackhandler.Frame, 0, len(p.responses)+1)
+	for _, data := range p.responses {
+		frames = append(frames, ackhandler.Frame{
+			Frame: &wire.PathResponseFrame{Data: data},
+			// PATH_RESPONSE frames are never retransmitted, see RFC 9000, Section 13.3.
+			OnLost: func(wire.Frame) {},
+		})
+	}
+	var challenge [8]byte
+	if sendChallenge {
+		if _, err := rand.Read(challenge[:]); err != nil {
+			return nil, challenge, err
+		}
+		frames = append(frames, ackhandler.Frame{
+			Frame: &wire.PathChallengeFrame{Data: challenge},
+			// We send a new PATH_CHALLENGE when the probe timer fires.
+			OnLost: func(wire.Frame) {},
+		})
+	}
+	return frames, challenge, nil
+}
+
+// ReceivedBytes is called when a packet is received on this path.
+func (p *path) ReceivedBytes(n protocol.ByteCount) {
+	p.bytesReceived += n
+}
+
+// SentBytes is called when a packet is sent on this path.
+func (p *path) SentBytes(n protocol.ByteCount) {
+	p.bytesSent += n
+}
+
+// SendBudget returns the number of bytes that can be sent on this path before it is validated.
+func (p *path) SendBudget() protocol.ByteCount {
+	if !p.amplificationLimited {
+		return protocol.MaxByteCount
+	}
+	if p.bytesSent >= protocol.AmplificationFactor*p.bytesReceived {
+		return 0
+	}
+	return protocol.AmplificationFactor*p.bytesReceived - p.bytesSent
+}
+
+// Done reports the result of path validation.
+func (p *path) Done(err error) {
+	if p.result != nil {
+		p.result <- err
+	}
+}
+
+func (p *path) String() string {
+	return fmt.Sprintf("%s <-> %s", p.conn.LocalAddr(), p.conn.RemoteAddr())
+}
+
+// isProbingFrame says if a frame is a probing frame, as defined in RFC 9000, Section 9.1.
+// Receiving a packet containing only probing frames from a new peer address doesn't initiate a migration.
+func isProbingFrame(f wire.Frame) bool {
+	switch f.(type) {
+	case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
+}
+
+// addrsEqual says if two addresses are the same.
+func addrsEqual(a, b net.Addr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	udpA, okA := a.(*net.UDPAddr)
+	udpB, okB := b.(*net.UDPAddr)
+	if okA && okB {
+		return udpA.IP.Equal(udpB.IP) && udpA.Port == udpB.Port && udpA.Zone == udpB.Zone
+	}
+	return a.Network() == b.Network() && a.String() == b.String()
+}
+
+// isPortChange says if the peer's address only changed its port.
+// This is usually the result of NAT rebinding, and the peer is still using the same network path.
+func isPortChange(a, b net.Addr) bool {
+	udpA, okA := a.(*net.UDPAddr)
+	udpB, okB := b.(*net.UDPAddr)
+	return okA && okB && udpA.IP.Equal(udpB.IP) && udpA.Port != udpB.Port
+}
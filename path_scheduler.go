@@ -0,0 +1,40 @@
+package quic
+
+// The minRTTScheduler is the default PathScheduler.
+// It sends on the path with the lowest smoothed RTT that the congestion controller allows sending on.
+// Available paths are preferred over standby paths, and paths that haven't experienced a probe timeout
+// are preferred over paths that might have failed.
+// Standby and potentially failed paths are only used if no other path exists,
+// not if the other paths are just congestion limited.
+type minRTTScheduler struct{}
+
+var _ PathScheduler = &minRTTScheduler{}
+
+func (s *minRTTScheduler) SelectPath(paths []PathInfo) int {
+	// Try healthy available paths first, then healthy standby paths, then all paths.
+	tiers := []func(PathInfo) bool{
+		func(p PathInfo) bool { return p.Status == PathStatusAvailable && !p.PotentiallyFailed },
+		func(p PathInfo) bool { return !p.PotentiallyFailed },
+		func(PathInfo) bool { return true },
+	}
+	for _, inTier := range tiers {
+		var hasPath bool
+		selected := -1
+		for i, p := range paths {
+			if !inTier(p) {
+				continue
+			}
+			hasPath = true
+			if !p.CanSend {
+				continue
+			}
+			if selected == -1 || p.SmoothedRTT < paths[selected].SmoothedRTT {
+				selected = i
+			}
+		}
+		if hasPath {
+			return selected
+		}
+	}
+	return -1
+}
@@ -0,0 +1,66 @@
+package quic
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Min RTT Path Scheduler", func() {
+	var s *minRTTScheduler
+
+	BeforeEach(func() {
+		s = &minRTTScheduler{}
+	})
+
+	It("selects the path with the lowest RTT", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 50 * time.Millisecond, CanSend: true},
+			{SmoothedRTT: 10 * time.Millisecond, CanSend: true},
+			{SmoothedRTT: 30 * time.Millisecond, CanSend: true},
+		})).To(Equal(1))
+	})
+
+	It("skips paths that are congestion limited", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 50 * time.Millisecond, CanSend: true},
+			{SmoothedRTT: 10 * time.Millisecond},
+		})).To(BeZero())
+	})
+
+	It("doesn't send if all paths are congestion limited", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 50 * time.Millisecond},
+			{SmoothedRTT: 10 * time.Millisecond},
+		})).To(Equal(-1))
+	})
+
+	It("prefers available paths over standby paths", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 10 * time.Millisecond, CanSend: true, Status: PathStatusStandby},
+			{SmoothedRTT: 50 * time.Millisecond, CanSend: true},
+		})).To(Equal(1))
+	})
+
+	It("doesn't use standby paths if the available paths are congestion limited", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 10 * time.Millisecond, CanSend: true, Status: PathStatusStandby},
+			{SmoothedRTT: 50 * time.Millisecond},
+		})).To(Equal(-1))
+	})
+
+	It("uses standby paths if all available paths failed", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 10 * time.Millisecond, CanSend: true, PotentiallyFailed: true},
+			{SmoothedRTT: 50 * time.Millisecond, CanSend: true, Status: PathStatusStandby},
+		})).To(Equal(1))
+	})
+
+	It("uses potentially failed paths if there's no other path", func() {
+		Expect(s.SelectPath([]PathInfo{
+			{SmoothedRTT: 10 * time.Millisecond, CanSend: true, PotentiallyFailed: true},
+			{SmoothedRTT: 50 * time.Millisecond, CanSend: true, PotentiallyFailed: true, Status: PathStatusStandby},
+		})).To(BeZero())
+	})
+})
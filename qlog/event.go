@@ -395,6 +395,8 @@ type eventTransportParameters struct {
 	PreferredAddress *preferredAddress
 
 	MaxDatagramFrameSize protocol.ByteCount
+	MinAckDelay          *time.Duration
+	EnableMultipath      bool
 }
 
 func (e eventTransportParameters) Category() category { return categoryTransport }
@@ -440,6 +442,12 @@ func (e eventTransportParameters) MarshalJSONObject(enc *gojay.Encoder) {
 	if e.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		enc.Int64Key("max_datagram_frame_size", int64(e.MaxDatagramFrameSize))
 	}
+	if e.MinAckDelay != nil {
+		enc.FloatKey("min_ack_delay", milliseconds(*e.MinAckDelay))
+	}
+	if e.EnableMultipath {
+		enc.BoolKey("enable_multipath", true)
+	}
 }
 
 type preferredAddress struct {
@@ -503,6 +511,14 @@ func (e eventLossTimerCanceled) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("event_type", "cancelled")
 }
 
+type eventPersistentCongestion struct{}
+
+func (e eventPersistentCongestion) Category() category { return categoryRecovery }
+func (e eventPersistentCongestion) Name() string       { return "persistent_congestion" }
+func (e eventPersistentCongestion) IsNil() bool        { return false }
+
+func (e eventPersistentCongestion) MarshalJSONObject(enc *gojay.Encoder) {}
+
 type eventCongestionStateUpdated struct {
 	state congestionState
 }
@@ -515,6 +531,22 @@ func (e eventCongestionStateUpdated) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("new", e.state.String())
 }
 
+type eventECNStateUpdated struct {
+	state   ecnState
+	trigger ecnStateTrigger
+}
+
+func (e eventECNStateUpdated) Category() category { return categoryRecovery }
+func (e eventECNStateUpdated) Name() string       { return "ecn_state_updated" }
+func (e eventECNStateUpdated) IsNil() bool        { return false }
+
+func (e eventECNStateUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("new", e.state.String())
+	if e.trigger != ecnStateTrigger(logging.ECNTriggerNoTrigger) {
+		enc.StringKey("trigger", e.trigger.String())
+	}
+}
+
 type eventGeneric struct {
 	name string
 	msg  string
@@ -59,6 +59,16 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalHandshakeDoneFrame(enc, frame)
 	case *logging.DatagramFrame:
 		marshalDatagramFrame(enc, frame)
+	case *logging.AckFrequencyFrame:
+		marshalAckFrequencyFrame(enc, frame)
+	case *logging.ImmediateAckFrame:
+		marshalImmediateAckFrame(enc, frame)
+	case *logging.AckMPFrame:
+		marshalAckMPFrame(enc, frame)
+	case *logging.PathAbandonFrame:
+		marshalPathAbandonFrame(enc, frame)
+	case *logging.PathStatusFrame:
+		marshalPathStatusFrame(enc, frame)
 	default:
 		panic("unknown frame type")
 	}
@@ -102,6 +112,10 @@ func (ar ackRange) IsNil() bool { return false }
 
 func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.StringKey("frame_type", "ack")
+	marshalAckFrameFields(enc, f)
+}
+
+func marshalAckFrameFields(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.FloatKeyOmitEmpty("ack_delay", milliseconds(f.DelayTime))
 	enc.ArrayKey("acked_ranges", ackRanges(f.AckRanges))
 	if hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0; hasECN {
@@ -225,3 +239,42 @@ func marshalDatagramFrame(enc *gojay.Encoder, f *logging.DatagramFrame) {
 	enc.StringKey("frame_type", "datagram")
 	enc.Int64Key("length", int64(f.Length))
 }
+
+func marshalAckFrequencyFrame(enc *gojay.Encoder, f *logging.AckFrequencyFrame) {
+	enc.StringKey("frame_type", "ack_frequency")
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	enc.Uint64Key("ack_eliciting_threshold", f.AckElicitingThreshold)
+	enc.FloatKey("request_max_ack_delay", milliseconds(f.RequestMaxAckDelay))
+	enc.Uint64Key("reordering_threshold", f.ReorderingThreshold)
+}
+
+func marshalImmediateAckFrame(enc *gojay.Encoder, _ *logging.ImmediateAckFrame) {
+	enc.StringKey("frame_type", "immediate_ack")
+}
+
+func marshalAckMPFrame(enc *gojay.Encoder, f *logging.AckMPFrame) {
+	enc.StringKey("frame_type", "ack_mp")
+	enc.Uint64Key("packet_number_space", f.PathIdentifier)
+	marshalAckFrameFields(enc, &f.AckFrame)
+}
+
+func marshalPathAbandonFrame(enc *gojay.Encoder, f *logging.PathAbandonFrame) {
+	enc.StringKey("frame_type", "path_abandon")
+	enc.Uint64Key("path_id", f.PathIdentifier)
+	enc.Uint64Key("error_code", f.ErrorCode)
+	enc.StringKey("reason", f.ReasonPhrase)
+}
+
+func marshalPathStatusFrame(enc *gojay.Encoder, f *logging.PathStatusFrame) {
+	enc.StringKey("frame_type", "path_status")
+	enc.Uint64Key("path_id", f.PathIdentifier)
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	switch f.Status {
+	case wire.PathStatusStandby:
+		enc.StringKey("status", "standby")
+	case wire.PathStatusAvailable:
+		enc.StringKey("status", "available")
+	default:
+		enc.Uint64Key("status", f.Status)
+	}
+}
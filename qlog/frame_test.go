@@ -7,6 +7,7 @@ import (
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/internal/wire"
 	"github.com/lucas-clemente/quic-go/logging"
 
 	"github.com/francoispqt/gojay"
@@ -374,4 +375,81 @@ var _ = Describe("Frames", func() {
 			},
 		)
 	})
+
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        42,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    25 * time.Millisecond,
+				ReorderingThreshold:   1,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         42,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   25,
+				"reordering_threshold":    1,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
+
+	It("marshals ACK_MP frames", func() {
+		check(
+			&logging.AckMPFrame{
+				PathIdentifier: 3,
+				AckFrame: logging.AckFrame{
+					DelayTime: 86 * time.Millisecond,
+					AckRanges: []logging.AckRange{{Smallest: 100, Largest: 120}},
+				},
+			},
+			map[string]interface{}{
+				"frame_type":          "ack_mp",
+				"packet_number_space": 3,
+				"ack_delay":           86,
+				"acked_ranges":        [][]float64{{100, 120}},
+			},
+		)
+	})
+
+	It("marshals PATH_ABANDON frames", func() {
+		check(
+			&logging.PathAbandonFrame{
+				PathIdentifier: 2,
+				ErrorCode:      0x42,
+				ReasonPhrase:   "foobar",
+			},
+			map[string]interface{}{
+				"frame_type": "path_abandon",
+				"path_id":    2,
+				"error_code": 0x42,
+				"reason":     "foobar",
+			},
+		)
+	})
+
+	It("marshals PATH_STATUS frames", func() {
+		check(
+			&logging.PathStatusFrame{
+				PathIdentifier: 2,
+				SequenceNumber: 5,
+				Status:         wire.PathStatusStandby,
+			},
+			map[string]interface{}{
+				"frame_type":      "path_status",
+				"path_id":         2,
+				"sequence_number": 5,
+				"status":          "standby",
+			},
+		)
+	})
 })
@@ -86,7 +86,11 @@ type connectionTracer struct {
 	lastMetrics *metrics
 }
 
-var _ logging.ConnectionTracer = &connectionTracer{}
+var (
+	_ logging.ConnectionTracer           = &connectionTracer{}
+	_ logging.PersistentCongestionTracer = &connectionTracer{}
+	_ logging.ECNTracer                  = &connectionTracer{}
+)
 
 // NewConnectionTracer creates a new tracer to record a qlog for a connection.
 func NewConnectionTracer(w io.WriteCloser, p protocol.Perspective, odcid protocol.ConnectionID) logging.ConnectionTracer {
@@ -273,6 +277,8 @@ func (t *connectionTracer) toTransportParameters(tp *wire.TransportParameters) *
 		InitialMaxStreamsUni:            int64(tp.MaxUniStreamNum),
 		PreferredAddress:                pa,
 		MaxDatagramFrameSize:            tp.MaxDatagramFrameSize,
+		MinAckDelay:                     tp.MinAckDelay,
+		EnableMultipath:                 tp.EnableMultipath,
 	}
 }
 
@@ -383,12 +389,24 @@ func (t *connectionTracer) LostPacket(encLevel protocol.EncryptionLevel, pn prot
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) DetectedPersistentCongestion() {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventPersistentCongestion{})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) UpdatedCongestionState(state logging.CongestionState) {
 	t.mutex.Lock()
 	t.recordEvent(time.Now(), &eventCongestionStateUpdated{state: congestionState(state)})
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger) {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventECNStateUpdated{state: ecnState(state), trigger: ecnStateTrigger(trigger)})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) UpdatedPTOCount(value uint32) {
 	t.mutex.Lock()
 	t.recordEvent(time.Now(), &eventUpdatedPTO{Value: value})
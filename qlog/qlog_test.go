@@ -314,6 +314,8 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("initial_max_streams_uni", float64(20)))
 				Expect(ev).ToNot(HaveKey("preferred_address"))
 				Expect(ev).ToNot(HaveKey("max_datagram_frame_size"))
+				Expect(ev).ToNot(HaveKey("min_ack_delay"))
+				Expect(ev).ToNot(HaveKey("enable_multipath"))
 			})
 
 			It("records the server's transport parameters, without a stateless reset token", func() {
@@ -377,6 +379,24 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("max_datagram_frame_size", float64(1337)))
 			})
 
+			It("records transport parameters that enable the ACK Frequency extension", func() {
+				minAckDelay := 1500 * time.Microsecond
+				tracer.SentTransportParameters(&logging.TransportParameters{
+					MinAckDelay: &minAckDelay,
+				})
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:parameters_set"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("min_ack_delay", 1.5))
+			})
+
+			It("records transport parameters that enable the multipath extension", func() {
+				tracer.SentTransportParameters(&logging.TransportParameters{EnableMultipath: true})
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:parameters_set"))
+				Expect(entry.Event).To(HaveKeyWithValue("enable_multipath", true))
+			})
+
 			It("records received transport parameters", func() {
 				tracer.ReceivedTransportParameters(&logging.TransportParameters{})
 				entry := exportAndParseSingle()
@@ -689,6 +709,14 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("trigger", "reordering_threshold"))
 			})
 
+			It("records persistent congestion", func() {
+				tracer.(logging.PersistentCongestionTracer).DetectedPersistentCongestion()
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("recovery:persistent_congestion"))
+				Expect(entry.Event).To(BeEmpty())
+			})
+
 			It("records congestion state updates", func() {
 				tracer.UpdatedCongestionState(logging.CongestionStateCongestionAvoidance)
 				entry := exportAndParseSingle()
@@ -698,6 +726,23 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("new", "congestion_avoidance"))
 			})
 
+			It("records ECN state updates", func() {
+				tracer.(logging.ECNTracer).ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("recovery:ecn_state_updated"))
+				Expect(entry.Event).To(HaveKeyWithValue("new", "testing"))
+				Expect(entry.Event).ToNot(HaveKey("trigger"))
+			})
+
+			It("records ECN state updates with a trigger", func() {
+				tracer.(logging.ECNTracer).ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedManglingDetected)
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("recovery:ecn_state_updated"))
+				Expect(entry.Event).To(HaveKeyWithValue("new", "failed"))
+				Expect(entry.Event).To(HaveKeyWithValue("trigger", "ECN mangling detected"))
+			})
+
 			It("records PTO changes", func() {
 				tracer.UpdatedPTOCount(42)
 				entry := exportAndParseSingle()
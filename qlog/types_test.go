@@ -126,5 +126,6 @@ var _ = Describe("Types", func() {
 		Expect(congestionState(logging.CongestionStateCongestionAvoidance).String()).To(Equal("congestion_avoidance"))
 		Expect(congestionState(logging.CongestionStateApplicationLimited).String()).To(Equal("application_limited"))
 		Expect(congestionState(logging.CongestionStateRecovery).String()).To(Equal("recovery"))
+		Expect(congestionState(logging.CongestionStateConservativeSlowStart).String()).To(Equal("conservative_slow_start"))
 	})
 })
@@ -0,0 +1,289 @@
+// Package quiclb implements the connection ID encodings of QUIC-LB,
+// as specified in draft-ietf-quic-load-balancers-13 (https://datatracker.ietf.org/doc/html/draft-ietf-quic-load-balancers-13).
+//
+// QUIC-LB allows layer 4 load balancers to route packets to the right server,
+// even after the client migrated to a new address or switched to a new connection ID.
+// Servers encode their server ID into every connection ID they issue (using the Encoder),
+// and load balancers extract it from the Destination Connection ID of incoming packets (using the Decoder).
+//
+// The Encoder implements the quic.ConnectionIDGenerator interface.
+// Warning: This API is experimental and might change soon.
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrUnroutable is returned by the Decoder if a connection ID can't be routed.
+var ErrUnroutable = errors.New("quiclb: unroutable connection ID")
+
+// maxConnIDLen is the maximum length of a connection ID in QUIC version 1.
+const maxConnIDLen = 20
+
+// unroutableConfigID is the config rotation codepoint reserved for unroutable connection IDs.
+const unroutableConfigID = 3
+
+// A Mode is the algorithm used to encode the server ID into the connection ID.
+type Mode uint8
+
+const (
+	// Plaintext encodes the server ID in the clear.
+	// It is the cheapest mode, but it allows observers to link connection IDs of the same server.
+	Plaintext Mode = 1 + iota
+	// StreamCipher encrypts the server ID (and the nonce) using a three-pass AES-ECB based algorithm.
+	StreamCipher
+	// BlockCipher encrypts the server ID and the nonce as a single AES block.
+	BlockCipher
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Plaintext:
+		return "plaintext"
+	case StreamCipher:
+		return "stream cipher"
+	case BlockCipher:
+		return "block cipher"
+	default:
+		return fmt.Sprintf("unknown mode: %d", m)
+	}
+}
+
+// A Config is a QUIC-LB configuration.
+// The load balancer and all servers in the pool must use the same Config.
+type Config struct {
+	// ID is the config rotation codepoint. It is encoded in the first two bits of the connection ID,
+	// allowing the load balancer to use multiple configurations at the same time, e.g. during a key rotation.
+	// It must be 0, 1 or 2.
+	ID uint8
+	// Mode is the encoding algorithm.
+	Mode Mode
+	// ServerIDLen is the length of the server ID, in bytes.
+	ServerIDLen int
+	// NonceLen is the number of bytes following the server ID that are chosen randomly for every connection ID.
+	// For the Plaintext mode, it must be at least 4.
+	// For the StreamCipher mode, it must be between 8 and 16.
+	// For the BlockCipher mode, ServerIDLen and NonceLen must add up to 16, and NonceLen must be at least 4.
+	// The resulting connection ID is 1 + ServerIDLen + NonceLen bytes long, which must not exceed 20 bytes.
+	NonceLen int
+	// Key is the 16 byte AES-128 key used by the StreamCipher and the BlockCipher mode.
+	Key []byte
+	// LengthSelfEncoding encodes the length of the connection ID into the first byte,
+	// for load balancers that need to parse the connection ID without knowing the configuration.
+	// If not set, these bits are chosen randomly.
+	LengthSelfEncoding bool
+}
+
+func (c *Config) connIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) validate() error {
+	if c.ID >= unroutableConfigID {
+		return fmt.Errorf("quiclb: invalid config ID: %d", c.ID)
+	}
+	if c.ServerIDLen < 1 {
+		return fmt.Errorf("quiclb: invalid server ID length: %d", c.ServerIDLen)
+	}
+	if c.connIDLen() > maxConnIDLen {
+		return fmt.Errorf("quiclb: connection ID too long: %d bytes", c.connIDLen())
+	}
+	switch c.Mode {
+	case Plaintext:
+		if c.NonceLen < 4 {
+			return fmt.Errorf("quiclb: invalid nonce length for %s mode: %d", c.Mode, c.NonceLen)
+		}
+		return nil
+	case StreamCipher:
+		if c.NonceLen < 8 || c.NonceLen > 16 {
+			return fmt.Errorf("quiclb: invalid nonce length for %s mode: %d", c.Mode, c.NonceLen)
+		}
+	case BlockCipher:
+		if c.NonceLen < 4 || c.ServerIDLen+c.NonceLen != aes.BlockSize {
+			return fmt.Errorf("quiclb: invalid nonce length for %s mode: %d", c.Mode, c.NonceLen)
+		}
+	default:
+		return fmt.Errorf("quiclb: invalid mode: %d", c.Mode)
+	}
+	if len(c.Key) != 16 {
+		return fmt.Errorf("quiclb: invalid key length: %d", len(c.Key))
+	}
+	return nil
+}
+
+// codec implements the encoding and decoding for a single Config.
+type codec struct {
+	conf  Config
+	block cipher.Block // nil for the plaintext mode
+}
+
+func newCodec(conf *Config) (*codec, error) {
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+	c := &codec{conf: *conf}
+	if conf.Mode != Plaintext {
+		block, err := aes.NewCipher(conf.Key)
+		if err != nil {
+			return nil, err
+		}
+		c.block = block
+	}
+	return c, nil
+}
+
+// mask XORs dst with truncate(AES-ECB(key, pad(src)), len(dst)), as used by the stream cipher mode.
+// pad appends zeros to src up to the AES block size.
+func (c *codec) mask(dst, src []byte) {
+	var in, out [aes.BlockSize]byte
+	copy(in[:], src)
+	c.block.Encrypt(out[:], in[:])
+	for i := range dst {
+		dst[i] ^= out[i]
+	}
+}
+
+func (c *codec) firstByte(random byte) byte {
+	b := c.conf.ID << 6
+	if c.conf.LengthSelfEncoding {
+		return b | byte(c.conf.connIDLen()-1)&0x3f
+	}
+	return b | random&0x3f
+}
+
+// encode encodes the server ID into connID.
+// The caller fills connID with random bytes before.
+func (c *codec) encode(connID, serverID []byte) {
+	connID[0] = c.firstByte(connID[0])
+	switch c.conf.Mode {
+	case Plaintext:
+		copy(connID[1:], serverID)
+	case StreamCipher:
+		nonce := connID[1 : 1+c.conf.NonceLen]
+		sid := connID[1+c.conf.NonceLen:]
+		copy(sid, serverID)
+		c.mask(sid, nonce)
+		c.mask(nonce, sid)
+		c.mask(sid, nonce)
+	case BlockCipher:
+		copy(connID[1:], serverID)
+		c.block.Encrypt(connID[1:], connID[1:])
+	}
+}
+
+func (c *codec) decode(connID []byte) []byte {
+	switch c.conf.Mode {
+	case Plaintext:
+		serverID := make([]byte, c.conf.ServerIDLen)
+		copy(serverID, connID[1:])
+		return serverID
+	case StreamCipher:
+		nonce := make([]byte, c.conf.NonceLen)
+		copy(nonce, connID[1:])
+		serverID := make([]byte, c.conf.ServerIDLen)
+		copy(serverID, connID[1+c.conf.NonceLen:])
+		c.mask(serverID, nonce)
+		c.mask(nonce, serverID)
+		c.mask(serverID, nonce)
+		return serverID
+	case BlockCipher:
+		var b [aes.BlockSize]byte
+		c.block.Decrypt(b[:], connID[1:1+aes.BlockSize])
+		return b[:c.conf.ServerIDLen]
+	default:
+		panic("unknown mode")
+	}
+}
+
+// An Encoder generates connection IDs that encode a server ID.
+// It is safe for concurrent use.
+type Encoder struct {
+	codec    *codec
+	serverID []byte
+}
+
+// NewEncoder creates a new Encoder.
+// The length of the serverID must match the ServerIDLen of the Config.
+func NewEncoder(conf *Config, serverID []byte) (*Encoder, error) {
+	c, err := newCodec(conf)
+	if err != nil {
+		return nil, err
+	}
+	if len(serverID) != conf.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: invalid server ID length: %d (expected %d)", len(serverID), conf.ServerIDLen)
+	}
+	return &Encoder{
+		codec:    c,
+		serverID: append([]byte(nil), serverID...),
+	}, nil
+}
+
+// GenerateConnectionID generates a new connection ID.
+func (e *Encoder) GenerateConnectionID() ([]byte, error) {
+	connID := make([]byte, e.ConnectionIDLen())
+	if _, err := rand.Read(connID); err != nil {
+		return nil, err
+	}
+	e.codec.encode(connID, e.serverID)
+	return connID, nil
+}
+
+// ConnectionIDLen is the length of the connection IDs generated by the Encoder.
+func (e *Encoder) ConnectionIDLen() int {
+	return e.codec.conf.connIDLen()
+}
+
+// A Decoder extracts the server ID from connection IDs.
+// It is safe for concurrent use.
+type Decoder struct {
+	codecs [unroutableConfigID]*codec
+}
+
+// NewDecoder creates a new Decoder.
+// It accepts one Config for every config rotation codepoint in use.
+func NewDecoder(confs ...*Config) (*Decoder, error) {
+	d := &Decoder{}
+	for _, conf := range confs {
+		c, err := newCodec(conf)
+		if err != nil {
+			return nil, err
+		}
+		if d.codecs[conf.ID] != nil {
+			return nil, fmt.Errorf("quiclb: duplicate config ID: %d", conf.ID)
+		}
+		d.codecs[conf.ID] = c
+	}
+	return d, nil
+}
+
+// ConnectionIDLen returns the length of the connection ID, determined by its first byte.
+// This is useful for parsing short header packets, which don't contain the connection ID length.
+// It returns ErrUnroutable if the connection ID uses an unknown config rotation codepoint.
+func (d *Decoder) ConnectionIDLen(firstByte byte) (int, error) {
+	configID := firstByte >> 6
+	if configID >= unroutableConfigID || d.codecs[configID] == nil {
+		return 0, ErrUnroutable
+	}
+	return d.codecs[configID].conf.connIDLen(), nil
+}
+
+// ServerID returns the server ID encoded in a connection ID.
+// The connection ID may be followed by arbitrary data, e.g. the rest of a short header packet.
+// It returns ErrUnroutable if the connection ID uses an unknown config rotation codepoint, or if it is too short.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) == 0 {
+		return nil, ErrUnroutable
+	}
+	l, err := d.ConnectionIDLen(connID[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(connID) < l {
+		return nil, ErrUnroutable
+	}
+	return d.codecs[connID[0]>>6].decode(connID), nil
+}
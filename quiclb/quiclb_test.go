@@ -0,0 +1,231 @@
+package quiclb
+
+import (
+	"bytes"
+	"crypto/aes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QUIC-LB", func() {
+	key := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	It("has a string representation for the mode", func() {
+		Expect(Plaintext.String()).To(Equal("plaintext"))
+		Expect(StreamCipher.String()).To(Equal("stream cipher"))
+		Expect(BlockCipher.String()).To(Equal("block cipher"))
+		Expect(Mode(42).String()).To(Equal("unknown mode: 42"))
+	})
+
+	DescribeTable("validating the config",
+		func(conf Config, errMsg string) {
+			_, err := NewDecoder(&conf)
+			if errMsg == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(errMsg))
+			}
+		},
+		Entry("valid plaintext config", Config{Mode: Plaintext, ServerIDLen: 2, NonceLen: 4}, ""),
+		Entry("valid stream cipher config", Config{Mode: StreamCipher, ServerIDLen: 3, NonceLen: 8, Key: key}, ""),
+		Entry("valid block cipher config", Config{Mode: BlockCipher, ServerIDLen: 4, NonceLen: 12, Key: key}, ""),
+		Entry("invalid config ID", Config{ID: 3, Mode: Plaintext, ServerIDLen: 2, NonceLen: 4}, "quiclb: invalid config ID: 3"),
+		Entry("invalid mode", Config{Mode: 42, ServerIDLen: 2, NonceLen: 4}, "quiclb: invalid mode: 42"),
+		Entry("no server ID", Config{Mode: Plaintext, NonceLen: 4}, "quiclb: invalid server ID length: 0"),
+		Entry("too long", Config{Mode: Plaintext, ServerIDLen: 10, NonceLen: 10}, "quiclb: connection ID too long: 21 bytes"),
+		Entry("plaintext nonce too short", Config{Mode: Plaintext, ServerIDLen: 2, NonceLen: 3}, "quiclb: invalid nonce length for plaintext mode: 3"),
+		Entry("stream cipher nonce too short", Config{Mode: StreamCipher, ServerIDLen: 2, NonceLen: 7, Key: key}, "quiclb: invalid nonce length for stream cipher mode: 7"),
+		Entry("stream cipher nonce too long", Config{Mode: StreamCipher, ServerIDLen: 1, NonceLen: 17, Key: key}, "quiclb: invalid nonce length for stream cipher mode: 17"),
+		Entry("block cipher not filling a block", Config{Mode: BlockCipher, ServerIDLen: 2, NonceLen: 12, Key: key}, "quiclb: invalid nonce length for block cipher mode: 12"),
+		Entry("block cipher nonce too short", Config{Mode: BlockCipher, ServerIDLen: 13, NonceLen: 3, Key: key}, "quiclb: invalid nonce length for block cipher mode: 3"),
+		Entry("missing key", Config{Mode: StreamCipher, ServerIDLen: 3, NonceLen: 8}, "quiclb: invalid key length: 0"),
+	)
+
+	It("rejects server IDs of the wrong length", func() {
+		_, err := NewEncoder(&Config{Mode: Plaintext, ServerIDLen: 2, NonceLen: 4}, []byte{1, 2, 3})
+		Expect(err).To(MatchError("quiclb: invalid server ID length: 3 (expected 2)"))
+	})
+
+	It("rejects duplicate config IDs", func() {
+		_, err := NewDecoder(
+			&Config{ID: 1, Mode: Plaintext, ServerIDLen: 2, NonceLen: 4},
+			&Config{ID: 1, Mode: Plaintext, ServerIDLen: 3, NonceLen: 4},
+		)
+		Expect(err).To(MatchError("quiclb: duplicate config ID: 1"))
+	})
+
+	It("encodes the server ID in the clear in plaintext mode", func() {
+		conf := &Config{ID: 1, Mode: Plaintext, ServerIDLen: 3, NonceLen: 5}
+		e, err := NewEncoder(conf, []byte{0xde, 0xad, 0xbe})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.ConnectionIDLen()).To(Equal(9))
+		connID, err := e.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID).To(HaveLen(9))
+		Expect(connID[0] >> 6).To(BeEquivalentTo(1))
+		Expect(connID[1:4]).To(Equal([]byte{0xde, 0xad, 0xbe}))
+	})
+
+	It("hides the server ID in the cipher modes", func() {
+		serverID := []byte{0xde, 0xad, 0xbe, 0xef}
+		for _, conf := range []*Config{
+			{Mode: StreamCipher, ServerIDLen: 4, NonceLen: 8, Key: key},
+			{Mode: BlockCipher, ServerIDLen: 4, NonceLen: 12, Key: key},
+		} {
+			e, err := NewEncoder(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			connID, err := e.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bytes.Contains(connID, serverID)).To(BeFalse())
+		}
+	})
+
+	It("encodes the length", func() {
+		e, err := NewEncoder(&Config{ID: 2, Mode: StreamCipher, ServerIDLen: 3, NonceLen: 10, Key: key, LengthSelfEncoding: true}, []byte{1, 2, 3})
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			connID, err := e.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID[0]).To(Equal(byte(2<<6 | 13)))
+		}
+	})
+
+	It("randomizes the first byte if the length is not encoded", func() {
+		e, err := NewEncoder(&Config{ID: 2, Mode: Plaintext, ServerIDLen: 3, NonceLen: 10}, []byte{1, 2, 3})
+		Expect(err).ToNot(HaveOccurred())
+		firstBytes := make(map[byte]struct{})
+		for i := 0; i < 100; i++ {
+			connID, err := e.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID[0] >> 6).To(BeEquivalentTo(2))
+			firstBytes[connID[0]] = struct{}{}
+		}
+		Expect(len(firstBytes)).To(BeNumerically(">", 10))
+	})
+
+	DescribeTable("decoding connection IDs",
+		func(conf Config) {
+			serverID := make([]byte, conf.ServerIDLen)
+			for i := range serverID {
+				serverID[i] = byte(i + 1)
+			}
+			e, err := NewEncoder(&conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err := NewDecoder(&conf)
+			Expect(err).ToNot(HaveOccurred())
+			connIDs := make(map[string]struct{})
+			for i := 0; i < 100; i++ {
+				connID, err := e.GenerateConnectionID()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(connID).To(HaveLen(e.ConnectionIDLen()))
+				connIDs[string(connID)] = struct{}{}
+				l, err := d.ConnectionIDLen(connID[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(l).To(Equal(e.ConnectionIDLen()))
+				Expect(d.ServerID(connID)).To(Equal(serverID))
+				// trailing data is ignored
+				Expect(d.ServerID(append(connID, []byte("foobar")...))).To(Equal(serverID))
+			}
+			Expect(connIDs).To(HaveLen(100))
+		},
+		Entry("plaintext", Config{Mode: Plaintext, ServerIDLen: 2, NonceLen: 4}),
+		Entry("plaintext, long", Config{ID: 1, Mode: Plaintext, ServerIDLen: 15, NonceLen: 4}),
+		Entry("stream cipher", Config{Mode: StreamCipher, ServerIDLen: 3, NonceLen: 8, Key: key}),
+		Entry("stream cipher, long", Config{ID: 2, Mode: StreamCipher, ServerIDLen: 3, NonceLen: 16, Key: key, LengthSelfEncoding: true}),
+		Entry("block cipher", Config{Mode: BlockCipher, ServerIDLen: 4, NonceLen: 12, Key: key}),
+		Entry("block cipher, long", Config{ID: 1, Mode: BlockCipher, ServerIDLen: 12, NonceLen: 4, Key: key}),
+	)
+
+	Context("encoding, following the algorithms of the draft step by step", func() {
+		serverID := []byte{0xde, 0xad, 0xbe, 0xef}
+		nonce := []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, 0x9, 0xa, 0xb, 0xc}
+
+		// AES-ECB(key, pad(in)), with pad appending zeros up to the AES block size
+		aesECB := func(in []byte) []byte {
+			block, err := aes.NewCipher(key)
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, aes.BlockSize)
+			copy(b, in)
+			block.Encrypt(b, b)
+			return b
+		}
+		xorTruncated := func(a, b []byte) []byte {
+			out := make([]byte, len(a))
+			for i := range a {
+				out[i] = a[i] ^ b[i]
+			}
+			return out
+		}
+		encode := func(conf *Config, connID []byte) []byte {
+			c, err := newCodec(conf)
+			Expect(err).ToNot(HaveOccurred())
+			c.encode(connID, serverID)
+			d, err := NewDecoder(conf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.ServerID(connID)).To(Equal(serverID))
+			return connID
+		}
+
+		It("encodes in plaintext mode: first octet, server ID, nonce", func() {
+			conf := &Config{ID: 1, Mode: Plaintext, ServerIDLen: 4, NonceLen: 5, LengthSelfEncoding: true}
+			connID := encode(conf, append(make([]byte, 5), nonce[:5]...))
+			Expect(connID).To(Equal(append([]byte{1<<6 | 9, 0xde, 0xad, 0xbe, 0xef}, nonce[:5]...)))
+		})
+
+		It("encodes in stream cipher mode: first octet, encrypted nonce, encrypted server ID", func() {
+			conf := &Config{ID: 2, Mode: StreamCipher, ServerIDLen: 4, NonceLen: 8, Key: key, LengthSelfEncoding: true}
+			connID := encode(conf, append(append([]byte{0}, nonce[:8]...), 0, 0, 0, 0))
+			encryptedServerID := xorTruncated(serverID, aesECB(nonce[:8]))
+			encryptedNonce := xorTruncated(nonce[:8], aesECB(encryptedServerID))
+			encryptedServerID = xorTruncated(encryptedServerID, aesECB(encryptedNonce))
+			Expect(connID[0]).To(Equal(byte(2<<6 | 12)))
+			Expect(connID[1:9]).To(Equal(encryptedNonce))
+			Expect(connID[9:]).To(Equal(encryptedServerID))
+		})
+
+		It("encodes in block cipher mode: first octet, AES-ECB(server ID || nonce)", func() {
+			conf := &Config{Mode: BlockCipher, ServerIDLen: 4, NonceLen: 12, Key: key, LengthSelfEncoding: true}
+			connID := encode(conf, append(make([]byte, 5), nonce...))
+			Expect(connID[0]).To(Equal(byte(16)))
+			Expect(connID[1:]).To(Equal(aesECB(append(append([]byte{}, serverID...), nonce...))))
+		})
+	})
+
+	It("uses the config indicated by the connection ID", func() {
+		conf1 := &Config{ID: 0, Mode: Plaintext, ServerIDLen: 2, NonceLen: 4}
+		conf2 := &Config{ID: 1, Mode: StreamCipher, ServerIDLen: 3, NonceLen: 8, Key: key}
+		d, err := NewDecoder(conf1, conf2)
+		Expect(err).ToNot(HaveOccurred())
+		e1, err := NewEncoder(conf1, []byte{1, 2})
+		Expect(err).ToNot(HaveOccurred())
+		e2, err := NewEncoder(conf2, []byte{3, 4, 5})
+		Expect(err).ToNot(HaveOccurred())
+		connID1, err := e1.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		connID2, err := e2.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ServerID(connID1)).To(Equal([]byte{1, 2}))
+		Expect(d.ServerID(connID2)).To(Equal([]byte{3, 4, 5}))
+	})
+
+	It("rejects unroutable connection IDs", func() {
+		d, err := NewDecoder(&Config{ID: 1, Mode: Plaintext, ServerIDLen: 2, NonceLen: 4})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID(nil)
+		Expect(err).To(MatchError(ErrUnroutable))
+		// unknown config ID
+		_, err = d.ServerID([]byte{0, 1, 2, 3, 4, 5, 6})
+		Expect(err).To(MatchError(ErrUnroutable))
+		// reserved config ID
+		_, err = d.ServerID([]byte{0xc0, 1, 2, 3, 4, 5, 6})
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = d.ConnectionIDLen(0xc0)
+		Expect(err).To(MatchError(ErrUnroutable))
+		// too short
+		_, err = d.ServerID([]byte{0x40, 1, 2, 3, 4, 5})
+		Expect(err).To(MatchError(ErrUnroutable))
+		Expect(d.ServerID([]byte{0x40, 1, 2, 3, 4, 5, 6})).To(Equal([]byte{1, 2}))
+	})
+})
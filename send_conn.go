@@ -2,14 +2,24 @@ package quic
 
 import (
 	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
-	Write([]byte) error
+	// Write sends a packet. The ECN bits are only set if the connection supports it.
+	// If gsoSize is not 0, the buffer contains multiple packets, which are sent using GSO.
+	// This must only be used if the connection supports GSO.
+	Write(b []byte, gsoSize uint16, ecn protocol.ECN) error
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
+	// WithRemoteAddr returns a sendConn that sends on the same packet conn, but to a different remote address.
+	WithRemoteAddr(net.Addr, *packetInfo) sendConn
+
+	capabilities() connCapabilities
 }
 
 type sconn struct {
@@ -18,6 +28,9 @@ type sconn struct {
 	remoteAddr net.Addr
 	info       *packetInfo
 	oob        []byte
+
+	// set when the kernel supports GSO, but the network interface doesn't
+	gsoFailed utils.AtomicBool
 }
 
 var _ sendConn = &sconn{}
@@ -31,15 +44,38 @@ func newSendConn(c connection, remote net.Addr, info *packetInfo) sendConn {
 	}
 }
 
-func (c *sconn) Write(p []byte) error {
-	_, err := c.WritePacket(p, c.remoteAddr, c.oob)
-	return err
+func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
+	_, err := c.WritePacket(p, c.remoteAddr, c.oob, gsoSize, ecn)
+	if err == nil || gsoSize == 0 || !isGSOError(err) {
+		return err
+	}
+	// Disable GSO for future writes, and send the packets one by one.
+	utils.DefaultLogger.Debugf("Sending with GSO failed (%s). Disabling GSO.", err)
+	c.gsoFailed.Set(true)
+	for len(p) > 0 {
+		l := utils.Min(len(p), int(gsoSize))
+		if _, err := c.WritePacket(p[:l], c.remoteAddr, c.oob, 0, ecn); err != nil {
+			return err
+		}
+		p = p[l:]
+	}
+	return nil
+}
+
+func (c *sconn) capabilities() connCapabilities {
+	capabilities := c.connection.capabilities()
+	capabilities.GSO = capabilities.GSO && !c.gsoFailed.Get()
+	return capabilities
 }
 
 func (c *sconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
 
+func (c *sconn) WithRemoteAddr(remote net.Addr, info *packetInfo) sendConn {
+	return newSendConn(c.connection, remote, info)
+}
+
 func (c *sconn) LocalAddr() net.Addr {
 	addr := c.connection.LocalAddr()
 	if c.info != nil {
@@ -64,11 +100,17 @@ func newSendPconn(c net.PacketConn, remote net.Addr) sendConn {
 	return &spconn{PacketConn: c, remoteAddr: remote}
 }
 
-func (c *spconn) Write(p []byte) error {
+func (c *spconn) Write(p []byte, _ uint16, _ protocol.ECN) error {
 	_, err := c.WriteTo(p, c.remoteAddr)
 	return err
 }
 
+func (c *spconn) capabilities() connCapabilities { return connCapabilities{} }
+
 func (c *spconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
+
+func (c *spconn) WithRemoteAddr(remote net.Addr, _ *packetInfo) sendConn {
+	return newSendPconn(c.PacketConn, remote)
+}
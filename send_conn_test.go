@@ -1,8 +1,12 @@
 package quic
 
 import (
+	"errors"
 	"net"
 
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -22,13 +26,26 @@ var _ = Describe("Connection (for sending packets)", func() {
 
 	It("writes", func() {
 		packetConn.EXPECT().WriteTo([]byte("foobar"), addr)
-		Expect(c.Write([]byte("foobar"))).To(Succeed())
+		Expect(c.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
+	})
+
+	It("doesn't support ECN", func() {
+		Expect(c.capabilities().ECN).To(BeFalse())
 	})
 
 	It("gets the remote address", func() {
 		Expect(c.RemoteAddr().String()).To(Equal("192.168.100.200:1337"))
 	})
 
+	It("sends to a different remote address", func() {
+		newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1338}
+		newConn := c.WithRemoteAddr(newAddr, nil)
+		Expect(newConn.RemoteAddr()).To(Equal(newAddr))
+		Expect(c.RemoteAddr()).To(Equal(addr))
+		packetConn.EXPECT().WriteTo([]byte("foobar"), newAddr)
+		Expect(newConn.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
+	})
+
 	It("gets the local address", func() {
 		addr := &net.UDPAddr{
 			IP:   net.IPv4(192, 168, 0, 1),
@@ -43,3 +60,38 @@ var _ = Describe("Connection (for sending packets)", func() {
 		Expect(c.Close()).To(Succeed())
 	})
 })
+
+var _ = Describe("Connection (for sending packets, using a connection)", func() {
+	var (
+		c          sendConn
+		rawConn    *MockConnection
+		remoteAddr net.Addr
+	)
+
+	BeforeEach(func() {
+		remoteAddr = &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+		rawConn = NewMockConnection(mockCtrl)
+		c = newSendConn(rawConn, remoteAddr, nil)
+	})
+
+	It("writes", func() {
+		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECT0)
+		Expect(c.Write([]byte("foobar"), 0, protocol.ECT0)).To(Succeed())
+	})
+
+	It("writes using GSO", func() {
+		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(3), protocol.ECNNon)
+		Expect(c.Write([]byte("foobar"), 3, protocol.ECNNon)).To(Succeed())
+	})
+
+	It("returns errors that are not related to GSO", func() {
+		testErr := errors.New("test error")
+		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(3), protocol.ECNNon).Return(0, testErr)
+		Expect(c.Write([]byte("foobar"), 3, protocol.ECNNon)).To(MatchError(testErr))
+	})
+
+	It("reports the capabilities of the connection", func() {
+		rawConn.EXPECT().capabilities().Return(connCapabilities{ECN: true, GSO: true})
+		Expect(c.capabilities()).To(Equal(connCapabilities{ECN: true, GSO: true}))
+	})
+})
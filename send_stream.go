@@ -52,6 +52,12 @@ type sendStream struct {
 	writeChan chan struct{}
 	deadline  time.Time
 
+	// The priorityMutex is held while reading the priority and notifying the streamSender.
+	// This makes sure that a notification carrying an old priority can't overtake the one sent by SetPriority.
+	// It must be acquired before the mutex.
+	priorityMutex sync.Mutex
+	priority      streamPriority
+
 	flowController flowcontrol.StreamFlowController
 
 	version protocol.VersionNumber
@@ -73,6 +79,7 @@ func newSendStream(
 		sender:         sender,
 		flowController: flowController,
 		writeChan:      make(chan struct{}, 1),
+		priority:       defaultStreamPriority,
 		version:        version,
 	}
 	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
@@ -156,7 +163,7 @@ func (s *sendStream) Write(p []byte) (int, error) {
 
 		s.mutex.Unlock()
 		if !notifiedSender {
-			s.sender.onHasStreamData(s.streamID) // must be called without holding the mutex
+			s.notifyHasStreamData() // must be called without holding the mutex
 			notifiedSender = true
 		}
 		if copied {
@@ -386,7 +393,7 @@ func (s *sendStream) queueRetransmission(f wire.Frame) {
 	}
 	s.mutex.Unlock()
 
-	s.sender.onHasStreamData(s.streamID)
+	s.notifyHasStreamData()
 }
 
 func (s *sendStream) Close() error {
@@ -403,7 +410,7 @@ func (s *sendStream) Close() error {
 	s.finishedWriting = true
 	s.mutex.Unlock()
 
-	s.sender.onHasStreamData(s.streamID) // need to send the FIN, must be called without holding the mutex
+	s.notifyHasStreamData() // need to send the FIN, must be called without holding the mutex
 	return nil
 }
 
@@ -444,7 +451,7 @@ func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 
 	s.flowController.UpdateSendWindow(limit)
 	if hasStreamData {
-		s.sender.onHasStreamData(s.streamID)
+		s.notifyHasStreamData()
 	}
 }
 
@@ -467,6 +474,32 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetPriority(urgency uint8, incremental bool) {
+	if urgency > maxStreamUrgency {
+		urgency = maxStreamUrgency
+	}
+	s.priorityMutex.Lock()
+	defer s.priorityMutex.Unlock()
+
+	s.priority = streamPriority{urgency: urgency, incremental: incremental}
+	s.mutex.Lock()
+	// If the stream is already queued for sending, it needs to be rescheduled.
+	hasStreamData := !s.canceledWrite && (s.dataForWriting != nil || s.nextFrame != nil || len(s.retransmissionQueue) > 0 || (s.finishedWriting && !s.finSent))
+	s.mutex.Unlock()
+
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID, s.priority)
+	}
+}
+
+// notifyHasStreamData tells the streamSender that there's data to send on this stream.
+// It must be called without holding the mutex.
+func (s *sendStream) notifyHasStreamData() {
+	s.priorityMutex.Lock()
+	s.sender.onHasStreamData(s.streamID, s.priority)
+	s.priorityMutex.Unlock()
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -77,7 +77,7 @@ var _ = Describe("Send Stream", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				n, err := strWithTimeout.Write([]byte("foobar"))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(6))
@@ -100,7 +100,7 @@ var _ = Describe("Send Stream", func() {
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				n, err := strWithTimeout.Write([]byte("foobar"))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(6))
@@ -129,7 +129,7 @@ var _ = Describe("Send Stream", func() {
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
-				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority).Times(2)
 				n, err := strWithTimeout.Write([]byte("foo"))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(3))
@@ -155,7 +155,7 @@ var _ = Describe("Send Stream", func() {
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				n, err := strWithTimeout.Write(getData(5000))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(5000))
@@ -179,7 +179,7 @@ var _ = Describe("Send Stream", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				_, err := strWithTimeout.Write(getData(protocol.MaxPacketBufferSize + 3))
 				Expect(err).ToNot(HaveOccurred())
 			}()
@@ -200,14 +200,14 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("only unblocks Write once a previously buffered STREAM frame has been fully dequeued", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			_, err := strWithTimeout.Write([]byte("foobar"))
 			Expect(err).ToNot(HaveOccurred())
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				_, err := str.Write(getData(protocol.MaxPacketBufferSize))
 				Expect(err).ToNot(HaveOccurred())
 			}()
@@ -238,7 +238,7 @@ var _ = Describe("Send Stream", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				n, err := strWithTimeout.Write(bytes.Repeat([]byte{0}, 100))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(100))
@@ -269,7 +269,7 @@ var _ = Describe("Send Stream", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				n, err := strWithTimeout.Write(s)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(n).To(Equal(3))
@@ -300,7 +300,7 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("cancels the context when Close is called", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			Expect(str.Context().Done()).ToNot(BeClosed())
 			Expect(str.Close()).To(Succeed())
 			Expect(str.Context().Done()).To(BeClosed())
@@ -318,7 +318,7 @@ var _ = Describe("Send Stream", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					_, err := str.Write([]byte("foobar"))
 					Expect(err).ToNot(HaveOccurred())
 				}()
@@ -336,7 +336,7 @@ var _ = Describe("Send Stream", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					_, err := str.Write([]byte("foobar"))
 					Expect(err).ToNot(HaveOccurred())
 				}()
@@ -375,7 +375,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("unblocks after the deadline", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				deadline := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				str.SetWriteDeadline(deadline)
 				n, err := strWithTimeout.Write(getData(5000))
@@ -385,7 +385,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("unblocks when the deadline is changed to the past", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				str.SetWriteDeadline(time.Now().Add(time.Hour))
 				done := make(chan struct{})
 				go func() {
@@ -409,7 +409,7 @@ var _ = Describe("Send Stream", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(writeReturned)
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					var err error
 					n, err = strWithTimeout.Write(getData(5000))
 					Expect(err).To(MatchError(errDeadline))
@@ -432,7 +432,7 @@ var _ = Describe("Send Stream", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(writeReturned)
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					_, err := strWithTimeout.Write(getData(5000))
 					Expect(err).To(MatchError(errDeadline))
 				}()
@@ -447,7 +447,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("doesn't unblock if the deadline is changed before the first one expires", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				deadline1 := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				deadline2 := time.Now().Add(scaleDuration(100 * time.Millisecond))
 				str.SetWriteDeadline(deadline1)
@@ -469,7 +469,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("unblocks earlier, when a new deadline is set", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				deadline1 := time.Now().Add(scaleDuration(200 * time.Millisecond))
 				deadline2 := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				done := make(chan struct{})
@@ -490,7 +490,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("doesn't unblock if the deadline is removed", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				deadline := time.Now().Add(scaleDuration(50 * time.Millisecond))
 				str.SetWriteDeadline(deadline)
 				deadlineUnset := make(chan struct{})
@@ -520,14 +520,14 @@ var _ = Describe("Send Stream", func() {
 
 		Context("closing", func() {
 			It("doesn't allow writes after it has been closed", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				str.Close()
 				_, err := strWithTimeout.Write([]byte("foobar"))
 				Expect(err).To(MatchError("write on closed stream 1337"))
 			})
 
 			It("allows FIN", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				str.Close()
 				frame, hasMoreData := str.popStreamFrame(1000)
 				Expect(frame).ToNot(BeNil())
@@ -540,7 +540,7 @@ var _ = Describe("Send Stream", func() {
 
 			It("doesn't send a FIN when there's still data", func() {
 				const frameHeaderLen protocol.ByteCount = 4
-				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority).Times(2)
 				_, err := strWithTimeout.Write([]byte("foobar"))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(str.Close()).To(Succeed())
@@ -562,10 +562,10 @@ var _ = Describe("Send Stream", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					_, err := strWithTimeout.Write(getData(5000))
 					Expect(err).ToNot(HaveOccurred())
-					mockSender.EXPECT().onHasStreamData(streamID)
+					mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 					Expect(str.Close()).To(Succeed())
 				}()
 				waitForWrite()
@@ -596,7 +596,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("doesn't allow FIN twice", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				str.Close()
 				frame, _ := str.popStreamFrame(1000)
 				Expect(frame).ToNot(BeNil())
@@ -622,7 +622,7 @@ var _ = Describe("Send Stream", func() {
 			It("doesn't get data for writing if an error occurred", func() {
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 				mockFC.EXPECT().AddBytesSent(gomock.Any())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				done := make(chan struct{})
 				go func() {
 					defer GinkgoRecover()
@@ -657,7 +657,7 @@ var _ = Describe("Send Stream", func() {
 
 		It("says when it has data for sending", func() {
 			mockFC.EXPECT().UpdateSendWindow(gomock.Any())
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
@@ -666,7 +666,7 @@ var _ = Describe("Send Stream", func() {
 				close(done)
 			}()
 			waitForWrite()
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			str.updateSendWindow(42)
 			// make sure the Write go routine returns
 			str.closeForShutdown(nil)
@@ -694,7 +694,7 @@ var _ = Describe("Send Stream", func() {
 			// for reliable results it has to be run many times.
 			It("returns a nil error when the whole slice has been sent out", func() {
 				mockSender.EXPECT().queueControlFrame(gomock.Any()).MaxTimes(1)
-				mockSender.EXPECT().onHasStreamData(streamID).MaxTimes(1)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority).MaxTimes(1)
 				mockSender.EXPECT().onStreamCompleted(streamID).MaxTimes(1)
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).MaxTimes(1)
 				mockFC.EXPECT().AddBytesSent(gomock.Any()).MaxTimes(1)
@@ -717,7 +717,7 @@ var _ = Describe("Send Stream", func() {
 
 			It("unblocks Write", func() {
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 				mockFC.EXPECT().AddBytesSent(gomock.Any())
 				writeReturned := make(chan struct{})
@@ -740,7 +740,7 @@ var _ = Describe("Send Stream", func() {
 
 			It("doesn't pop STREAM frames after being canceled", func() {
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 				mockFC.EXPECT().AddBytesSent(gomock.Any())
 				writeReturned := make(chan struct{})
@@ -763,7 +763,7 @@ var _ = Describe("Send Stream", func() {
 
 			It("doesn't pop STREAM frames after being canceled, for large writes", func() {
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 				mockFC.EXPECT().AddBytesSent(gomock.Any())
 				writeReturned := make(chan struct{})
@@ -787,7 +787,7 @@ var _ = Describe("Send Stream", func() {
 
 			It("ignores acknowledgements for STREAM frames after it was cancelled", func() {
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 				mockFC.EXPECT().AddBytesSent(gomock.Any())
 				writeReturned := make(chan struct{})
@@ -829,7 +829,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("queues a RESET_STREAM frame, even if the stream was already closed", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockSender.EXPECT().queueControlFrame(gomock.Any()).Do(func(f wire.Frame) {
 					Expect(f).To(BeAssignableToTypeOf(&wire.ResetStreamFrame{}))
 				})
@@ -855,7 +855,7 @@ var _ = Describe("Send Stream", func() {
 			})
 
 			It("unblocks Write", func() {
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				mockSender.EXPECT().queueControlFrame(gomock.Any())
 				mockSender.EXPECT().onStreamCompleted(gomock.Any())
 				done := make(chan struct{})
@@ -900,7 +900,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			str.queueRetransmission(f)
 			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
 			Expect(frame).ToNot(BeNil())
@@ -917,7 +917,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			str.queueRetransmission(sf)
 			frame, hasMoreData := str.popStreamFrame(sf.Length(str.version) - 3)
 			Expect(frame).ToNot(BeNil())
@@ -941,7 +941,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			str.queueRetransmission(f)
 			frame, hasMoreData := str.popStreamFrame(2)
 			Expect(hasMoreData).To(BeTrue())
@@ -949,7 +949,7 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("queues lost STREAM frames", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(9999))
 			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
 			done := make(chan struct{})
@@ -966,7 +966,7 @@ var _ = Describe("Send Stream", func() {
 			Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal([]byte("foobar")))
 
 			// now lose the frame
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			frame.OnLost(frame.Frame)
 			newFrame, _ := str.popStreamFrame(protocol.MaxByteCount)
 			Expect(newFrame).ToNot(BeNil())
@@ -974,7 +974,7 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("doesn't queue retransmissions for a stream that was canceled", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
 			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
 			done := make(chan struct{})
@@ -998,6 +998,80 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("priorities", func() {
+		It("uses the default priority of RFC 9218", func() {
+			Expect(str.priority).To(Equal(streamPriority{urgency: 3, incremental: false}))
+		})
+
+		It("doesn't notify the sender when the priority is set on a stream without data", func() {
+			str.SetPriority(1, false)
+			Expect(str.priority).To(Equal(streamPriority{urgency: 1}))
+		})
+
+		It("limits the urgency", func() {
+			str.SetPriority(100, true)
+			Expect(str.priority).To(Equal(streamPriority{urgency: maxStreamUrgency, incremental: true}))
+		})
+
+		It("uses the priority when notifying the sender", func() {
+			prio := streamPriority{urgency: 6, incremental: false}
+			str.SetPriority(6, false)
+			mockSender.EXPECT().onHasStreamData(streamID, prio)
+			Expect(str.Close()).To(Succeed())
+		})
+
+		It("reschedules the stream when the priority changes while it has data", func() {
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := str.Write(getData(protocol.MaxPacketBufferSize))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			waitForWrite()
+			mockSender.EXPECT().onHasStreamData(streamID, streamPriority{urgency: 0, incremental: true})
+			str.SetPriority(0, true)
+			// unblock the Write call
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+			for {
+				_, hasMoreData := str.popStreamFrame(protocol.MaxPacketBufferSize)
+				if !hasMoreData {
+					break
+				}
+			}
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("doesn't let a notification with the old priority overtake a priority change", func() {
+			prios := make(chan streamPriority, 2)
+			unblock := make(chan struct{})
+			mockSender.EXPECT().onHasStreamData(streamID, gomock.Any()).Do(func(_ protocol.StreamID, prio streamPriority) {
+				prios <- prio
+				<-unblock
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(str.Close()).To(Succeed())
+			}()
+			Eventually(prios).Should(Receive(Equal(defaultStreamPriority)))
+			mockSender.EXPECT().onHasStreamData(streamID, gomock.Any()).Do(func(_ protocol.StreamID, prio streamPriority) {
+				prios <- prio
+			})
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				str.SetPriority(0, false)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			close(unblock)
+			Eventually(done).Should(BeClosed())
+			Expect(prios).To(Receive(Equal(streamPriority{urgency: 0})))
+		})
+	})
+
 	Context("determining when a stream is completed", func() {
 		BeforeEach(func() {
 			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
@@ -1005,7 +1079,7 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("says when a stream is completed", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
@@ -1036,7 +1110,7 @@ var _ = Describe("Send Stream", func() {
 			}
 
 			// Now close the stream and acknowledge the FIN.
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			Expect(str.Close()).To(Succeed())
 			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
 			Expect(frame).ToNot(BeNil())
@@ -1045,7 +1119,7 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("says when a stream is completed, if Close() is called before popping the frame", func() {
-			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority).Times(2)
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
@@ -1067,13 +1141,13 @@ var _ = Describe("Send Stream", func() {
 		})
 
 		It("doesn't say it's completed when there are frames waiting to be retransmitted", func() {
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			done := make(chan struct{})
 			go func() {
 				defer GinkgoRecover()
 				_, err := strWithTimeout.Write(getData(100))
 				Expect(err).ToNot(HaveOccurred())
-				mockSender.EXPECT().onHasStreamData(streamID)
+				mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 				Expect(str.Close()).To(Succeed())
 				close(done)
 			}()
@@ -1097,7 +1171,7 @@ var _ = Describe("Send Stream", func() {
 			for _, f := range frames[1:] {
 				f.OnAcked(f.Frame)
 			}
-			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority)
 			frames[0].OnLost(frames[0].Frame)
 
 			// get the retransmission and acknowledge it
@@ -1113,7 +1187,7 @@ var _ = Describe("Send Stream", func() {
 		// and has to be retransmitted.
 		It("retransmits data until everything has been acknowledged", func() {
 			const dataLen = 1 << 22 // 4 MB
-			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			mockSender.EXPECT().onHasStreamData(streamID, defaultStreamPriority).AnyTimes()
 			mockFC.EXPECT().SendWindowSize().DoAndReturn(func() protocol.ByteCount {
 				return protocol.ByteCount(mrand.Intn(500)) + 50
 			}).AnyTimes()
@@ -36,9 +36,13 @@ type unknownPacketHandler interface {
 type packetHandlerManager interface {
 	AddWithConnID(protocol.ConnectionID, protocol.ConnectionID, func() packetHandler) bool
 	Destroy() error
+	// CloseIfUnused stops using the packet conn if no session and no server use it, without closing the packet conn.
+	CloseIfUnused()
 	sessionRunner
 	SetServer(unknownPacketHandler)
 	CloseServer()
+	RotateStatelessResetKey([]byte)
+	StatelessResetStats() StatelessResetStats
 }
 
 type quicSession interface {
@@ -50,6 +54,7 @@ type quicSession interface {
 	run() error
 	destroy(error)
 	shutdown()
+	closeWithTransportError(TransportErrorCode)
 }
 
 // A Listener of QUIC
@@ -69,6 +74,9 @@ type baseServer struct {
 	tokenGenerator *handshake.TokenGenerator
 
 	sessionHandler packetHandlerManager
+	// preferredAddrHandlers are the packetHandlerManagers for the packet conns
+	// that the server listens on for its preferred addresses.
+	preferredAddrHandlers []packetHandlerManager
 
 	receivedPackets chan *receivedPacket
 
@@ -97,6 +105,12 @@ type baseServer struct {
 	closed      bool
 	running     chan struct{} // closed as soon as run() returns
 
+	// shuttingDown is set when Shutdown is called. New connections are refused from then on.
+	shuttingDown bool
+	shutdownChan chan struct{} // closed when Shutdown is called
+	// activeSessions counts the sessions that haven't been closed yet. It is used by Shutdown.
+	activeSessions sync.WaitGroup
+
 	sessionQueue    chan quicSession
 	sessionQueueLen int32 // to be used as an atomic
 
@@ -143,6 +157,7 @@ func listenAddr(addr string, tlsConf *tls.Config, config *Config, acceptEarly bo
 	}
 	serv, err := listen(conn, tlsConf, config, acceptEarly)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 	serv.createdPacketConn = true
@@ -187,31 +202,47 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 		}
 	}
 
-	sessionHandler, err := getMultiplexer().AddConn(conn, config.ConnectionIDLength, config.StatelessResetKey, config.Tracer)
+	var tokenProtector handshake.TokenProtector = config.TokenProtector
+	if tokenProtector == nil {
+		var err error
+		tokenProtector, err = handshake.NewTokenProtector(rand.Reader, config.TokenKeys...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tokenGenerator := handshake.NewTokenGeneratorWithProtector(tokenProtector)
+	c, err := wrapConn(conn)
 	if err != nil {
 		return nil, err
 	}
-	tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+	// Register the packet conn with the multiplexer last,
+	// so that it doesn't need to be removed if any of the previous steps fail.
+	preferredAddrHandlers, err := listenPreferredAddresses(config)
 	if err != nil {
 		return nil, err
 	}
-	c, err := wrapConn(conn)
+	sessionHandler, err := getMultiplexer().AddConn(conn, config.ConnectionIDLength, config.StatelessResetKey, config.Tracer)
 	if err != nil {
+		for _, h := range preferredAddrHandlers {
+			h.Destroy()
+		}
 		return nil, err
 	}
 	s := &baseServer{
-		conn:                c,
-		tlsConf:             tlsConf,
-		config:              config,
-		tokenGenerator:      tokenGenerator,
-		sessionHandler:      sessionHandler,
-		sessionQueue:        make(chan quicSession),
-		errorChan:           make(chan struct{}),
-		running:             make(chan struct{}),
-		receivedPackets:     make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
-		newSession:          newSession,
-		logger:              utils.DefaultLogger.WithPrefix("server"),
-		acceptEarlySessions: acceptEarly,
+		conn:                  c,
+		tlsConf:               tlsConf,
+		config:                config,
+		tokenGenerator:        tokenGenerator,
+		sessionHandler:        sessionHandler,
+		preferredAddrHandlers: preferredAddrHandlers,
+		sessionQueue:          make(chan quicSession),
+		errorChan:             make(chan struct{}),
+		running:               make(chan struct{}),
+		shutdownChan:          make(chan struct{}),
+		receivedPackets:       make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
+		newSession:            newSession,
+		logger:                utils.DefaultLogger.WithPrefix("server"),
+		acceptEarlySessions:   acceptEarly,
 	}
 	go s.run()
 	sessionHandler.SetServer(s)
@@ -219,6 +250,47 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 	return s, nil
 }
 
+// listenPreferredAddresses creates the packet conns for the preferred addresses configured in the config.
+// The config is updated with the addresses that the packet conns are actually bound to.
+func listenPreferredAddresses(config *Config) ([]packetHandlerManager, error) {
+	var handlers []packetHandlerManager
+	if config.PreferredAddressIPv4 != nil {
+		handler, addr, err := listenPreferredAddress("udp4", config.PreferredAddressIPv4, config)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+		config.PreferredAddressIPv4 = addr
+	}
+	if config.PreferredAddressIPv6 != nil {
+		handler, addr, err := listenPreferredAddress("udp6", config.PreferredAddressIPv6, config)
+		if err != nil {
+			for _, h := range handlers {
+				h.Destroy()
+			}
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+		config.PreferredAddressIPv6 = addr
+	}
+	return handlers, nil
+}
+
+func listenPreferredAddress(network string, addr *net.UDPAddr, config *Config) (packetHandlerManager, *net.UDPAddr, error) {
+	conn, err := net.ListenUDP(network, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The packetHandlerManager doesn't have a server.
+	// It only handles packets for sessions that are already established.
+	handler, err := getMultiplexer().AddConn(conn, config.ConnectionIDLength, config.StatelessResetKey, config.Tracer)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return handler, conn.LocalAddr().(*net.UDPAddr), nil
+}
+
 func (s *baseServer) run() {
 	defer close(s.running)
 	for {
@@ -258,6 +330,9 @@ var defaultAcceptToken = func(clientAddr net.Addr, token *Token) bool {
 	return sourceAddr == token.RemoteAddr
 }
 
+// ErrServerClosed is returned by Accept after the server was closed, or when it is shutting down.
+var ErrServerClosed = errors.New("quic: server closed")
+
 // Accept returns sessions that already completed the handshake.
 // It is only valid if acceptEarlySessions is false.
 func (s *baseServer) Accept(ctx context.Context) (Session, error) {
@@ -273,7 +348,39 @@ func (s *baseServer) accept(ctx context.Context) (quicSession, error) {
 		return sess, nil
 	case <-s.errorChan:
 		return nil, s.serverError
+	case <-s.shutdownChan:
+		return nil, ErrServerClosed
+	}
+}
+
+// Shutdown gracefully shuts down the server.
+func (s *baseServer) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	if !s.shuttingDown {
+		s.logger.Debugf("Shutting down server. Waiting for active sessions to close.")
+		s.shuttingDown = true
+		close(s.shutdownChan)
+	}
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.activeSessions.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	case <-s.errorChan:
 	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	return ctx.Err()
 }
 
 // Close the server
@@ -284,7 +391,7 @@ func (s *baseServer) Close() error {
 		return nil
 	}
 	if s.serverError == nil {
-		s.serverError = errors.New("server closed")
+		s.serverError = ErrServerClosed
 	}
 	// If the server was started with ListenAddr, we created the packet conn.
 	// We need to close it in order to make the go routine reading from that conn return.
@@ -295,12 +402,40 @@ func (s *baseServer) Close() error {
 
 	<-s.running
 	s.sessionHandler.CloseServer()
+	// The packet conns for the preferred addresses were always created by the server.
+	// All sessions are registered with them, so destroying them closes all sessions.
+	for _, h := range s.preferredAddrHandlers {
+		h.Destroy()
+	}
 	if createdPacketConn {
 		return s.sessionHandler.Destroy()
 	}
 	return nil
 }
 
+// RotateStatelessResetKey rotates the stateless reset key.
+func (s *baseServer) RotateStatelessResetKey(key []byte) error {
+	if len(key) == 0 {
+		return errors.New("empty stateless reset key")
+	}
+	s.sessionHandler.RotateStatelessResetKey(key)
+	for _, h := range s.preferredAddrHandlers {
+		h.RotateStatelessResetKey(key)
+	}
+	return nil
+}
+
+// StatelessResetStats returns statistics about the stateless resets sent and received.
+func (s *baseServer) StatelessResetStats() StatelessResetStats {
+	stats := s.sessionHandler.StatelessResetStats()
+	for _, h := range s.preferredAddrHandlers {
+		st := h.StatelessResetStats()
+		stats.Sent += st.Sent
+		stats.Received += st.Received
+	}
+	return stats
+}
+
 func (s *baseServer) setCloseError(e error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -447,8 +582,26 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		return nil
 	}
 
-	connID, err := protocol.GenerateConnectionID(s.config.ConnectionIDLength)
+	s.mutex.Lock()
+	shuttingDown := s.shuttingDown
+	if !shuttingDown {
+		s.activeSessions.Add(1)
+	}
+	s.mutex.Unlock()
+	if shuttingDown {
+		s.logger.Debugf("Rejecting new connection. Server is shutting down.")
+		go func() {
+			defer p.buffer.Release()
+			if err := s.sendConnectionRefused(p.remoteAddr, hdr, p.info); err != nil {
+				s.logger.Debugf("Error rejecting connection: %s", err)
+			}
+		}()
+		return nil
+	}
+
+	connID, err := s.config.newConnectionID()
 	if err != nil {
+		s.activeSessions.Done()
 		return err
 	}
 	s.logger.Debugf("Changing connection ID to %s.", connID)
@@ -470,7 +623,7 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		}
 		sess = s.newSession(
 			newSendConn(s.conn, p.remoteAddr, p.info),
-			s.sessionHandler,
+			s.sessionRunner(),
 			origDestConnID,
 			retrySrcConnID,
 			hdr.DestConnectionID,
@@ -489,6 +642,7 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		sess.handlePacket(p)
 		return sess
 	}); !added {
+		s.activeSessions.Done()
 		return nil
 	}
 	go sess.run()
@@ -500,14 +654,35 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 	return nil
 }
 
+// sessionRunner returns the sessionRunner for a new session.
+// If the server listens on preferred addresses, the session needs to be reachable on all of them.
+func (s *baseServer) sessionRunner() sessionRunner {
+	if len(s.preferredAddrHandlers) == 0 {
+		return s.sessionHandler
+	}
+	runners := newSessionRunners(s.sessionHandler)
+	for _, h := range s.preferredAddrHandlers {
+		runners.AddRunner(h)
+	}
+	return runners
+}
+
 func (s *baseServer) handleNewSession(sess quicSession) {
 	sessCtx := sess.Context()
+	defer func() {
+		<-sessCtx.Done()
+		s.activeSessions.Done()
+	}()
+
 	if s.acceptEarlySessions {
 		// wait until the early session is ready (or the handshake fails)
 		select {
 		case <-sess.earlySessionReady():
 		case <-sessCtx.Done():
 			return
+		case <-s.shutdownChan:
+			sess.closeWithTransportError(ConnectionRefused)
+			return
 		}
 	} else {
 		// wait until the handshake is complete (or fails)
@@ -515,6 +690,9 @@ func (s *baseServer) handleNewSession(sess quicSession) {
 		case <-sess.HandshakeComplete().Done():
 		case <-sessCtx.Done():
 			return
+		case <-s.shutdownChan:
+			sess.closeWithTransportError(ConnectionRefused)
+			return
 		}
 	}
 
@@ -525,6 +703,10 @@ func (s *baseServer) handleNewSession(sess quicSession) {
 	case <-sessCtx.Done():
 		atomic.AddInt32(&s.sessionQueueLen, -1)
 		// don't pass sessions that were already closed to Accept()
+	case <-s.shutdownChan:
+		atomic.AddInt32(&s.sessionQueueLen, -1)
+		// Sessions that weren't accepted yet won't be accepted any more.
+		sess.closeWithTransportError(ConnectionRefused)
 	}
 }
 
@@ -532,7 +714,7 @@ func (s *baseServer) sendRetry(remoteAddr net.Addr, hdr *wire.Header, info *pack
 	// Log the Initial packet now.
 	// If no Retry is sent, the packet will be logged by the session.
 	(&wire.ExtendedHeader{Header: *hdr}).Log(s.logger)
-	srcConnID, err := protocol.GenerateConnectionID(s.config.ConnectionIDLength)
+	srcConnID, err := s.config.newConnectionID()
 	if err != nil {
 		return err
 	}
@@ -565,7 +747,7 @@ func (s *baseServer) sendRetry(remoteAddr net.Addr, hdr *wire.Header, info *pack
 	if s.config.Tracer != nil {
 		s.config.Tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(buf.Len()), nil)
 	}
-	_, err = s.conn.WritePacket(buf.Bytes(), remoteAddr, info.OOB())
+	_, err = s.conn.WritePacket(buf.Bytes(), remoteAddr, info.OOB(), 0, protocol.ECNNon)
 	return err
 }
 
@@ -642,7 +824,7 @@ func (s *baseServer) sendError(remoteAddr net.Addr, hdr *wire.Header, sealer han
 	if s.config.Tracer != nil {
 		s.config.Tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(len(raw)), []logging.Frame{ccf})
 	}
-	_, err := s.conn.WritePacket(raw, remoteAddr, info.OOB())
+	_, err := s.conn.WritePacket(raw, remoteAddr, info.OOB(), 0, protocol.ECNNon)
 	return err
 }
 
@@ -665,7 +847,7 @@ func (s *baseServer) sendVersionNegotiationPacket(p *receivedPacket, hdr *wire.H
 			nil,
 		)
 	}
-	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.OOB()); err != nil {
+	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNNon); err != nil {
 		s.logger.Debugf("Error sending Version Negotiation: %s", err)
 	}
 }
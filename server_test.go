@@ -157,6 +157,52 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("accepts tokens issued by a server using the same token keys", func() {
+		key1 := bytes.Repeat([]byte{1}, 32)
+		key2 := bytes.Repeat([]byte{2}, 32)
+		ln1, err := ListenAddr("localhost:0", tlsConf, &Config{TokenKeys: [][]byte{key1}})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln1.Close()
+		ln2, err := ListenAddr("localhost:0", tlsConf, &Config{TokenKeys: [][]byte{key2, key1}})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln2.Close()
+		ln3, err := ListenAddr("localhost:0", tlsConf, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln3.Close()
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		token, err := ln1.(*baseServer).tokenGenerator.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		t, err := ln2.(*baseServer).tokenGenerator.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.RemoteAddr).To(Equal("192.168.0.1"))
+		_, err = ln3.(*baseServer).tokenGenerator.DecodeToken(token)
+		Expect(err).To(HaveOccurred())
+		// ln2 uses the new key to issue tokens
+		token, err = ln2.(*baseServer).tokenGenerator.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = ln1.(*baseServer).tokenGenerator.DecodeToken(token)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("uses the TokenProtector", func() {
+		tokenProtector := NewMockTokenProtector(mockCtrl)
+		ln, err := Listen(conn, tlsConf, &Config{TokenProtector: tokenProtector})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		tokenProtector.EXPECT().NewToken(gomock.Any()).Return([]byte("token"), nil)
+		token, err := ln.(*baseServer).tokenGenerator.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token).To(Equal([]byte("token")))
+	})
+
+	It("errors when both TokenKeys and a TokenProtector are set", func() {
+		_, err := Listen(conn, tlsConf, &Config{
+			TokenKeys:      [][]byte{bytes.Repeat([]byte{1}, 32)},
+			TokenProtector: NewMockTokenProtector(mockCtrl),
+		})
+		Expect(err).To(MatchError("cannot use both Config.TokenKeys and Config.TokenProtector"))
+	})
+
 	It("listens on a given address", func() {
 		addr := "127.0.0.1:13579"
 		ln, err := ListenAddr(addr, tlsConf, &Config{})
@@ -166,6 +212,41 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("listens on the preferred addresses", func() {
+		ln, err := ListenAddr("127.0.0.1:0", tlsConf, &Config{PreferredAddressIPv4: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}})
+		Expect(err).ToNot(HaveOccurred())
+		server := ln.(*baseServer)
+		Expect(server.preferredAddrHandlers).To(HaveLen(1))
+		preferredAddr := server.config.PreferredAddressIPv4
+		Expect(preferredAddr.Port).ToNot(BeZero())
+		// new sessions are registered with the packet handler managers for all addresses
+		runner, ok := server.sessionRunner().(*sessionRunners)
+		Expect(ok).To(BeTrue())
+		Expect(runner.Has(server.sessionHandler)).To(BeTrue())
+		Expect(runner.Has(server.preferredAddrHandlers[0])).To(BeTrue())
+		_, err = net.ListenUDP("udp4", preferredAddr)
+		Expect(err).To(HaveOccurred())
+		// stop the listener
+		Expect(ln.Close()).To(Succeed())
+		// the packet conn for the preferred address is closed as well
+		conn, err := net.ListenUDP("udp4", preferredAddr)
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+	})
+
+	It("errors if it can't listen on a preferred address", func() {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		_, err = Listen(conn, tlsConf, &Config{PreferredAddressIPv4: &net.UDPAddr{IP: net.IPv4(1, 1, 1, 1), Port: 1111}})
+		Expect(err).To(BeAssignableToTypeOf(&net.OpError{}))
+		// the packet conn is not registered with the multiplexer
+		mux := getMultiplexer().(*connMultiplexer)
+		mux.mutex.Lock()
+		defer mux.mutex.Unlock()
+		Expect(mux.conns).ToNot(HaveKey(conn.LocalAddr().Network() + " " + conn.LocalAddr().String()))
+	})
+
 	It("errors if given an invalid address", func() {
 		addr := "127.0.0.1"
 		_, err := ListenAddr(addr, tlsConf, &Config{})
@@ -503,6 +584,33 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("uses the ConnectionIDGenerator for the Retry packet", func() {
+				connIDGenerator := NewMockConnectionIDGenerator(mockCtrl)
+				connIDGenerator.EXPECT().GenerateConnectionID().Return([]byte{0xde, 0xca, 0xfb, 0xad, 0x42}, nil)
+				serv.config.ConnectionIDGenerator = connIDGenerator
+				serv.config.ConnectionIDLength = 5
+				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return false }
+				hdr := &wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  protocol.ConnectionID{5, 4, 3, 2, 1},
+					DestConnectionID: protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+					Version:          protocol.VersionTLS,
+				}
+				packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+				tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), nil)
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), packet.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					replyHdr := parseHeader(b)
+					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					Expect(replyHdr.SrcConnectionID).To(Equal(protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad, 0x42}))
+					return len(b), nil
+				})
+				serv.handlePacket(packet)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("sends an INVALID_TOKEN error, if an invalid retry token is received", func() {
 				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return false }
 				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, nil, nil)
@@ -541,7 +649,7 @@ var _ = Describe("Server", func() {
 					Expect(err).ToNot(HaveOccurred())
 					data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 					Expect(err).ToNot(HaveOccurred())
-					f, err := wire.NewFrameParser(false, hdr.Version).ParseNext(bytes.NewReader(data), protocol.EncryptionInitial)
+					f, err := wire.NewFrameParser(false, false, false, hdr.Version).ParseNext(bytes.NewReader(data), protocol.EncryptionInitial)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 					ccf := f.(*wire.ConnectionCloseFrame)
@@ -971,6 +1079,166 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 		})
+
+		Context("rotating the stateless reset key", func() {
+			It("rotates the key", func() {
+				phm.EXPECT().RotateStatelessResetKey([]byte("foobar"))
+				Expect(serv.RotateStatelessResetKey([]byte("foobar"))).To(Succeed())
+			})
+
+			It("rotates the key for the preferred addresses", func() {
+				phm2 := NewMockPacketHandlerManager(mockCtrl)
+				serv.preferredAddrHandlers = []packetHandlerManager{phm2}
+				phm.EXPECT().RotateStatelessResetKey([]byte("foobar"))
+				phm2.EXPECT().RotateStatelessResetKey([]byte("foobar"))
+				Expect(serv.RotateStatelessResetKey([]byte("foobar"))).To(Succeed())
+				phm2.EXPECT().Destroy()
+			})
+
+			It("rejects empty keys", func() {
+				Expect(serv.RotateStatelessResetKey(nil)).To(MatchError("empty stateless reset key"))
+			})
+
+			It("adds up the stateless reset statistics of all packet handler maps", func() {
+				phm2 := NewMockPacketHandlerManager(mockCtrl)
+				serv.preferredAddrHandlers = []packetHandlerManager{phm2}
+				phm.EXPECT().StatelessResetStats().Return(StatelessResetStats{Sent: 1, Received: 2})
+				phm2.EXPECT().StatelessResetStats().Return(StatelessResetStats{Sent: 3, Received: 4})
+				Expect(serv.StatelessResetStats()).To(Equal(StatelessResetStats{Sent: 4, Received: 6}))
+				phm2.EXPECT().Destroy()
+			})
+		})
+
+		Context("shutting down", func() {
+			// newSession makes the server create a new session, which completes the handshake immediately if handshakeComplete is set.
+			// The session is closed when sessCtx is canceled.
+			newSession := func(sessCtx context.Context, handshakeComplete bool) *MockQuicSession {
+				sess := NewMockQuicSession(mockCtrl)
+				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return true }
+				serv.newSession = func(
+					_ sendConn,
+					runner sessionRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ bool,
+					_ logging.ConnectionTracer,
+					_ uint64,
+					_ utils.Logger,
+					_ protocol.VersionNumber,
+				) quicSession {
+					handshakeCtx := context.Background()
+					if handshakeComplete {
+						ctx, cancel := context.WithCancel(context.Background())
+						cancel()
+						handshakeCtx = ctx
+					}
+					sess.EXPECT().handlePacket(gomock.Any())
+					sess.EXPECT().HandshakeComplete().Return(handshakeCtx)
+					sess.EXPECT().run()
+					sess.EXPECT().Context().Return(sessCtx)
+					return sess
+				}
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() packetHandler) bool {
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					fn()
+					return true
+				})
+				tracer.EXPECT().TracerForConnection(gomock.Any(), protocol.PerspectiveServer, gomock.Any())
+				serv.handleInitialImpl(
+					&receivedPacket{buffer: getPacketBuffer()},
+					&wire.Header{DestConnectionID: protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}},
+				)
+				return sess
+			}
+
+			It("returns Accept when shutting down", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					_, err := serv.Accept(context.Background())
+					Expect(err).To(MatchError(ErrServerClosed))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				phm.EXPECT().CloseServer()
+				Expect(serv.Shutdown(context.Background())).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("refuses new connections while shutting down", func() {
+				serv.shuttingDown = true
+				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return true }
+				p := getInitialWithRandomDestConnID()
+				hdr, _, _, err := wire.ParsePacket(p.data, 0)
+				Expect(err).ToNot(HaveOccurred())
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					rejectHdr := parseHeader(b)
+					Expect(rejectHdr.Type).To(Equal(protocol.PacketTypeInitial))
+					Expect(rejectHdr.DestConnectionID).To(Equal(hdr.SrcConnectionID))
+					return len(b), nil
+				})
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("waits for active sessions to close", func() {
+				sessCtx, closeSess := context.WithCancel(context.Background())
+				sess := newSession(sessCtx, true)
+				s, err := serv.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(s).To(Equal(sess))
+
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					Expect(serv.Shutdown(context.Background())).To(Succeed())
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				phm.EXPECT().CloseServer()
+				closeSess()
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("closes the server when the context expires", func() {
+				sessCtx, closeSess := context.WithCancel(context.Background())
+				defer closeSess()
+				newSession(sessCtx, true)
+				_, err := serv.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+				defer cancel()
+				phm.EXPECT().CloseServer()
+				Expect(serv.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+			})
+
+			It("closes sessions that weren't accepted yet", func() {
+				sessCtx, closeSess := context.WithCancel(context.Background())
+				sess := newSession(sessCtx, true)
+				sess.EXPECT().closeWithTransportError(ConnectionRefused).Do(func(TransportErrorCode) { closeSess() })
+				phm.EXPECT().CloseServer()
+				Expect(serv.Shutdown(context.Background())).To(Succeed())
+			})
+
+			It("closes sessions that are still handshaking", func() {
+				sessCtx, closeSess := context.WithCancel(context.Background())
+				sess := newSession(sessCtx, false)
+				sess.EXPECT().closeWithTransportError(ConnectionRefused).Do(func(TransportErrorCode) { closeSess() })
+				phm.EXPECT().CloseServer()
+				Expect(serv.Shutdown(context.Background())).To(Succeed())
+			})
+		})
 	})
 
 	Context("server accepting sessions that haven't completed the handshake", func() {
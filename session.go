@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/flowcontrol"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
@@ -26,6 +27,7 @@ import (
 
 type unpacker interface {
 	Unpack(hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error)
+	UnpackPath(pathID uint64, hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error)
 }
 
 type streamGetter interface {
@@ -53,11 +55,13 @@ type streamManager interface {
 type cryptoStreamHandler interface {
 	RunHandshake()
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.VersionNumber)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
 	GetSessionTicket() ([]byte, error)
 	io.Closer
 	ConnectionState() handshake.ConnectionState
+	sealingManager
 }
 
 type packetInfo struct {
@@ -75,6 +79,8 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info *packetInfo
+	// rcvConn is the packet conn that the packet was received on
+	rcvConn connection
 }
 
 func (p *receivedPacket) Size() protocol.ByteCount { return protocol.ByteCount(len(p.data)) }
@@ -87,6 +93,7 @@ func (p *receivedPacket) Clone() *receivedPacket {
 		buffer:     p.buffer,
 		ecn:        p.ecn,
 		info:       p.info,
+		rcvConn:    p.rcvConn,
 	}
 }
 
@@ -102,12 +109,14 @@ type sessionRunner interface {
 
 type handshakeRunner struct {
 	onReceivedParams    func(*wire.TransportParameters)
+	onChangedVersion    func(protocol.VersionNumber)
 	onError             func(error)
 	dropKeys            func(protocol.EncryptionLevel)
 	onHandshakeComplete func()
 }
 
 func (r *handshakeRunner) OnReceivedParams(tp *wire.TransportParameters) { r.onReceivedParams(tp) }
+func (r *handshakeRunner) OnChangedVersion(v protocol.VersionNumber)     { r.onChangedVersion(v) }
 func (r *handshakeRunner) OnError(e error)                               { r.onError(e) }
 func (r *handshakeRunner) DropKeys(el protocol.EncryptionLevel)          { r.dropKeys(el) }
 func (r *handshakeRunner) OnHandshakeComplete()                          { r.onHandshakeComplete() }
@@ -127,6 +136,8 @@ func (e *errCloseForRecreating) Error() string {
 	return "closing session in order to recreate it"
 }
 
+var errSessionClosed = errors.New("session closed")
+
 var sessionTracingID uint64        // to be accessed atomically
 func nextSessionTracingID() uint64 { return atomic.AddUint64(&sessionTracingID, 1) }
 
@@ -143,11 +154,21 @@ type session struct {
 
 	perspective protocol.Perspective
 	version     protocol.VersionNumber
-	config      *Config
+	// The version used for the first Initial packet.
+	// It differs from version if the version was changed by compatible version negotiation (RFC 9368).
+	initialVersion protocol.VersionNumber
+	config         *Config
 
+	// connMutex protects conn, since it's replaced when migrating to a new path
+	connMutex sync.RWMutex
 	conn      sendConn
+	// rcvConn is the packet conn that packets on the current path are received on.
+	// Only used by the server, to detect when the client migrates to the preferred address.
+	rcvConn   connection
 	sendQueue sender
 
+	runners *sessionRunners
+
 	streamsMap      streamManager
 	connIDManager   *connIDManager
 	connIDGenerator *connIDGenerator
@@ -168,12 +189,33 @@ type session struct {
 	frameParser   wire.FrameParser
 	packer        packer
 	mtuDiscoverer mtuDiscoverer // initialized when the handshake completes
+	// initialized when the transport parameters are received, if both peers support the ACK Frequency extension
+	ackFrequencyManager *ackFrequencyManager
 
 	oneRTTStream        cryptoStream // only set for the server
 	cryptoStreamHandler cryptoStreamHandler
 
 	receivedPackets  chan *receivedPacket
 	sendingScheduled chan struct{}
+	// statsRequests is used to request a snapshot of the session's statistics from the run loop
+	statsRequests chan chan<- ConnectionStats
+
+	pathProbes  chan *path // paths that a client wants to migrate to
+	probingPath *path      // the path that is currently being validated
+
+	// multipath is set if both peers enabled the multipath extension.
+	multipath bool
+	// paths are the paths of a multipath connection, in addition to the path the handshake was performed on.
+	paths         []*multipathPath
+	pathAdditions chan *path      // paths that a client wants to add
+	pathUpdates   chan pathUpdate // status changes and closing of paths, requested by the application
+	// initialPathPeerStatus is the status of the path the handshake was performed on, as announced by the peer.
+	initialPathPeerStatus peerPathStatus
+	// lastAckFrequencyFrame is the last ACK_FREQUENCY frame received. It is applied to new paths.
+	lastAckFrequencyFrame *wire.AckFrequencyFrame
+	// largestRcvd1RTTPacketNumber is the largest packet number received in a 1-RTT packet.
+	// Only the packet with the largest packet number can trigger a migration to a new peer address.
+	largestRcvd1RTTPacketNumber protocol.PacketNumber
 
 	closeOnce sync.Once
 	// closeChan is used to notify the run loop that it should terminate
@@ -258,26 +300,29 @@ var newSession = func(
 		tracer:                tracer,
 		logger:                logger,
 		version:               v,
+		initialVersion:        v,
 	}
 	if origDestConnID != nil {
 		s.logID = origDestConnID.String()
 	} else {
 		s.logID = destConnID.String()
 	}
+	s.runners = newSessionRunners(runner)
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runners.AddResetToken(token, s) },
+		s.runners.RemoveResetToken,
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		clientDestConnID,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		s.config.newConnectionID,
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
+		s.runners.GetStatelessResetToken,
+		s.runners.Remove,
+		s.runners.Retire,
+		s.runners.ReplaceWithClosed,
 		s.queueControlFrame,
 		s.version,
 	)
@@ -287,6 +332,8 @@ var newSession = func(
 		0,
 		getMaxPacketSize(s.conn.RemoteAddr()),
 		s.rttStats,
+		s.newCongestionControlFunc(),
+		s.conn.capabilities().ECN,
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -304,16 +351,30 @@ var newSession = func(
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
-		DisableActiveMigration:          true,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		ActiveConnectionIDLimit:         protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:       srcConnID,
 		RetrySourceConnectionID:         retrySrcConnID,
+		VersionInformation:              s.getVersionInformation(),
+		EnableMultipath:                 s.config.EnableMultipath && srcConnID.Len() > 0,
+		DisableActiveMigration:          s.config.DisableActiveMigration,
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	if s.config.PreferredAddressIPv4 != nil || s.config.PreferredAddressIPv6 != nil {
+		preferredAddress, err := s.newPreferredAddress()
+		if err != nil {
+			s.logger.Errorf("Not sending the preferred_address transport parameter: %s", err)
+		} else {
+			params.PreferredAddress = preferredAddress
+		}
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -326,6 +387,7 @@ var newSession = func(
 		params,
 		&handshakeRunner{
 			onReceivedParams: s.handleTransportParameters,
+			onChangedVersion: s.changeVersion,
 			onError:          s.closeLocal,
 			dropKeys:         s.dropEncryptionLevel,
 			onHandshakeComplete: func() {
@@ -335,6 +397,7 @@ var newSession = func(
 		},
 		tlsConf,
 		enable0RTT,
+		s.newAllow0RTTFunc(),
 		s.rttStats,
 		tracer,
 		logger,
@@ -390,21 +453,24 @@ var newClientSession = func(
 		tracer:                tracer,
 		versionNegotiated:     hasNegotiatedVersion,
 		version:               v,
+		initialVersion:        v,
 	}
+	s.runners = newSessionRunners(runner)
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runners.AddResetToken(token, s) },
+		s.runners.RemoveResetToken,
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		nil,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		s.config.newConnectionID,
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
+		s.runners.GetStatelessResetToken,
+		s.runners.Remove,
+		s.runners.Retire,
+		s.runners.ReplaceWithClosed,
 		s.queueControlFrame,
 		s.version,
 	)
@@ -414,6 +480,8 @@ var newClientSession = func(
 		initialPacketNumber,
 		getMaxPacketSize(s.conn.RemoteAddr()),
 		s.rttStats,
+		s.newCongestionControlFunc(),
+		s.conn.capabilities().ECN,
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -431,13 +499,19 @@ var newClientSession = func(
 		MaxUniStreamNum:                protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                    protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:               protocol.AckDelayExponent,
-		DisableActiveMigration:         true,
 		ActiveConnectionIDLimit:        protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:      srcConnID,
+		VersionInformation:             s.getVersionInformation(),
+		EnableMultipath:                s.config.EnableMultipath && srcConnID.Len() > 0,
+		DisableActiveMigration:         s.config.DisableActiveMigration,
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -450,6 +524,7 @@ var newClientSession = func(
 		params,
 		&handshakeRunner{
 			onReceivedParams:    s.handleTransportParameters,
+			onChangedVersion:    s.changeVersion,
 			onError:             s.closeLocal,
 			dropKeys:            s.dropEncryptionLevel,
 			onHandshakeComplete: func() { close(s.handshakeCompleteChan) },
@@ -493,10 +568,62 @@ var newClientSession = func(
 	return s
 }
 
+// newPreferredAddress generates the value of the preferred_address transport parameter.
+// The transport parameter always contains both an IPv4 and an IPv6 address.
+// If only one of them is configured, the other one is sent as the unspecified address with port 0.
+func (s *session) newPreferredAddress() (*wire.PreferredAddress, error) {
+	connID, token, err := s.connIDGenerator.GeneratePreferredAddressConnID()
+	if err != nil {
+		return nil, err
+	}
+	pa := &wire.PreferredAddress{
+		IPv4:                net.IPv4zero.To4(),
+		IPv6:                net.IPv6zero,
+		ConnectionID:        connID,
+		StatelessResetToken: token,
+	}
+	if addr := s.config.PreferredAddressIPv4; addr != nil {
+		pa.IPv4 = addr.IP.To4()
+		pa.IPv4Port = uint16(addr.Port)
+	}
+	if addr := s.config.PreferredAddressIPv6; addr != nil {
+		pa.IPv6 = addr.IP.To16()
+		pa.IPv6Port = uint16(addr.Port)
+	}
+	return pa, nil
+}
+
+// newCongestionControlFunc returns the function used to create the congestion controller.
+// It returns nil if no congestion controller is configured, in which case the default is used.
+func (s *session) newCongestionControlFunc() func(congestion.Parameters) congestion.CongestionControl {
+	if s.config.CongestionControl == nil {
+		return nil
+	}
+	return func(p congestion.Parameters) congestion.CongestionControl {
+		// When migrating to a new path, the congestion controller is created after switching to the new path.
+		p.RemoteAddr = s.RemoteAddr()
+		return s.config.CongestionControl(p)
+	}
+}
+
+// newAllow0RTTFunc returns the function used by the server to decide whether to accept 0-RTT.
+// It returns nil if neither Allow0RTT nor AntiReplay is configured.
+func (s *session) newAllow0RTTFunc() func(ticketID []byte, issued time.Time) bool {
+	if s.config.Allow0RTT == nil && s.config.AntiReplay == nil {
+		return nil
+	}
+	return func(ticketID []byte, issued time.Time) bool {
+		if s.config.Allow0RTT != nil && !s.config.Allow0RTT(s.RemoteAddr()) {
+			return false
+		}
+		return s.config.AntiReplay == nil || s.config.AntiReplay.Accept(ticketID, issued)
+	}
+}
+
 func (s *session) preSetup() {
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue(s.version)
-	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.version)
+	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableAckFrequency, s.config.EnableMultipath, s.version)
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
@@ -518,6 +645,11 @@ func (s *session) preSetup() {
 	s.receivedPackets = make(chan *receivedPacket, protocol.MaxSessionUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
+	s.pathProbes = make(chan *path)
+	s.pathAdditions = make(chan *path)
+	s.pathUpdates = make(chan pathUpdate)
+	s.statsRequests = make(chan chan<- ConnectionStats)
+	s.largestRcvd1RTTPacketNumber = protocol.InvalidPacketNumber
 	s.handshakeCtx, s.handshakeCtxCancel = context.WithCancel(context.Background())
 
 	now := time.Now()
@@ -526,7 +658,7 @@ func (s *session) preSetup() {
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
 	if s.config.EnableDatagrams {
-		s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+		s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config.DatagramSendQueueLen, s.config.DatagramDropPolicy, s.logger)
 	}
 }
 
@@ -537,11 +669,7 @@ func (s *session) run() error {
 	s.timer = utils.NewTimer()
 
 	go s.cryptoStreamHandler.RunHandshake()
-	go func() {
-		if err := s.sendQueue.Run(); err != nil {
-			s.destroyImpl(err)
-		}
-	}()
+	go s.runSendQueue(s.sendQueue)
 
 	if s.perspective == protocol.PerspectiveClient {
 		select {
@@ -602,6 +730,15 @@ runLoop:
 				// We do all the interesting stuff after the switch statement, so
 				// nothing to see here.
 			case <-sendQueueAvailable:
+			case p := <-s.pathProbes:
+				s.startProbingPath(p)
+			case p := <-s.pathAdditions:
+				s.addPath(p)
+			case u := <-s.pathUpdates:
+				s.handlePathUpdate(u)
+			case c := <-s.statsRequests:
+				c <- s.getStats()
+				continue
 			case firstPacket := <-s.receivedPackets:
 				wasProcessed := s.handlePacketImpl(firstPacket)
 				// Don't set timers and send packets if the packet made us close the session.
@@ -651,6 +788,13 @@ runLoop:
 				s.closeLocal(err)
 			}
 		}
+		for _, p := range s.paths {
+			if timeout := p.sentPacketHandler.GetLossDetectionTimeout(); !timeout.IsZero() && timeout.Before(now) {
+				if err := p.sentPacketHandler.OnLossDetectionTimeout(); err != nil {
+					s.closeLocal(err)
+				}
+			}
+		}
 
 		if keepAliveTime := s.nextKeepAliveTime(); !keepAliveTime.IsZero() && !now.Before(keepAliveTime) {
 			// send a PING frame since there is no activity in the session
@@ -669,7 +813,19 @@ runLoop:
 			}
 		}
 
-		if s.sendQueue.WouldBlock() {
+		if s.probingPath != nil {
+			if err := s.maybeSendPathProbe(now); err != nil {
+				s.closeLocal(err)
+			}
+		}
+		if len(s.paths) > 0 {
+			if err := s.maybeSendMultipathProbes(now); err != nil {
+				s.closeLocal(err)
+			}
+		}
+
+		// When sending on multiple paths, packets can still be sent on the other paths.
+		if s.sendQueue.WouldBlock() && !s.sendsOnMultiplePaths() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
 			sendQueueAvailable = s.sendQueue.Available()
@@ -692,10 +848,20 @@ runLoop:
 	s.logger.Infof("Connection %s closed.", s.logID)
 	s.cryptoStreamHandler.Close()
 	s.sendQueue.Close()
+	for _, p := range s.paths {
+		close(p.done)
+		p.sendQueue.Close()
+	}
 	s.timer.Stop()
 	return closeErr.err
 }
 
+func (s *session) runSendQueue(q sender) {
+	if err := q.Run(); err != nil {
+		s.destroyImpl(err)
+	}
+}
+
 // blocks until the early session can be used
 func (s *session) earlySessionReady() <-chan struct{} {
 	return s.earlySessionReadyChan
@@ -713,6 +879,28 @@ func (s *session) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize != protocol.InvalidByteCount
 }
 
+// updateMaxDatagramSize updates the maximum size of a message that can be sent in a DATAGRAM frame.
+// It must be called every time the maximum packet size or the peer's transport parameters change.
+func (s *session) updateMaxDatagramSize() {
+	if s.datagramQueue == nil || s.peerParams == nil || !s.supportsDatagrams() {
+		return
+	}
+	// Assume a short header packet with the longest possible connection ID and packet number,
+	// so that the value doesn't change when a new connection ID is used.
+	overhead := 1 + protocol.MaxConnIDLen + protocol.ByteCount(protocol.PacketNumberLen4) + 16 /* AEAD tag */
+	maxPacketSize := s.packer.MaxPacketSize()
+	// DATAGRAM frames can be sent on any path, so they need to fit into packets on all paths.
+	for _, p := range s.paths {
+		maxPacketSize = utils.MinByteCount(maxPacketSize, p.packer.MaxPacketSize())
+	}
+	if maxPacketSize <= overhead {
+		s.datagramQueue.SetMaxDataLen(0)
+		return
+	}
+	maxFrameSize := utils.MinByteCount(maxPacketSize-overhead, s.peerParams.MaxDatagramFrameSize)
+	s.datagramQueue.SetMaxDataLen((&wire.DatagramFrame{DataLenPresent: true}).MaxDataLen(maxFrameSize, s.version))
+}
+
 func (s *session) ConnectionState() ConnectionState {
 	return ConnectionState{
 		TLS:               s.cryptoStreamHandler.ConnectionState(),
@@ -720,6 +908,54 @@ func (s *session) ConnectionState() ConnectionState {
 	}
 }
 
+func (s *session) Stats() ConnectionStats {
+	c := make(chan ConnectionStats, 1)
+	select {
+	case s.statsRequests <- c:
+		return <-c
+	case <-s.ctx.Done():
+		// The run loop has exited, so the state of the session doesn't change any more.
+		return s.getStats()
+	}
+}
+
+// getStats must only be called from the run loop, or after the run loop has exited.
+func (s *session) getStats() ConnectionStats {
+	stats := s.sentPacketHandler.GetStats()
+	cs := ConnectionStats{
+		MinRTT:             s.rttStats.MinRTT(),
+		LatestRTT:          s.rttStats.LatestRTT(),
+		SmoothedRTT:        s.rttStats.SmoothedRTT(),
+		MeanDeviation:      s.rttStats.MeanDeviation(),
+		CongestionWindow:   uint64(stats.CongestionWindow),
+		BytesInFlight:      uint64(stats.BytesInFlight),
+		BytesSent:          uint64(stats.BytesSent),
+		BytesReceived:      uint64(stats.BytesReceived),
+		BytesRetransmitted: uint64(stats.BytesRetransmitted),
+		PacketsSent:        stats.PacketsSent,
+		PacketsLost:        stats.PacketsLost,
+		MaxDatagramSize:    uint64(s.packer.MaxPacketSize()),
+	}
+	// When using multipath, the counters include all paths,
+	// while the RTT estimate and the MTU are those of the path the handshake was performed on.
+	for _, p := range s.paths {
+		stats := p.sentPacketHandler.GetStats()
+		cs.CongestionWindow += uint64(stats.CongestionWindow)
+		cs.BytesInFlight += uint64(stats.BytesInFlight)
+		cs.BytesSent += uint64(stats.BytesSent)
+		cs.BytesRetransmitted += uint64(stats.BytesRetransmitted)
+		cs.PacketsSent += stats.PacketsSent
+		cs.PacketsLost += stats.PacketsLost
+	}
+	if s.datagramQueue != nil {
+		cs.DatagramsDropped = s.datagramQueue.NumDropped()
+	}
+	if sealer, err := s.cryptoStreamHandler.Get1RTTSealer(); err == nil {
+		cs.KeyUpdates = sealer.NumKeyUpdates()
+	}
+	return cs
+}
+
 // Time when the next keep-alive packet should be sent.
 // It returns a zero time if no keep-alive should be sent.
 func (s *session) nextKeepAliveTime() time.Time {
@@ -749,6 +985,15 @@ func (s *session) maybeResetTimer() {
 		}
 	}
 
+	if s.probingPath != nil {
+		deadline = utils.MinTime(deadline, utils.MinTime(s.probingPath.nextProbe, s.probingPath.deadline))
+	}
+	for _, p := range s.paths {
+		if t := p.nextTimeout(); !t.IsZero() {
+			deadline = utils.MinTime(deadline, t)
+		}
+	}
+
 	if ackAlarm := s.receivedPacketHandler.GetAlarmTimeout(); !ackAlarm.IsZero() {
 		deadline = utils.MinTime(deadline, ackAlarm)
 	}
@@ -808,25 +1053,43 @@ func (s *session) handleHandshakeConfirmed() {
 	s.cryptoStreamHandler.SetHandshakeConfirmed()
 
 	if !s.config.DisablePathMTUDiscovery {
-		maxPacketSize := s.peerParams.MaxUDPPayloadSize
-		if maxPacketSize == 0 {
-			maxPacketSize = protocol.MaxByteCount
-		}
-		maxPacketSize = utils.MinByteCount(maxPacketSize, protocol.MaxPacketBufferSize)
-		s.mtuDiscoverer = newMTUDiscoverer(
-			s.rttStats,
-			getMaxPacketSize(s.conn.RemoteAddr()),
-			maxPacketSize,
-			func(size protocol.ByteCount) {
-				s.sentPacketHandler.SetMaxDatagramSize(size)
-				s.packer.SetMaxPacketSize(size)
-			},
-		)
+		s.startMTUDiscovery()
+	}
+	// Migrating to the preferred address would change the connection ID used on the initial path.
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil && !s.multipath {
+		s.migrateToPreferredAddress()
 	}
 }
 
+func (s *session) startMTUDiscovery() {
+	maxPacketSize := s.peerParams.MaxUDPPayloadSize
+	if maxPacketSize == 0 {
+		maxPacketSize = protocol.MaxByteCount
+	}
+	maxPacketSize = utils.MinByteCount(maxPacketSize, protocol.MaxPacketBufferSize)
+	var discoverer mtuDiscoverer
+	discoverer = newMTUDiscoverer(
+		s.rttStats,
+		getMaxPacketSize(s.conn.RemoteAddr()),
+		maxPacketSize,
+		func(size protocol.ByteCount) {
+			// The MTU probe might have been sent on a path that we migrated away from.
+			if discoverer != s.mtuDiscoverer {
+				return
+			}
+			s.sentPacketHandler.SetMaxDatagramSize(size)
+			s.packer.SetMaxPacketSize(size)
+			s.updateMaxDatagramSize()
+		},
+	)
+	s.mtuDiscoverer = discoverer
+}
+
 func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 	s.sentPacketHandler.ReceivedBytes(rp.Size())
+	if s.rcvConn == nil {
+		s.rcvConn = rp.rcvConn
+	}
 
 	if wire.IsVersionNegotiationPacket(rp.data) {
 		s.handleVersionNegotiationPacket(rp)
@@ -857,7 +1120,13 @@ func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 			break
 		}
 
-		if hdr.IsLongHeader && hdr.Version != s.version {
+		if s.isCompatibleVersionUpgrade(hdr) {
+			s.cryptoStreamHandler.ChangeVersion(hdr.Version)
+			s.changeVersion(hdr.Version)
+		}
+
+		// 0-RTT packets are sent using the initial version, even if the version was changed during the handshake.
+		if hdr.IsLongHeader && hdr.Version != s.version && !(hdr.Type == protocol.PacketType0RTT && hdr.Version == s.initialVersion) {
 			if s.tracer != nil {
 				s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
 			}
@@ -893,6 +1162,32 @@ func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 	return processed
 }
 
+// isCompatibleVersionUpgrade says if the client should switch to the version of this packet.
+// Using compatible version negotiation (RFC 9368), the server can switch to a different version
+// by responding to the client's first Initial with an Initial of that version.
+func (s *session) isCompatibleVersionUpgrade(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveClient &&
+		!s.receivedFirstPacket &&
+		hdr.IsLongHeader &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		hdr.Version != s.version &&
+		protocol.IsSupportedVersion(s.config.Versions, hdr.Version) &&
+		protocol.AreCompatibleVersions(s.version, hdr.Version)
+}
+
+func (s *session) changeVersion(v protocol.VersionNumber) {
+	s.logger.Infof("Switching to QUIC version %s (compatible version negotiation).", v)
+	s.version = v
+	s.packer.SetVersion(v)
+}
+
+func (s *session) getVersionInformation() *wire.VersionInformation {
+	return &wire.VersionInformation{
+		ChosenVersion:     s.version,
+		AvailableVersions: s.config.Versions,
+	}
+}
+
 func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -924,44 +1219,16 @@ func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /
 		return false
 	}
 
+	// When using multipath, packets sent on additional paths use a different connection ID than the initial path.
+	if s.multipath && !hdr.IsLongHeader {
+		if pathID, ok := s.connIDGenerator.SequenceNumber(hdr.DestConnectionID); ok && pathID != 0 {
+			return s.handleMultipathPacket(p, hdr, pathID)
+		}
+	}
+
 	packet, err := s.unpacker.Unpack(hdr, p.rcvTime, p.data)
 	if err != nil {
-		switch err {
-		case handshake.ErrKeysDropped:
-			if s.tracer != nil {
-				s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropKeyUnavailable)
-			}
-			s.logger.Debugf("Dropping %s packet (%d bytes) because we already dropped the keys.", hdr.PacketType(), p.Size())
-		case handshake.ErrKeysNotYetAvailable:
-			// Sealer for this encryption level not yet available.
-			// Try again later.
-			wasQueued = true
-			s.tryQueueingUndecryptablePacket(p, hdr)
-		case wire.ErrInvalidReservedBits:
-			s.closeLocal(&qerr.TransportError{
-				ErrorCode:    qerr.ProtocolViolation,
-				ErrorMessage: err.Error(),
-			})
-		case handshake.ErrDecryptionFailed:
-			// This might be a packet injected by an attacker. Drop it.
-			if s.tracer != nil {
-				s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropPayloadDecryptError)
-			}
-			s.logger.Debugf("Dropping %s packet (%d bytes) that could not be unpacked. Error: %s", hdr.PacketType(), p.Size(), err)
-		default:
-			var headerErr *headerParseError
-			if errors.As(err, &headerErr) {
-				// This might be a packet injected by an attacker. Drop it.
-				if s.tracer != nil {
-					s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropHeaderParseError)
-				}
-				s.logger.Debugf("Dropping %s packet (%d bytes) for which we couldn't unpack the header. Error: %s", hdr.PacketType(), p.Size(), err)
-			} else {
-				// This is an error returned by the AEAD (other than ErrDecryptionFailed).
-				// For example, a PROTOCOL_VIOLATION due to key updates.
-				s.closeLocal(err)
-			}
-		}
+		wasQueued = s.handleUnpackError(err, p, hdr)
 		return false
 	}
 
@@ -978,13 +1245,96 @@ func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /
 		return false
 	}
 
-	if err := s.handleUnpackedPacket(packet, p.ecn, p.rcvTime, p.Size()); err != nil {
+	// The server needs to validate new peer addresses, see RFC 9000, Section 9.3.
+	// A packet might also arrive on a different packet conn, if the client migrated to our preferred address.
+	var peerPath *path
+	if s.perspective == protocol.PerspectiveServer && packet.encryptionLevel == protocol.Encryption1RTT && s.isNewPath(p) {
+		// If we disabled active migration, the client is only allowed to migrate to our preferred address.
+		// Packets from a new client address are dropped (RFC 9000, Section 9).
+		if s.config.DisableActiveMigration && (p.rcvConn == nil || p.rcvConn == s.rcvConn) {
+			if s.tracer != nil {
+				s.tracer.DroppedPacket(logging.PacketType1RTT, p.Size(), logging.PacketDropUnexpectedPacket)
+			}
+			s.logger.Debugf("Dropping packet from %s, since active migration is disabled.", p.remoteAddr)
+			return false
+		}
+		peerPath = s.getPeerPath(p)
+		peerPath.ReceivedBytes(p.Size())
+	}
+
+	if err := s.handleUnpackedPacket(packet, p.ecn, p.rcvTime, p.Size(), peerPath); err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	// PATH_CHALLENGEs received on the path that is currently being validated are answered together with the next PATH_CHALLENGE.
+	// All other PATH_CHALLENGEs are answered right away.
+	if peerPath != nil && peerPath != s.probingPath && len(peerPath.responses) > 0 {
+		if err := s.sendPathProbe(peerPath, false, p.rcvTime); err != nil {
+			s.closeLocal(err)
+		}
+	}
 	return true
 }
 
+// handleUnpackError handles an error returned when unpacking a packet.
+// It returns true if the packet was queued for later decryption.
+func (s *session) handleUnpackError(err error, p *receivedPacket, hdr *wire.Header) bool /* was the packet queued */ {
+	switch err {
+	case handshake.ErrKeysDropped:
+		if s.tracer != nil {
+			s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropKeyUnavailable)
+		}
+		s.logger.Debugf("Dropping %s packet (%d bytes) because we already dropped the keys.", hdr.PacketType(), p.Size())
+	case handshake.ErrKeysNotYetAvailable:
+		// Sealer for this encryption level not yet available.
+		// Try again later.
+		s.tryQueueingUndecryptablePacket(p, hdr)
+		return true
+	case wire.ErrInvalidReservedBits:
+		s.closeLocal(&qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: err.Error(),
+		})
+	case handshake.ErrDecryptionFailed:
+		// This might be a packet injected by an attacker. Drop it.
+		if s.tracer != nil {
+			s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropPayloadDecryptError)
+		}
+		s.logger.Debugf("Dropping %s packet (%d bytes) that could not be unpacked. Error: %s", hdr.PacketType(), p.Size(), err)
+	default:
+		var headerErr *headerParseError
+		if errors.As(err, &headerErr) {
+			// This might be a packet injected by an attacker. Drop it.
+			if s.tracer != nil {
+				s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), p.Size(), logging.PacketDropHeaderParseError)
+			}
+			s.logger.Debugf("Dropping %s packet (%d bytes) for which we couldn't unpack the header. Error: %s", hdr.PacketType(), p.Size(), err)
+		} else {
+			// This is an error returned by the AEAD (other than ErrDecryptionFailed).
+			// For example, a PROTOCOL_VIOLATION due to key updates.
+			s.closeLocal(err)
+		}
+	}
+	return false
+}
+
+// isNewPath says if a packet was received on a path other than the current one.
+func (s *session) isNewPath(p *receivedPacket) bool {
+	return !addrsEqual(p.remoteAddr, s.conn.RemoteAddr()) || (p.rcvConn != nil && p.rcvConn != s.rcvConn)
+}
+
+// getPeerPath returns the path for a packet received on a path other than the current one.
+func (s *session) getPeerPath(p *receivedPacket) *path {
+	if s.probingPath != nil && s.probingPath.rcvConn == p.rcvConn && addrsEqual(s.probingPath.conn.RemoteAddr(), p.remoteAddr) {
+		return s.probingPath
+	}
+	// Responses have to be sent from the address that the packet was sent to.
+	if p.rcvConn != nil && p.rcvConn != s.rcvConn {
+		return newPeerPath(newSendConn(p.rcvConn, p.remoteAddr, p.info), p.rcvConn)
+	}
+	return newPeerPath(s.conn.WithRemoteAddr(p.remoteAddr, p.info), p.rcvConn)
+}
+
 func (s *session) handleRetryPacket(hdr *wire.Header, data []byte) bool /* was this a valid Retry */ {
 	if s.perspective == protocol.PerspectiveServer {
 		if s.tracer != nil {
@@ -1106,6 +1456,7 @@ func (s *session) handleUnpackedPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	packetSize protocol.ByteCount, // only for logging
+	rcvPath *path, // the path the packet was received on, nil if it was received on the current path
 ) error {
 	if len(packet.data) == 0 {
 		return &qerr.TransportError{
@@ -1158,15 +1509,36 @@ func (s *session) handleUnpackedPacket(
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
 
+	isAckEliciting, isNonProbing, err := s.handleFrames(packet, packetSize, rcvPath)
+	if err != nil {
+		return err
+	}
+
+	if packet.encryptionLevel == protocol.Encryption1RTT && packet.packetNumber > s.largestRcvd1RTTPacketNumber {
+		s.largestRcvd1RTTPacketNumber = packet.packetNumber
+		// The peer's address changed if it sent a non-probing packet with the largest packet number from a new address.
+		if rcvPath != nil && isNonProbing && rcvPath != s.probingPath {
+			s.startValidatingPeerAddress(rcvPath, rcvTime)
+		}
+	}
+
+	return s.receivedPacketHandler.ReceivedPacket(packet.packetNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
+}
+
+// handleFrames parses and handles all frames of a packet.
+func (s *session) handleFrames(
+	packet *unpackedPacket,
+	packetSize protocol.ByteCount, // only for logging
+	rcvPath *path,
+) (isAckEliciting, isNonProbing bool, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []wire.Frame
 	r := bytes.NewReader(packet.data)
-	var isAckEliciting bool
 	for {
 		frame, err := s.frameParser.ParseNext(r, packet.encryptionLevel)
 		if err != nil {
-			return err
+			return false, false, err
 		}
 		if frame == nil {
 			break
@@ -1174,11 +1546,14 @@ func (s *session) handleUnpackedPacket(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !isProbingFrame(frame) {
+			isNonProbing = true
+		}
 		// Only process frames now if we're not logging.
 		// If we're logging, we need to make sure that the packet_received event is logged first.
 		if s.tracer == nil {
-			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID); err != nil {
-				return err
+			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID, rcvPath); err != nil {
+				return false, false, err
 			}
 		} else {
 			frames = append(frames, frame)
@@ -1192,16 +1567,15 @@ func (s *session) handleUnpackedPacket(
 		}
 		s.tracer.ReceivedPacket(packet.hdr, packetSize, fs)
 		for _, frame := range frames {
-			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID); err != nil {
-				return err
+			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID, rcvPath); err != nil {
+				return false, false, err
 			}
 		}
 	}
-
-	return s.receivedPacketHandler.ReceivedPacket(packet.packetNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
+	return isAckEliciting, isNonProbing, nil
 }
 
-func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, destConnID protocol.ConnectionID) error {
+func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, destConnID protocol.ConnectionID, rcvPath *path) error {
 	var err error
 	wire.LogFrame(s.logger, f, false)
 	switch frame := f.(type) {
@@ -1228,10 +1602,9 @@ func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, d
 		err = s.handleStopSendingFrame(frame)
 	case *wire.PingFrame:
 	case *wire.PathChallengeFrame:
-		s.handlePathChallengeFrame(frame)
+		s.handlePathChallengeFrame(frame, rcvPath)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1242,6 +1615,12 @@ func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, d
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAckFrame()
+	case *wire.AckMPFrame, *wire.PathAbandonFrame, *wire.PathStatusFrame:
+		err = s.handleMultipathFrame(frame)
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1350,10 +1729,269 @@ func (s *session) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	return nil
 }
 
-func (s *session) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
+func (s *session) handlePathChallengeFrame(frame *wire.PathChallengeFrame, rcvPath *path) {
+	// The PATH_RESPONSE has to be sent on the path that the PATH_CHALLENGE was received on.
+	if rcvPath != nil {
+		rcvPath.AddResponse(frame.Data)
+		return
+	}
 	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
 }
 
+func (s *session) handlePathResponseFrame(frame *wire.PathResponseFrame) {
+	if p := s.getPathWithChallenge(frame.Data); p != nil {
+		s.pathValidated(p)
+		return
+	}
+	// PATH_RESPONSE frames might arrive after the path was validated or abandoned,
+	// e.g. if we sent multiple PATH_CHALLENGEs on the path.
+	if s.probingPath == nil || !s.probingPath.HasChallenge(frame.Data) {
+		s.logger.Debugf("Ignoring PATH_RESPONSE frame that doesn't correspond to a PATH_CHALLENGE")
+		return
+	}
+	s.migrateToPath(s.probingPath)
+}
+
+func (s *session) startProbingPath(p *path) {
+	if !s.handshakeConfirmed {
+		p.result <- errors.New("can't migrate before the handshake is confirmed")
+		return
+	}
+	if s.peerParams.DisableActiveMigration {
+		p.result <- errors.New("the peer disabled active migration")
+		return
+	}
+	if s.probingPath != nil {
+		p.result <- errors.New("already migrating to a new path")
+		return
+	}
+	// The packet number space of the initial path is identified by the connection ID used on that path.
+	if s.multipath {
+		p.result <- errors.New("can't migrate a multipath connection")
+		return
+	}
+	connID, ok := s.connIDManager.GetConnIDForPath()
+	if !ok {
+		p.result <- errors.New("no unused connection ID available")
+		return
+	}
+	p.connID = connID
+	if err := s.addRunnerForPath(p); err != nil {
+		s.connIDManager.RetireConnIDForPath(connID)
+		p.result <- err
+		return
+	}
+	p.deadline = s.pathValidationDeadline(time.Now())
+	s.probingPath = p
+}
+
+// addRunnerForPath registers our connection IDs with the packet conn of a new path,
+// such that packets received on that packet conn are passed to this session.
+func (s *session) addRunnerForPath(p *path) error {
+	// We might be migrating back to a packet conn that we already used before.
+	if s.runners.Has(p.runner) {
+		return nil
+	}
+	connIDs := s.connIDGenerator.ActiveConnIDs()
+	for i, c := range connIDs {
+		if !p.runner.Add(c, s) {
+			for _, added := range connIDs[:i] {
+				p.runner.Remove(added)
+			}
+			return errors.New("packet conn already used by another session")
+		}
+	}
+	s.runners.AddRunner(p.runner)
+	p.addedRunner = true
+	return nil
+}
+
+// migrateToPreferredAddress starts validating the server's preferred address, see RFC 9000, Section 9.6.
+// The path is validated like any other path, and the session migrates to it if validation succeeds.
+// Migrating to the preferred address is allowed even if the server disabled active migration.
+func (s *session) migrateToPreferredAddress() {
+	if s.probingPath != nil {
+		s.logger.Debugf("Not migrating to the server's preferred address, since we're already migrating to a new path.")
+		return
+	}
+	remoteAddr := s.preferredRemoteAddr(s.peerParams.PreferredAddress)
+	if remoteAddr == nil {
+		s.logger.Debugf("Not migrating to the server's preferred address, since it doesn't contain an address of the same address family.")
+		return
+	}
+	connID, ok := s.connIDManager.GetConnIDForPath()
+	if !ok {
+		s.logger.Debugf("Not migrating to the server's preferred address, since no unused connection ID is available.")
+		return
+	}
+	s.logger.Debugf("Migrating to the server's preferred address %s.", remoteAddr)
+	p := newPath(context.Background(), s.conn.WithRemoteAddr(remoteAddr, nil), nil)
+	p.connID = connID
+	p.deadline = s.pathValidationDeadline(time.Now())
+	s.probingPath = p
+}
+
+// preferredRemoteAddr returns the address from the preferred_address transport parameter
+// that has the same address family as the current remote address.
+// It returns nil if the server didn't send an address of that family.
+func (s *session) preferredRemoteAddr(pa *wire.PreferredAddress) *net.UDPAddr {
+	udpAddr, ok := s.conn.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	if udpAddr.IP.To4() != nil {
+		if pa.IPv4Port == 0 || pa.IPv4.IsUnspecified() {
+			return nil
+		}
+		return &net.UDPAddr{IP: pa.IPv4, Port: int(pa.IPv4Port)}
+	}
+	if pa.IPv6Port == 0 || pa.IPv6.IsUnspecified() {
+		return nil
+	}
+	return &net.UDPAddr{IP: pa.IPv6, Port: int(pa.IPv6Port)}
+}
+
+// pathValidationDeadline returns the time when validation of a new path is abandoned, see RFC 9000, Section 8.2.4.
+func (s *session) pathValidationDeadline(now time.Time) time.Time {
+	return now.Add(utils.MaxDuration(3*s.rttStats.PTO(true), protocol.MinPathValidationTimeout))
+}
+
+func (s *session) startValidatingPeerAddress(p *path, now time.Time) {
+	if s.probingPath != nil {
+		s.abandonPath(errors.New("peer address changed"))
+	}
+	s.logger.Debugf("Peer address changed to %s. Validating the new path.", p.conn.RemoteAddr())
+	// When using multipath, the initial path keeps using its connection ID,
+	// since it identifies the packet number space of the path.
+	if !s.multipath {
+		if connID, ok := s.connIDManager.GetConnIDForPath(); ok {
+			p.connID = connID
+			p.reuseConnID = false
+		}
+	}
+	p.deadline = s.pathValidationDeadline(now)
+	s.probingPath = p
+}
+
+func (s *session) maybeSendPathProbe(now time.Time) error {
+	p := s.probingPath
+	if err := p.ctx.Err(); err != nil {
+		s.abandonPath(err)
+		return nil
+	}
+	if !now.Before(p.deadline) {
+		s.abandonPath(errors.New("path validation timed out"))
+		return nil
+	}
+	var sendChallenge bool
+	if !now.Before(p.nextProbe) {
+		sendChallenge = true
+		// back off exponentially, like we do for PTO probe packets
+		p.nextProbe = now.Add(s.rttStats.PTO(true) << p.NumProbes())
+	}
+	if !sendChallenge && len(p.responses) == 0 {
+		return nil
+	}
+	return s.sendPathProbe(p, sendChallenge, now)
+}
+
+// sendPathProbe sends a packet on a path that is not the current path.
+// It contains PATH_RESPONSE frames for the PATH_CHALLENGEs received on this path,
+// and, if sendChallenge is set, a new PATH_CHALLENGE.
+func (s *session) sendPathProbe(p *path, sendChallenge bool, now time.Time) error {
+	frames, challenge, err := p.ProbeFrames(sendChallenge)
+	if err != nil {
+		return err
+	}
+	connID := p.connID.ConnectionID
+	if p.reuseConnID {
+		connID = s.connIDManager.Get()
+	}
+	packet, err := s.packer.PackPathProbePacket(connID, frames, p.SendBudget())
+	if err != nil {
+		return err
+	}
+	if packet == nil {
+		s.logger.Debugf("Not sending path probe packet to %s. Blocked by the anti-amplification limit.", p.conn.RemoteAddr())
+		return nil
+	}
+	p.responses = nil
+	if sendChallenge {
+		p.AddChallenge(challenge)
+	}
+	s.logPacket(packet)
+	ackhandlerPacket := packet.ToAckHandlerPacket(now, s.retransmissionQueue)
+	s.sentPacketHandler.SentPacket(ackhandlerPacket)
+	p.SentBytes(protocol.ByteCount(len(packet.buffer.Data)))
+	err = p.conn.Write(packet.buffer.Data, 0, protocol.ECNNon)
+	packet.buffer.Release()
+	if err != nil {
+		if p == s.probingPath {
+			s.abandonPath(err)
+		} else {
+			s.logger.Debugf("Sending path probe packet to %s failed: %s", p.conn.RemoteAddr(), err)
+		}
+	}
+	return nil
+}
+
+func (s *session) migrateToPath(p *path) {
+	s.probingPath = nil
+	s.logger.Infof("Migrating connection %s to %s.", s.logID, p)
+	if !p.reuseConnID {
+		s.connIDManager.SwitchToConnID(p.connID)
+	}
+	oldRemoteAddr := s.conn.RemoteAddr()
+	samePacketConn := p.rcvConn == s.rcvConn
+	if p.rcvConn != nil {
+		s.rcvConn = p.rcvConn
+	}
+	s.connMutex.Lock()
+	s.conn = p.conn
+	s.connMutex.Unlock()
+	// Packets that were already queued are still sent on the old path.
+	// Closing the send queue blocks until they are sent, so don't block the run loop.
+	go s.sendQueue.Close()
+	s.sendQueue = newSendQueue(p.conn)
+	go s.runSendQueue(s.sendQueue)
+
+	// If only the client's port changed, this is most likely the result of NAT rebinding.
+	// The network path stays the same, so there's no need to reset the congestion controller,
+	// the RTT estimate and the MTU, see RFC 9000, Section 9.4.
+	if s.perspective == protocol.PerspectiveServer && samePacketConn && isPortChange(oldRemoteAddr, p.conn.RemoteAddr()) {
+		p.Done(nil)
+		return
+	}
+	// The congestion controller, the RTT estimate and the MTU only apply to the old path.
+	maxPacketSize := getMaxPacketSize(p.conn.RemoteAddr())
+	s.sentPacketHandler.MigratedPath(maxPacketSize)
+	if s.peerParams.MaxUDPPayloadSize != 0 {
+		maxPacketSize = utils.MinByteCount(maxPacketSize, s.peerParams.MaxUDPPayloadSize)
+	}
+	s.packer.SetMaxPacketSize(maxPacketSize)
+	s.updateMaxDatagramSize()
+	if !s.config.DisablePathMTUDiscovery {
+		s.startMTUDiscovery()
+	}
+	p.Done(nil)
+}
+
+func (s *session) abandonPath(err error) {
+	p := s.probingPath
+	s.probingPath = nil
+	s.logger.Debugf("Abandoning path %s: %s", p, err)
+	if !p.reuseConnID {
+		s.connIDManager.RetireConnIDForPath(p.connID)
+	}
+	if p.addedRunner {
+		for _, c := range s.connIDGenerator.ActiveConnIDs() {
+			p.runner.Remove(c)
+		}
+		s.runners.RemoveRunner(p.runner)
+	}
+	p.Done(err)
+}
+
 func (s *session) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1399,9 +2037,20 @@ func (s *session) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encrypt
 	if s.perspective == protocol.PerspectiveClient && !s.handshakeConfirmed {
 		s.handleHandshakeConfirmed()
 	}
+	s.maybeQueueAckFrequencyFrame()
 	return s.cryptoStreamHandler.SetLargest1RTTAcked(frame.LargestAcked())
 }
 
+func (s *session) maybeQueueAckFrequencyFrame() {
+	if s.ackFrequencyManager == nil {
+		return
+	}
+	cwnd := s.sentPacketHandler.GetStats().CongestionWindow
+	if f := s.ackFrequencyManager.GetFrame(cwnd, s.packer.MaxPacketSize(), time.Now()); f != nil {
+		s.queueControlFrame(f)
+	}
+}
+
 func (s *session) handleDatagramFrame(f *wire.DatagramFrame) error {
 	if f.Length(s.version) > protocol.MaxDatagramFrameSize {
 		return &qerr.TransportError{
@@ -1456,6 +2105,13 @@ func (s *session) shutdown() {
 	<-s.ctx.Done()
 }
 
+// closeWithTransportError closes the connection with a transport error.
+// It waits until the run loop has stopped before returning
+func (s *session) closeWithTransportError(code TransportErrorCode) {
+	s.closeLocal(&qerr.TransportError{ErrorCode: code})
+	<-s.ctx.Done()
+}
+
 func (s *session) CloseWithError(code ApplicationErrorCode, desc string) error {
 	s.closeLocal(&qerr.ApplicationError{
 		ErrorCode:    code,
@@ -1551,6 +2207,7 @@ func (s *session) restoreTransportParameters(params *wire.TransportParameters) {
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.streamsMap.UpdateLimits(params)
+	s.updateMaxDatagramSize()
 }
 
 func (s *session) handleTransportParameters(params *wire.TransportParameters) {
@@ -1584,6 +2241,19 @@ func (s *session) checkTransportParameters(params *wire.TransportParameters) err
 		return fmt.Errorf("expected initial_source_connection_id to equal %s, is %s", s.handshakeDestConnID, params.InitialSourceConnectionID)
 	}
 
+	// check the version_information
+	// The client's chosen version is the version of its first Initial,
+	// the server's chosen version is the version it switched the connection to.
+	if params.VersionInformation != nil {
+		expectedVersion := s.version
+		if s.perspective == protocol.PerspectiveServer {
+			expectedVersion = s.initialVersion
+		}
+		if params.VersionInformation.ChosenVersion != expectedVersion {
+			return fmt.Errorf("expected chosen version to equal %s, is %s", expectedVersion, params.VersionInformation.ChosenVersion)
+		}
+	}
+
 	if s.perspective == protocol.PerspectiveServer {
 		return nil
 	}
@@ -1611,21 +2281,33 @@ func (s *session) applyTransportParameters() {
 	s.keepAliveInterval = utils.MinDuration(s.idleTimeout/2, protocol.MaxKeepAliveInterval)
 	s.streamsMap.UpdateLimits(params)
 	s.packer.HandleTransportParameters(params)
+	s.updateMaxDatagramSize()
 	s.frameParser.SetAckDelayExponent(params.AckDelayExponent)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.rttStats.SetMaxAckDelay(params.MaxAckDelay)
+	if s.config.EnableAckFrequency && params.MinAckDelay != nil {
+		s.ackFrequencyManager = newAckFrequencyManager(s.rttStats, params.MaxAckDelay)
+	}
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
+	// The client migrates to the preferred_address once the handshake is confirmed.
+	// Until then, the connection ID can be used like any other connection ID.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
+	// Multipath identifies paths by connection IDs, so it can't be used with zero-length connection IDs.
+	if s.config.EnableMultipath && params.EnableMultipath && s.srcConnIDLen > 0 && s.handshakeDestConnID.Len() > 0 {
+		s.multipath = true
+		s.connIDManager.DisableRotation()
+	}
 }
 
 func (s *session) sendPackets() error {
+	if s.sendsOnMultiplePaths() {
+		return s.sendPacketsMultipath()
+	}
 	s.pacingDeadline = time.Time{}
 
 	var sentPacket bool // only used in for packets sent in send mode SendAny
@@ -1673,9 +2355,13 @@ func (s *session) sendPackets() error {
 			}
 		case ackhandler.SendAny:
 			sent, err := s.sendPacket()
-			if err != nil || !sent {
+			if err != nil {
 				return err
 			}
+			if !sent {
+				s.sentPacketHandler.OnApplicationLimited()
+				return nil
+			}
 			sentPacket = true
 		default:
 			return fmt.Errorf("BUG: invalid send mode %d", sendMode)
@@ -1765,7 +2451,8 @@ func (s *session) sendPacket() (bool, error) {
 			s.sentPacketHandler.SentPacket(p.ToAckHandlerPacket(now, s.retransmissionQueue))
 		}
 		s.connIDManager.SentPacket()
-		s.sendQueue.Send(packet.buffer)
+		// Packets are only ECN-marked once the handshake is confirmed, see sentPacketHandler.SentPacket.
+		s.sendQueue.Send(packet.buffer, 0, protocol.ECNNon)
 		return true, nil
 	}
 	if !s.config.DisablePathMTUDiscovery && s.mtuDiscoverer.ShouldSendProbe(now) {
@@ -1776,6 +2463,9 @@ func (s *session) sendPacket() (bool, error) {
 		s.sendPackedPacket(packet, now)
 		return true, nil
 	}
+	if s.conn.capabilities().GSO {
+		return s.sendPacketBatch(now)
+	}
 	packet, err := s.packer.PackPacket()
 	if err != nil || packet == nil {
 		return false, err
@@ -1784,14 +2474,66 @@ func (s *session) sendPacket() (bool, error) {
 	return true, nil
 }
 
+// sendPacketBatch packs multiple packets into a single buffer, which is then sent using a single syscall (GSO).
+// All packets have the maximum packet size, except for the last one, which may be smaller.
+// Packets are appended as long as the congestion controller and the pacer allow it.
+func (s *session) sendPacketBatch(now time.Time) (bool, error) {
+	buffer := getLargePacketBuffer()
+	maxSize := s.packer.MaxPacketSize()
+	var numPackets int
+	var ecn protocol.ECN
+	for {
+		packet, err := s.packer.AppendPacket(buffer)
+		if err != nil {
+			return false, err
+		}
+		if packet == nil {
+			break
+		}
+		packetECN := s.registerPackedPacket(packet, s.sentPacketHandler, now)
+		if numPackets == 0 {
+			ecn = packetECN
+		}
+		numPackets++
+		// Only append another packet if
+		// 1. the last packet had the maximum size (only the last packet of a batch may be smaller),
+		// 2. there's enough space left in the buffer,
+		// 3. the congestion controller and the pacer allow sending another packet, and
+		// 4. the next packet would be sent with the same ECN marking.
+		if packet.length != maxSize || buffer.Len()+maxSize > protocol.MaxLargePacketBufferSize ||
+			s.sentPacketHandler.SendMode() != ackhandler.SendAny || !s.sentPacketHandler.HasPacingBudget() ||
+			s.sentPacketHandler.ECNMode() != ecn {
+			break
+		}
+	}
+	if numPackets == 0 {
+		buffer.Release()
+		return false, nil
+	}
+	var gsoSize uint16
+	if numPackets > 1 {
+		gsoSize = uint16(maxSize)
+	}
+	s.sendQueue.Send(buffer, gsoSize, ecn)
+	return true, nil
+}
+
 func (s *session) sendPackedPacket(packet *packedPacket, now time.Time) {
+	ecn := s.registerPackedPacket(packet, s.sentPacketHandler, now)
+	s.sendQueue.Send(packet.buffer, 0, ecn)
+}
+
+// registerPackedPacket logs a packet and passes it to the sent packet handler of the path it is sent on.
+// It returns the ECN codepoint that the packet needs to be sent with.
+func (s *session) registerPackedPacket(packet *packedPacket, sph ackhandler.SentPacketHandler, now time.Time) protocol.ECN {
 	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && packet.IsAckEliciting() {
 		s.firstAckElicitingPacketAfterIdleSentTime = now
 	}
 	s.logPacket(packet)
-	s.sentPacketHandler.SentPacket(packet.ToAckHandlerPacket(now, s.retransmissionQueue))
+	p := packet.ToAckHandlerPacket(now, s.retransmissionQueue)
+	sph.SentPacket(p)
 	s.connIDManager.SentPacket()
-	s.sendQueue.Send(packet.buffer)
+	return p.ECN
 }
 
 func (s *session) sendConnectionClose(e error) ([]byte, error) {
@@ -1813,7 +2555,7 @@ func (s *session) sendConnectionClose(e error) ([]byte, error) {
 		return nil, err
 	}
 	s.logCoalescedPacket(packet)
-	return packet.buffer.Data, s.conn.Write(packet.buffer.Data)
+	return packet.buffer.Data, s.conn.Write(packet.buffer.Data, 0, protocol.ECNNon)
 }
 
 func (s *session) logPacketContents(p *packetContents) {
@@ -1854,7 +2596,7 @@ func (s *session) logCoalescedPacket(packet *coalescedPacket) {
 
 func (s *session) logPacket(packet *packedPacket) {
 	if s.logger.Debug() {
-		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.buffer.Len(), s.logID, packet.EncryptionLevel())
+		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.length, s.logID, packet.EncryptionLevel())
 	}
 	s.logPacketContents(packet.packetContents)
 }
@@ -1947,8 +2689,8 @@ func (s *session) onHasConnectionWindowUpdate() {
 	s.scheduleSending()
 }
 
-func (s *session) onHasStreamData(id protocol.StreamID) {
-	s.framer.AddActiveStream(id)
+func (s *session) onHasStreamData(id protocol.StreamID, prio streamPriority) {
+	s.framer.AddActiveStream(id, prio)
 	s.scheduleSending()
 }
 
@@ -1959,13 +2701,50 @@ func (s *session) onStreamCompleted(id protocol.StreamID) {
 }
 
 func (s *session) SendMessage(p []byte) error {
-	f := &wire.DatagramFrame{DataLenPresent: true}
-	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
-		return errors.New("message too large")
+	return s.SendMessages([][]byte{p})
+}
+
+func (s *session) SendMessageWithCallback(p []byte, callback func(acked bool)) error {
+	frames, err := s.newDatagramFrames([][]byte{p})
+	if err != nil {
+		return err
+	}
+	return s.datagramQueue.AddWithCallback(frames[0], callback)
+}
+
+func (s *session) SendMessages(msgs [][]byte) error {
+	frames, err := s.newDatagramFrames(msgs)
+	if err != nil {
+		return err
+	}
+	return s.datagramQueue.Add(frames...)
+}
+
+func (s *session) newDatagramFrames(msgs [][]byte) ([]*wire.DatagramFrame, error) {
+	if s.datagramQueue == nil {
+		return nil, errors.New("datagram support disabled")
 	}
-	f.Data = make([]byte, len(p))
-	copy(f.Data, p)
-	return s.datagramQueue.AddAndWait(f)
+	maxDataLen := s.datagramQueue.MaxDataLen()
+	if maxDataLen == 0 {
+		return nil, errors.New("datagram support not negotiated")
+	}
+	frames := make([]*wire.DatagramFrame, 0, len(msgs))
+	for _, p := range msgs {
+		if protocol.ByteCount(len(p)) > maxDataLen {
+			return nil, errors.New("message too large")
+		}
+		f := &wire.DatagramFrame{DataLenPresent: true, Data: make([]byte, len(p))}
+		copy(f.Data, p)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+func (s *session) MaxDatagramSize() int {
+	if s.datagramQueue == nil {
+		return 0
+	}
+	return int(s.datagramQueue.MaxDataLen())
 }
 
 func (s *session) ReceiveMessage() ([]byte, error) {
@@ -1973,13 +2752,63 @@ func (s *session) ReceiveMessage() ([]byte, error) {
 }
 
 func (s *session) LocalAddr() net.Addr {
+	s.connMutex.RLock()
+	defer s.connMutex.RUnlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *session) RemoteAddr() net.Addr {
+	s.connMutex.RLock()
+	defer s.connMutex.RUnlock()
 	return s.conn.RemoteAddr()
 }
 
+func (s *session) MigrateTo(ctx context.Context, conn net.PacketConn) error {
+	if s.perspective == protocol.PerspectiveServer {
+		return errors.New("only clients can migrate connections")
+	}
+	runner, err := getMultiplexer().AddConn(conn, s.srcConnIDLen, s.config.StatelessResetKey, s.config.Tracer)
+	if err != nil {
+		return err
+	}
+	p := newPath(ctx, newSendPconn(conn, s.RemoteAddr()), runner)
+	select {
+	case s.pathProbes <- p:
+	case <-ctx.Done():
+		runner.CloseIfUnused()
+		return ctx.Err()
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return errSessionClosed
+	}
+	select {
+	case err := <-p.result:
+		if err != nil {
+			runner.CloseIfUnused()
+		}
+		return err
+	case <-ctx.Done():
+		// make sure the run loop notices that the path was abandoned
+		s.scheduleSending()
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return errSessionClosed
+	}
+	// Wait for the run loop to abandon the path.
+	// Path validation might have succeeded in the meantime.
+	select {
+	case err := <-p.result:
+		if err != nil {
+			runner.CloseIfUnused()
+			return ctx.Err()
+		}
+		return nil
+	case <-s.ctx.Done():
+		runner.CloseIfUnused()
+		return errSessionClosed
+	}
+}
+
 func (s *session) Perspective() Perspective {
 	return s.perspective
 }
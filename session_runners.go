@@ -0,0 +1,88 @@
+package quic
+
+import "github.com/lucas-clemente/quic-go/internal/protocol"
+
+// sessionRunners passes calls on to all the sessionRunners a session is registered with.
+// A session is registered with more than one sessionRunner when it receives packets
+// on more than one packet conn, e.g. when migrating to a new path.
+type sessionRunners struct {
+	runners []sessionRunner
+}
+
+var _ sessionRunner = &sessionRunners{}
+
+func newSessionRunners(runner sessionRunner) *sessionRunners {
+	return &sessionRunners{runners: []sessionRunner{runner}}
+}
+
+// AddRunner adds a sessionRunner.
+// It is the caller's responsibility to register the currently active connection IDs and stateless reset tokens.
+func (r *sessionRunners) AddRunner(runner sessionRunner) {
+	r.runners = append(r.runners, runner)
+}
+
+// Has says if a sessionRunner was already added.
+func (r *sessionRunners) Has(runner sessionRunner) bool {
+	for _, rr := range r.runners {
+		if rr == runner {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveRunner removes a sessionRunner.
+// It is the caller's responsibility to remove the currently active connection IDs and stateless reset tokens.
+func (r *sessionRunners) RemoveRunner(runner sessionRunner) {
+	for i, rr := range r.runners {
+		if rr == runner {
+			r.runners = append(r.runners[:i], r.runners[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *sessionRunners) Add(connID protocol.ConnectionID, handler packetHandler) bool {
+	added := true
+	for _, runner := range r.runners {
+		if !runner.Add(connID, handler) {
+			added = false
+		}
+	}
+	return added
+}
+
+// GetStatelessResetToken returns the stateless reset token generated by the first sessionRunner.
+func (r *sessionRunners) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	return r.runners[0].GetStatelessResetToken(connID)
+}
+
+func (r *sessionRunners) Retire(connID protocol.ConnectionID) {
+	for _, runner := range r.runners {
+		runner.Retire(connID)
+	}
+}
+
+func (r *sessionRunners) Remove(connID protocol.ConnectionID) {
+	for _, runner := range r.runners {
+		runner.Remove(connID)
+	}
+}
+
+func (r *sessionRunners) ReplaceWithClosed(connID protocol.ConnectionID, handler packetHandler) {
+	for _, runner := range r.runners {
+		runner.ReplaceWithClosed(connID, handler)
+	}
+}
+
+func (r *sessionRunners) AddResetToken(token protocol.StatelessResetToken, handler packetHandler) {
+	for _, runner := range r.runners {
+		runner.AddResetToken(token, handler)
+	}
+}
+
+func (r *sessionRunners) RemoveResetToken(token protocol.StatelessResetToken) {
+	for _, runner := range r.runners {
+		runner.RemoveResetToken(token)
+	}
+}
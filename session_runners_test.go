@@ -0,0 +1,72 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session Runners", func() {
+	var (
+		r1, r2  *MockSessionRunner
+		runners *sessionRunners
+	)
+	connID := protocol.ConnectionID{1, 2, 3, 4}
+	token := protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	BeforeEach(func() {
+		r1 = NewMockSessionRunner(mockCtrl)
+		r2 = NewMockSessionRunner(mockCtrl)
+		runners = newSessionRunners(r1)
+		runners.AddRunner(r2)
+	})
+
+	It("adds connection IDs to all runners", func() {
+		handler := NewMockPacketHandler(mockCtrl)
+		r1.EXPECT().Add(connID, handler).Return(true)
+		r2.EXPECT().Add(connID, handler).Return(true)
+		Expect(runners.Add(connID, handler)).To(BeTrue())
+	})
+
+	It("reports if a connection ID couldn't be added to one of the runners", func() {
+		handler := NewMockPacketHandler(mockCtrl)
+		r1.EXPECT().Add(connID, handler).Return(true)
+		r2.EXPECT().Add(connID, handler).Return(false)
+		Expect(runners.Add(connID, handler)).To(BeFalse())
+	})
+
+	It("uses the first runner to generate stateless reset tokens", func() {
+		r1.EXPECT().GetStatelessResetToken(connID).Return(token)
+		Expect(runners.GetStatelessResetToken(connID)).To(Equal(token))
+	})
+
+	It("retires, removes and replaces connection IDs for all runners", func() {
+		handler := NewMockPacketHandler(mockCtrl)
+		r1.EXPECT().Retire(connID)
+		r2.EXPECT().Retire(connID)
+		runners.Retire(connID)
+		r1.EXPECT().Remove(connID)
+		r2.EXPECT().Remove(connID)
+		runners.Remove(connID)
+		r1.EXPECT().ReplaceWithClosed(connID, handler)
+		r2.EXPECT().ReplaceWithClosed(connID, handler)
+		runners.ReplaceWithClosed(connID, handler)
+	})
+
+	It("adds and removes stateless reset tokens for all runners", func() {
+		handler := NewMockPacketHandler(mockCtrl)
+		r1.EXPECT().AddResetToken(token, handler)
+		r2.EXPECT().AddResetToken(token, handler)
+		runners.AddResetToken(token, handler)
+		r1.EXPECT().RemoveResetToken(token)
+		r2.EXPECT().RemoveResetToken(token)
+		runners.RemoveResetToken(token)
+	})
+
+	It("removes runners", func() {
+		runners.RemoveRunner(r2)
+		r1.EXPECT().Remove(connID)
+		runners.Remove(connID)
+	})
+})
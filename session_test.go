@@ -28,6 +28,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -86,6 +87,7 @@ var _ = Describe("Session", func() {
 		sessionRunner = NewMockSessionRunner(mockCtrl)
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+		mconn.EXPECT().capabilities().AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
 		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
 		Expect(err).ToNot(HaveOccurred())
@@ -126,6 +128,80 @@ var _ = Describe("Session", func() {
 		Eventually(areSessionsRunning).Should(BeFalse())
 	})
 
+	It("sends the preferred_address transport parameter", func() {
+		var params *wire.TransportParameters
+		tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+		tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+		tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		resetToken := protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+		sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).Return(resetToken)
+		s := newSession(
+			mconn,
+			sessionRunner,
+			nil,
+			nil,
+			clientDestConnID,
+			destConnID,
+			srcConnID,
+			protocol.StatelessResetToken{},
+			populateServerConfig(&Config{PreferredAddressIPv4: &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1234}}),
+			nil, // tls.Config
+			tokenGenerator,
+			false,
+			tracer,
+			1234,
+			utils.DefaultLogger,
+			protocol.VersionTLS,
+		).(*session)
+		Expect(params.PreferredAddress).ToNot(BeNil())
+		Expect(params.PreferredAddress.IPv4).To(Equal(net.IPv4(192, 168, 0, 1).To4()))
+		Expect(params.PreferredAddress.IPv4Port).To(BeEquivalentTo(1234))
+		Expect(params.PreferredAddress.IPv6).To(Equal(net.IPv6zero))
+		Expect(params.PreferredAddress.IPv6Port).To(BeZero())
+		Expect(params.PreferredAddress.StatelessResetToken).To(Equal(resetToken))
+		Expect(s.connIDGenerator.ActiveConnIDs()).To(ContainElement(params.PreferredAddress.ConnectionID))
+		// the connection ID is registered once the client's transport parameters are received
+		sessionRunner.EXPECT().Add(params.PreferredAddress.ConnectionID, s)
+		Expect(s.connIDGenerator.SetMaxActiveConnIDs(1)).To(Succeed())
+	})
+
+	DescribeTable(
+		"sending the disable_active_migration transport parameter",
+		func(disableActiveMigration bool) {
+			var params *wire.TransportParameters
+			tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+			tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+			tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			newSession(
+				mconn,
+				sessionRunner,
+				nil,
+				nil,
+				clientDestConnID,
+				destConnID,
+				srcConnID,
+				protocol.StatelessResetToken{},
+				populateServerConfig(&Config{DisableActiveMigration: disableActiveMigration}),
+				nil, // tls.Config
+				tokenGenerator,
+				false,
+				tracer,
+				1234,
+				utils.DefaultLogger,
+				protocol.VersionTLS,
+			)
+			Expect(params.DisableActiveMigration).To(Equal(disableActiveMigration))
+		},
+		Entry("active migration enabled", false),
+		Entry("active migration disabled", true),
+	)
+
 	Context("frame handling", func() {
 		Context("handling STREAM frames", func() {
 			It("passes STREAM frames to the stream", func() {
@@ -169,6 +245,26 @@ var _ = Describe("Session", func() {
 				err := sess.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("queues an ACK_FREQUENCY frame when the congestion window grows", func() {
+				sess.ackFrequencyManager = newAckFrequencyManager(sess.rttStats, 20*time.Millisecond)
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(f, protocol.Encryption1RTT, gomock.Any()).Return(true, nil)
+				sph.EXPECT().GetStats().Return(ackhandler.Stats{CongestionWindow: 100 * 1000})
+				sess.sentPacketHandler = sph
+				packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1000))
+				cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
+				Expect(sess.handleAckFrame(f, protocol.Encryption1RTT)).To(Succeed())
+				frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(&wire.AckFrequencyFrame{
+					SequenceNumber:        0,
+					AckElicitingThreshold: 24,
+					RequestMaxAckDelay:    20 * time.Millisecond,
+					ReorderingThreshold:   1,
+				}))
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -203,7 +299,7 @@ var _ = Describe("Session", func() {
 				Expect(sess.handleFrame(&wire.ResetStreamFrame{
 					StreamID:  3,
 					ErrorCode: 42,
-				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			})
 		})
 
@@ -237,7 +333,7 @@ var _ = Describe("Session", func() {
 				Expect(sess.handleFrame(&wire.MaxStreamDataFrame{
 					StreamID:          10,
 					MaximumStreamData: 1337,
-				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			})
 		})
 
@@ -270,7 +366,7 @@ var _ = Describe("Session", func() {
 				Expect(sess.handleFrame(&wire.StopSendingFrame{
 					StreamID:  3,
 					ErrorCode: 1337,
-				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			})
 		})
 
@@ -278,23 +374,23 @@ var _ = Describe("Session", func() {
 			Expect(sess.handleFrame(&wire.NewConnectionIDFrame{
 				SequenceNumber: 10,
 				ConnectionID:   protocol.ConnectionID{1, 2, 3, 4},
-			}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			Expect(sess.connIDManager.queue.Back().Value.ConnectionID).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
 		})
 
 		It("handles PING frames", func() {
-			err := sess.handleFrame(&wire.PingFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			err := sess.handleFrame(&wire.PingFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("rejects PATH_RESPONSE frames", func() {
-			err := sess.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})
-			Expect(err).To(MatchError("unexpected PATH_RESPONSE frame"))
+		It("ignores PATH_RESPONSE frames that don't correspond to a PATH_CHALLENGE", func() {
+			err := sess.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
+			Expect(err).ToNot(HaveOccurred())
 		})
 
 		It("handles PATH_CHALLENGE frames", func() {
 			data := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
-			err := sess.handleFrame(&wire.PathChallengeFrame{Data: data}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			err := sess.handleFrame(&wire.PathChallengeFrame{Data: data}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
 			Expect(err).ToNot(HaveOccurred())
 			frames, _ := sess.framer.AppendControlFrames(nil, 1000)
 			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PathResponseFrame{Data: data}}}))
@@ -308,20 +404,161 @@ var _ = Describe("Session", func() {
 		})
 
 		It("handles BLOCKED frames", func() {
-			err := sess.handleFrame(&wire.DataBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			err := sess.handleFrame(&wire.DataBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("handles STREAM_BLOCKED frames", func() {
-			err := sess.handleFrame(&wire.StreamDataBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			err := sess.handleFrame(&wire.StreamDataBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("handles STREAMS_BLOCKED frames", func() {
-			err := sess.handleFrame(&wire.StreamsBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			err := sess.handleFrame(&wire.StreamsBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("handles ACK_FREQUENCY frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			sess.receivedPacketHandler = rph
+			f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 5, RequestMaxAckDelay: 10 * time.Millisecond}
+			testErr := errors.New("ack frequency error")
+			rph.EXPECT().ReceivedAckFrequencyFrame(f).Return(testErr)
+			Expect(sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(MatchError(testErr))
+		})
+
+		It("rejects multipath frames if multipath wasn't negotiated", func() {
+			for _, f := range []wire.Frame{
+				&wire.AckMPFrame{PathIdentifier: 1},
+				&wire.PathAbandonFrame{PathIdentifier: 1},
+				&wire.PathStatusFrame{PathIdentifier: 1, Status: wire.PathStatusStandby},
+			} {
+				err := sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)
+				Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+				Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+			}
+		})
+
+		Context("with multipath", func() {
+			BeforeEach(func() {
+				sess.multipath = true
+			})
+
+			It("handles ACK_MP frames for the initial path", func() {
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sess.sentPacketHandler = sph
+				f := &wire.AckMPFrame{AckFrame: wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}}
+				sph.EXPECT().ReceivedAck(&f.AckFrame, protocol.Encryption1RTT, gomock.Any())
+				Expect(sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+			})
+
+			It("ignores ACK_MP and PATH_ABANDON frames for unknown paths", func() {
+				Expect(sess.handleFrame(&wire.AckMPFrame{PathIdentifier: 7}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+				Expect(sess.handleFrame(&wire.PathAbandonFrame{PathIdentifier: 7}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+			})
+
+			It("handles PATH_STATUS frames for the initial path", func() {
+				Expect(sess.handleFrame(&wire.PathStatusFrame{SequenceNumber: 2, Status: wire.PathStatusStandby}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+				Expect(sess.initialPathPeerStatus.status).To(Equal(PathStatusStandby))
+				// reordered frame
+				Expect(sess.handleFrame(&wire.PathStatusFrame{SequenceNumber: 1, Status: wire.PathStatusAvailable}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+				Expect(sess.initialPathPeerStatus.status).To(Equal(PathStatusStandby))
+				Expect(sess.handleFrame(&wire.PathStatusFrame{SequenceNumber: 3, Status: wire.PathStatusAvailable}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+				Expect(sess.initialPathPeerStatus.status).To(Equal(PathStatusAvailable))
+			})
+
+			Context("sending PTO probes", func() {
+				var (
+					sph       *mockackhandler.MockSentPacketHandler
+					sendQueue *MockSender
+					p         *pathSender
+				)
+
+				BeforeEach(func() {
+					sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					sendQueue = NewMockSender(mockCtrl)
+					sendQueue.EXPECT().WouldBlock().AnyTimes()
+					p = &pathSender{conn: mconn, sentPacketHandler: sph, packer: packer, sendQueue: sendQueue}
+				})
+
+				It("retransmits data sent on the path", func() {
+					sph.EXPECT().GetStats().Return(ackhandler.Stats{PTOCount: 1})
+					gomock.InOrder(
+						sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData).Times(2),
+						sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT).Return(true),
+						packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT).Return(getPacket(10), nil),
+						sph.EXPECT().SentPacket(gomock.Any()),
+						sendQueue.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()),
+						sph.EXPECT().SendMode().Return(ackhandler.SendAny),
+					)
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(sess.maybeSendPTOProbes(p, []*pathSender{p}, time.Now())).To(Succeed())
+				})
+
+				It("sends a PING if there's no data to retransmit", func() {
+					sph.EXPECT().GetStats().Return(ackhandler.Stats{PTOCount: 1})
+					gomock.InOrder(
+						sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData).Times(2),
+						sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT),
+						packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT).DoAndReturn(func(protocol.EncryptionLevel) (*packedPacket, error) {
+							Expect(sess.retransmissionQueue.GetAppDataFrame(protocol.MaxByteCount)).To(Equal(&wire.PingFrame{}))
+							return getPacket(10), nil
+						}),
+						sph.EXPECT().SentPacket(gomock.Any()),
+						sendQueue.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()),
+						sph.EXPECT().SendMode().Return(ackhandler.SendAny),
+					)
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(sess.maybeSendPTOProbes(p, []*pathSender{p}, time.Now())).To(Succeed())
+				})
+
+				It("retransmits data sent on the initial path on the other paths, once it failed", func() {
+					otherSPH := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					otherSPH.EXPECT().GetStats().Return(ackhandler.Stats{})
+					sph.EXPECT().GetStats().Return(ackhandler.Stats{PTOCount: pathFailurePTOCount}).Times(2)
+					packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1234)).AnyTimes()
+					gomock.InOrder(
+						sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData),
+						sph.EXPECT().QueueAllForRetransmission(),
+						sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData),
+						packer.EXPECT().PackPathProbePacket(gomock.Any(), gomock.Any(), protocol.ByteCount(1234)).DoAndReturn(func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+							Expect(frames).To(HaveLen(1))
+							Expect(frames[0].Frame).To(Equal(&wire.PingFrame{}))
+							return getPacket(10), nil
+						}),
+						sph.EXPECT().SentPacket(gomock.Any()),
+						sendQueue.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()),
+						sph.EXPECT().SendMode().Return(ackhandler.SendAny),
+					)
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(sess.maybeSendPTOProbes(p, []*pathSender{p, {sentPacketHandler: otherSPH}}, time.Now())).To(Succeed())
+				})
+
+				It("keeps probing the path if all paths experienced a probe timeout", func() {
+					otherSPH := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					otherSPH.EXPECT().GetStats().Return(ackhandler.Stats{PTOCount: 1})
+					sph.EXPECT().GetStats().Return(ackhandler.Stats{PTOCount: pathFailurePTOCount}).Times(2)
+					gomock.InOrder(
+						sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData).Times(2),
+						sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT).Return(true),
+						packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT).Return(getPacket(10), nil),
+						sph.EXPECT().SentPacket(gomock.Any()),
+						sendQueue.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()),
+						sph.EXPECT().SendMode().Return(ackhandler.SendAny),
+					)
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(sess.maybeSendPTOProbes(p, []*pathSender{p, {sentPacketHandler: otherSPH}}, time.Now())).To(Succeed())
+				})
+			})
+		})
+
+		It("handles IMMEDIATE_ACK frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			sess.receivedPacketHandler = rph
+			rph.EXPECT().ReceivedImmediateAckFrame()
+			Expect(sess.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
+		})
+
 		It("handles CONNECTION_CLOSE frames, with a transport error code", func() {
 			expectedErr := &qerr.TransportError{
 				Remote:       true,
@@ -349,7 +586,7 @@ var _ = Describe("Session", func() {
 			Expect(sess.handleFrame(&wire.ConnectionCloseFrame{
 				ErrorCode:    uint64(qerr.StreamLimitError),
 				ReasonPhrase: "foobar",
-			}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			}, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
 
@@ -382,7 +619,7 @@ var _ = Describe("Session", func() {
 				ReasonPhrase:       "foobar",
 				IsApplicationError: true,
 			}
-			Expect(sess.handleFrame(ccf, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(sess.handleFrame(ccf, protocol.Encryption1RTT, protocol.ConnectionID{}, nil)).To(Succeed())
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
 
@@ -399,6 +636,43 @@ var _ = Describe("Session", func() {
 		Expect(sess.GetVersion()).To(Equal(protocol.VersionNumber(4242)))
 	})
 
+	Context("accepting 0-RTT", func() {
+		It("doesn't restrict 0-RTT if neither Allow0RTT nor AntiReplay is set", func() {
+			Expect(sess.newAllow0RTTFunc()).To(BeNil())
+		})
+
+		It("uses the Allow0RTT callback", func() {
+			var addr net.Addr
+			var allow bool
+			sess.config.Allow0RTT = func(a net.Addr) bool {
+				addr = a
+				return allow
+			}
+			allow0RTT := sess.newAllow0RTTFunc()
+			Expect(allow0RTT([]byte("foobar"), time.Now())).To(BeFalse())
+			Expect(addr).To(Equal(remoteAddr))
+			allow = true
+			Expect(allow0RTT([]byte("foobar"), time.Now())).To(BeTrue())
+		})
+
+		It("uses the AntiReplay", func() {
+			antiReplay := NewMockAntiReplay(mockCtrl)
+			sess.config.AntiReplay = antiReplay
+			issued := time.Now().Add(-time.Second)
+			allow0RTT := sess.newAllow0RTTFunc()
+			antiReplay.EXPECT().Accept([]byte("foobar"), issued).Return(true)
+			Expect(allow0RTT([]byte("foobar"), issued)).To(BeTrue())
+			antiReplay.EXPECT().Accept([]byte("foobar"), issued).Return(false)
+			Expect(allow0RTT([]byte("foobar"), issued)).To(BeFalse())
+		})
+
+		It("doesn't consult the AntiReplay if Allow0RTT rejects 0-RTT", func() {
+			sess.config.Allow0RTT = func(net.Addr) bool { return false }
+			sess.config.AntiReplay = NewMockAntiReplay(mockCtrl)
+			Expect(sess.newAllow0RTTFunc()([]byte("foobar"), time.Now())).To(BeFalse())
+		})
+	})
+
 	Context("closing", func() {
 		var (
 			runErr         chan error
@@ -440,7 +714,7 @@ var _ = Describe("Session", func() {
 				Expect(e.ErrorMessage).To(BeEmpty())
 				return &coalescedPacket{buffer: buffer}, nil
 			})
-			mconn.EXPECT().Write([]byte("connection close"))
+			mconn.EXPECT().Write([]byte("connection close"), gomock.Any(), protocol.ECNNon)
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(gomock.Any()).Do(func(e error) {
 					var appErr *ApplicationError
@@ -461,7 +735,7 @@ var _ = Describe("Session", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sess.shutdown()
@@ -480,7 +754,7 @@ var _ = Describe("Session", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(expectedErr).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -501,7 +775,7 @@ var _ = Describe("Session", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackConnectionClose(expectedErr).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -550,7 +824,7 @@ var _ = Describe("Session", func() {
 				close(returned)
 			}()
 			Consistently(returned).ShouldNot(BeClosed())
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sess.shutdown()
@@ -589,7 +863,7 @@ var _ = Describe("Session", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			sess.handlePacket(&receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       buf.Bytes(),
 			})
@@ -600,12 +874,13 @@ var _ = Describe("Session", func() {
 		It("closes when the sendQueue encounters an error", func() {
 			sess.handshakeConfirmed = true
 			conn := NewMockSendConn(mockCtrl)
-			conn.EXPECT().Write(gomock.Any()).Return(io.ErrClosedPipe).AnyTimes()
+			conn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe).AnyTimes()
 			sess.sendQueue = newSendQueue(conn)
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
 			sph.EXPECT().GetLossDetectionTimeout().Return(time.Now().Add(time.Hour)).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
 			// only expect a single SentPacket() call
 			sph.EXPECT().SentPacket(gomock.Any())
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
@@ -654,9 +929,10 @@ var _ = Describe("Session", func() {
 			buf := &bytes.Buffer{}
 			Expect(extHdr.Write(buf, sess.version)).To(Succeed())
 			return &receivedPacket{
-				data:    append(buf.Bytes(), data...),
-				buffer:  getPacketBuffer(),
-				rcvTime: time.Now(),
+				remoteAddr: remoteAddr,
+				data:       append(buf.Bytes(), data...),
+				buffer:     getPacketBuffer(),
+				rcvTime:    time.Now(),
 			}
 		}
 
@@ -732,6 +1008,52 @@ var _ = Describe("Session", func() {
 			Expect(sess.handlePacketImpl(p)).To(BeFalse())
 		})
 
+		Context("after switching to a compatible version", func() {
+			BeforeEach(func() {
+				Expect(sess.initialVersion).To(Equal(protocol.Version1))
+				packer.EXPECT().SetVersion(protocol.Version2)
+				sess.changeVersion(protocol.Version2)
+			})
+
+			It("accepts 0-RTT packets with the initial version", func() {
+				hdr := &wire.ExtendedHeader{
+					Header: wire.Header{
+						IsLongHeader:     true,
+						Type:             protocol.PacketType0RTT,
+						DestConnectionID: srcConnID,
+						Version:          protocol.Version1,
+						Length:           2 + 6,
+					},
+					PacketNumber:    0x37,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}
+				p := getPacket(hdr, []byte("foobar"))
+				unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(&unpackedPacket{
+					packetNumber:    0x37,
+					encryptionLevel: protocol.Encryption0RTT,
+					hdr:             hdr,
+					data:            []byte{0}, // one PADDING frame
+				}, nil)
+				tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				tracer.EXPECT().ReceivedPacket(gomock.Any(), p.Size(), gomock.Any())
+				Expect(sess.handlePacketImpl(p)).To(BeTrue())
+			})
+
+			It("drops other long header packets with the initial version", func() {
+				p := getPacket(&wire.ExtendedHeader{
+					Header: wire.Header{
+						IsLongHeader:     true,
+						Type:             protocol.PacketTypeHandshake,
+						DestConnectionID: srcConnID,
+						Version:          protocol.Version1,
+					},
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}, nil)
+				tracer.EXPECT().DroppedPacket(logging.PacketTypeHandshake, p.Size(), logging.PacketDropUnexpectedVersion)
+				Expect(sess.handlePacketImpl(p)).To(BeFalse())
+			})
+		})
+
 		It("informs the ReceivedPacketHandler about non-ack-eliciting packets", func() {
 			hdr := &wire.ExtendedHeader{
 				Header:          wire.Header{DestConnectionID: srcConnID},
@@ -836,7 +1158,7 @@ var _ = Describe("Session", func() {
 			// make the go routine return
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sess.closeLocal(errors.New("close"))
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
@@ -880,7 +1202,7 @@ var _ = Describe("Session", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sess.closeLocal(errors.New("close"))
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
@@ -925,7 +1247,7 @@ var _ = Describe("Session", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sess.closeLocal(errors.New("close"))
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
@@ -946,7 +1268,7 @@ var _ = Describe("Session", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			packet := getPacket(&wire.ExtendedHeader{
 				Header:          wire.Header{DestConnectionID: srcConnID},
 				PacketNumberLen: protocol.PacketNumberLen1,
@@ -979,7 +1301,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sess.shutdown()
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
@@ -1000,7 +1322,7 @@ var _ = Describe("Session", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			packet := getPacket(&wire.ExtendedHeader{
 				Header:          wire.Header{DestConnectionID: srcConnID},
 				PacketNumberLen: protocol.PacketNumberLen1,
@@ -1031,7 +1353,7 @@ var _ = Describe("Session", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sess.handlePacket(getPacket(&wire.ExtendedHeader{
@@ -1105,21 +1427,289 @@ var _ = Describe("Session", func() {
 			Expect(sess.undecryptablePackets).To(Equal([]*receivedPacket{packet}))
 		})
 
-		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
+		Context("peer address changes", func() {
+			newRemoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1337}
+			var (
+				newConn *MockSendConn
+				sph     *mockackhandler.MockSentPacketHandler
+				rcvConn connection
+			)
+
+			BeforeEach(func() {
+				rcvConn = nil
+				sess.receivedFirstPacket = true
+				sess.peerParams = &wire.TransportParameters{}
+				sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sess.sentPacketHandler = sph
+				newConn = NewMockSendConn(mockCtrl)
+				newConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				newConn.EXPECT().RemoteAddr().Return(newRemoteAddr).AnyTimes()
+			})
+
+			receivePacket := func(pn protocol.PacketNumber, from net.Addr, frames ...wire.Frame) *receivedPacket {
+				buf := &bytes.Buffer{}
+				for _, f := range frames {
+					Expect(f.Write(buf, sess.version)).To(Succeed())
+				}
+				hdr := &wire.ExtendedHeader{
+					Header:          wire.Header{DestConnectionID: srcConnID},
+					PacketNumber:    pn,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}
+				packet := getPacket(hdr, nil)
+				packet.remoteAddr = from
+				packet.rcvConn = rcvConn
 				unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(&unpackedPacket{
+					packetNumber:    pn,
 					encryptionLevel: protocol.Encryption1RTT,
-					hdr:             &wire.ExtendedHeader{},
-					data:            []byte{0}, // one PADDING frame
+					hdr:             hdr,
+					data:            buf.Bytes(),
 				}, nil)
-				packet := getPacket(&wire.ExtendedHeader{
+				tracer.EXPECT().ReceivedPacket(gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(sess.handlePacketImpl(packet)).To(BeTrue())
+				return packet
+			}
+
+			expectPathProbe := func(connID protocol.ConnectionID, maxSize interface{}) *[]ackhandler.Frame {
+				var frames []ackhandler.Frame
+				packer.EXPECT().PackPathProbePacket(connID, gomock.Any(), maxSize).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+					frames = fs
+					return &packedPacket{
+						buffer: getPacketBuffer(),
+						packetContents: &packetContents{
+							header:            &wire.ExtendedHeader{PacketNumber: 10},
+							frames:            fs,
+							isPathProbePacket: true,
+						},
+					}, nil
+				})
+				sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+					Expect(p.IsPathProbePacket).To(BeTrue())
+				})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				// path probes are never ECN-marked
+				newConn.EXPECT().Write(gomock.Any(), uint16(0), protocol.ECNNon)
+				return &frames
+			}
+
+			It("answers PATH_CHALLENGEs on the path they were received on", func() {
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				frames := expectPathProbe(destConnID, gomock.Any())
+				receivePacket(10, newRemoteAddr, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				Expect(*frames).To(HaveLen(1))
+				Expect((*frames)[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+				// packets containing only probing frames don't trigger a migration
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("validates a new peer address and migrates to it", func() {
+				newConnID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+				Expect(sess.connIDManager.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      1,
+					ConnectionID:        newConnID,
+					StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				})).To(Succeed())
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				packet := receivePacket(10, newRemoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).ToNot(BeNil())
+				Expect(sess.probingPath.conn).To(Equal(newConn))
+				// packets are sent on the old path until the new path is validated
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+
+				frames := expectPathProbe(newConnID, 3*packet.Size())
+				Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+				Expect(*frames).To(HaveLen(1))
+				Expect((*frames)[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+				challenge := (*frames)[0].Frame.(*wire.PathChallengeFrame).Data
+
+				sendQueue := NewMockSender(mockCtrl)
+				sess.sendQueue = sendQueue
+				sendQueueClosed := make(chan struct{})
+				sendQueue.EXPECT().Close().Do(func() { close(sendQueueClosed) })
+				sessionRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, sess)
+				sph.EXPECT().MigratedPath(protocol.ByteCount(protocol.InitialPacketSizeIPv4))
+				packer.EXPECT().SetMaxPacketSize(protocol.ByteCount(protocol.InitialPacketSizeIPv4))
+				Expect(sess.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, srcConnID, nil)).To(Succeed())
+				// the old send queue is closed asynchronously
+				Eventually(sendQueueClosed).Should(BeClosed())
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(newRemoteAddr))
+				Expect(sess.connIDManager.Get()).To(Equal(newConnID))
+				sess.sendQueue.Close()
+			})
+
+			It("keeps the congestion controller state if only the peer's port changed", func() {
+				newRemoteAddr := &net.UDPAddr{IP: remoteAddr.IP, Port: remoteAddr.Port + 1}
+				newConn := NewMockSendConn(mockCtrl)
+				newConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				newConn.EXPECT().RemoteAddr().Return(newRemoteAddr).AnyTimes()
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				receivePacket(10, newRemoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).ToNot(BeNil())
+				// no unused connection ID available, so the current connection ID is used
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+					return &packedPacket{
+						buffer: getPacketBuffer(),
+						packetContents: &packetContents{
+							header: &wire.ExtendedHeader{PacketNumber: 10},
+							frames: fs,
+						},
+					}, nil
+				})
+				sph.EXPECT().SentPacket(gomock.Any())
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				newConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+				Expect(sess.probingPath.NumProbes()).To(Equal(1))
+
+				sendQueue := NewMockSender(mockCtrl)
+				sess.sendQueue = sendQueue
+				sendQueueClosed := make(chan struct{})
+				sendQueue.EXPECT().Close().Do(func() { close(sendQueueClosed) })
+				// don't EXPECT any calls to MigratedPath and SetMaxPacketSize
+				Expect(sess.handleFrame(&wire.PathResponseFrame{Data: sess.probingPath.challenges[0]}, protocol.Encryption1RTT, srcConnID, nil)).To(Succeed())
+				// the old send queue is closed asynchronously
+				Eventually(sendQueueClosed).Should(BeClosed())
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(newRemoteAddr))
+				Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+				sess.sendQueue.Close()
+			})
+
+			It("doesn't send PATH_CHALLENGEs if that would exceed the anti-amplification limit", func() {
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				packet := receivePacket(10, newRemoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).ToNot(BeNil())
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), 3*packet.Size())
+				Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+				Expect(sess.probingPath.NumProbes()).To(BeZero())
+			})
+
+			It("only validates the peer address when receiving a packet with the largest packet number", func() {
+				receivePacket(10, remoteAddr, &wire.PingFrame{})
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				receivePacket(5, newRemoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("validates the path when the client migrates to the preferred address", func() {
+				preferredAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4321}
+				origConn := NewMockPacketConn(mockCtrl)
+				origConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				sess.rcvConn = &basicConn{PacketConn: origConn}
+				preferredConn := NewMockPacketConn(mockCtrl)
+				preferredConn.EXPECT().LocalAddr().Return(preferredAddr).AnyTimes()
+				rcvConn = &basicConn{PacketConn: preferredConn}
+				expectProbe := func() *[]ackhandler.Frame {
+					var frames []ackhandler.Frame
+					packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+						frames = fs
+						return &packedPacket{
+							buffer: getPacketBuffer(),
+							packetContents: &packetContents{
+								header: &wire.ExtendedHeader{PacketNumber: 10},
+								frames: fs,
+							},
+						}, nil
+					})
+					sph.EXPECT().SentPacket(gomock.Any())
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					// the packet is sent from the preferred address, to the client's (unchanged) address
+					preferredConn.EXPECT().WriteTo(gomock.Any(), remoteAddr)
+					return &frames
+				}
+
+				// the client probes the preferred address
+				frames := expectProbe()
+				receivePacket(10, remoteAddr, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				Expect(*frames).To(HaveLen(1))
+				Expect((*frames)[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+				Expect(sess.probingPath).To(BeNil())
+
+				// the client migrates to the preferred address
+				receivePacket(11, remoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).ToNot(BeNil())
+				Expect(sess.probingPath.conn.LocalAddr()).To(Equal(preferredAddr))
+				frames = expectProbe()
+				Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+				Expect(*frames).To(HaveLen(1))
+				challenge := (*frames)[0].Frame.(*wire.PathChallengeFrame).Data
+
+				sendQueue := NewMockSender(mockCtrl)
+				sess.sendQueue = sendQueue
+				sendQueueClosed := make(chan struct{})
+				sendQueue.EXPECT().Close().Do(func() { close(sendQueueClosed) })
+				sph.EXPECT().MigratedPath(protocol.ByteCount(protocol.InitialPacketSizeIPv4))
+				packer.EXPECT().SetMaxPacketSize(protocol.ByteCount(protocol.InitialPacketSizeIPv4))
+				Expect(sess.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, srcConnID, nil)).To(Succeed())
+				// the old send queue is closed asynchronously
+				Eventually(sendQueueClosed).Should(BeClosed())
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.LocalAddr()).To(Equal(preferredAddr))
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+				Expect(sess.rcvConn).To(Equal(rcvConn))
+				sess.sendQueue.Close()
+
+				// packets received on the preferred address now belong to the current path
+				receivePacket(12, remoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).To(BeNil())
+			})
+
+			It("drops packets from a new peer address if active migration is disabled", func() {
+				sess.config.DisableActiveMigration = true
+				hdr := &wire.ExtendedHeader{
 					Header:          wire.Header{DestConnectionID: srcConnID},
-					PacketNumberLen: protocol.PacketNumberLen1,
+					PacketNumber:    10,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}
+				packet := getPacket(hdr, nil)
+				packet.remoteAddr = newRemoteAddr
+				unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(&unpackedPacket{
+					packetNumber:    10,
+					encryptionLevel: protocol.Encryption1RTT,
+					hdr:             hdr,
+					data:            []byte{0x1}, // PING frame
 				}, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
-				tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
-				tracer.EXPECT().ReceivedPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any())
-				Expect(sess.handlePacketImpl(packet)).To(BeTrue())
+				tracer.EXPECT().DroppedPacket(logging.PacketType1RTT, packet.Size(), logging.PacketDropUnexpectedPacket)
+				Expect(sess.handlePacketImpl(packet)).To(BeFalse())
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("answers probes for the preferred address if active migration is disabled", func() {
+				sess.config.DisableActiveMigration = true
+				origConn := NewMockPacketConn(mockCtrl)
+				origConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				sess.rcvConn = &basicConn{PacketConn: origConn}
+				preferredConn := NewMockPacketConn(mockCtrl)
+				preferredConn.EXPECT().LocalAddr().Return(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4321}).AnyTimes()
+				rcvConn = &basicConn{PacketConn: preferredConn}
+				var frames []ackhandler.Frame
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+					frames = fs
+					return &packedPacket{
+						buffer:         getPacketBuffer(),
+						packetContents: &packetContents{header: &wire.ExtendedHeader{PacketNumber: 10}, frames: fs},
+					}, nil
+				})
+				sph.EXPECT().SentPacket(gomock.Any())
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				preferredConn.EXPECT().WriteTo(gomock.Any(), remoteAddr)
+				receivePacket(10, remoteAddr, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+			})
+
+			It("keeps using the current peer address if validation fails", func() {
+				mconn.EXPECT().WithRemoteAddr(newRemoteAddr, nil).Return(newConn)
+				receivePacket(10, newRemoteAddr, &wire.PingFrame{})
+				Expect(sess.probingPath).ToNot(BeNil())
+				Expect(sess.maybeSendPathProbe(time.Now().Add(time.Hour))).To(Succeed())
+				Expect(sess.probingPath).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
 			})
 		})
 
@@ -1258,7 +1848,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1283,6 +1873,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
 			sph.EXPECT().SentPacket(gomock.Any())
 			sess.sentPacketHandler = sph
 			runSession()
@@ -1291,7 +1882,29 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackPacket().Return(nil, nil).AnyTimes()
 			sent := make(chan struct{})
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
+			tracer.EXPECT().SentPacket(p.header, p.buffer.Len(), nil, []logging.Frame{})
+			sess.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+		})
+
+		It("sends packets with the ECN marking chosen by the sent packet handler", func() {
+			sess.handshakeConfirmed = true
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) { p.ECN = protocol.ECT0 })
+			sess.sentPacketHandler = sph
+			runSession()
+			p := getPacket(1)
+			packer.EXPECT().PackPacket().Return(p, nil)
+			packer.EXPECT().PackPacket().Return(nil, nil).AnyTimes()
+			sent := make(chan struct{})
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECT0).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
 			tracer.EXPECT().SentPacket(p.header, p.buffer.Len(), nil, []logging.Frame{})
 			sess.scheduleSending()
 			Eventually(sent).Should(BeClosed())
@@ -1326,6 +1939,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
 			sph.EXPECT().SentPacket(gomock.Any())
 			sess.sentPacketHandler = sph
 			fc := mocks.NewMockConnectionFlowController(mockCtrl)
@@ -1337,7 +1951,7 @@ var _ = Describe("Session", func() {
 			sess.connFlowController = fc
 			runSession()
 			sent := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 			tracer.EXPECT().SentPacket(p.header, p.length, nil, []logging.Frame{})
 			sess.scheduleSending()
 			Eventually(sent).Should(BeClosed())
@@ -1393,7 +2007,7 @@ var _ = Describe("Session", func() {
 					sess.sentPacketHandler = sph
 					runSession()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 					tracer.EXPECT().SentPacket(p.header, p.length, gomock.Any(), gomock.Any())
 					sess.scheduleSending()
 					Eventually(sent).Should(BeClosed())
@@ -1414,7 +2028,7 @@ var _ = Describe("Session", func() {
 					sess.sentPacketHandler = sph
 					runSession()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 					tracer.EXPECT().SentPacket(p.header, p.length, gomock.Any(), gomock.Any())
 					sess.scheduleSending()
 					Eventually(sent).Should(BeClosed())
@@ -1426,6 +2040,143 @@ var _ = Describe("Session", func() {
 		}
 	})
 
+	Context("sending packets using GSO", func() {
+		var (
+			sph     *mockackhandler.MockSentPacketHandler
+			sender  *MockSender
+			gsoConn *MockSendConn
+		)
+
+		appendPacket := func(pn protocol.PacketNumber, length protocol.ByteCount) func(*packetBuffer) (*packedPacket, error) {
+			return func(buffer *packetBuffer) (*packedPacket, error) {
+				buffer.Data = append(buffer.Data, make([]byte, length)...)
+				return &packedPacket{
+					buffer: buffer,
+					packetContents: &packetContents{
+						header: &wire.ExtendedHeader{PacketNumber: pn},
+						length: length,
+					},
+				}, nil
+			}
+		}
+
+		BeforeEach(func() {
+			gsoConn = NewMockSendConn(mockCtrl)
+			gsoConn.EXPECT().capabilities().Return(connCapabilities{GSO: true}).AnyTimes()
+			gsoConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+			gsoConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+			sess.conn = gsoConn
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour)).AnyTimes()
+			sess.handshakeConfirmed = true
+			sess.sentPacketHandler = sph
+			sender = NewMockSender(mockCtrl)
+			sender.EXPECT().Run()
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sess.sendQueue = sender
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1000)).AnyTimes()
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+		})
+
+		AfterEach(func() {
+			// make the go routine return
+			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			gsoConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			sender.EXPECT().Close()
+			sess.shutdown()
+			Eventually(sess.Context().Done()).Should(BeClosed())
+		})
+
+		runSession := func() {
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
+				sess.run()
+			}()
+		}
+
+		It("sends multiple packets in a single batch", func() {
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().ECNMode().Return(protocol.ECNNon).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any()).Times(4)
+			gomock.InOrder(
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(10, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(11, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(12, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(13, 500)),
+				packer.EXPECT().AppendPacket(gomock.Any()).AnyTimes(),
+			)
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), uint16(1000), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+				Expect(p.Data).To(HaveLen(3500))
+				close(sent)
+			})
+			runSession()
+			sess.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			time.Sleep(50 * time.Millisecond) // make sure that no other packets are sent
+		})
+
+		It("stops appending packets when the pacer doesn't allow sending more", func() {
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().ECNMode().Return(protocol.ECNNon).AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any()).Times(2)
+			gomock.InOrder(
+				sph.EXPECT().HasPacingBudget().Return(true).Times(2),
+				sph.EXPECT().HasPacingBudget().Return(false).Times(2),
+			)
+			packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(10, 1000))
+			packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(11, 1000))
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), uint16(1000), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+				Expect(p.Data).To(HaveLen(2000))
+				close(sent)
+			})
+			runSession()
+			sess.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			time.Sleep(50 * time.Millisecond) // make sure that no other packets are sent
+		})
+
+		It("doesn't put packets with different ECN markings into the same batch", func() {
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited().AnyTimes()
+			gomock.InOrder(
+				sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) { p.ECN = protocol.ECT0 }),
+				sph.EXPECT().SentPacket(gomock.Any()),
+			)
+			sph.EXPECT().ECNMode().Return(protocol.ECNNon).AnyTimes()
+			gomock.InOrder(
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(10, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(11, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any()).AnyTimes(),
+			)
+			sent := make(chan struct{})
+			gomock.InOrder(
+				// A batch consisting of a single packet is sent without GSO.
+				sender.EXPECT().Send(gomock.Any(), uint16(0), protocol.ECT0).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+					Expect(p.Data).To(HaveLen(1000))
+				}),
+				sender.EXPECT().Send(gomock.Any(), uint16(0), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+					Expect(p.Data).To(HaveLen(1000))
+					close(sent)
+				}),
+			)
+			runSession()
+			sess.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+		})
+	})
+
 	Context("packet pacing", func() {
 		var (
 			sph    *mockackhandler.MockSentPacketHandler
@@ -1450,7 +2201,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1467,7 +2218,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackPacket().Return(getPacket(10), nil)
 			packer.EXPECT().PackPacket().Return(getPacket(11), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).Times(2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1481,10 +2232,11 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SentPacket(gomock.Any())
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).Times(2)
+			sph.EXPECT().OnApplicationLimited()
 			packer.EXPECT().PackPacket().Return(getPacket(10), nil)
 			packer.EXPECT().PackPacket().Return(nil, nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1501,7 +2253,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny)
 			packer.EXPECT().MaybePackAckPacket(gomock.Any()).Return(getPacket(10), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1520,7 +2272,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAck)
 			packer.EXPECT().PackPacket().Return(getPacket(100), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1547,7 +2299,7 @@ var _ = Describe("Session", func() {
 			)
 			written := make(chan struct{}, 2)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} }).Times(2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} }).Times(2)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1570,7 +2322,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackPacket().Return(getPacket(1002), nil)
 			written := make(chan struct{}, 3)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} }).Times(3)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} }).Times(3)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1597,9 +2349,10 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SentPacket(gomock.Any())
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			packer.EXPECT().PackPacket().Return(getPacket(1000), nil)
 			packer.EXPECT().PackPacket().Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { close(written) })
 			available <- struct{}{}
 			Eventually(written).Should(BeClosed())
 		})
@@ -1620,9 +2373,10 @@ var _ = Describe("Session", func() {
 			})
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			packer.EXPECT().PackPacket().Return(getPacket(1000), nil)
 			packer.EXPECT().PackPacket().Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { close(written) })
 
 			sess.scheduleSending()
 			time.Sleep(scaleDuration(50 * time.Millisecond))
@@ -1638,7 +2392,7 @@ var _ = Describe("Session", func() {
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock()
 			sender.EXPECT().WouldBlock().Return(true).Times(2)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1654,10 +2408,11 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SentPacket(gomock.Any())
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			sender.EXPECT().WouldBlock().AnyTimes()
 			packer.EXPECT().PackPacket().Return(getPacket(1001), nil)
 			packer.EXPECT().PackPacket().Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			available <- struct{}{}
 			Eventually(written).Should(Receive())
 
@@ -1669,6 +2424,7 @@ var _ = Describe("Session", func() {
 		It("doesn't set a pacing timer when there is no data to send", func() {
 			sph.EXPECT().HasPacingBudget().Return(true)
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			sender.EXPECT().WouldBlock().AnyTimes()
 			packer.EXPECT().PackPacket()
 			// don't EXPECT any calls to mconn.Write()
@@ -1691,7 +2447,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendNone)
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			gomock.InOrder(
 				mtuDiscoverer.EXPECT().NextProbeTime(),
 				mtuDiscoverer.EXPECT().ShouldSendProbe(gomock.Any()).Return(true),
@@ -1727,7 +2483,7 @@ var _ = Describe("Session", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sender.EXPECT().Close()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
@@ -1741,6 +2497,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().TimeUntilSend().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			sph.EXPECT().SentPacket(gomock.Any())
 			sess.sentPacketHandler = sph
 			packer.EXPECT().PackPacket().Return(getPacket(1), nil)
@@ -1755,7 +2512,7 @@ var _ = Describe("Session", func() {
 			time.Sleep(50 * time.Millisecond)
 			// only EXPECT calls after scheduleSending is called
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(*packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			sess.scheduleSending()
 			Eventually(written).Should(BeClosed())
@@ -1768,6 +2525,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().OnApplicationLimited()
 			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
 				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(1234)))
 			})
@@ -1779,7 +2537,7 @@ var _ = Describe("Session", func() {
 			sess.receivedPacketHandler = rph
 
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(*packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			go func() {
 				defer GinkgoRecover()
@@ -1826,6 +2584,7 @@ var _ = Describe("Session", func() {
 
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+		sph.EXPECT().OnApplicationLimited().AnyTimes()
 		sph.EXPECT().TimeUntilSend().Return(time.Now()).AnyTimes()
 		gomock.InOrder(
 			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
@@ -1849,7 +2608,7 @@ var _ = Describe("Session", func() {
 		)
 
 		sent := make(chan struct{})
-		mconn.EXPECT().Write([]byte("foobar")).Do(func([]byte) { close(sent) })
+		mconn.EXPECT().Write([]byte("foobar"), gomock.Any(), protocol.ECNNon).Do(func([]byte, uint16, protocol.ECN) { close(sent) })
 
 		go func() {
 			defer GinkgoRecover()
@@ -1865,7 +2624,7 @@ var _ = Describe("Session", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		sess.shutdown()
@@ -1900,7 +2659,7 @@ var _ = Describe("Session", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		sess.shutdown()
@@ -1945,7 +2704,7 @@ var _ = Describe("Session", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		sess.shutdown()
@@ -1967,7 +2726,7 @@ var _ = Describe("Session", func() {
 		}()
 		handshakeCtx := sess.HandshakeComplete()
 		Consistently(handshakeCtx.Done()).ShouldNot(BeClosed())
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		sess.closeLocal(errors.New("handshake error"))
 		Consistently(handshakeCtx.Done()).ShouldNot(BeClosed())
 		Eventually(sess.Context().Done()).Should(BeClosed())
@@ -1979,9 +2738,10 @@ var _ = Describe("Session", func() {
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
 		sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+		sph.EXPECT().OnApplicationLimited().AnyTimes()
 		sph.EXPECT().SetHandshakeConfirmed()
 		sph.EXPECT().SentPacket(gomock.Any())
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		sess.sentPacketHandler = sph
 		done := make(chan struct{})
@@ -2004,7 +2764,7 @@ var _ = Describe("Session", func() {
 			cryptoSetup.EXPECT().RunHandshake()
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
 			cryptoSetup.EXPECT().GetSessionTicket()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			close(sess.handshakeCompleteChan)
 			sess.run()
 		}()
@@ -2032,7 +2792,7 @@ var _ = Describe("Session", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		sess.shutdown()
@@ -2056,13 +2816,130 @@ var _ = Describe("Session", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		Expect(sess.CloseWithError(0x1337, testErr.Error())).To(Succeed())
 		Eventually(done).Should(BeClosed())
 	})
 
+	It("returns statistics", func() {
+		sess.rttStats.UpdateRTT(50*time.Millisecond, 0, time.Now())
+		sess.sentPacketHandler.ReceivedBytes(1000)
+		sealer := mocks.NewMockShortHeaderSealer(mockCtrl)
+		sealer.EXPECT().NumKeyUpdates().Return(uint64(3)).Times(2)
+		cryptoSetup.EXPECT().Get1RTTSealer().Return(sealer, nil).Times(2)
+		packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400)).Times(2)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
+			Expect(sess.run()).To(Succeed())
+			close(done)
+		}()
+		stats := sess.Stats()
+		Expect(stats.MinRTT).To(Equal(50 * time.Millisecond))
+		Expect(stats.LatestRTT).To(Equal(50 * time.Millisecond))
+		Expect(stats.SmoothedRTT).To(Equal(50 * time.Millisecond))
+		Expect(stats.MeanDeviation).To(Equal(25 * time.Millisecond))
+		Expect(stats.CongestionWindow).ToNot(BeZero())
+		Expect(stats.BytesReceived).To(BeEquivalentTo(1000))
+		Expect(stats.BytesSent).To(BeZero())
+		Expect(stats.MaxDatagramSize).To(BeEquivalentTo(1400))
+		Expect(stats.KeyUpdates).To(BeEquivalentTo(3))
+
+		streamManager.EXPECT().CloseWithError(gomock.Any())
+		expectReplaceWithClosed()
+		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+		cryptoSetup.EXPECT().Close()
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		tracer.EXPECT().ClosedConnection(gomock.Any())
+		tracer.EXPECT().Close()
+		sess.shutdown()
+		Eventually(done).Should(BeClosed())
+		// statistics are still available after the session was closed
+		Expect(sess.Stats().BytesReceived).To(BeEquivalentTo(1000))
+	})
+
+	Context("datagrams", func() {
+		BeforeEach(func() {
+			sess.datagramQueue = newDatagramQueue(func() {}, 2, DatagramDropNewest, utils.DefaultLogger)
+		})
+
+		It("errors when sending datagrams if datagram support is disabled", func() {
+			sess.datagramQueue = nil
+			Expect(sess.MaxDatagramSize()).To(BeZero())
+			Expect(sess.SendMessage([]byte("foobar"))).To(MatchError("datagram support disabled"))
+		})
+
+		It("errors when sending datagrams if the peer doesn't support datagrams", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: protocol.InvalidByteCount}
+			sess.updateMaxDatagramSize()
+			Expect(sess.MaxDatagramSize()).To(BeZero())
+			Expect(sess.SendMessage([]byte("foobar"))).To(MatchError("datagram support not negotiated"))
+		})
+
+		It("limits the datagram size by the peer's max_datagram_frame_size", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 100}
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			sess.updateMaxDatagramSize()
+			// 1 byte frame type, 2 bytes length
+			Expect(sess.MaxDatagramSize()).To(Equal(97))
+		})
+
+		It("limits the datagram size by the packet size", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: protocol.MaxByteCount}
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			sess.updateMaxDatagramSize()
+			// 1 byte first byte, 20 bytes connection ID, 4 bytes packet number, 16 bytes AEAD tag, 1 byte frame type, 2 bytes length
+			Expect(sess.MaxDatagramSize()).To(Equal(1400 - 1 - 20 - 4 - 16 - 1 - 2))
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1200))
+			sess.updateMaxDatagramSize()
+			Expect(sess.MaxDatagramSize()).To(Equal(1200 - 1 - 20 - 4 - 16 - 1 - 2))
+		})
+
+		It("sends multiple datagrams", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 100}
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			sess.updateMaxDatagramSize()
+			Expect(sess.SendMessages([][]byte{[]byte("foo"), []byte("bar"), []byte("baz")})).To(Succeed())
+			f, ok := sess.datagramQueue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, sess.version)
+			Expect(ok).To(BeTrue())
+			Expect(f.Frame.(*wire.DatagramFrame).Data).To(Equal([]byte("foo")))
+			f, ok = sess.datagramQueue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, sess.version)
+			Expect(ok).To(BeTrue())
+			Expect(f.Frame.(*wire.DatagramFrame).Data).To(Equal([]byte("bar")))
+			f, ok = sess.datagramQueue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, sess.version)
+			Expect(ok).To(BeTrue())
+			Expect(f.Frame.(*wire.DatagramFrame).Data).To(Equal([]byte("baz")))
+			Expect(sess.datagramQueue.HasData()).To(BeFalse())
+			Expect(sess.datagramQueue.NumDropped()).To(BeZero())
+		})
+
+		It("sends datagrams with a callback", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 100}
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			sess.updateMaxDatagramSize()
+			var acked bool
+			Expect(sess.SendMessageWithCallback([]byte("foobar"), func(a bool) { acked = a })).To(Succeed())
+			f, ok := sess.datagramQueue.PopIfFits(protocol.MaxByteCount, protocol.MaxByteCount, sess.version)
+			Expect(ok).To(BeTrue())
+			Expect(f.Frame.(*wire.DatagramFrame).Data).To(Equal([]byte("foobar")))
+			f.OnAcked(f.Frame)
+			Expect(acked).To(BeTrue())
+			Expect(sess.SendMessageWithCallback(make([]byte, 98), func(bool) {})).To(MatchError("message too large"))
+		})
+
+		It("doesn't send any datagrams if one of them is too large", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 100}
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			sess.updateMaxDatagramSize()
+			Expect(sess.SendMessages([][]byte{[]byte("foo"), make([]byte, 98)})).To(MatchError("message too large"))
+			Expect(sess.datagramQueue.HasData()).To(BeFalse())
+			Expect(sess.SendMessage(make([]byte, 97))).To(Succeed())
+		})
+	})
+
 	Context("transport parameters", func() {
 		It("processes transport parameters received from the client", func() {
 			params := &wire.TransportParameters{
@@ -2084,6 +2961,19 @@ var _ = Describe("Session", func() {
 			sess.handleTransportParameters(params)
 			Expect(sess.earlySessionReady()).To(BeClosed())
 		})
+
+		It("rejects a version_information that doesn't contain the version of the client's first Initial", func() {
+			sess.version = protocol.Version2
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version2,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+				},
+			}
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(sess.checkTransportParameters(params)).To(MatchError("expected chosen version to equal v1, is v2"))
+		})
 	})
 
 	Context("keep-alives", func() {
@@ -2117,7 +3007,7 @@ var _ = Describe("Session", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sess.shutdown()
@@ -2253,7 +3143,7 @@ var _ = Describe("Session", func() {
 			// make the go routine return
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			sess.shutdown()
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
@@ -2330,7 +3220,7 @@ var _ = Describe("Session", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sess.shutdown()
@@ -2411,6 +3301,15 @@ var _ = Describe("Session", func() {
 	It("returns the remote address", func() {
 		Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
 	})
+
+	It("refuses to migrate", func() {
+		Expect(sess.MigrateTo(context.Background(), NewMockPacketConn(mockCtrl))).To(MatchError("only clients can migrate connections"))
+	})
+
+	It("refuses to add paths", func() {
+		_, err := sess.AddPath(context.Background(), NewMockPacketConn(mockCtrl))
+		Expect(err).To(MatchError("only clients can add paths"))
+	})
 })
 
 var _ = Describe("Client Session", func() {
@@ -2453,6 +3352,7 @@ var _ = Describe("Client Session", func() {
 
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+		mconn.EXPECT().capabilities().AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
 		if tlsConf == nil {
 			tlsConf = &tls.Config{}
@@ -2484,6 +3384,83 @@ var _ = Describe("Client Session", func() {
 		sess.cryptoStreamHandler = cryptoSetup
 	})
 
+	It("refuses to add paths if multipath is disabled", func() {
+		_, err := sess.AddPath(context.Background(), NewMockPacketConn(mockCtrl))
+		Expect(err).To(MatchError("multipath not enabled"))
+	})
+
+	DescribeTable(
+		"sending the disable_active_migration transport parameter",
+		func(disableActiveMigration bool) {
+			var params *wire.TransportParameters
+			tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+			tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+			newClientSession(
+				mconn,
+				sessionRunner,
+				destConnID,
+				protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8},
+				populateClientConfig(&Config{DisableActiveMigration: disableActiveMigration}, true),
+				&tls.Config{},
+				42, // initial packet number
+				false,
+				false,
+				tracer,
+				1234,
+				utils.DefaultLogger,
+				protocol.VersionTLS,
+			)
+			Expect(params.DisableActiveMigration).To(Equal(disableActiveMigration))
+		},
+		Entry("active migration enabled", false),
+		Entry("active migration disabled", true),
+	)
+
+	Context("releasing the packet conn of a path that couldn't be added", func() {
+		var (
+			origMultiplexer multiplexer
+			manager         *MockPacketHandlerManager
+		)
+
+		BeforeEach(func() {
+			getMultiplexer() // make the sync.Once execute
+			mockMultiplexer := NewMockMultiplexer(mockCtrl)
+			origMultiplexer = connMuxer
+			connMuxer = mockMultiplexer
+			manager = NewMockPacketHandlerManager(mockCtrl)
+			mockMultiplexer.EXPECT().AddConn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(manager, nil)
+		})
+
+		JustBeforeEach(func() {
+			sess.config.EnableMultipath = true
+		})
+
+		AfterEach(func() {
+			connMuxer = origMultiplexer
+		})
+
+		It("releases the packet conn when the context is canceled", func() {
+			manager.EXPECT().CloseIfUnused()
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := sess.AddPath(ctx, NewMockPacketConn(mockCtrl))
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("releases the packet conn when path validation fails", func() {
+			go func() {
+				defer GinkgoRecover()
+				p := <-sess.pathAdditions
+				p.Done(errors.New("path validation failed"))
+			}()
+			manager.EXPECT().CloseIfUnused()
+			_, err := sess.AddPath(context.Background(), NewMockPacketConn(mockCtrl))
+			Expect(err).To(MatchError("path validation failed"))
+		})
+	})
+
 	It("changes the connection ID when receiving the first packet from the server", func() {
 		unpacker := NewMockUnpacker(mockCtrl)
 		unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ time.Time, data []byte) (*unpackedPacket, error) {
@@ -2517,13 +3494,73 @@ var _ = Describe("Client Session", func() {
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		expectReplaceWithClosed()
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		sess.shutdown()
 		Eventually(sess.Context().Done()).Should(BeClosed())
 	})
 
+	Context("compatible version negotiation", func() {
+		getInitial := func(v protocol.VersionNumber) *receivedPacket {
+			return getPacket(&wire.ExtendedHeader{
+				Header: wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  destConnID,
+					DestConnectionID: srcConnID,
+					Length:           2 + 6,
+					Version:          v,
+				},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}, []byte("foobar"))
+		}
+
+		It("switches to a compatible version when receiving the first Initial", func() {
+			unpacker := NewMockUnpacker(mockCtrl)
+			unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ time.Time, data []byte) (*unpackedPacket, error) {
+				return &unpackedPacket{
+					encryptionLevel: protocol.EncryptionInitial,
+					hdr:             &wire.ExtendedHeader{Header: *hdr},
+					data:            []byte{0}, // one PADDING frame
+				}, nil
+			})
+			sess.unpacker = unpacker
+			p := getInitial(protocol.Version2)
+			gomock.InOrder(
+				cryptoSetup.EXPECT().ChangeVersion(protocol.Version2),
+				packer.EXPECT().SetVersion(protocol.Version2),
+			)
+			tracer.EXPECT().ReceivedPacket(gomock.Any(), p.Size(), []logging.Frame{})
+			Expect(sess.handlePacketImpl(p)).To(BeTrue())
+			Expect(sess.version).To(Equal(protocol.Version2))
+			Expect(sess.initialVersion).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch to an incompatible version", func() {
+			p := getInitial(protocol.VersionDraft29)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(sess.handlePacketImpl(p)).To(BeFalse())
+			Expect(sess.version).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch to a version that it doesn't support", func() {
+			sess.config.Versions = []protocol.VersionNumber{protocol.Version1}
+			p := getInitial(protocol.Version2)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(sess.handlePacketImpl(p)).To(BeFalse())
+			Expect(sess.version).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch the version after receiving the first packet", func() {
+			sess.receivedFirstPacket = true
+			p := getInitial(protocol.Version2)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(sess.handlePacketImpl(p)).To(BeFalse())
+			Expect(sess.version).To(Equal(protocol.Version1))
+		})
+	})
+
 	It("continues accepting Long Header packets after using a new connection ID", func() {
 		unpacker := NewMockUnpacker(mockCtrl)
 		sess.unpacker = unpacker
@@ -2573,6 +3610,242 @@ var _ = Describe("Client Session", func() {
 		Expect(sess.handleAckFrame(ack, protocol.Encryption1RTT)).To(Succeed())
 	})
 
+	Context("connection migration", func() {
+		var (
+			newConn   *MockSendConn
+			newRunner *MockSessionRunner
+			sph       *mockackhandler.MockSentPacketHandler
+			p         *path
+		)
+		newConnID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+		newResetToken := protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+		newLocalAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+
+		BeforeEach(func() {
+			quicConf.DisablePathMTUDiscovery = true
+		})
+
+		JustBeforeEach(func() {
+			sess.handshakeConfirmed = true
+			sess.peerParams = &wire.TransportParameters{}
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sess.sentPacketHandler = sph
+			Expect(sess.connIDManager.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        newConnID,
+				StatelessResetToken: newResetToken,
+			})).To(Succeed())
+			newConn = NewMockSendConn(mockCtrl)
+			newConn.EXPECT().LocalAddr().Return(newLocalAddr).AnyTimes()
+			newConn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+			newRunner = NewMockSessionRunner(mockCtrl)
+			p = newPath(context.Background(), newConn, newRunner)
+		})
+
+		sendProbe := func() [8]byte {
+			var challenge [8]byte
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), protocol.MaxByteCount).DoAndReturn(func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+				challenge = frames[0].Frame.(*wire.PathChallengeFrame).Data
+				return &packedPacket{
+					buffer: getPacketBuffer(),
+					packetContents: &packetContents{
+						header: &wire.ExtendedHeader{PacketNumber: 10},
+						frames: frames,
+					},
+				}, nil
+			})
+			sph.EXPECT().SentPacket(gomock.Any())
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			newConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+			return challenge
+		}
+
+		Context("releasing the packet conn", func() {
+			var (
+				origMultiplexer multiplexer
+				manager         *MockPacketHandlerManager
+			)
+
+			BeforeEach(func() {
+				getMultiplexer() // make the sync.Once execute
+				mockMultiplexer := NewMockMultiplexer(mockCtrl)
+				origMultiplexer = connMuxer
+				connMuxer = mockMultiplexer
+				manager = NewMockPacketHandlerManager(mockCtrl)
+				mockMultiplexer.EXPECT().AddConn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(manager, nil)
+			})
+
+			AfterEach(func() {
+				connMuxer = origMultiplexer
+			})
+
+			It("releases the packet conn when the context is canceled", func() {
+				manager.EXPECT().CloseIfUnused()
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				Expect(sess.MigrateTo(ctx, NewMockPacketConn(mockCtrl))).To(MatchError(context.Canceled))
+			})
+
+			It("releases the packet conn when path validation fails", func() {
+				go func() {
+					defer GinkgoRecover()
+					p := <-sess.pathProbes
+					p.Done(errors.New("path validation failed"))
+				}()
+				manager.EXPECT().CloseIfUnused()
+				Expect(sess.MigrateTo(context.Background(), NewMockPacketConn(mockCtrl))).To(MatchError("path validation failed"))
+			})
+		})
+
+		It("refuses to migrate before the handshake is confirmed", func() {
+			sess.handshakeConfirmed = false
+			sess.startProbingPath(p)
+			Expect(p.result).To(Receive(MatchError("can't migrate before the handshake is confirmed")))
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("refuses to migrate if the peer disabled active migration", func() {
+			sess.peerParams.DisableActiveMigration = true
+			sess.startProbingPath(p)
+			Expect(p.result).To(Receive(MatchError("the peer disabled active migration")))
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("refuses to migrate if there's no unused connection ID", func() {
+			_, ok := sess.connIDManager.GetConnIDForPath()
+			Expect(ok).To(BeTrue())
+			sess.startProbingPath(p)
+			Expect(p.result).To(Receive(MatchError("no unused connection ID available")))
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("refuses to migrate to a packet conn used by another session", func() {
+			newRunner.EXPECT().Add(srcConnID, sess).Return(false)
+			sess.startProbingPath(p)
+			Expect(p.result).To(Receive(MatchError("packet conn already used by another session")))
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("validates the new path and migrates to it", func() {
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startProbingPath(p)
+			Expect(sess.probingPath).To(Equal(p))
+			Expect(sess.runners.Has(newRunner)).To(BeTrue())
+			challenge := sendProbe()
+			// the connection ID is only used on the new path
+			Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+
+			sendQueue := NewMockSender(mockCtrl)
+			sess.sendQueue = sendQueue
+			sendQueueClosed := make(chan struct{})
+			sendQueue.EXPECT().Close().Do(func() { close(sendQueueClosed) })
+			sessionRunner.EXPECT().AddResetToken(newResetToken, sess)
+			newRunner.EXPECT().AddResetToken(newResetToken, sess)
+			sph.EXPECT().MigratedPath(protocol.ByteCount(protocol.InitialPacketSizeIPv6))
+			packer.EXPECT().SetMaxPacketSize(protocol.ByteCount(protocol.InitialPacketSizeIPv6))
+			Expect(sess.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, srcConnID, nil)).To(Succeed())
+			// the old send queue is closed asynchronously
+			Eventually(sendQueueClosed).Should(BeClosed())
+			Expect(p.result).To(Receive(BeNil()))
+			Expect(sess.probingPath).To(BeNil())
+			Expect(sess.LocalAddr()).To(Equal(newLocalAddr))
+			Expect(sess.connIDManager.Get()).To(Equal(newConnID))
+			sess.sendQueue.Close()
+		})
+
+		It("abandons the path when validation times out", func() {
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startProbingPath(p)
+			sendProbe()
+			newRunner.EXPECT().Remove(srcConnID)
+			Expect(sess.maybeSendPathProbe(time.Now().Add(time.Hour))).To(Succeed())
+			Expect(p.result).To(Receive(MatchError("path validation timed out")))
+			Expect(sess.probingPath).To(BeNil())
+			Expect(sess.runners.Has(newRunner)).To(BeFalse())
+			Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+			frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+			Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+		})
+
+		It("abandons the path when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			p.ctx = ctx
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startProbingPath(p)
+			sendProbe()
+			cancel()
+			newRunner.EXPECT().Remove(srcConnID)
+			Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+			Expect(p.result).To(Receive(Equal(context.Canceled)))
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("migrates to the server's preferred address when the handshake is confirmed", func() {
+			preferredAddr := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1234}
+			sess.handshakeConfirmed = false
+			sess.peerParams.PreferredAddress = &wire.PreferredAddress{
+				IPv4:         net.IPv4zero.To4(),
+				IPv6:         preferredAddr.IP,
+				IPv6Port:     1234,
+				ConnectionID: newConnID,
+			}
+			// migrating to the preferred address is allowed even if active migration is disabled
+			sess.peerParams.DisableActiveMigration = true
+			mconn.EXPECT().WithRemoteAddr(preferredAddr, nil).Return(newConn)
+			sph.EXPECT().SetHandshakeConfirmed()
+			cryptoSetup.EXPECT().SetHandshakeConfirmed()
+			sess.handleHandshakeConfirmed()
+			Expect(sess.probingPath).ToNot(BeNil())
+			Expect(sess.probingPath.conn).To(Equal(newConn))
+			challenge := sendProbe()
+
+			sendQueue := NewMockSender(mockCtrl)
+			sess.sendQueue = sendQueue
+			sendQueueClosed := make(chan struct{})
+			sendQueue.EXPECT().Close().Do(func() { close(sendQueueClosed) })
+			sessionRunner.EXPECT().AddResetToken(newResetToken, sess)
+			sph.EXPECT().MigratedPath(protocol.ByteCount(protocol.InitialPacketSizeIPv6))
+			packer.EXPECT().SetMaxPacketSize(protocol.ByteCount(protocol.InitialPacketSizeIPv6))
+			Expect(sess.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, srcConnID, nil)).To(Succeed())
+			// the old send queue is closed asynchronously
+			Eventually(sendQueueClosed).Should(BeClosed())
+			Expect(sess.probingPath).To(BeNil())
+			Expect(sess.conn).To(Equal(newConn))
+			Expect(sess.connIDManager.Get()).To(Equal(newConnID))
+			sess.sendQueue.Close()
+		})
+
+		It("doesn't migrate to the preferred address if it doesn't contain an address of the same address family", func() {
+			sess.handshakeConfirmed = false
+			sess.peerParams.PreferredAddress = &wire.PreferredAddress{
+				IPv4:         net.IPv4(192, 168, 0, 1).To4(),
+				IPv4Port:     1234,
+				IPv6:         net.IPv6zero,
+				ConnectionID: newConnID,
+			}
+			sph.EXPECT().SetHandshakeConfirmed()
+			cryptoSetup.EXPECT().SetHandshakeConfirmed()
+			sess.handleHandshakeConfirmed()
+			Expect(sess.probingPath).To(BeNil())
+		})
+
+		It("retransmits PATH_CHALLENGEs", func() {
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startProbingPath(p)
+			first := sendProbe()
+			// no retransmission before the probe timer fires
+			Expect(sess.maybeSendPathProbe(time.Now())).To(Succeed())
+			p.nextProbe = time.Now()
+			second := sendProbe()
+			Expect(second).ToNot(Equal(first))
+			Expect(p.HasChallenge(first)).To(BeTrue())
+			Expect(p.HasChallenge(second)).To(BeTrue())
+		})
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2762,7 +4035,7 @@ var _ = Describe("Client Session", func() {
 					packer.EXPECT().PackConnectionClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil).MaxTimes(1)
 				}
 				cryptoSetup.EXPECT().Close()
-				mconn.EXPECT().Write(gomock.Any())
+				mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 				gomock.InOrder(
 					tracer.EXPECT().ClosedConnection(gomock.Any()),
 					tracer.EXPECT().Close(),
@@ -2898,6 +4171,25 @@ var _ = Describe("Client Session", func() {
 				ErrorMessage: "expected original_destination_connection_id to equal deadbeef, is decafbad",
 			})))
 		})
+
+		It("errors if the server's chosen version doesn't match the version in use", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version2,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version2},
+				},
+			}
+			expectClose(false)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "expected chosen version to equal v1, is v2",
+			})))
+		})
 	})
 
 	Context("handling potentially injected packets", func() {
@@ -21,10 +21,21 @@ func (deadlineError) Unwrap() error   { return os.ErrDeadlineExceeded }
 
 var errDeadline net.Error = &deadlineError{}
 
+const maxStreamUrgency = 7
+
+// The streamPriority determines the order in which data is sent on the streams of a session.
+type streamPriority struct {
+	urgency     uint8
+	incremental bool
+}
+
+// The default priority, as defined in RFC 9218: an urgency of 3, non-incremental.
+var defaultStreamPriority = streamPriority{urgency: 3}
+
 // The streamSender is notified by the stream about various events.
 type streamSender interface {
 	queueControlFrame(wire.Frame)
-	onHasStreamData(protocol.StreamID)
+	onHasStreamData(protocol.StreamID, streamPriority)
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -40,8 +51,8 @@ func (s *uniStreamSender) queueControlFrame(f wire.Frame) {
 	s.streamSender.queueControlFrame(f)
 }
 
-func (s *uniStreamSender) onHasStreamData(id protocol.StreamID) {
-	s.streamSender.onHasStreamData(id)
+func (s *uniStreamSender) onHasStreamData(id protocol.StreamID, prio streamPriority) {
+	s.streamSender.onHasStreamData(id, prio)
 }
 
 func (s *uniStreamSender) onStreamCompleted(protocol.StreamID) {
@@ -35,6 +35,15 @@ func (s *singleOriginTokenStore) Len() int {
 	return s.len
 }
 
+// tokensInOrder returns the tokens, starting with the one that was added first.
+func (s *singleOriginTokenStore) tokensInOrder() []*ClientToken {
+	tokens := make([]*ClientToken, 0, s.len)
+	for i := s.len; i > 0; i-- {
+		tokens = append(tokens, s.tokens[s.index(s.p-i)])
+	}
+	return tokens
+}
+
 func (s *singleOriginTokenStore) index(i int) int {
 	mod := len(s.tokens)
 	return (i + mod) % mod